@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -14,11 +18,21 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/auth"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/compress"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/config"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/jsoncase"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/policyenforcer"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/server"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/shutdown"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/startup"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/timeout"
 )
 
 func main() {
@@ -34,7 +48,7 @@ func main() {
 	}
 
 	// Initialize logger
-	logger := initLogger(cfg.Logging)
+	logger, logLevel := initLogger(cfg.Logging)
 	defer logger.Sync()
 
 	logger.Info("starting Policy Enforcer",
@@ -43,14 +57,42 @@ func main() {
 	)
 
 	// Initialize eFLINT manager
+	excludedPorts, err := eflint.ParsePortRanges(cfg.EFlint.ExcludedPorts)
+	if err != nil {
+		logger.Fatal("invalid eflint.excluded_ports", zap.Error(err))
+	}
 	eflintConfig := &eflint.ManagerConfig{
 		EflintServerPath:  cfg.EFlint.ServerPath,
-		MinPort:           1025,
-		MaxPort:           65535,
+		ServerArgs:        cfg.EFlint.ServerArgs,
+		MinPort:           cfg.EFlint.MinPort,
+		MaxPort:           cfg.EFlint.MaxPort,
+		ExcludedPorts:     excludedPorts,
+		ModelURLHosts:     cfg.EFlint.ModelURLHosts,
 		StartupDelay:      3 * time.Second,
 		ConnectionTimeout: cfg.EFlint.Timeout,
+		DialTimeout:       cfg.EFlint.DialTimeout,
+		CommandTimeout:    cfg.EFlint.CommandTimeout,
+		MaxResponseSize:   cfg.EFlint.MaxResponseSize,
+		PoolSize:          cfg.EFlint.PoolSize,
+		PoolMaxOpen:       cfg.EFlint.PoolMaxOpen,
+		PoolIdleTimeout:   cfg.EFlint.PoolIdleTimeout,
+		HistorySize:       cfg.EFlint.HistorySize,
+		RedactPhrases:     cfg.EFlint.RedactPhrases,
+		ServerLogSize:     cfg.EFlint.ServerLogSize,
+		KillTimeout:       cfg.EFlint.KillTimeout,
+		PIDFile:           cfg.EFlint.PIDFile,
+		ReadyTimeout:      cfg.EFlint.ReadyTimeout,
+		ReadOnly:          cfg.EFlint.ReadOnly,
+		ReadOnlyCommands:  cfg.EFlint.ReadOnlyCommands,
 	}
 	eflintManager := eflint.NewManager(eflintConfig, logger)
+
+	// Kill eflint-server processes left behind by a previous run that did not stop them
+	if killed, err := eflintManager.CleanupOrphans(); err != nil {
+		logger.Warn("failed to clean up orphaned eFLINT processes", zap.Error(err))
+	} else if killed > 0 {
+		logger.Warn("killed orphaned eFLINT processes", zap.Int("count", killed))
+	}
 	logger.Info("eFLINT manager initialized",
 		zap.String("server_path", cfg.EFlint.ServerPath),
 	)
@@ -59,9 +101,33 @@ func main() {
 	instanceAPIHandler := eflint.NewInstanceAPIHandler(eflintManager, logger)
 
 	// Initialize eFLINT State Manager (POC for export/import)
-	stateManager := eflint.NewStateManager(eflintManager, "/tmp/eflint-states", logger)
-	stateAPIHandler := eflint.NewStateAPIHandler(stateManager, logger)
-	logger.Info("eFLINT state manager initialized (POC)")
+	var stateManager *eflint.StateManager
+	var stateAPIHandler *eflint.StateAPIHandler
+	if cfg.EFlint.StateAPIEnabled {
+		stateStore, err := eflint.NewStateStore(eflint.StateStoreConfig{
+			Type: cfg.EFlint.StateStore,
+			Dir:  cfg.EFlint.StateDir,
+			S3: eflint.S3Config{
+				Endpoint:        cfg.EFlint.StateS3.Endpoint,
+				Region:          cfg.EFlint.StateS3.Region,
+				Bucket:          cfg.EFlint.StateS3.Bucket,
+				Prefix:          cfg.EFlint.StateS3.Prefix,
+				AccessKeyID:     cfg.EFlint.StateS3.AccessKeyID,
+				SecretAccessKey: cfg.EFlint.StateS3.SecretAccessKey,
+				SessionToken:    cfg.EFlint.StateS3.SessionToken,
+				Timeout:         cfg.EFlint.StateS3.Timeout,
+			},
+		})
+		if err != nil {
+			logger.Fatal("failed to initialize eFLINT state store", zap.Error(err))
+		}
+		stateManager = eflint.NewStateManager(eflintManager, stateStore, logger)
+		stateAPIHandler = eflint.NewStateAPIHandler(stateManager, logger)
+		logger.Info("eFLINT state manager initialized (POC)",
+			zap.String("state_store", cfg.EFlint.StateStore),
+			zap.String("state_dir", cfg.EFlint.StateDir),
+		)
+	}
 
 	// Initialize RabbitMQ consumer
 	// amqpURL := fmt.Sprintf("amqp://%s:%s@%s:%d/",
@@ -72,9 +138,15 @@ func main() {
 	// )
 
 	// consumer, err := rabbitmq.NewConsumer(
+	// 	context.Background(),
 	// 	amqpURL,
 	// 	cfg.RabbitMQ.Queue,
 	// 	cfg.RabbitMQ.PrefetchCount,
+	// 	rabbitmq.ConnectConfig{
+	// 		ReconnectDelay:    cfg.RabbitMQ.ReconnectDelay,
+	// 		MaxReconnectDelay: cfg.RabbitMQ.MaxReconnectDelay,
+	// 		Timeout:           cfg.RabbitMQ.ConnectTimeout,
+	// 	},
 	// 	logger,
 	// )
 	// if err != nil {
@@ -83,13 +155,43 @@ func main() {
 	// defer consumer.Close()
 
 	// // Initialize handler
-	// reqHandler := handler.NewHandler(eflintClient, policyCache, logger)
+	// reqHandler := handler.NewHandler(eflintManager, &handler.HandlerConfig{
+	// 	MaxRedeliveries: cfg.RabbitMQ.MaxRedeliveries,
+	// }, logger)
 
 	// Initialize HTTP server
 	e := echo.New()
 	e.HideBanner = true
+
+	// Render errors returned by handlers (apperr.Error, echo.HTTPError, others as 500) uniformly
+	e.HTTPErrorHandler = apperr.HTTPErrorHandler(logger)
+
+	// Tag every request with an X-Request-ID that is echoed back and carried by downstream logs
+	e.Use(requestid.Middleware(logger))
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	if cfg.Server.BodyLimit != "" {
+		e.Use(middleware.BodyLimit(cfg.Server.BodyLimit))
+	}
+
+	// gzip large responses (facts, state graphs) for clients that accept it
+	if cfg.Server.Compression.Enabled {
+		e.Use(compress.Middleware(compress.Config{
+			Level:     cfg.Server.Compression.Level,
+			MinLength: cfg.Server.Compression.MinLength,
+		}))
+	}
+
+	// Let callers cap the wait per request via ?timeout= or the X-Timeout header
+	// (bound reloadable with server.max_request_timeout)
+	requestTimeout := timeout.NewLimit(cfg.Server.MaxRequestTimeout)
+	e.Use(timeout.Middleware(timeout.Config{Limit: requestTimeout}))
+
+	// Serve camelCase JSON keys to clients asking for them (Accept: application/json; case=camel or ?case=camel)
+	// Only the API's own field names are converted, never keys of embedded data
+	jsonFields := jsoncase.FieldsOf(append(append(policyenforcer.APITypes(), eflint.APITypes()...),
+		cfg, config.ReloadResult{})...).Add("http_status")
+	e.Use(jsoncase.Middleware(jsonFields))
 
 	// Define HTTP endpoints
 	e.GET("/", func(c echo.Context) error {
@@ -100,33 +202,235 @@ func main() {
 		return c.JSON(http.StatusOK, struct{ Status string }{Status: "OK"})
 	})
 
+	// Readiness requires the eFLINT server to actually answer, not just be alive, and
+	// stays off after a (re)start until the new model answers (eflint.ready_timeout)
+	e.GET("/health/ready", func(c echo.Context) error {
+		if err := eflintManager.Ready(c.Request().Context()); err != nil {
+			return c.JSON(http.StatusServiceUnavailable, struct {
+				Status string
+				Error  string
+			}{Status: "NOT READY", Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, struct{ Status string }{Status: "READY"})
+	})
+
+	// Require a JWT bearer token on the API groups if configured; /health stays open
+	var apiMiddleware []echo.MiddlewareFunc
+	var authenticator *auth.Authenticator // nil unless auth is enabled; also used by the gRPC server
+	if cfg.Auth.Enabled {
+		authenticator, err = auth.NewAuthenticator(auth.Config{
+			Secret:         cfg.Auth.Secret,
+			JWKSURL:        cfg.Auth.JWKSURL,
+			Issuer:         cfg.Auth.Issuer,
+			Audience:       cfg.Auth.Audience,
+			RequiredClaims: cfg.Auth.RequiredClaims,
+			Leeway:         cfg.Auth.Leeway,
+			JWKSRefresh:    cfg.Auth.JWKSRefresh,
+		}, logger)
+		if err != nil {
+			logger.Fatal("failed to initialize authentication", zap.Error(err))
+		}
+		apiMiddleware = append(apiMiddleware, authenticator.Middleware())
+		logger.Info("JWT authentication enabled")
+	}
+
+	// Debugging endpoints expose internals, so each must be enabled explicitly
+	debugGroup := e.Group("/debug", apiMiddleware...)
+
+	// Effective configuration with secrets redacted, for debugging deployments
+	if cfg.Debug.ConfigEndpoint {
+		debugGroup.GET("/config", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, cfg.Redacted())
+		})
+		logger.Warn("debug config endpoint enabled", zap.String("path", "/debug/config"))
+	}
+
+	// Runtime metrics (e.g. clause cache hits and misses) in expvar format
+	if cfg.Debug.Metrics {
+		debugGroup.GET("/vars", echo.WrapHandler(expvar.Handler()))
+		logger.Warn("debug metrics endpoint enabled", zap.String("path", "/debug/vars"))
+	}
+
+	// Runtime profiles for performance investigation (go tool pprof)
+	if cfg.Debug.Pprof {
+		debugGroup.GET("/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+		debugGroup.GET("/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+		debugGroup.GET("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+		debugGroup.POST("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+		debugGroup.GET("/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+		debugGroup.GET("/pprof/*", echo.WrapHandler(http.HandlerFunc(pprof.Index))) // Index and named profiles (heap, goroutine, ...)
+		logger.Warn("pprof endpoints enabled", zap.String("path", "/debug/pprof/"))
+	}
+
 	// Register eFLINT Instance API routes
-	eflintGroup := e.Group("/eflint")
+	eflintGroup := e.Group("/eflint", apiMiddleware...)
 	instanceAPIHandler.RegisterRoutes(eflintGroup)
 
 	// Register eFLINT State Management API routes (POC)
-	stateGroup := e.Group("/eflint/state")
-	stateAPIHandler.RegisterRoutes(stateGroup)
+	if stateAPIHandler != nil {
+		stateGroup := e.Group("/eflint/state", apiMiddleware...)
+		stateAPIHandler.RegisterRoutes(stateGroup)
+	}
 
-	// Create the eFLINT reasoner (implements the Reasoner interface)
-	eflintReasoner := reasoner.NewEflintReasoner(eflintManager, logger)
+	// Checkpoints record their facts (for /policy-enforcer/changes-since) only if enabled
+	var checkpoints *eflint.StateManager
+	if cfg.EFlint.StateFacts {
+		checkpoints = stateManager
+	}
+
+	// Create the configured reasoner (eFLINT by default; implements the Reasoner interface)
+	policyReasoner, err := reasoner.New(reasoner.Config{
+		Type: cfg.Reasoner.Type,
+		Eflint: reasoner.EflintConfig{
+			CircuitBreaker: reasoner.CircuitBreakerConfig{
+				FailureThreshold: cfg.Reasoner.CircuitBreaker.FailureThreshold,
+				Cooldown:         cfg.Reasoner.CircuitBreaker.Cooldown,
+			},
+			FactsCommand: cfg.EFlint.FactsCommand,
+			FactsKey:     cfg.EFlint.FactsKey,
+			CacheFacts:   cfg.EFlint.FactsCache,
+			KnownActs:    knownActs(cfg.EFlint.KnownActs),
+			ClauseTypes:  clauseTypes(cfg.EFlint.ClauseTypes),
+			RequestAct:   reasoner.RequestAct(cfg.EFlint.RequestAct),
+			Checkpoints:  checkpoints,
+		},
+		Symboleo: reasoner.SymboleoConfig{
+			Endpoint: cfg.Reasoner.Symboleo.Endpoint,
+			Timeout:  cfg.Reasoner.Symboleo.Timeout,
+		},
+	}, eflintManager, logger)
+	if err != nil {
+		logger.Fatal("failed to create reasoner", zap.Error(err))
+	}
+	logger.Info("reasoner initialized", zap.String("reasoner", policyReasoner.Name()))
 
 	// Create the policy enforcer (uses the Reasoner interface)
-	enforcer := policyenforcer.NewEnforcer(eflintReasoner, logger)
+	enforcer := policyenforcer.NewEnforcer(policyReasoner, &policyenforcer.EnforcerConfig{
+		Cache: policyenforcer.CacheConfig{
+			Size: cfg.Cache.Size,
+			TTL:  cfg.Cache.TTL,
+		},
+		Concurrency: policyenforcer.ConcurrencyConfig{
+			MaxInFlight:  cfg.Concurrency.MaxInFlight,
+			QueueSize:    cfg.Concurrency.QueueSize,
+			QueueTimeout: cfg.Concurrency.QueueTimeout,
+		},
+	}, logger)
 
 	// Register HTTP handlers for policy enforcer
-	policyEnforcerGroup := e.Group("/policy-enforcer")
-	policyEnforcerHandler := policyenforcer.NewHTTPHandler(enforcer, logger)
+	policyEnforcerGroup := e.Group("/policy-enforcer", apiMiddleware...)
+	policyEnforcerHandler := policyenforcer.NewHTTPHandler(enforcer, &policyenforcer.HTTPHandlerConfig{
+		RateLimit: rateLimitConfig(cfg.RateLimit),
+		OrgScope:  orgScopeConfig(cfg.OrgScope),
+		Async: policyenforcer.AsyncConfig{
+			Enabled:   cfg.Async.Enabled,
+			Workers:   cfg.Async.Workers,
+			QueueSize: cfg.Async.QueueSize,
+			TTL:       cfg.Async.TTL,
+			Timeout:   cfg.Async.Timeout,
+		},
+		DefaultOrganization: cfg.Policy.DefaultOrganization,
+		DefaultRequester:    cfg.Policy.DefaultRequester,
+		ExecuteActs:         cfg.Policy.ExecuteActs,
+		StrictJSON:          cfg.Policy.StrictJSON,
+		DenialStatus:        policyenforcer.DenialStatus(cfg.Policy.DenialStatus),
+	}, logger)
 	policyEnforcerHandler.RegisterRoutes(policyEnforcerGroup)
 
+	// -----------------------------------------------------------------------------
+	// Config Reload - SIGHUP or POST /admin/reload-config re-reads the config file
+	// and applies these settings; other changes are logged as requiring a restart
+	// -----------------------------------------------------------------------------
+	reloader := config.NewReloader(*configPath, cfg)
+	reloader.Handle("logging.level", func(next *config.Config) error {
+		level, err := config.ParseLevel(next.Logging.Level)
+		if err != nil {
+			return err
+		}
+		logLevel.SetLevel(level)
+		return nil
+	})
+	reloader.Handle("server.max_request_timeout", func(next *config.Config) error {
+		requestTimeout.Set(next.Server.MaxRequestTimeout)
+		return nil
+	})
+	reloader.Handle("rate_limit", func(next *config.Config) error {
+		return policyEnforcerHandler.SetRateLimit(rateLimitConfig(next.RateLimit))
+	})
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			reloadConfig(reloader, logger)
+		}
+	}()
+
+	// Admin endpoints change the running service, so they must be enabled explicitly
+	if cfg.Admin.Enabled {
+		adminGroup := e.Group("/admin", apiMiddleware...)
+		adminGroup.POST("/reload-config", func(c echo.Context) error {
+			result, err := reloadConfig(reloader, logger)
+			if err != nil {
+				return apperr.Unprocessable("config file is invalid; nothing was applied: %v", err)
+			}
+			return c.JSON(http.StatusOK, result)
+		})
+
+		// Log level at runtime, e.g. debug logging during an incident; until the next
+		// restart or config reload that changes logging.level
+		adminGroup.GET("/log-level", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, logLevelBody{Level: logLevel.Level().String()})
+		})
+		adminGroup.PUT("/log-level", func(c echo.Context) error {
+			var body logLevelBody
+			if err := c.Bind(&body); err != nil {
+				return apperr.BadRequest("invalid request body")
+			}
+			level, err := config.ParseLevel(body.Level)
+			if err != nil || body.Level == "" {
+				return apperr.BadRequest("level must be debug, info, warn or error").WithFields("level")
+			}
+			// Logged at the more verbose of both levels, so the change itself is recorded
+			previous := logLevel.Level()
+			logLevel.SetLevel(min(previous, level))
+			logger.Warn("log level changed", zap.Stringer("from", previous), zap.Stringer("to", level))
+			logLevel.SetLevel(level)
+			return c.JSON(http.StatusOK, logLevelBody{Level: level.String()})
+		})
+	}
+
 	// Auto-start eFLINT server with the configured model
-	if cfg.EFlint.ModelPath != "" {
+	if models := cfg.EFlint.ModelLocations(); len(models) == 0 {
+		logger.Info("no eFLINT model configured; start one via POST /eflint/start")
+	} else {
+		// A configured model that is missing is a deployment error, not "no model"
+		if err := eflint.CheckModelLocations(models); err != nil {
+			logger.Fatal("configured eFLINT model not found; check eflint.model_path and eflint.model_paths", zap.Error(err))
+		}
 		logger.Info("auto-starting eFLINT server",
-			zap.String("model", cfg.EFlint.ModelPath),
+			zap.Strings("models", models),
 		)
-		if err := eflintManager.Start(cfg.EFlint.ModelPath); err != nil {
+		if err := eflintManager.StartModels(models); errors.Is(err, eflint.ErrServerBinaryNotFound) {
+			// A missing binary cannot be fixed via the API, so fail loudly at boot
+			logger.Fatal("eFLINT server binary not found; check eflint.server_path", zap.Error(err))
+		} else if err != nil {
 			logger.Error("failed to auto-start eFLINT server", zap.Error(err))
 			// Continue anyway - the server can be started manually via API
+		} else {
+			if cfg.EFlint.SelfTest {
+				if st, ok := policyReasoner.(reasoner.SelfTester); ok {
+					startup.ModelSelfTest(st, cfg.EFlint.FailOnEmptyModel, logger)
+				}
+				if checker, ok := policyReasoner.(reasoner.RequestActChecker); ok {
+					startup.RequestActCheck(checker, cfg.EFlint.FailOnEmptyModel, logger)
+				}
+			}
+			if cfg.EFlint.Warmup {
+				if fp, ok := policyReasoner.(reasoner.FactsProvider); ok {
+					startup.Warmup(fp, cfg.EFlint.WarmupTimeout, cfg.EFlint.WarmupStrict, logger)
+				}
+			}
 		}
 	}
 
@@ -137,18 +441,26 @@ func main() {
 	}
 
 	// Start HTTP server in a goroutine
+	srv := server.New(e, cfg.Server.Addr(httpPort), server.TLSConfig{
+		Enabled:          cfg.Server.TLS.Enabled,
+		CertFile:         cfg.Server.TLS.CertFile,
+		KeyFile:          cfg.Server.TLS.KeyFile,
+		AutocertDomains:  cfg.Server.TLS.AutocertDomains,
+		AutocertCacheDir: cfg.Server.TLS.AutocertCacheDir,
+		HTTP2:            cfg.Server.TLS.HTTP2,
+		RedirectAddress:  cfg.Server.TLS.RedirectAddress,
+	}, logger)
 	go func() {
-		logger.Info("starting HTTP server", zap.String("port", httpPort))
-		if err := e.Start(":" + httpPort); err != nil && err != http.ErrServerClosed {
+		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("failed to start HTTP server", zap.Error(err))
 		}
 	}()
 
-	// Start consuming messages
-	// msgs, err := consumer.Consume()
-	// if err != nil {
-	// 	logger.Fatal("failed to start consuming messages", zap.Error(err))
-	// }
+	// Serve the PolicyEnforcer gRPC service on its own port, if enabled
+	var grpcSrv *server.GRPCServer
+	if cfg.GRPC.Enabled {
+		grpcSrv = startGRPCServer(cfg, enforcer, authenticator, logger)
+	}
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -156,12 +468,13 @@ func main() {
 
 	logger.Info("Policy Enforcer started, waiting for messages...")
 
-	// Message processing loop
+	// Message processing with a pool of cfg.RabbitMQ.Workers workers
+	// consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	// consumerDone := make(chan struct{})
 	// go func() {
-	// 	for msg := range msgs {
-	// 		if err := reqHandler.Handle(msg); err != nil {
-	// 			logger.Error("failed to handle message", zap.Error(err))
-	// 		}
+	// 	defer close(consumerDone)
+	// 	if err := consumer.Process(consumeCtx, cfg.RabbitMQ.Workers, reqHandler.Handle); err != nil {
+	// 		logger.Error("message processing stopped", zap.Error(err))
 	// 	}
 	// }()
 
@@ -169,36 +482,178 @@ func main() {
 	<-sigChan
 	logger.Info("shutting down Policy Enforcer...")
 
-	// Stop eFLINT instance if running
-	if eflintManager.IsRunning() {
-		logger.Info("stopping eFLINT instance...")
-		if err := eflintManager.Stop(); err != nil {
-			logger.Error("failed to stop eFLINT instance", zap.Error(err))
+	// Each stage has its own timeout, so a stuck stage cannot block the ones after it
+	shutdown.Run([]shutdown.Stage{
+		{
+			// Refuse new requests and let in-flight ones finish before eFLINT goes away
+			Name:    "drain-requests",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run: func(ctx context.Context) error {
+				// Let workers finish their current message as well
+				// stopConsuming()
+				// select {
+				// case <-consumerDone:
+				// case <-ctx.Done():
+				// }
+				return srv.Shutdown(ctx)
+			},
+		},
+		{
+			// Let in-flight gRPC calls finish as well; open streams are cut off at the timeout
+			Name:    "drain-grpc",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run: func(ctx context.Context) error {
+				if grpcSrv == nil {
+					return nil
+				}
+				return grpcSrv.Shutdown(ctx)
+			},
+		},
+		{
+			// Finish queued asynchronous validations while the reasoner is still up
+			Name:    "drain-validation-jobs",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run:     policyEnforcerHandler.Shutdown,
+		},
+		{
+			Name:    "stop-eflint",
+			Timeout: cfg.Shutdown.EflintTimeout,
+			Run: func(context.Context) error {
+				if !eflintManager.IsRunning() {
+					return nil
+				}
+				return eflintManager.Stop()
+			},
+		},
+		{
+			Name:    "close-rabbitmq",
+			Timeout: cfg.Shutdown.RabbitMQTimeout,
+			Run: func(context.Context) error {
+				// return consumer.Close()
+				return nil
+			},
+		},
+		{
+			// Close whatever the drain left behind (e.g. open log streams)
+			Name:    "shutdown-http",
+			Timeout: cfg.Shutdown.HTTPTimeout,
+			Run: func(context.Context) error {
+				if grpcSrv != nil {
+					grpcSrv.Close()
+				}
+				return srv.Close()
+			},
+		},
+	}, logger)
+
+	logger.Info("shutdown complete")
+}
+
+// logLevelBody is the request and response body of /admin/log-level.
+type logLevelBody struct {
+	Level string `json:"level"` // debug, info, warn or error
+}
+
+// rateLimitConfig converts the configured rate limits for the policy enforcer API.
+func rateLimitConfig(cfg config.RateLimitConfig) policyenforcer.RateLimitConfig {
+	return policyenforcer.RateLimitConfig{
+		Enabled:        cfg.Enabled,
+		RequesterRate:  cfg.RequesterRate,
+		RequesterBurst: cfg.RequesterBurst,
+		GlobalRate:     cfg.GlobalRate,
+		GlobalBurst:    cfg.GlobalBurst,
+	}
+}
+
+// orgScopeConfig converts the configured org scoping for the policy enforcer API.
+func orgScopeConfig(cfg config.OrgScopeConfig) policyenforcer.OrgScopeConfig {
+	return policyenforcer.OrgScopeConfig{
+		Enabled:         cfg.Enabled,
+		PrincipalHeader: cfg.PrincipalHeader,
+		PrincipalClaim:  cfg.PrincipalClaim,
+		Organizations:   cfg.Organizations,
+	}
+}
+
+// startGRPCServer serves the PolicyEnforcer gRPC service in the background. Calls
+// are authenticated like the HTTP API if authenticator is set.
+func startGRPCServer(cfg *config.Config, enforcer *policyenforcer.Enforcer, authenticator *auth.Authenticator, logger *zap.Logger) *server.GRPCServer {
+	var opts []grpc.ServerOption
+	if authenticator != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+		)
+	}
+
+	grpcSrv, err := server.NewGRPC(cfg.Server.Addr(strconv.Itoa(cfg.GRPC.Port)), server.TLSConfig{
+		Enabled:  cfg.Server.TLS.Enabled,
+		CertFile: cfg.Server.TLS.CertFile,
+		KeyFile:  cfg.Server.TLS.KeyFile,
+	}, logger, opts...)
+	if err != nil {
+		logger.Fatal("failed to create gRPC server", zap.Error(err))
+	}
+
+	policyenforcer.NewGRPCHandler(enforcer, &policyenforcer.GRPCHandlerConfig{
+		OrgScope:            orgScopeConfig(cfg.OrgScope),
+		DefaultOrganization: cfg.Policy.DefaultOrganization,
+		DefaultRequester:    cfg.Policy.DefaultRequester,
+	}, logger).Register(grpcSrv)
+
+	go func() {
+		if err := grpcSrv.Start(); err != nil {
+			logger.Fatal("failed to start gRPC server", zap.Error(err))
 		}
+	}()
+	return grpcSrv
+}
+
+// reloadConfig re-reads the config file and logs which changed settings were
+// applied, could not be applied, or take effect only after a restart.
+func reloadConfig(reloader *config.Reloader, logger *zap.Logger) (*config.ReloadResult, error) {
+	result, err := reloader.Reload()
+	if err != nil {
+		logger.Error("config reload failed; keeping the current configuration", zap.Error(err))
+		return nil, err
 	}
 
-	// Gracefully shutdown HTTP server
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := e.Shutdown(ctx); err != nil {
-		logger.Error("failed to shutdown HTTP server gracefully", zap.Error(err))
+	logger.Info("config reloaded", zap.Strings("applied", result.Applied))
+	for setting, reason := range result.Failed {
+		logger.Error("config reload could not apply setting", zap.String("setting", setting), zap.String("error", reason))
 	}
+	if len(result.RestartRequired) > 0 {
+		logger.Warn("changed settings take effect after a restart", zap.Strings("settings", result.RestartRequired))
+	}
+	return result, nil
 }
 
-// initLogger creates a configured zap logger
-func initLogger(cfg config.LoggingConfig) *zap.Logger {
-	// Parse log level
-	level := zapcore.InfoLevel
-	switch cfg.Level {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
+// knownActs converts the configured known acts for the eFLINT reasoner. An unset
+// list (nil) selects reasoner.DefaultKnownActs.
+func knownActs(acts []config.KnownAct) []reasoner.KnownAct {
+	if acts == nil {
+		return nil
+	}
+	converted := make([]reasoner.KnownAct, 0, len(acts))
+	for _, act := range acts {
+		converted = append(converted, reasoner.KnownAct{Type: act.Act, Arguments: act.Arguments})
 	}
+	return converted
+}
+
+// clauseTypes converts the configured clause types for the eFLINT reasoner.
+func clauseTypes(types []config.ClauseType) []reasoner.ClauseType {
+	converted := make([]reasoner.ClauseType, 0, len(types))
+	for _, t := range types {
+		converted = append(converted, reasoner.ClauseType{Name: t.Name, FactType: t.FactType, ValueFactType: t.ValueFactType})
+	}
+	return converted
+}
+
+// initLogger creates a configured zap logger
+func initLogger(cfg config.LoggingConfig) (*zap.Logger, zap.AtomicLevel) {
+	// Parse log level; unknown levels log at info
+	level, _ := config.ParseLevel(cfg.Level)
 
 	// Create config
 	zapConfig := zap.Config{
@@ -221,5 +676,5 @@ func initLogger(cfg config.LoggingConfig) *zap.Logger {
 		os.Exit(1)
 	}
 
-	return logger
+	return logger, zapConfig.Level
 }
@@ -0,0 +1,217 @@
+// Package apperr defines the error type that services return to the HTTP layer
+// and the Echo error handler that renders it. An Error carries the HTTP status,
+// a stable machine-readable code and the message shown to the client, so
+// handlers can return service errors as is instead of mapping them to statuses.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// Stable error codes. Services may define more specific ones.
+const (
+	CodeBadRequest     = "bad_request"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeUnprocessable  = "unprocessable"
+	CodeRateLimited    = "rate_limited"
+	CodeInternal       = "internal"
+	CodeNotImplemented = "not_implemented"
+	CodeBadGateway     = "bad_gateway"
+	CodeUnavailable    = "unavailable"
+	CodeTimeout        = "timeout"
+)
+
+// Error is an error with everything needed to render it as an HTTP response.
+type Error struct {
+	Status     int           // HTTP status code
+	Code       string        // Stable machine-readable code, e.g. "not_found"
+	Message    string        // Human-readable message returned to the client
+	Fields     []string      // Offending request fields, for validation errors
+	RetryAfter time.Duration // Sent as the Retry-After header if set, e.g. for 503 when overloaded
+	Err        error         // Underlying cause, if any; available via errors.Is and errors.As
+}
+
+// Error returns the client-facing message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying cause for use with errors.Is() and errors.As().
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithFields returns a copy of e listing the offending request fields.
+func (e *Error) WithFields(fields ...string) *Error {
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
+
+// WithRetryAfter returns a copy of e telling the client to retry after d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	copied := *e
+	copied.RetryAfter = d
+	return &copied
+}
+
+// New creates an error with the given status, code and message.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Wrap creates an error with the given status, code and message caused by err.
+func Wrap(err error, status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message, Err: err}
+}
+
+// BadRequest returns a 400 error.
+func BadRequest(format string, args ...any) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, fmt.Sprintf(format, args...))
+}
+
+// Unauthorized returns a 401 error.
+func Unauthorized(format string, args ...any) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, fmt.Sprintf(format, args...))
+}
+
+// Forbidden returns a 403 error.
+func Forbidden(format string, args ...any) *Error {
+	return New(http.StatusForbidden, CodeForbidden, fmt.Sprintf(format, args...))
+}
+
+// NotFound returns a 404 error.
+func NotFound(format string, args ...any) *Error {
+	return New(http.StatusNotFound, CodeNotFound, fmt.Sprintf(format, args...))
+}
+
+// Unprocessable returns a 422 error.
+func Unprocessable(format string, args ...any) *Error {
+	return New(http.StatusUnprocessableEntity, CodeUnprocessable, fmt.Sprintf(format, args...))
+}
+
+// Unavailable returns a 503 error.
+func Unavailable(format string, args ...any) *Error {
+	return New(http.StatusServiceUnavailable, CodeUnavailable, fmt.Sprintf(format, args...))
+}
+
+// Internal returns a 500 error caused by err, with err's message.
+func Internal(err error) *Error {
+	return Wrap(err, http.StatusInternalServerError, CodeInternal, err.Error())
+}
+
+// From returns err as an *Error. Echo's HTTP errors keep their status; any other
+// error becomes a 500.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+		return Wrap(err, httpErr.Code, codeForStatus(httpErr.Code), message)
+	}
+
+	return Internal(err)
+}
+
+// codeForStatus returns the generic code for an HTTP status.
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Response is the JSON body of an error response.
+type Response = api.ErrorResponse
+
+// HTTPErrorHandler returns an Echo error handler that renders errors returned by
+// handlers and middleware as a Response with the error's status (see From).
+// Server errors (5xx) are logged with their cause; 501 and 503 only as warnings.
+func HTTPErrorHandler(logger *zap.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		appErr := From(err)
+		log := requestid.Logger(c.Request().Context(), logger)
+		if appErr.Status >= http.StatusInternalServerError {
+			logAt := log.Error
+			if appErr.Status == http.StatusNotImplemented || appErr.Status == http.StatusServiceUnavailable {
+				logAt = log.Warn // Expected while a backend is down or lacks a capability
+			}
+			logAt("request failed",
+				zap.String("method", c.Request().Method),
+				zap.String("path", c.Path()),
+				zap.Int("status", appErr.Status),
+				zap.String("code", appErr.Code),
+				zap.String("error", appErr.Message),
+				zap.NamedError("cause", appErr.Err),
+			)
+		}
+
+		if appErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(appErr.Status)
+		} else {
+			writeErr = c.JSON(appErr.Status, Response{
+				Error:  appErr.Message,
+				Code:   appErr.Code,
+				Fields: appErr.Fields,
+			})
+		}
+		if writeErr != nil {
+			log.Error("failed to write error response", zap.Error(writeErr))
+		}
+	}
+}
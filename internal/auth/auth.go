@@ -0,0 +1,221 @@
+// Package auth provides an Echo middleware and gRPC interceptors that authenticate
+// requests with JWT bearer tokens. Tokens are verified with a shared secret
+// (HS256/384/512) or with public keys from a JWKS endpoint (RS256/384/512,
+// ES256/384/512). The validated claims are stored in the request context for
+// downstream authorization.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, wrongly signed, expired
+// or missing required claims.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Config configures JWT authentication.
+type Config struct {
+	Secret         string        // Shared secret for HMAC-signed tokens
+	JWKSURL        string        // URL of a JSON Web Key Set for RSA/ECDSA-signed tokens
+	Issuer         string        // Required "iss" claim, if set
+	Audience       string        // Required entry of the "aud" claim, if set
+	RequiredClaims []string      // Claims that must be present (e.g. "sub")
+	Leeway         time.Duration // Allowed clock skew when checking "exp" and "nbf"
+	JWKSRefresh    time.Duration // How often the key set is re-fetched (default 1h)
+}
+
+// Claims holds the claims of a validated token.
+type Claims map[string]interface{}
+
+// String returns a string claim, or "" if it is missing or not a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims.
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated request, if any.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(Claims)
+	return claims, ok
+}
+
+// Authenticator validates bearer tokens.
+type Authenticator struct {
+	config Config
+	keys   *keySet // nil without JWKSURL
+	logger *zap.Logger
+}
+
+// NewAuthenticator creates an authenticator. Either Secret or JWKSURL is required.
+func NewAuthenticator(config Config, logger *zap.Logger) (*Authenticator, error) {
+	if config.Secret == "" && config.JWKSURL == "" {
+		return nil, fmt.Errorf("auth requires a secret or a JWKS URL")
+	}
+	if config.JWKSRefresh <= 0 {
+		config.JWKSRefresh = time.Hour
+	}
+
+	a := &Authenticator{config: config, logger: logger}
+	if config.JWKSURL != "" {
+		a.keys = newKeySet(config.JWKSURL, config.JWKSRefresh)
+	}
+	return a, nil
+}
+
+// Middleware returns a middleware that rejects requests without a valid
+// `Authorization: Bearer <token>` header with 401 and stores the claims of valid
+// tokens in the request context. If the JWKS cannot be fetched it responds 503.
+func (a *Authenticator) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := bearerToken(c.Request())
+			if !ok {
+				return unauthorized(c, "bearer token is required")
+			}
+
+			claims, err := a.Validate(c.Request().Context(), token)
+			if err != nil {
+				logger := requestid.Logger(c.Request().Context(), a.logger)
+				if !errors.Is(err, ErrInvalidToken) {
+					// The key set is unavailable; the token may well be valid
+					logger.Error("failed to verify token", zap.Error(err))
+					return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "failed to verify token"})
+				}
+				logger.Info("rejected token", zap.Error(err))
+				return unauthorized(c, err.Error())
+			}
+
+			c.SetRequest(c.Request().WithContext(NewContext(c.Request().Context(), claims)))
+			return next(c)
+		}
+	}
+}
+
+// Validate verifies the token's signature and claims and returns the claims.
+// Errors wrap ErrInvalidToken unless the key set could not be fetched.
+func (a *Authenticator) Validate(ctx context.Context, token string) (Claims, error) {
+	parsed, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.verifySignature(ctx, parsed); err != nil {
+		return nil, err
+	}
+
+	if err := a.checkClaims(parsed.claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return parsed.claims, nil
+}
+
+// verifySignature checks the signature with the secret or the key set, depending
+// on the algorithm family. Each family is only accepted if its key is configured,
+// so an HMAC token cannot be signed with a public key.
+func (a *Authenticator) verifySignature(ctx context.Context, t *token) error {
+	switch {
+	case strings.HasPrefix(t.header.Alg, "HS"):
+		if a.config.Secret == "" {
+			return fmt.Errorf("%w: HMAC-signed tokens are not accepted", ErrInvalidToken)
+		}
+		return verifyHMAC(t, []byte(a.config.Secret))
+	case strings.HasPrefix(t.header.Alg, "RS"), strings.HasPrefix(t.header.Alg, "ES"):
+		if a.keys == nil {
+			return fmt.Errorf("%w: %s-signed tokens are not accepted", ErrInvalidToken, t.header.Alg)
+		}
+		key, err := a.keys.get(ctx, t.header.Kid)
+		if err != nil {
+			return err
+		}
+		return verifyPublicKey(t, key)
+	}
+	return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, t.header.Alg)
+}
+
+// checkClaims validates the time-based, issuer, audience and required claims.
+func (a *Authenticator) checkClaims(claims Claims, now time.Time) error {
+	if exp, ok := numericClaim(claims, "exp"); ok && now.After(exp.Add(a.config.Leeway)) {
+		return fmt.Errorf("%w: token has expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(a.config.Leeway).Before(nbf) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if a.config.Issuer != "" && claims.String("iss") != a.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if a.config.Audience != "" && !hasAudience(claims, a.config.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	for _, name := range a.config.RequiredClaims {
+		if _, ok := claims[name]; !ok {
+			return fmt.Errorf("%w: missing claim %q", ErrInvalidToken, name)
+		}
+	}
+	return nil
+}
+
+// numericClaim returns a NumericDate claim (seconds since the epoch) as a time.
+func numericClaim(claims Claims, name string) (time.Time, bool) {
+	v, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// hasAudience reports whether the "aud" claim (a string or an array) contains aud.
+func hasAudience(claims Claims, aud string) bool {
+	switch v := claims["aud"].(type) {
+	case string:
+		return v == aud
+	case []interface{}:
+		for _, entry := range v {
+			if entry == aud {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bearerToken extracts the token from the Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	return parseBearer(r.Header.Get(echo.HeaderAuthorization))
+}
+
+// parseBearer extracts the token from an Authorization value "Bearer <token>".
+func parseBearer(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// unauthorized writes a 401 response with a Bearer challenge.
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+	return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// newTestServer serves /health openly and /api behind the authenticator, as the
+// service does. /api/whoami responds with the subject of the request's claims.
+func newTestServer(t *testing.T, config Config) *echo.Echo {
+	t.Helper()
+	a, err := NewAuthenticator(config, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.GET("/health", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	api := e.Group("/api", a.Middleware())
+	api.GET("/whoami", func(c echo.Context) error {
+		claims, ok := ClaimsFromContext(c.Request().Context())
+		if !ok {
+			return c.String(http.StatusInternalServerError, "no claims in context")
+		}
+		return c.String(http.StatusOK, claims.Subject())
+	})
+	return e
+}
+
+// get sends a GET request with the given Authorization header, if any.
+func get(e *echo.Echo, target, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareRequiresBearerToken(t *testing.T) {
+	e := newTestServer(t, Config{Secret: testSecret})
+
+	for name, authorization := range map[string]string{
+		"no header":       "",
+		"basic":           "Basic YWxpY2U6c2VjcmV0",
+		"empty token":     "Bearer ",
+		"malformed token": "Bearer not-a-token",
+		"wrong signature": "Bearer " + signHMAC(t, "HS256", Claims{"sub": "alice"}) + "x",
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := get(e, "/api/whoami", authorization)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("GET /api/whoami = %d %s, want 401", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get(echo.HeaderWWWAuthenticate); got != `Bearer error="invalid_token"` {
+				t.Errorf("WWW-Authenticate = %q, want a Bearer challenge", got)
+			}
+		})
+	}
+
+	rec := get(e, "/api/whoami", "bearer "+signHMAC(t, "HS256", Claims{"sub": "alice"}))
+	if rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+		t.Errorf("GET /api/whoami with a valid token = %d %s, want 200 with the subject", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareLeavesHealthOpen(t *testing.T) {
+	e := newTestServer(t, Config{Secret: testSecret})
+	if rec := get(e, "/health", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /health without a token = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareJWKSUnavailable(t *testing.T) {
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+	e := newTestServer(t, Config{JWKSURL: jwks.URL})
+
+	// The key set cannot be fetched, so the signature is never checked
+	token := encodeSegments(t, "RS256", Claims{"sub": "alice"}) + ".c2lnbmF0dXJl"
+	rec := get(e, "/api/whoami", "Bearer "+token)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GET /api/whoami = %d %s, want 503", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get(echo.HeaderWWWAuthenticate); got != "" {
+		t.Errorf("WWW-Authenticate = %q on 503, want no challenge", got)
+	}
+}
+
+func TestMiddlewareChecksClaims(t *testing.T) {
+	e := newTestServer(t, Config{
+		Secret:         testSecret,
+		Issuer:         "https://idp.example.com",
+		Audience:       "policy-enforcer",
+		RequiredClaims: []string{"sub", "org"},
+		Leeway:         time.Minute,
+	})
+	at := func(d time.Duration) float64 { return float64(time.Now().Add(d).Unix()) }
+
+	tests := []struct {
+		name   string
+		change Claims // Overrides the valid claims; nil values delete a claim
+		want   int
+	}{
+		{"valid", nil, http.StatusOK},
+		{"expired within leeway", Claims{"exp": at(-30 * time.Second)}, http.StatusOK},
+		{"expired beyond leeway", Claims{"exp": at(-2 * time.Minute)}, http.StatusUnauthorized},
+		{"not yet valid within leeway", Claims{"nbf": at(30 * time.Second)}, http.StatusOK},
+		{"not yet valid beyond leeway", Claims{"nbf": at(2 * time.Minute)}, http.StatusUnauthorized},
+		{"wrong issuer", Claims{"iss": "https://other.example.com"}, http.StatusUnauthorized},
+		{"missing issuer", Claims{"iss": nil}, http.StatusUnauthorized},
+		{"audience in array", Claims{"aud": []any{"other", "policy-enforcer"}}, http.StatusOK},
+		{"wrong audience", Claims{"aud": "other"}, http.StatusUnauthorized},
+		{"audience array without ours", Claims{"aud": []any{"other"}}, http.StatusUnauthorized},
+		{"missing required claim", Claims{"org": nil}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := Claims{
+				"sub": "alice",
+				"org": "VU",
+				"iss": "https://idp.example.com",
+				"aud": "policy-enforcer",
+				"exp": at(time.Hour),
+				"nbf": at(-time.Hour),
+			}
+			for name, value := range tt.change {
+				if value == nil {
+					delete(claims, name)
+				} else {
+					claims[name] = value
+				}
+			}
+
+			rec := get(e, "/api/whoami", "Bearer "+signHMAC(t, "HS256", claims))
+			if rec.Code != tt.want {
+				t.Errorf("GET /api/whoami = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
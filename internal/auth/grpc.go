@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor that authenticates calls like
+// Middleware does requests, reading the token from the "authorization" metadata
+// ("Bearer <token>"). Calls without a valid token fail with Unauthenticated, and
+// with Unavailable if the JWKS cannot be fetched.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticateCall(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateCall(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream is a server stream whose context carries the caller's claims.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the claims.
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticateCall validates the bearer token of a gRPC call and returns a copy
+// of ctx carrying its claims.
+func (a *Authenticator) authenticateCall(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var authorization string
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	token, ok := parseBearer(authorization)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "bearer token is required")
+	}
+
+	claims, err := a.Validate(ctx, token)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidToken) {
+			// The key set is unavailable; the token may well be valid
+			a.logger.Error("failed to verify token", zap.String("method", method), zap.Error(err))
+			return nil, status.Error(codes.Unavailable, "failed to verify token")
+		}
+		a.logger.Info("rejected token", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return NewContext(ctx, claims), nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a, err := NewAuthenticator(Config{Secret: testSecret}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := a.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/policyenforcer.v1.PolicyEnforcer/ValidateRequest"}
+	// whoami responds with the subject of the call's claims
+	whoami := func(ctx context.Context, _ any) (any, error) {
+		claims, ok := ClaimsFromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Internal, "no claims in context")
+		}
+		return claims.Subject(), nil
+	}
+	call := func(authorization string) (any, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		return interceptor(ctx, nil, info, whoami)
+	}
+
+	for name, authorization := range map[string]string{
+		"no metadata":     "",
+		"basic":           "Basic YWxpY2U6c2VjcmV0",
+		"wrong signature": "Bearer " + signHMAC(t, "HS256", Claims{"sub": "alice"}) + "x",
+	} {
+		if _, err := call(authorization); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("call with %s error = %v, want Unauthenticated", name, err)
+		}
+	}
+
+	resp, err := call("Bearer " + signHMAC(t, "HS256", Claims{"sub": "alice"}))
+	if err != nil || resp != "alice" {
+		t.Errorf("call with a valid token = %v, %v, want the subject", resp, err)
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+// token is a parsed, not yet verified JWT in compact serialization.
+type token struct {
+	header    tokenHeader
+	claims    Claims
+	signed    []byte // The signing input: base64url(header) "." base64url(payload)
+	signature []byte
+}
+
+// tokenHeader is the JOSE header of a token.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// parseToken splits and decodes a compact JWT.
+func parseToken(raw string) (*token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	t := &token{signed: []byte(parts[0] + "." + parts[1])}
+	if err := decodeSegment(parts[0], &t.header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	if err := decodeSegment(parts[1], &t.claims); err != nil || t.claims == nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	t.signature = signature
+	return t, nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment into v.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hashFor returns the hash of a supported algorithm (HS, RS or ES with 256, 384
+// or 512 bits).
+func hashFor(alg string) (crypto.Hash, func() hash.Hash, bool) {
+	switch alg {
+	case "HS256", "RS256", "ES256":
+		return crypto.SHA256, sha256.New, true
+	case "HS384", "RS384", "ES384":
+		return crypto.SHA384, sha512.New384, true
+	case "HS512", "RS512", "ES512":
+		return crypto.SHA512, sha512.New, true
+	}
+	return 0, nil, false
+}
+
+// verifyHMAC verifies an HS256/384/512 signature.
+func verifyHMAC(t *token, secret []byte) error {
+	_, newHash, ok := hashFor(t.header.Alg)
+	if !ok || !strings.HasPrefix(t.header.Alg, "HS") {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, t.header.Alg)
+	}
+
+	mac := hmac.New(newHash, secret)
+	mac.Write(t.signed)
+	if !hmac.Equal(mac.Sum(nil), t.signature) {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+	return nil
+}
+
+// verifyPublicKey verifies an RS256/384/512 or ES256/384/512 signature. The key
+// type must match the algorithm family.
+func verifyPublicKey(t *token, key crypto.PublicKey) error {
+	hashID, newHash, ok := hashFor(t.header.Alg)
+	if !ok {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, t.header.Alg)
+	}
+	h := newHash()
+	h.Write(t.signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(t.header.Alg, "RS") {
+			break
+		}
+		if rsa.VerifyPKCS1v15(k, hashID, digest, t.signature) != nil {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(t.header.Alg, "ES") {
+			break
+		}
+		// JWS encodes ECDSA signatures as the fixed-size concatenation r || s
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) != 2*size {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: key does not match algorithm %q", ErrInvalidToken, t.header.Alg)
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const testSecret = "test-secret"
+
+// signHMAC builds a compact token with the given header alg, signed with
+// HMAC-SHA256 over testSecret.
+func signHMAC(t *testing.T, alg string, claims Claims) string {
+	t.Helper()
+	signed := encodeSegments(t, alg, claims)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encodeSegments returns base64url(header) "." base64url(claims).
+func encodeSegments(t *testing.T, alg string, claims Claims) string {
+	t.Helper()
+	header, err := json.Marshal(tokenHeader{Alg: alg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func TestValidateHMAC(t *testing.T) {
+	a, err := NewAuthenticator(Config{Secret: testSecret}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := a.Validate(context.Background(), signHMAC(t, "HS256", Claims{"sub": "alice"}))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if claims.Subject() != "alice" {
+		t.Errorf("Subject() = %q, want alice", claims.Subject())
+	}
+
+	expired := signHMAC(t, "HS256", Claims{"exp": float64(time.Now().Add(-time.Hour).Unix())})
+	if _, err := a.Validate(context.Background(), expired); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Validate(expired) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateRejectsShortAndUnknownAlgorithms(t *testing.T) {
+	a, err := NewAuthenticator(Config{Secret: testSecret}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, alg := range []string{"", "H", "HS", "RS", "ES", "HS1", "HS25", "HS2566", "HS999", "none", "RS256", "ES256"} {
+		t.Run(alg, func(t *testing.T) {
+			_, err := a.Validate(context.Background(), signHMAC(t, alg, Claims{"sub": "alice"}))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Validate(alg %q) error = %v, want ErrInvalidToken", alg, err)
+			}
+		})
+	}
+}
+
+func TestValidatePublicKeyRejectsShortAndUnknownAlgorithms(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jsonWebKey{{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	a, err := NewAuthenticator(Config{JWKSURL: jwks.URL}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(alg string) string {
+		signed := encodeSegments(t, alg, Claims{"sub": "alice"})
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	if _, err := a.Validate(context.Background(), sign("RS256")); err != nil {
+		t.Fatalf("Validate(RS256) error = %v", err)
+	}
+
+	for _, alg := range []string{"RS", "ES", "RS1", "RS2566", "RS999", "ES256", "HS256"} {
+		t.Run(alg, func(t *testing.T) {
+			_, err := a.Validate(context.Background(), sign(alg))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Validate(alg %q) error = %v, want ErrInvalidToken", alg, err)
+			}
+		})
+	}
+}
+
+func TestParseTokenMalformed(t *testing.T) {
+	for _, raw := range []string{"", "a.b", "a.b.c.d", "!!.e30.", "e30.!!.", "e30.e30.!!"} {
+		if _, err := parseToken(raw); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("parseToken(%q) error = %v, want ErrInvalidToken", raw, err)
+		}
+	}
+}
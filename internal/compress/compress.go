@@ -0,0 +1,51 @@
+// Package compress provides an Echo middleware that gzips responses for clients
+// sending Accept-Encoding: gzip. Facts and execution graphs can be megabytes of
+// JSON, which typically compress to a small fraction of their size.
+package compress
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// DefaultMinLength is the default response size, in bytes, below which responses
+// are sent uncompressed: for small bodies the gzip overhead outweighs the savings.
+const DefaultMinLength = 1024
+
+// Config configures response compression.
+type Config struct {
+	Level     int // gzip level: 1 (fastest) to 9 (smallest), or -1 for the default (6)
+	MinLength int // Responses shorter than this many bytes are sent uncompressed
+}
+
+// Middleware returns a middleware that gzips responses of at least MinLength
+// bytes. Server-sent event streams are never compressed, so that every event
+// reaches the client as soon as it is written, and neither are state archives and
+// pprof profiles, which are gzip-compressed already.
+func Middleware(config Config) echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		Skipper: func(c echo.Context) bool {
+			return isEventStream(c) || isArchive(c) || isProfile(c)
+		},
+		Level:     config.Level,
+		MinLength: config.MinLength,
+	})
+}
+
+// isEventStream reports whether the request is for a server-sent event stream.
+func isEventStream(c echo.Context) bool {
+	return strings.HasSuffix(c.Path(), "/stream") ||
+		strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream")
+}
+
+// isArchive reports whether the request is for a compressed state archive.
+func isArchive(c echo.Context) bool {
+	return strings.HasSuffix(c.Path(), "/checkpoints/export")
+}
+
+// isProfile reports whether the request is for a pprof endpoint.
+func isProfile(c echo.Context) bool {
+	return strings.HasPrefix(c.Path(), "/debug/pprof")
+}
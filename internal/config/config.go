@@ -1,29 +1,116 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
 )
 
+// ErrInvalidConfig is returned (wrapped) by Load for malformed settings that must
+// not be silently replaced by defaults, such as the bind address.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
 // Config holds all configuration for the policy enforcer
 type Config struct {
+	Server   ServerConfig   `mapstructure:"server"`
+	GRPC     GRPCConfig     `mapstructure:"grpc"`
 	RabbitMQ RabbitMQConfig `mapstructure:"rabbitmq"`
+	Reasoner ReasonerConfig `mapstructure:"reasoner"`
+	Policy   PolicyConfig   `mapstructure:"policy"`
 	EFlint   EFlintConfig   `mapstructure:"eflint"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
+
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	Concurrency ConcurrencyConfig `mapstructure:"concurrency"`
+	OrgScope    OrgScopeConfig    `mapstructure:"org_scope"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Shutdown    ShutdownConfig    `mapstructure:"shutdown"`
+	Async       AsyncConfig       `mapstructure:"async"`
+	Debug       DebugConfig       `mapstructure:"debug"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+}
+
+// ServerConfig holds HTTP server settings
+type ServerConfig struct {
+	BindAddress       string            `mapstructure:"bind_address"`        // Interface the HTTP server listens on (e.g. "127.0.0.1"); "" or "0.0.0.0" binds all
+	BodyLimit         string            `mapstructure:"body_limit"`          // Maximum request body size (e.g. "10M"), enforced by Echo
+	MaxRequestTimeout time.Duration     `mapstructure:"max_request_timeout"` // Upper bound for per-request timeouts set via ?timeout= or X-Timeout
+	TLS               TLSConfig         `mapstructure:"tls"`
+	Compression       CompressionConfig `mapstructure:"compression"`
+}
+
+// CompressionConfig holds gzip response compression settings. Only clients sending
+// Accept-Encoding: gzip get compressed responses.
+type CompressionConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	Level     int  `mapstructure:"level"`      // gzip level: 1 (fastest) to 9 (smallest), or -1 for the default
+	MinLength int  `mapstructure:"min_length"` // Responses shorter than this many bytes are sent uncompressed
+}
+
+// TLSConfig holds HTTPS settings. Certificates come either from CertFile/KeyFile
+// or, if AutocertDomains is set, from an ACME provider (Let's Encrypt).
+type TLSConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	CertFile         string   `mapstructure:"cert_file"`
+	KeyFile          string   `mapstructure:"key_file"`
+	AutocertDomains  []string `mapstructure:"autocert_domains"`   // Domains to obtain certificates for
+	AutocertCacheDir string   `mapstructure:"autocert_cache_dir"` // Where obtained certificates are kept across restarts
+	HTTP2            bool     `mapstructure:"http2"`              // Negotiate HTTP/2 over TLS
+	RedirectAddress  string   `mapstructure:"redirect_address"`   // Plain HTTP listener redirecting to HTTPS (e.g. ":80"); "" disables it
+}
+
+// GRPCConfig holds settings for the gRPC API (pkg/proto/policyenforcer/v1). It is
+// served on its own port, on server.bind_address and with the certificate files
+// of server.tls, and shares authentication and org scoping with the HTTP API.
+type GRPCConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"` // Port the gRPC server listens on
 }
 
 // RabbitMQConfig holds RabbitMQ connection settings
 type RabbitMQConfig struct {
-	Host           string        `mapstructure:"host"`
-	Port           int           `mapstructure:"port"`
-	Username       string        `mapstructure:"username"`
-	Password       string        `mapstructure:"password"`
-	Queue          string        `mapstructure:"queue"`
-	Exchange       string        `mapstructure:"exchange"`
-	RoutingKey     string        `mapstructure:"routing_key"`
-	PrefetchCount  int           `mapstructure:"prefetch_count"`
-	ReconnectDelay time.Duration `mapstructure:"reconnect_delay"`
+	Host              string        `mapstructure:"host"`
+	Port              int           `mapstructure:"port"`
+	Username          string        `mapstructure:"username"`
+	Password          string        `mapstructure:"password" sensitive:"true"`
+	Queue             string        `mapstructure:"queue"`
+	Exchange          string        `mapstructure:"exchange"`
+	RoutingKey        string        `mapstructure:"routing_key"`
+	PrefetchCount     int           `mapstructure:"prefetch_count"`
+	Workers           int           `mapstructure:"workers"`             // Concurrent message handlers (1..prefetch_count)
+	MaxRedeliveries   int           `mapstructure:"max_redeliveries"`    // Failed attempts before a message is dead-lettered
+	ReconnectDelay    time.Duration `mapstructure:"reconnect_delay"`     // Wait before retrying the initial connection; doubled per attempt
+	MaxReconnectDelay time.Duration `mapstructure:"max_reconnect_delay"` // Upper bound for the wait between connection attempts
+	ConnectTimeout    time.Duration `mapstructure:"connect_timeout"`     // Give up connecting at startup after this long (0 = no limit)
+}
+
+// ReasonerConfig selects the policy reasoning backend
+type ReasonerConfig struct {
+	Type           string               `mapstructure:"type"` // "eflint" (default) or "symboleo"
+	Symboleo       SymboleoConfig       `mapstructure:"symboleo"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"` // Applies to the eFLINT reasoner
+}
+
+// CircuitBreakerConfig holds circuit breaker settings for reasoner backend calls
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"` // Consecutive failures that open the circuit (0 = disabled)
+	Cooldown         time.Duration `mapstructure:"cooldown"`          // How long the circuit stays open before probing
+}
+
+// SymboleoConfig holds Symboleo engine settings
+type SymboleoConfig struct {
+	Endpoint string        `mapstructure:"endpoint"` // Base URL of the Symboleo engine
+	Timeout  time.Duration `mapstructure:"timeout"`
 }
 
 // EFlintConfig holds eFLINT server settings
@@ -31,10 +118,174 @@ type EFlintConfig struct {
 	Host           string        `mapstructure:"host"`
 	Port           int           `mapstructure:"port"`
 	ServerPath     string        `mapstructure:"server_path"`
+	ServerArgs     []string      `mapstructure:"server_args"`    // Argument template with {model} and {port} placeholders
+	FactsCommand   string        `mapstructure:"facts_command"`  // Command listing all facts, for server builds with another dialect
+	FactsKey       string        `mapstructure:"facts_key"`      // Key of the facts array in the response to FactsCommand
+	KnownActs      []KnownAct    `mapstructure:"known_acts"`     // Acts checked for GET /policy-enforcer/enabled-acts when the server does not list enabled transitions
+	ClauseTypes    []ClauseType  `mapstructure:"clause_types"`   // Clause types queryable via GET /policy-enforcer/allowed in addition to the built-in ones
+	RequestAct     RequestAct    `mapstructure:"request_act"`    // Act request validations are checked with, and its parameter fact types
+	MinPort        int           `mapstructure:"min_port"`       // Lowest port an eFLINT server may listen on
+	MaxPort        int           `mapstructure:"max_port"`       // Highest port an eFLINT server may listen on
+	ExcludedPorts  []string      `mapstructure:"excluded_ports"` // Ports ("5432") and ranges ("8000-8100") never used
 	ModelPath      string        `mapstructure:"model_path"`
-	Timeout        time.Duration `mapstructure:"timeout"`
+	ModelPaths     []string      `mapstructure:"model_paths"`     // Model files or directories merged in order; overrides model_path
+	ModelURLHosts  []string      `mapstructure:"model_url_hosts"` // Hosts models may be downloaded from ("host" or "host:port"); empty refuses model URLs
+	Timeout        time.Duration `mapstructure:"timeout"`         // Default for dial_timeout and command_timeout
+	DialTimeout    time.Duration `mapstructure:"dial_timeout"`    // Time to connect to the eFLINT server (0 = timeout)
+	CommandTimeout time.Duration `mapstructure:"command_timeout"` // Time to get an answer to a command (0 = timeout)
 	ReconnectDelay time.Duration `mapstructure:"reconnect_delay"`
 	MaxRetries     int           `mapstructure:"max_retries"`
+
+	MaxResponseSize int64         `mapstructure:"max_response_size"` // Maximum size in bytes of a single eFLINT response
+	PoolSize        int           `mapstructure:"pool_size"`         // Maximum idle connections kept per instance (0 = disabled)
+	PoolMaxOpen     int           `mapstructure:"pool_max_open"`     // Maximum connections open per instance; further commands wait (0 = unlimited)
+	PoolIdleTimeout time.Duration `mapstructure:"pool_idle_timeout"` // Idle pooled connections older than this are discarded
+
+	SelfTest         bool `mapstructure:"self_test"`           // Run a facts query after auto-start to verify the model
+	FailOnEmptyModel bool `mapstructure:"fail_on_empty_model"` // Abort startup if the self-test fails, finds no facts or the model lacks the request act
+
+	FactsCache    bool          `mapstructure:"facts_cache"`    // Reuse the facts of a facts query until the eFLINT state changes
+	Warmup        bool          `mapstructure:"warmup"`         // Fetch the facts once after auto-start, filling the facts cache
+	WarmupTimeout time.Duration `mapstructure:"warmup_timeout"` // Startup waits at most this long for the warmup
+	WarmupStrict  bool          `mapstructure:"warmup_strict"`  // Abort startup if the warmup fails instead of warning
+
+	HistorySize   int  `mapstructure:"history_size"`    // Number of recent commands kept for GET /eflint/history (0 = disabled)
+	RedactPhrases bool `mapstructure:"redact_phrases"`  // Replace phrase text in the command history
+	ServerLogSize int  `mapstructure:"server_log_size"` // Recent server output lines kept for GET /eflint/logs/stream (0 = disabled)
+
+	KillTimeout time.Duration `mapstructure:"kill_timeout"` // Time eflint-server gets to exit after SIGTERM before SIGKILL
+	PIDFile     string        `mapstructure:"pid_file"`     // File recording started eflint-server processes, to kill orphans on startup ("" = disabled)
+
+	ReadyTimeout time.Duration `mapstructure:"ready_timeout"` // Time a (re)started model gets to answer before /health/ready reports failure (0 = no gate)
+
+	ReadOnly         bool     `mapstructure:"read_only"`          // Refuse commands that change the state with 403, e.g. on a query replica
+	ReadOnlyCommands []string `mapstructure:"read_only_commands"` // Commands refused in read-only mode
+
+	StateAPIEnabled bool          `mapstructure:"state_api_enabled"` // Whether the state management API is exposed
+	StateStore      string        `mapstructure:"state_store"`       // Storage for saved states: "filesystem" (default), "memory" or "s3"
+	StateFacts      bool          `mapstructure:"state_facts"`       // Record the facts with every saved state and checkpoint (one extra facts query per export)
+	StateDir        string        `mapstructure:"state_dir"`         // Directory for persisting saved states and checkpoints (filesystem store)
+	StateS3         StateS3Config `mapstructure:"state_s3"`          // Bucket settings (s3 store)
+}
+
+// KnownAct is an act whose arguments are all filled in from the principal of an
+// enabled-acts query
+type KnownAct struct {
+	Act       string   `mapstructure:"act"`       // The act, e.g. "register-requester"
+	Arguments []string `mapstructure:"arguments"` // Argument fact types in order: "org" (organization) or "req" (requester)
+}
+
+// ClauseType defines a clause type of the model beyond the four built-in ones
+type ClauseType struct {
+	Name          string `mapstructure:"name"`            // Name used in ?type=, e.g. "purpose"
+	FactType      string `mapstructure:"fact_type"`       // Fact type granting it, with arguments (organization, requester, value), e.g. "allowed-purpose"
+	ValueFactType string `mapstructure:"value_fact_type"` // Fact type of the value argument, e.g. "purpose"
+}
+
+// RequestAct names the act that request validations are checked with and the fact
+// types of its parameters, in the order the act declares them
+type RequestAct struct {
+	Act             string `mapstructure:"act"`              // e.g. "submit-request"
+	Requester       string `mapstructure:"requester"`        // e.g. "req"
+	Organization    string `mapstructure:"organization"`     // e.g. "org"
+	RequestType     string `mapstructure:"request_type"`     // e.g. "rtype"
+	DataSet         string `mapstructure:"data_set"`         // e.g. "dataset"
+	Archetype       string `mapstructure:"archetype"`        // e.g. "arch"
+	ComputeProvider string `mapstructure:"compute_provider"` // e.g. "provider"
+}
+
+// StateS3Config holds S3 settings for the eFLINT state store
+type StateS3Config struct {
+	Endpoint        string        `mapstructure:"endpoint"`                           // Base URL of the S3 service (default: AWS for the region)
+	Region          string        `mapstructure:"region"`                             // Region used for request signing
+	Bucket          string        `mapstructure:"bucket"`                             // Bucket holding the states
+	Prefix          string        `mapstructure:"prefix"`                             // Key prefix for state objects
+	AccessKeyID     string        `mapstructure:"access_key_id" sensitive:"true"`     // Defaults to AWS_ACCESS_KEY_ID
+	SecretAccessKey string        `mapstructure:"secret_access_key" sensitive:"true"` // Defaults to AWS_SECRET_ACCESS_KEY
+	SessionToken    string        `mapstructure:"session_token" sensitive:"true"`     // Temporary credentials; defaults to AWS_SESSION_TOKEN with access_key_id
+	Timeout         time.Duration `mapstructure:"timeout"`
+}
+
+// PolicyConfig holds defaults for policy enforcer queries
+type PolicyConfig struct {
+	DefaultOrganization string `mapstructure:"default_organization"` // Used when a request omits the organization (single-tenant deployments)
+	DefaultRequester    string `mapstructure:"default_requester"`    // Used when a request omits the requester (single-tenant deployments)
+	ExecuteActs         bool   `mapstructure:"execute_acts"`         // Expose POST /policy-enforcer/execute-act, which changes the reasoner state
+	StrictJSON          bool   `mapstructure:"strict_json"`          // Reject validate and import bodies with unknown fields
+	DenialStatus        string `mapstructure:"denial_status"`        // HTTP status of denied validations: "ok" (200) or "semantic" (403)
+}
+
+// RateLimitConfig holds token-bucket rate limiting settings for the policy enforcer API
+type RateLimitConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`
+	RequesterRate  float64 `mapstructure:"requester_rate"`  // Requests per second per requester
+	RequesterBurst int     `mapstructure:"requester_burst"` // Burst size per requester
+	GlobalRate     float64 `mapstructure:"global_rate"`     // Requests per second across all requesters
+	GlobalBurst    int     `mapstructure:"global_burst"`    // Burst size across all requesters
+}
+
+// CacheConfig holds settings for the allowed-clauses response cache
+type CacheConfig struct {
+	Size int           `mapstructure:"size"` // Maximum number of cached (organization, requester) entries (0 = disabled)
+	TTL  time.Duration `mapstructure:"ttl"`  // Maximum age of a cached entry
+}
+
+// ConcurrencyConfig limits concurrent validations and facts fetches against the reasoner
+type ConcurrencyConfig struct {
+	MaxInFlight  int           `mapstructure:"max_in_flight"` // Operations running at once (0 = unlimited)
+	QueueSize    int           `mapstructure:"queue_size"`    // Operations waiting for a slot; further ones get 503
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"` // How long an operation waits for a slot before it gets 503
+}
+
+// AsyncConfig holds settings for asynchronous validation (POST /policy-enforcer/validate?async=true)
+type AsyncConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Workers   int           `mapstructure:"workers"`    // Validations run concurrently
+	QueueSize int           `mapstructure:"queue_size"` // Jobs waiting for a worker; further jobs get 503
+	TTL       time.Duration `mapstructure:"ttl"`        // How long a finished job can be fetched
+	Timeout   time.Duration `mapstructure:"timeout"`    // Maximum duration of a single validation (0 = no limit)
+}
+
+// DebugConfig enables endpoints for debugging a deployment. They expose internals,
+// so they are off by default and require authentication when auth is enabled.
+type DebugConfig struct {
+	ConfigEndpoint bool `mapstructure:"config_endpoint"` // GET /debug/config: the effective configuration, secrets redacted
+	Metrics        bool `mapstructure:"metrics"`         // GET /debug/vars: runtime metrics such as cache and circuit breaker state (expvar)
+	Pprof          bool `mapstructure:"pprof"`           // /debug/pprof/*: CPU, heap and other runtime profiles (net/http/pprof)
+}
+
+// AdminConfig enables the /admin endpoints, which change the running service (e.g.
+// POST /admin/reload-config). They require authentication when auth is enabled.
+type AdminConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// OrgScopeConfig restricts which organizations each principal may query via the policy enforcer API
+type OrgScopeConfig struct {
+	Enabled         bool                `mapstructure:"enabled"`
+	PrincipalHeader string              `mapstructure:"principal_header"` // Header set by the authenticating proxy
+	PrincipalClaim  string              `mapstructure:"principal_claim"`  // JWT claim naming the principal when auth is enabled
+	Organizations   map[string][]string `mapstructure:"organizations"`    // Principal to allowed organizations ("*" = all); principals are case-insensitive
+}
+
+// AuthConfig holds JWT bearer authentication settings for the /eflint and /policy-enforcer APIs
+type AuthConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Secret         string        `mapstructure:"secret" sensitive:"true"` // Shared secret for HS256/384/512 tokens
+	JWKSURL        string        `mapstructure:"jwks_url"`                // JWKS endpoint for RS/ES-signed tokens
+	Issuer         string        `mapstructure:"issuer"`                  // Required "iss" claim (empty = not checked)
+	Audience       string        `mapstructure:"audience"`                // Required "aud" claim entry (empty = not checked)
+	RequiredClaims []string      `mapstructure:"required_claims"`         // Claims every token must carry
+	Leeway         time.Duration `mapstructure:"leeway"`                  // Allowed clock skew for "exp" and "nbf"
+	JWKSRefresh    time.Duration `mapstructure:"jwks_refresh"`            // How often the JWKS is re-fetched
+}
+
+// ShutdownConfig holds the timeouts of the shutdown stages, which run in this order
+type ShutdownConfig struct {
+	DrainTimeout    time.Duration `mapstructure:"drain_timeout"`    // Wait for in-flight HTTP requests (no new ones are accepted), then for queued async validations
+	EflintTimeout   time.Duration `mapstructure:"eflint_timeout"`   // Stop the eFLINT server
+	RabbitMQTimeout time.Duration `mapstructure:"rabbitmq_timeout"` // Stop consuming and close the RabbitMQ connection
+	HTTPTimeout     time.Duration `mapstructure:"http_timeout"`     // Close the HTTP server and any remaining connections
 }
 
 // LoggingConfig holds logging settings
@@ -63,6 +314,90 @@ func Load(configPath string) (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvPrefix("PE") // Policy Enforcer
 
+	// Defaults
+	v.SetDefault("reasoner.type", "eflint")
+	v.SetDefault("reasoner.circuit_breaker.failure_threshold", 5)
+	v.SetDefault("reasoner.circuit_breaker.cooldown", "30s")
+	v.SetDefault("rabbitmq.workers", 1)
+	v.SetDefault("rabbitmq.max_redeliveries", 5)
+	v.SetDefault("rabbitmq.reconnect_delay", "5s")
+	v.SetDefault("rabbitmq.max_reconnect_delay", "1m")
+	v.SetDefault("rabbitmq.connect_timeout", "2m")
+	v.SetDefault("server.bind_address", "0.0.0.0")
+	v.SetDefault("server.body_limit", "10M")
+	v.SetDefault("server.max_request_timeout", "60s")
+	v.SetDefault("server.tls.enabled", false)
+	v.SetDefault("server.tls.autocert_cache_dir", "autocert-cache")
+	v.SetDefault("server.tls.http2", true)
+	v.SetDefault("server.compression.enabled", true)
+	v.SetDefault("server.compression.level", -1)
+	v.SetDefault("server.compression.min_length", 1024)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.port", 9090)
+	v.SetDefault("eflint.max_response_size", 64<<20)
+	v.SetDefault("eflint.pool_max_open", 64)
+	v.SetDefault("rate_limit.enabled", false)
+	v.SetDefault("rate_limit.requester_rate", 20)
+	v.SetDefault("rate_limit.requester_burst", 40)
+	v.SetDefault("rate_limit.global_rate", 200)
+	v.SetDefault("rate_limit.global_burst", 400)
+	v.SetDefault("async.enabled", false)
+	v.SetDefault("async.workers", 4)
+	v.SetDefault("async.queue_size", 100)
+	v.SetDefault("async.ttl", "10m")
+	v.SetDefault("async.timeout", "5m")
+	v.SetDefault("cache.size", 0)
+	v.SetDefault("cache.ttl", "30s")
+	v.SetDefault("policy.strict_json", true)
+	v.SetDefault("policy.denial_status", "ok")
+	v.SetDefault("debug.config_endpoint", false)
+	v.SetDefault("debug.metrics", false)
+	v.SetDefault("debug.pprof", false)
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("concurrency.max_in_flight", 4)
+	v.SetDefault("concurrency.queue_size", 32)
+	v.SetDefault("concurrency.queue_timeout", "2s")
+	v.SetDefault("org_scope.enabled", false)
+	v.SetDefault("org_scope.principal_header", "X-Principal")
+	v.SetDefault("org_scope.principal_claim", "sub")
+	v.SetDefault("auth.enabled", false)
+	v.SetDefault("auth.leeway", "30s")
+	v.SetDefault("auth.jwks_refresh", "1h")
+	v.SetDefault("eflint.self_test", true)
+	v.SetDefault("eflint.facts_cache", false)
+	v.SetDefault("eflint.warmup", false)
+	v.SetDefault("eflint.warmup_timeout", "10s")
+	v.SetDefault("eflint.warmup_strict", false)
+	v.SetDefault("eflint.history_size", 100)
+	v.SetDefault("eflint.server_log_size", 500)
+	v.SetDefault("eflint.kill_timeout", "5s")
+	v.SetDefault("eflint.ready_timeout", "30s")
+	v.SetDefault("eflint.read_only", false)
+	v.SetDefault("eflint.read_only_commands", []string{"phrase", "create", "terminate", "load-export", "revert", "kill"})
+	v.SetDefault("eflint.facts_command", `{"command": "facts"}`)
+	v.SetDefault("eflint.min_port", 49152)
+	v.SetDefault("eflint.max_port", 65535)
+	v.SetDefault("eflint.facts_key", "values")
+	v.SetDefault("eflint.known_acts", []map[string]interface{}{
+		{"act": "register-requester", "arguments": []string{"org", "req"}},
+		{"act": "unregister-requester", "arguments": []string{"org", "req"}},
+	})
+	v.SetDefault("eflint.request_act.act", "submit-request")
+	v.SetDefault("eflint.request_act.requester", "req")
+	v.SetDefault("eflint.request_act.organization", "org")
+	v.SetDefault("eflint.request_act.request_type", "rtype")
+	v.SetDefault("eflint.request_act.data_set", "dataset")
+	v.SetDefault("eflint.request_act.archetype", "arch")
+	v.SetDefault("eflint.request_act.compute_provider", "provider")
+	v.SetDefault("shutdown.drain_timeout", "10s")
+	v.SetDefault("shutdown.eflint_timeout", "10s")
+	v.SetDefault("shutdown.rabbitmq_timeout", "5s")
+	v.SetDefault("shutdown.http_timeout", "5s")
+	v.SetDefault("eflint.state_api_enabled", true)
+	v.SetDefault("eflint.state_store", "filesystem")
+	v.SetDefault("eflint.state_facts", true)
+	v.SetDefault("eflint.state_dir", "eflint-states")
+
 	// Read config file
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
@@ -73,5 +408,279 @@ func Load(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	if config.EFlint.StateAPIEnabled && (config.EFlint.StateStore == "" || config.EFlint.StateStore == "filesystem") {
+		if err := validateStateDir(config.EFlint.StateDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateBindAddress(config.Server.BindAddress); err != nil {
+		return nil, err
+	}
+
+	if err := validateTLS(config.Server.TLS); err != nil {
+		return nil, err
+	}
+
+	if err := validateCompression(config.Server.Compression); err != nil {
+		return nil, err
+	}
+
+	if err := validateGRPC(config.GRPC, config.Server.TLS); err != nil {
+		return nil, err
+	}
+
+	if err := validateServerArgs(config.EFlint.ServerArgs); err != nil {
+		return nil, err
+	}
+
+	if err := validateFactsQuery(config.EFlint.FactsCommand, config.EFlint.FactsKey); err != nil {
+		return nil, err
+	}
+
+	if err := validateClauseTypes(config.EFlint.ClauseTypes); err != nil {
+		return nil, err
+	}
+
+	if err := validateKnownActs(config.EFlint.KnownActs); err != nil {
+		return nil, err
+	}
+
+	if err := validatePorts(config.EFlint.MinPort, config.EFlint.MaxPort, config.EFlint.ExcludedPorts); err != nil {
+		return nil, err
+	}
+
+	if err := validatePool(config.EFlint.PoolSize, config.EFlint.PoolMaxOpen); err != nil {
+		return nil, err
+	}
+
+	if err := validateRabbitMQ(config.RabbitMQ); err != nil {
+		return nil, err
+	}
+
+	if err := validateDenialStatus(config.Policy.DenialStatus); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
+
+// Addr returns the address the HTTP server listens on for the given port.
+func (c ServerConfig) Addr(port string) string {
+	return net.JoinHostPort(c.BindAddress, port)
+}
+
+// validateBindAddress checks that the bind address is an IP address or a host
+// name, without a port. An empty address binds all interfaces.
+func validateBindAddress(addr string) error {
+	if addr == "" || net.ParseIP(addr) != nil || hostnamePattern.MatchString(addr) {
+		return nil
+	}
+	return fmt.Errorf("%w: server.bind_address %q is not a valid IP address or host name", ErrInvalidConfig, addr)
+}
+
+// hostnamePattern matches RFC 1123 host names.
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// validateTLS checks that an enabled TLS setup has exactly one certificate source
+// and that the certificate and key files can be read and form a valid pair.
+func validateTLS(cfg TLSConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	hasFiles := cfg.CertFile != "" || cfg.KeyFile != ""
+	switch {
+	case hasFiles && len(cfg.AutocertDomains) > 0:
+		return fmt.Errorf("%w: server.tls: set either cert_file/key_file or autocert_domains, not both", ErrInvalidConfig)
+	case len(cfg.AutocertDomains) > 0:
+		return nil
+	case cfg.CertFile == "" || cfg.KeyFile == "":
+		return fmt.Errorf("%w: server.tls requires cert_file and key_file, or autocert_domains", ErrInvalidConfig)
+	}
+
+	if _, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+		return fmt.Errorf("%w: server.tls: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// validateGRPC checks the port of an enabled gRPC server and that TLS, if enabled,
+// uses certificate files, since autocert certificates are only served over HTTP.
+func validateGRPC(cfg GRPCConfig, tls TLSConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("%w: grpc.port %d must be between 1 and 65535", ErrInvalidConfig, cfg.Port)
+	}
+	if tls.Enabled && len(tls.AutocertDomains) > 0 {
+		return fmt.Errorf("%w: grpc requires server.tls cert_file and key_file; autocert_domains is not supported", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// validateCompression checks the gzip level and minimum length of enabled compression.
+func validateCompression(cfg CompressionConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Level != -1 && (cfg.Level < 1 || cfg.Level > 9) {
+		return fmt.Errorf("%w: server.compression.level %d must be -1 or between 1 and 9", ErrInvalidConfig, cfg.Level)
+	}
+	if cfg.MinLength < 0 {
+		return fmt.Errorf("%w: server.compression.min_length must not be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// validateServerArgs checks that a custom eflint-server argument template contains
+// both the {model} and the {port} placeholder.
+func validateServerArgs(args []string) error {
+	if err := eflint.ValidateServerArgs(args); err != nil {
+		return fmt.Errorf("%w: eflint.server_args: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// validateFactsQuery checks that the facts command and the key of its facts array
+// are set; they default to the eflint-server dialect and may only be replaced.
+func validateFactsQuery(command, key string) error {
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("%w: eflint.facts_command must not be empty", ErrInvalidConfig)
+	}
+	if key == "" {
+		return fmt.Errorf("%w: eflint.facts_key must not be empty", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// validateClauseTypes checks that every clause type has a unique name and both fact types.
+func validateClauseTypes(clauseTypes []ClauseType) error {
+	seen := make(map[string]bool, len(clauseTypes))
+	for i, clauseType := range clauseTypes {
+		if strings.TrimSpace(clauseType.Name) == "" {
+			return fmt.Errorf("%w: eflint.clause_types[%d] has no name", ErrInvalidConfig, i)
+		}
+		if clauseType.FactType == "" || clauseType.ValueFactType == "" {
+			return fmt.Errorf("%w: eflint.clause_types[%d] (%s) needs fact_type and value_fact_type",
+				ErrInvalidConfig, i, clauseType.Name)
+		}
+		if seen[clauseType.Name] {
+			return fmt.Errorf("%w: eflint.clause_types has %q twice", ErrInvalidConfig, clauseType.Name)
+		}
+		seen[clauseType.Name] = true
+	}
+	return nil
+}
+
+// validateKnownActs checks that every known act is named and that each of its
+// arguments is the organization ("org") or the requester ("req") of the principal.
+func validateKnownActs(acts []KnownAct) error {
+	for i, act := range acts {
+		if strings.TrimSpace(act.Act) == "" {
+			return fmt.Errorf("%w: eflint.known_acts[%d] has no act", ErrInvalidConfig, i)
+		}
+		for _, arg := range act.Arguments {
+			if arg != "org" && arg != "req" {
+				return fmt.Errorf("%w: eflint.known_acts[%d] (%s) has argument %q; only \"org\" and \"req\" can be filled in",
+					ErrInvalidConfig, i, act.Act, arg)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePorts checks that the eFLINT port range is valid, that every exclusion is
+// a port or a port range, and that the exclusions leave at least one port.
+func validatePorts(minPort, maxPort int, excluded []string) error {
+	ranges, err := eflint.ParsePortRanges(excluded)
+	if err != nil {
+		return fmt.Errorf("%w: eflint.excluded_ports: %w", ErrInvalidConfig, err)
+	}
+	if err := eflint.ValidatePortRange(minPort, maxPort, ranges); err != nil {
+		return fmt.Errorf("%w: eflint.min_port and eflint.max_port: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// validatePool checks the connection pool limits of eflint.pool_size and
+// eflint.pool_max_open.
+func validatePool(size, maxOpen int) error {
+	if size < 0 {
+		return fmt.Errorf("%w: eflint.pool_size must not be negative, got %d", ErrInvalidConfig, size)
+	}
+	if maxOpen < 0 {
+		return fmt.Errorf("%w: eflint.pool_max_open must not be negative, got %d", ErrInvalidConfig, maxOpen)
+	}
+	return nil
+}
+
+// validateDenialStatus checks that denied validations answer with a known status mode.
+func validateDenialStatus(mode string) error {
+	switch mode {
+	case "", "ok", "semantic":
+		return nil
+	}
+	return fmt.Errorf("%w: policy.denial_status %q must be \"ok\" or \"semantic\"", ErrInvalidConfig, mode)
+}
+
+// ModelLocations returns the model locations to start eFLINT with: ModelPaths if
+// set, otherwise ModelPath. It returns nil if no model is configured.
+func (c EFlintConfig) ModelLocations() []string {
+	if len(c.ModelPaths) > 0 {
+		return c.ModelPaths
+	}
+	if c.ModelPath != "" {
+		return []string{c.ModelPath}
+	}
+	return nil
+}
+
+// validateRabbitMQ checks the message processing settings. The worker pool must be
+// kept busy: workers beyond the prefetch count would never receive a message.
+func validateRabbitMQ(cfg RabbitMQConfig) error {
+	if cfg.Workers < 1 {
+		return fmt.Errorf("rabbitmq.workers must be at least 1, got %d", cfg.Workers)
+	}
+	if cfg.PrefetchCount > 0 && cfg.Workers > cfg.PrefetchCount {
+		return fmt.Errorf("rabbitmq.workers (%d) must not exceed rabbitmq.prefetch_count (%d)",
+			cfg.Workers, cfg.PrefetchCount)
+	}
+	if cfg.MaxRedeliveries < 1 {
+		return fmt.Errorf("rabbitmq.max_redeliveries must be at least 1, got %d", cfg.MaxRedeliveries)
+	}
+	if cfg.ReconnectDelay <= 0 {
+		return fmt.Errorf("rabbitmq.reconnect_delay must be positive, got %s", cfg.ReconnectDelay)
+	}
+	if cfg.MaxReconnectDelay < cfg.ReconnectDelay {
+		return fmt.Errorf("rabbitmq.max_reconnect_delay (%s) must not be less than rabbitmq.reconnect_delay (%s)",
+			cfg.MaxReconnectDelay, cfg.ReconnectDelay)
+	}
+	if cfg.ConnectTimeout < 0 {
+		return fmt.Errorf("rabbitmq.connect_timeout must not be negative, got %s", cfg.ConnectTimeout)
+	}
+	return nil
+}
+
+// validateStateDir ensures the state directory exists (creating it if needed)
+// and that the service can write to it.
+func validateStateDir(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("eflint.state_dir is required when the state API is enabled")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory %q: %w", dir, err)
+	}
+
+	// Probe writability with a throwaway file
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("state directory %q is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return nil
+}
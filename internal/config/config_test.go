@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+func TestValidateServerArgs(t *testing.T) {
+	for _, args := range [][]string{nil, {"{model}", "{port}"}, {"--model={model}", "--port", "{port}"}} {
+		if err := validateServerArgs(args); err != nil {
+			t.Errorf("validateServerArgs(%q) error = %v", args, err)
+		}
+	}
+	for _, args := range [][]string{{"{model}"}, {"--port", "{port}"}} {
+		err := validateServerArgs(args)
+		if !errors.Is(err, ErrInvalidConfig) || !errors.Is(err, eflint.ErrInvalidServerArgs) {
+			t.Errorf("validateServerArgs(%q) error = %v, want ErrInvalidConfig wrapping eflint.ErrInvalidServerArgs", args, err)
+		}
+	}
+}
+
+func TestValidatePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		excluded []string
+		valid    bool
+	}{
+		{"range", 8000, 8100, nil, true},
+		{"exclusions", 8000, 8100, []string{"8000-8050", " 8060 ", "9000"}, true},
+		{"one port left", 8000, 8002, []string{"8000", "8002"}, true},
+		{"reversed range", 8100, 8000, nil, false},
+		{"port zero", 0, 8000, nil, false},
+		{"beyond 65535", 8000, 70000, nil, false},
+		{"malformed exclusion", 8000, 8100, []string{"80a0"}, false},
+		{"reversed exclusion", 8000, 8100, []string{"8050-8010"}, false},
+		{"every port excluded", 8000, 8100, []string{"7000-8050", "8051-9000"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePorts(tt.min, tt.max, tt.excluded)
+			if tt.valid && err != nil {
+				t.Errorf("validatePorts() error = %v", err)
+			}
+			if !tt.valid && (!errors.Is(err, ErrInvalidConfig) || !errors.Is(err, eflint.ErrInvalidPortRange)) {
+				t.Errorf("validatePorts() error = %v, want ErrInvalidConfig wrapping eflint.ErrInvalidPortRange", err)
+			}
+		})
+	}
+}
+
+func TestValidateGRPC(t *testing.T) {
+	files := TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}
+	autocert := TLSConfig{Enabled: true, AutocertDomains: []string{"pe.example.com"}}
+
+	tests := []struct {
+		name  string
+		grpc  GRPCConfig
+		tls   TLSConfig
+		valid bool
+	}{
+		{"disabled", GRPCConfig{Port: 0}, autocert, true},
+		{"plain", GRPCConfig{Enabled: true, Port: 9090}, TLSConfig{}, true},
+		{"certificate files", GRPCConfig{Enabled: true, Port: 9090}, files, true},
+		{"port zero", GRPCConfig{Enabled: true}, TLSConfig{}, false},
+		{"beyond 65535", GRPCConfig{Enabled: true, Port: 70000}, TLSConfig{}, false},
+		{"autocert", GRPCConfig{Enabled: true, Port: 9090}, autocert, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGRPC(tt.grpc, tt.tls)
+			if tt.valid && err != nil {
+				t.Errorf("validateGRPC() error = %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("validateGRPC() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces the value of sensitive settings in Redacted.
+const redactedValue = "[REDACTED]"
+
+// Redacted returns the configuration as nested maps keyed by the setting names of
+// the config file (e.g. "rabbitmq" -> "password"), for GET /debug/config. Settings
+// tagged `sensitive:"true"` are replaced by "[REDACTED]" when set, so the result
+// can be shown to operators; unset ones stay empty, which shows they are missing.
+// Durations are rendered as in the config file (e.g. "30s").
+func (c *Config) Redacted() map[string]any {
+	return redact(reflect.ValueOf(*c)).(map[string]any)
+}
+
+// redact converts v to JSON-friendly values, redacting sensitive struct fields.
+func redact(v reflect.Value) any {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if field.Tag.Get("sensitive") == "true" {
+				if v.Field(i).IsZero() {
+					out[name] = ""
+				} else {
+					out[name] = redactedValue
+				}
+				continue
+			}
+			out[name] = redact(v.Field(i))
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redact(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = redact(iter.Value())
+		}
+		return out
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// -----------------------------------------------------------------------------
+// Config Reload
+// -----------------------------------------------------------------------------
+//
+// A running service can re-read its config file (on SIGHUP or POST
+// /admin/reload-config) and apply the settings that can change at runtime, such
+// as the log level and rate limits. Every other changed setting is reported as
+// requiring a restart; it keeps its old value until then.
+
+// ReloadResult reports the outcome of a config reload. Settings are named as in
+// the config file, e.g. "rate_limit.global_rate".
+type ReloadResult struct {
+	Applied         []string          `json:"applied"`          // Changed settings now in effect
+	RestartRequired []string          `json:"restart_required"` // Changed settings that take effect after a restart
+	Failed          map[string]string `json:"failed,omitempty"` // Changed settings that could not be applied, with the reason
+}
+
+// reloadHandler applies a setting, or all settings of a section, at runtime.
+type reloadHandler struct {
+	setting string
+	apply   func(*Config) error
+}
+
+// Reloader re-reads the configuration file and applies the changed settings that
+// have a handler. Thread-safe for concurrent access.
+type Reloader struct {
+	path     string
+	started  *Config // Configuration the service was started with
+	current  *Config // Configuration of the last reload
+	handlers []reloadHandler
+	mu       sync.Mutex
+}
+
+// NewReloader creates a reloader for the config file at path (see Load), given
+// the configuration the service was started with.
+func NewReloader(path string, started *Config) *Reloader {
+	return &Reloader{path: path, started: started, current: started}
+}
+
+// Handle registers apply for a setting, or for every setting of a section when
+// setting names one (e.g. "rate_limit"). On reload, apply is called once with the
+// new configuration if any of its settings changed; it returns an error if the
+// change cannot be applied at runtime, e.g. enabling a feature that was off at
+// startup. Register handlers before the first reload.
+func (r *Reloader) Handle(setting string, apply func(*Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, reloadHandler{setting: setting, apply: apply})
+}
+
+// Reload re-reads the config file and applies the changed settings that have a
+// handler. An invalid config file returns an error and changes nothing.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	changed := Changes(r.current, next)
+	for _, h := range r.handlers {
+		settings := slices.DeleteFunc(slices.Clone(changed), func(s string) bool { return !h.covers(s) })
+		if len(settings) == 0 {
+			continue
+		}
+		if err := h.apply(next); err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			for _, s := range settings {
+				result.Failed[s] = err.Error()
+			}
+			continue
+		}
+		result.Applied = append(result.Applied, settings...)
+	}
+
+	// Compared with the startup configuration, so that they are reported until the restart
+	for _, s := range Changes(r.started, next) {
+		if !r.handled(s) {
+			result.RestartRequired = append(result.RestartRequired, s)
+		}
+	}
+
+	// Failed settings keep their previous values, so that they are reported as
+	// changed, and applied again, on the next reload
+	current := *next
+	for s := range result.Failed {
+		copySetting(reflect.ValueOf(&current).Elem(), reflect.ValueOf(r.current).Elem(), s)
+	}
+	r.current = &current
+	return result, nil
+}
+
+// covers reports whether the handler applies setting.
+func (h reloadHandler) covers(setting string) bool {
+	return setting == h.setting || strings.HasPrefix(setting, h.setting+".")
+}
+
+// handled reports whether a handler applies setting. Caller must hold mu.
+func (r *Reloader) handled(setting string) bool {
+	for _, h := range r.handlers {
+		if h.covers(setting) {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes returns the names of the settings whose values differ between old and
+// next, e.g. ["logging.level", "rate_limit.global_rate"].
+func Changes(old, next *Config) []string {
+	var changed []string
+	collectChanges(reflect.ValueOf(*old), reflect.ValueOf(*next), "", &changed)
+	return changed
+}
+
+// collectChanges appends the names of the differing fields of a and b to changed.
+// Structs are compared field by field; any other values as a whole.
+func collectChanges(a, b reflect.Value, prefix string, changed *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, prefix)
+		}
+		return
+	}
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := settingName(field)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		collectChanges(a.Field(i), b.Field(i), name, changed)
+	}
+}
+
+// copySetting sets a setting, named as in Changes, of the config dst to its value
+// in src.
+func copySetting(dst, src reflect.Value, setting string) {
+	for _, name := range strings.Split(setting, ".") {
+		if dst.Kind() != reflect.Struct {
+			return
+		}
+		i := fieldIndex(dst.Type(), name)
+		if i < 0 {
+			return
+		}
+		dst, src = dst.Field(i), src.Field(i)
+	}
+	dst.Set(src)
+}
+
+// fieldIndex returns the index of the exported field of t named name in the config
+// file, or -1.
+func fieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() && settingName(field) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// settingName returns the name of a config field as in the config file.
+func settingName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+// ParseLevel returns the log level for logging.level: debug, info, warn or error.
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info", "":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	}
+	return zapcore.InfoLevel, fmt.Errorf("%w: logging.level %q must be debug, info, warn or error", ErrInvalidConfig, level)
+}
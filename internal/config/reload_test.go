@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeConfig writes a config file with the given YAML content to path. The
+// content must not set eflint: the file keeps the state directory next to it
+// rather than in the working directory.
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	content += "eflint:\n  state_dir: " + filepath.Join(filepath.Dir(path), "states") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	old := &Config{}
+	old.Logging.Level = "info"
+	old.RateLimit.GlobalRate = 200
+	old.Server.BodyLimit = "10M"
+
+	next := *old
+	if changed := Changes(old, &next); len(changed) != 0 {
+		t.Errorf("Changes() = %v for equal configs, want none", changed)
+	}
+
+	next.Logging.Level = "debug"
+	next.RateLimit.GlobalRate = 100
+	next.Server.BodyLimit = "1M"
+	want := []string{"server.body_limit", "rate_limit.global_rate", "logging.level"}
+	changed := Changes(old, &next)
+	slices.Sort(changed)
+	slices.Sort(want)
+	if !slices.Equal(changed, want) {
+		t.Errorf("Changes() = %v, want %v", changed, want)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "logging:\n  level: info\n")
+	started, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	reloader := NewReloader(path, started)
+	var levels []string
+	reloader.Handle("logging.level", func(next *Config) error {
+		levels = append(levels, next.Logging.Level)
+		return nil
+	})
+	rateLimitErr := errors.New("rate limiting was disabled at startup")
+	failRateLimit := true
+	var rates []float64
+	reloader.Handle("rate_limit", func(next *Config) error {
+		rates = append(rates, next.RateLimit.GlobalRate)
+		if failRateLimit {
+			return rateLimitErr
+		}
+		return nil
+	})
+
+	writeConfig(t, path, "logging:\n  level: debug\nrate_limit:\n  global_rate: 50\nserver:\n  body_limit: 1M\n")
+	result, err := reloader.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !slices.Equal(result.Applied, []string{"logging.level"}) {
+		t.Errorf("Applied = %v, want [logging.level]", result.Applied)
+	}
+	if result.Failed["rate_limit.global_rate"] != rateLimitErr.Error() || len(result.Failed) != 1 {
+		t.Errorf("Failed = %v, want rate_limit.global_rate", result.Failed)
+	}
+	if !slices.Equal(result.RestartRequired, []string{"server.body_limit"}) {
+		t.Errorf("RestartRequired = %v, want [server.body_limit]", result.RestartRequired)
+	}
+
+	// The failed setting is still a change on the next reload; the applied one is not
+	result, err = reloader.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(result.Applied) != 0 || result.Failed["rate_limit.global_rate"] == "" {
+		t.Errorf("second Reload() = %+v, want only rate_limit.global_rate failing again", result)
+	}
+	if !slices.Equal(result.RestartRequired, []string{"server.body_limit"}) {
+		t.Errorf("RestartRequired = %v, want [server.body_limit] until the restart", result.RestartRequired)
+	}
+	if !slices.Equal(levels, []string{"debug"}) || !slices.Equal(rates, []float64{50, 50}) {
+		t.Errorf("handlers applied levels %v and rates %v, want [debug] and [50 50]", levels, rates)
+	}
+
+	// Once the handler succeeds, the setting is applied and no longer a change
+	failRateLimit = false
+	if result, err = reloader.Reload(); err != nil || !slices.Equal(result.Applied, []string{"rate_limit.global_rate"}) || result.Failed != nil {
+		t.Errorf("third Reload() = %+v, %v, want rate_limit.global_rate applied", result, err)
+	}
+	if result, err = reloader.Reload(); err != nil || len(result.Applied) != 0 || result.Failed != nil {
+		t.Errorf("fourth Reload() = %+v, %v, want nothing to apply", result, err)
+	}
+}
+
+func TestReloadInvalidFileChangesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "logging:\n  level: info\n")
+	started, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	reloader := NewReloader(path, started)
+	applied := false
+	reloader.Handle("logging.level", func(*Config) error {
+		applied = true
+		return nil
+	})
+
+	writeConfig(t, path, "logging: [level: debug\n")
+	if _, err := reloader.Reload(); err == nil {
+		t.Error("Reload() succeeded for an invalid config file")
+	}
+	if applied || reloader.current != started {
+		t.Error("Reload() of an invalid config file applied changes")
+	}
+}
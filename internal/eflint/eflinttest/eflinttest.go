@@ -0,0 +1,277 @@
+// Package eflinttest provides a fake eflint-server for tests of code that drives
+// an eflint.Manager.
+//
+// The fake server is the test binary itself: a Manager configured with Path as its
+// server executable starts the test binary with a model and a port, and Main,
+// called from the package's TestMain, turns that child process into a proxy that
+// listens on the port and forwards every connection to a Server in the test. The
+// Server answers each command line with its Handler, so tests script responses
+// in-process while the Manager manages a real process.
+package eflinttest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// childEnv marks a process started by a Manager as a fake server.
+const childEnv = "EFLINTTEST_CHILD"
+
+// Model file directives understood by the child process.
+const (
+	proxyDirective = "proxy "
+	failDirective  = "fail "
+)
+
+// Main runs the tests of a package, or the fake server if the test binary was
+// started by a Manager. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { eflinttest.Main(m) }
+func Main(m *testing.M) {
+	if os.Getenv(childEnv) != "" {
+		os.Exit(runChild(os.Args[1:]))
+	}
+	// Processes started by the tests inherit the variable
+	os.Setenv(childEnv, "1")
+	os.Exit(m.Run())
+}
+
+// Path returns the executable to configure as eflint.ManagerConfig.EflintServerPath.
+// It is only a fake server when the package's TestMain calls Main.
+func Path() string {
+	path, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		return os.Args[0]
+	}
+	return path
+}
+
+// FailingModel writes a model that the fake server rejects at startup: it prints
+// message to stderr and exits with status 1, like eflint-server on a parse error.
+func FailingModel(t testing.TB, message string) string {
+	t.Helper()
+	return writeModel(t, failDirective+message)
+}
+
+// writeModel writes a model file with the given content to a test directory.
+func writeModel(t testing.TB, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.eflint")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	return path
+}
+
+// WaitReady calls ping until it succeeds, failing the test after 10 seconds. Use
+// it with a Manager's Ping after Start, since the fake server process needs a
+// moment to listen on its port.
+func WaitReady(t testing.TB, ping func(ctx context.Context) error) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		err := ping(context.Background())
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fake eflint-server did not become ready: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Server
+// -----------------------------------------------------------------------------
+
+// Handler answers a command (without its newline) with a response, which the
+// server terminates with a newline. It is called concurrently for commands on
+// different connections and may block, e.g. to simulate a slow query.
+type Handler func(command string) string
+
+// Server answers the commands that fake server processes forward to it.
+// Thread-safe for concurrent access.
+type Server struct {
+	Model string // Model file to start a Manager with; its processes forward to this Server
+
+	handler  Handler
+	listener net.Listener
+	mu       sync.Mutex
+	commands []string
+	conns    map[net.Conn]struct{}
+	accepted int
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server answering commands with handler. It is closed when the
+// test ends.
+func NewServer(t testing.TB, handler Handler) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &Server{
+		handler:  handler,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.Model = writeModel(t, proxyDirective+listener.Addr().String())
+
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Commands returns the commands received so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Accepted returns the number of connections accepted so far. Every connection a
+// Manager opens to a fake server process is forwarded as one connection.
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Close stops accepting connections and closes the open ones.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// serve accepts connections until the listener is closed.
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.accepted++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle answers the commands of one connection.
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSuffix(line, "\n")
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, s.handler(command)+"\n"); err != nil {
+			return
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Child Process
+// -----------------------------------------------------------------------------
+
+// runChild runs the fake server for the model and port given as arguments and
+// returns its exit status.
+func runChild(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <model> <port>\n", os.Args[0])
+		return 2
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	directive := strings.TrimSpace(string(content))
+	if message, ok := strings.CutPrefix(directive, failDirective); ok {
+		fmt.Fprintln(os.Stderr, message)
+		return 1
+	}
+	upstream, ok := strings.CutPrefix(directive, proxyDirective)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: not a fake server model\n", args[0])
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:"+args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	go exitWithParent()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return 1
+		}
+		go proxy(conn, upstream)
+	}
+}
+
+// proxy forwards a connection to the test's Server until either side closes it.
+func proxy(conn net.Conn, upstream string) {
+	defer conn.Close()
+	server, err := net.Dial("tcp", upstream)
+	if err != nil {
+		return
+	}
+	defer server.Close()
+
+	done := make(chan struct{}, 2)
+	go func() { io.Copy(server, conn); done <- struct{}{} }()
+	go func() { io.Copy(conn, server); done <- struct{}{} }()
+	<-done
+}
+
+// exitWithParent exits once the test binary that started the process is gone, so
+// that a failed test does not leave servers behind.
+func exitWithParent() {
+	parent := os.Getppid()
+	for range time.Tick(100 * time.Millisecond) {
+		if os.Getppid() != parent {
+			os.Exit(0)
+		}
+	}
+}
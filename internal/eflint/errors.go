@@ -24,14 +24,61 @@ var (
 	// that is already running.
 	ErrInstanceAlreadyExists = errors.New("instance already exists")
 
+	// ErrRestartInProgress is returned when a start, restart or model update is
+	// requested while another one is still running.
+	ErrRestartInProgress = errors.New("eFLINT server restart already in progress")
+
+	// ErrNotReady is returned by Ready while a (re)started instance has not yet
+	// answered, or when it did not answer within ManagerConfig.ReadyTimeout.
+	ErrNotReady = errors.New("eFLINT server is not ready")
+
+	// ErrReadOnly is returned when a command that would change the state is sent to
+	// a manager in read-only mode (see ManagerConfig.ReadOnly).
+	ErrReadOnly = errors.New("eFLINT server is read-only")
+
 	// ErrProcessStartFailed is returned when the eFLINT server process fails to start.
 	// The wrapped error contains details about the failure.
 	ErrProcessStartFailed = errors.New("failed to start eFLINT server process")
 
+	// ErrServerBinaryNotFound is returned when the configured eflint-server executable
+	// does not exist or is not executable. The wrapped message contains the path.
+	ErrServerBinaryNotFound = errors.New("eflint-server binary not found")
+
+	// ErrInvalidServerArgs is returned when the eflint-server argument template lacks
+	// the {model} or {port} placeholder.
+	ErrInvalidServerArgs = errors.New("invalid eflint-server arguments")
+
+	// ErrInvalidPortRange is returned when the port range is invalid or every port in
+	// it is excluded.
+	ErrInvalidPortRange = errors.New("invalid eFLINT server port range")
+
+	// ErrModelFetchFailed is returned when a model given as a URL cannot be downloaded.
+	ErrModelFetchFailed = errors.New("failed to fetch eFLINT model")
+
+	// ErrModelURLNotAllowed is returned when a model URL, or a redirect it leads to,
+	// is not on a host in ManagerConfig.ModelURLHosts.
+	ErrModelURLNotAllowed = errors.New("eFLINT model URL not allowed")
+
+	// ErrModelNotFound is returned when a model file or directory does not exist
+	// or a model directory contains no model files.
+	ErrModelNotFound = errors.New("eFLINT model not found")
+
+	// ErrInvalidTypeName is returned when a fact or act type to be written into a
+	// phrase is not a valid eFLINT type name.
+	ErrInvalidTypeName = errors.New("invalid eFLINT type name")
+
+	// ErrInvalidValue is returned when a fact argument to be written into a phrase
+	// is not a valid value of its kind, e.g. an integer that does not parse.
+	ErrInvalidValue = errors.New("invalid eFLINT value")
+
 	// ErrConnectionFailed is returned when a TCP connection to an eFLINT instance fails.
 	// This can occur due to network issues or if the server is not responding.
 	ErrConnectionFailed = errors.New("failed to connect to eFLINT server instance")
 
+	// ErrEmptyCommand is returned when a command is empty or consists only of
+	// whitespace. Such commands are rejected before anything is sent to the server.
+	ErrEmptyCommand = errors.New("eFLINT command is empty")
+
 	// ErrCommandFailed is returned when sending a command to an eFLINT instance fails.
 	// This can occur due to write failures or protocol errors.
 	ErrCommandFailed = errors.New("failed to send command to eFLINT server instance")
@@ -42,6 +89,22 @@ var (
 	// ErrStateImportFailed is returned when importing an eFLINT state fails.
 	ErrStateImportFailed = errors.New("failed to import eFLINT state")
 
+	// ErrInvalidState is returned when a saved state does not have the structure of an
+	// eFLINT execution graph. Such states are rejected before any load-export is attempted.
+	ErrInvalidState = errors.New("invalid eFLINT state")
+
+	// ErrStateIncompatible is returned when a saved state has an unsupported schema
+	// version or was exported against a different model than the one loaded.
+	ErrStateIncompatible = errors.New("incompatible eFLINT state")
+
+	// ErrStateNotFound is returned when a saved state or checkpoint does not exist
+	// in the state store.
+	ErrStateNotFound = errors.New("saved eFLINT state not found")
+
+	// ErrServerLogDisabled is returned when server output is requested but the
+	// Manager was configured without output capture (ServerLogSize is 0).
+	ErrServerLogDisabled = errors.New("eFLINT server output capture is disabled")
+
 	// ErrInvalidResponse is returned when the eFLINT server returns an invalid
 	// or unexpected response format.
 	ErrInvalidResponse = errors.New("invalid response from eFLINT server")
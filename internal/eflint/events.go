@@ -0,0 +1,120 @@
+package eflint
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// -----------------------------------------------------------------------------
+// Change Events
+// -----------------------------------------------------------------------------
+//
+// The Manager notifies subscribers whenever the policy state of the eFLINT
+// instance may have changed: after a mutating command succeeds and whenever the
+// instance is (re)started or stopped. Consumers such as caches use this to
+// invalidate derived data.
+//
+// Every change also bumps a generation counter. Readers record Generation()
+// before sending a query and compare it afterwards: if it moved, a mutation
+// completed while the query was in flight and the result may predate it, so
+// the read should be retried. This gives the following guarantee: a read that
+// returns with an unchanged generation reflects every mutating command that
+// had returned before the read finished.
+//
+// Lock ordering: listeners run after the Manager has released its instance lock,
+// so they may query the Manager (e.g. Status or IsRunning). Listeners notified of
+// a (re)start, model update or stop still run under the restart lock, so they
+// must not start, stop or restart the instance themselves.
+
+// ChangeListener is called with a short reason (e.g. "phrase", "restart")
+// whenever the instance state may have changed. Listeners must not block.
+type ChangeListener func(reason string)
+
+// mutatingCommands lists the eFLINT commands that can change the instance state.
+// Phrases are treated as mutating because they may create or terminate facts.
+var mutatingCommands = map[string]bool{
+	"phrase":      true,
+	"create":      true,
+	"terminate":   true,
+	"load-export": true,
+	"revert":      true,
+	"kill":        true,
+}
+
+// changeNotifier keeps the set of registered change listeners.
+// Thread-safe for concurrent access.
+type changeNotifier struct {
+	generation atomic.Uint64
+	listeners  map[int]ChangeListener
+	nextID     int
+	mu         sync.Mutex
+}
+
+// Generation returns a counter that is incremented on every state change.
+// See the package notes on change events for how readers use it to detect stale reads.
+func (m *Manager) Generation() uint64 {
+	return m.changes.generation.Load()
+}
+
+// OnChange registers a listener for instance state changes.
+// It returns a function that unregisters the listener.
+func (m *Manager) OnChange(listener ChangeListener) (unsubscribe func()) {
+	n := &m.changes
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.listeners == nil {
+		n.listeners = make(map[int]ChangeListener)
+	}
+	id := n.nextID
+	n.nextID++
+	n.listeners[id] = listener
+
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.listeners, id)
+	}
+}
+
+// notifyChange calls all registered listeners with the given reason.
+func (m *Manager) notifyChange(reason string) {
+	n := &m.changes
+	n.generation.Add(1)
+
+	switch reason {
+	case "start", "restart", "update-model":
+		m.gateReadiness()
+	case "stop":
+		m.readiness.reset()
+	}
+
+	n.mu.Lock()
+	listeners := make([]ChangeListener, 0, len(n.listeners))
+	for _, l := range n.listeners {
+		listeners = append(listeners, l)
+	}
+	n.mu.Unlock()
+
+	for _, l := range listeners {
+		l(reason)
+	}
+}
+
+// commandName extracts the "command" field from a JSON command string.
+// It returns an empty string if the command cannot be parsed.
+func commandName(command string) string {
+	var cmd struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal([]byte(command), &cmd); err != nil {
+		return ""
+	}
+	return cmd.Command
+}
+
+// IsMutatingCommand reports whether a JSON command may change the instance state.
+func IsMutatingCommand(command string) bool {
+	return mutatingCommands[commandName(command)]
+}
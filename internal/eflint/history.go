@@ -0,0 +1,132 @@
+package eflint
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Command History
+// -----------------------------------------------------------------------------
+
+// DefaultHistorySize is the number of recent commands kept by default.
+const DefaultHistorySize = 100
+
+// historyResponseLimit is the maximum number of response bytes kept per entry.
+const historyResponseLimit = 1024
+
+// redactedText replaces phrase contents when phrase redaction is enabled.
+const redactedText = "[redacted]"
+
+// HistoryEntry records a single command sent to the eFLINT server.
+type HistoryEntry struct {
+	Timestamp  time.Time `json:"timestamp"`            // When the command was sent
+	Command    string    `json:"command"`              // The command, with phrase text redacted if configured
+	Response   string    `json:"response,omitempty"`   // The response, truncated to a bounded length
+	Truncated  bool      `json:"truncated,omitempty"`  // Whether the response was truncated
+	DurationMs int64     `json:"duration_ms"`          // Round-trip duration in milliseconds
+	Error      string    `json:"error,omitempty"`      // Error message if the command failed
+	RequestID  string    `json:"request_id,omitempty"` // ID of the HTTP request that sent the command, if any
+}
+
+// commandHistory is a bounded ring buffer of recent commands.
+// Thread-safe for concurrent access.
+type commandHistory struct {
+	entries       []HistoryEntry
+	next          int  // Index of the slot to write next
+	full          bool // Whether the buffer has wrapped around
+	redactPhrases bool
+	mu            sync.Mutex
+}
+
+// newCommandHistory creates a history of the given size, or nil if size <= 0.
+func newCommandHistory(size int, redactPhrases bool) *commandHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &commandHistory{
+		entries:       make([]HistoryEntry, size),
+		redactPhrases: redactPhrases,
+	}
+}
+
+// record appends an entry, overwriting the oldest one when the buffer is full.
+func (h *commandHistory) record(start time.Time, requestID, command, response string, err error) {
+	if h == nil {
+		return
+	}
+
+	entry := HistoryEntry{
+		Timestamp:  start,
+		Command:    command,
+		DurationMs: time.Since(start).Milliseconds(),
+		RequestID:  requestID,
+	}
+	if h.redactPhrases {
+		entry.Command = redactPhrase(command)
+	}
+	if len(response) > historyResponseLimit {
+		entry.Response = response[:historyResponseLimit]
+		entry.Truncated = true
+	} else {
+		entry.Response = response
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// recent returns up to limit entries, newest first. A limit <= 0 returns all entries.
+func (h *commandHistory) recent(limit int) []HistoryEntry {
+	if h == nil {
+		return []HistoryEntry{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	result := make([]HistoryEntry, 0, limit)
+	for i := 1; i <= limit; i++ {
+		idx := (h.next - i + len(h.entries)) % len(h.entries)
+		result = append(result, h.entries[idx])
+	}
+	return result
+}
+
+// redactPhrase replaces the text of a phrase command. Other commands are returned unchanged.
+func redactPhrase(command string) string {
+	var cmd map[string]any
+	if err := json.Unmarshal([]byte(command), &cmd); err != nil || cmd["command"] != "phrase" {
+		return command
+	}
+	cmd["text"] = redactedText
+	redacted, err := json.Marshal(cmd)
+	if err != nil {
+		return command
+	}
+	return string(redacted)
+}
+
+// History returns up to limit recently sent commands, newest first.
+// A limit <= 0 returns the whole buffer. The result is empty if history is disabled.
+func (m *Manager) History(limit int) []HistoryEntry {
+	return m.history.recent(limit)
+}
@@ -1,8 +1,12 @@
 package eflint
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
 	"sync"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -16,8 +20,13 @@ type Instance struct {
 	Port          int       // TCP port the server is listening on
 	Process       *exec.Cmd // Handle to the running process
 	ModelLocation string    // Path to the eFLINT model file
+	StartedAt     time.Time // When the process was started
 
-	mu sync.RWMutex // Protects concurrent access to instance fields
+	pool     *connPool    // Optional pool of idle connections to this instance
+	inflight int          // Number of commands currently being sent to the instance
+	stopping bool         // Set once a stop has begun; no new commands are admitted
+	killed   bool         // Set once Kill has been called
+	mu       sync.RWMutex // Protects concurrent access to instance fields
 }
 
 // NewInstance creates a new Instance with the given parameters.
@@ -26,6 +35,7 @@ func NewInstance(port int, process *exec.Cmd, modelLocation string) *Instance {
 		Port:          port,
 		Process:       process,
 		ModelLocation: modelLocation,
+		StartedAt:     time.Now(),
 	}
 }
 
@@ -35,7 +45,7 @@ func (i *Instance) IsAlive() bool {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
 
-	if i.Process == nil {
+	if i.Process == nil || i.stopping || i.killed {
 		return false
 	}
 
@@ -44,17 +54,97 @@ func (i *Instance) IsAlive() bool {
 	return i.Process.ProcessState == nil
 }
 
-// Kill terminates the eFLINT server process.
-// Returns nil if the process was successfully killed or was already terminated.
-func (i *Instance) Kill() error {
+// killWaitTimeout bounds how long Kill waits for the process to exit after SIGKILL.
+const killWaitTimeout = 2 * time.Second
+
+// Kill terminates the eFLINT server process. It sends SIGTERM and waits up to
+// timeout for the process to exit, then escalates to SIGKILL; a timeout <= 0
+// kills immediately. On platforms without SIGTERM the process is killed
+// directly. Any pooled connections to the instance are closed.
+// Returns nil if the process exited or was already terminated, and an error if
+// it could not be signalled or did not exit even after SIGKILL.
+func (i *Instance) Kill(timeout time.Duration) error {
 	i.mu.Lock()
-	defer i.mu.Unlock()
+	if i.killed {
+		i.mu.Unlock()
+		return nil
+	}
+	i.killed = true
+	if i.pool != nil {
+		i.pool.close()
+	}
+	cmd := i.Process
+	i.mu.Unlock()
 
-	if i.Process == nil || i.Process.Process == nil {
+	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
 
-	return i.Process.Process.Kill()
+	// Reap the process; IsAlive no longer reads ProcessState once killed is set
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	if timeout > 0 && terminate(cmd.Process) == nil {
+		select {
+		case <-exited:
+			return nil
+		case <-time.After(timeout):
+		}
+	}
+
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill eflint-server process %d: %w", cmd.Process.Pid, err)
+	}
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(killWaitTimeout):
+		return fmt.Errorf("eflint-server process %d did not exit after SIGKILL", cmd.Process.Pid)
+	}
+}
+
+// beginCommand registers an in-flight command. It returns false if the instance
+// is stopping or has been killed, in which case no command may be sent.
+func (i *Instance) beginCommand() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.stopping || i.killed {
+		return false
+	}
+	i.inflight++
+	return true
+}
+
+// endCommand unregisters an in-flight command started with beginCommand.
+func (i *Instance) endCommand() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.inflight--
+}
+
+// drain stops admitting new commands and waits up to timeout for in-flight
+// commands to finish. It returns the number of commands still in flight.
+func (i *Instance) drain(timeout time.Duration) int {
+	i.mu.Lock()
+	i.stopping = true
+	i.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		i.mu.RLock()
+		remaining := i.inflight
+		i.mu.RUnlock()
+
+		if remaining == 0 || time.Now().After(deadline) {
+			return remaining
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 }
 
 // GetPort returns the TCP port the instance is listening on.
@@ -64,6 +154,18 @@ func (i *Instance) GetPort() int {
 	return i.Port
 }
 
+// address returns the TCP address of the instance (127.0.0.1 forces IPv4).
+func (i *Instance) address() string {
+	return fmt.Sprintf("127.0.0.1:%d", i.GetPort())
+}
+
+// GetStartedAt returns when the instance's process was started.
+func (i *Instance) GetStartedAt() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.StartedAt
+}
+
 // GetModelLocation returns the path to the eFLINT model file.
 func (i *Instance) GetModelLocation() string {
 	i.mu.RLock()
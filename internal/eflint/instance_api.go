@@ -1,11 +1,21 @@
 package eflint
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
 )
 
 // -----------------------------------------------------------------------------
@@ -27,6 +37,11 @@ func NewInstanceAPIHandler(manager *Manager, logger *zap.Logger) *InstanceAPIHan
 	}
 }
 
+// log returns the logger for a request, tagged with its request ID.
+func (h *InstanceAPIHandler) log(c echo.Context) *zap.Logger {
+	return requestid.Logger(c.Request().Context(), h.logger)
+}
+
 // RegisterRoutes registers all instance management API routes on the given Echo group.
 // Routes are registered under the group prefix (e.g., /eflint).
 //
@@ -37,7 +52,25 @@ func (h *InstanceAPIHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/status", h.GetStatus)
 	g.POST("/start", h.Start)
 	g.POST("/stop", h.Stop)
+	g.POST("/reset", h.Reset)
 	g.POST("/command", h.SendCommand)
+	g.POST("/validate-model", h.ValidateModel)
+	g.POST("/facts/import", h.ImportFacts)
+	g.GET("/history", h.GetHistory)
+	g.GET("/logs/stream", h.StreamServerLogs)
+}
+
+// APITypes returns a value of each request and response type of the instance and
+// state APIs, e.g. to derive their field names with jsoncase.FieldsOf.
+func APITypes() []any {
+	return []any{
+		StartRequest{}, StatusResponse{}, ValidateModelRequest{}, ModelValidation{},
+		CommandRequest{}, CommandResponse{}, ErrorResponse{}, ImportFactsRequest{},
+		ImportFactsResponse{}, HistoryResponse{}, AllowedArchetypesResponse{},
+		ExportStateResponse{}, ImportStateRequest{}, CheckpointRequest{},
+		CheckpointCreatedResponse{}, CheckpointListResponse{}, SuccessResponse{},
+		StateResponse{}, ArchiveImportResult{}, ReplayResult{},
+	}
 }
 
 // -----------------------------------------------------------------------------
@@ -45,17 +78,15 @@ func (h *InstanceAPIHandler) RegisterRoutes(g *echo.Group) {
 // -----------------------------------------------------------------------------
 
 // StatusResponse represents the response for status-related endpoints.
-type StatusResponse struct {
-	Running       bool            `json:"running"`                  // Whether the instance is running
-	Port          int             `json:"port,omitempty"`           // The port the instance is listening on
-	ModelLocation string          `json:"model_location,omitempty"` // Path to the loaded model
-	EflintStatus  json.RawMessage `json:"eflint_status,omitempty"`  // Status response from the eFLINT server
-}
+type StatusResponse = api.EflintStatusResponse
 
 // StartRequest represents the request body for starting an instance.
-type StartRequest struct {
-	ModelLocation string `json:"model_location" validate:"required"` // Path to the eFLINT model file
-	Force         bool   `json:"force,omitempty"`                    // Force restart if already running
+type StartRequest = api.EflintStartRequest
+
+// ValidateModelRequest represents the request body for validating a model.
+type ValidateModelRequest struct {
+	ModelLocation string `json:"model_location,omitempty"` // Local path of the model file or directory
+	ModelContent  string `json:"model_content,omitempty"`  // Inline model source, used instead of model_location
 }
 
 // CommandRequest represents the request body for sending a command.
@@ -68,7 +99,8 @@ type CommandRequest struct {
 
 // CommandResponse represents the response from a command execution.
 type CommandResponse struct {
-	Parsed json.RawMessage `json:"response"` // The parsed JSON response from eFLINT
+	Parsed json.RawMessage `json:"response"`        // The parsed JSON response from eFLINT
+	Typed  bool            `json:"typed,omitempty"` // Whether response holds a typed result (see ParseTypedResponse)
 }
 
 // ErrorResponse represents an error response returned by the API.
@@ -76,6 +108,40 @@ type ErrorResponse struct {
 	Error string `json:"error"` // Human-readable error message
 }
 
+// FactSpec describes a single fact to create, e.g. allowed-archetype("VU", "x", "computeToData").
+type FactSpec struct {
+	FactType  string   `json:"fact_type"` // The eFLINT fact type (e.g., "allowed-archetype")
+	Arguments []string `json:"arguments"` // Positional string arguments of the fact
+}
+
+// ImportFactsRequest represents the request body for bulk-importing facts.
+type ImportFactsRequest struct {
+	Facts           []FactSpec `json:"facts" validate:"required"` // Facts to create, in order
+	ContinueOnError bool       `json:"continue_on_error"`         // Keep going after a fact fails
+}
+
+// FactImportResult reports the outcome of importing a single fact.
+type FactImportResult struct {
+	FactType  string   `json:"fact_type"`       // The fact type that was submitted
+	Arguments []string `json:"arguments"`       // The arguments that were submitted
+	Phrase    string   `json:"phrase"`          // The eFLINT phrase sent to the server
+	Success   bool     `json:"success"`         // Whether the fact was created
+	Error     string   `json:"error,omitempty"` // Error message if the fact failed
+}
+
+// ImportFactsResponse represents the response for a bulk facts import.
+type ImportFactsResponse struct {
+	Imported int                `json:"imported"` // Number of facts created successfully
+	Failed   int                `json:"failed"`   // Number of facts that failed
+	Results  []FactImportResult `json:"results"`  // Per-fact results (stops at the first failure unless continue_on_error)
+}
+
+// HistoryResponse represents the response for the command history endpoint.
+type HistoryResponse struct {
+	Count   int            `json:"count"`   // Number of entries returned
+	Entries []HistoryEntry `json:"entries"` // Recent commands, newest first
+}
+
 // AllowedArchetypesResponse represents the response for querying allowed archetypes.
 type AllowedArchetypesResponse struct {
 	Organization string   `json:"organization"` // The organization/steward
@@ -108,9 +174,12 @@ func mustMarshal(v interface{}) []byte {
 //
 // Note: Object commands are always re-marshaled to compact JSON (no newlines) because the eFLINT
 // server expects single-line JSON input.
+//
+// An absent command, or a string command that is empty or only whitespace, returns
+// ErrEmptyCommand.
 func parseCommandToString(raw json.RawMessage) (string, error) {
 	if len(raw) == 0 {
-		return "", nil
+		return "", ErrEmptyCommand
 	}
 
 	// Trim whitespace to check the first character
@@ -120,7 +189,7 @@ func parseCommandToString(raw json.RawMessage) (string, error) {
 	}
 
 	if len(trimmed) == 0 {
-		return "", nil
+		return "", ErrEmptyCommand
 	}
 
 	// Check if the raw message is a JSON string (starts with a quote)
@@ -130,6 +199,9 @@ func parseCommandToString(raw json.RawMessage) (string, error) {
 		if err := json.Unmarshal(raw, &str); err != nil {
 			return "", err
 		}
+		if strings.TrimSpace(str) == "" {
+			return "", ErrEmptyCommand
+		}
 		return str, nil
 	}
 
@@ -159,18 +231,13 @@ func parseCommandToString(raw json.RawMessage) (string, error) {
 // GET /eflint/status
 func (h *InstanceAPIHandler) GetStatus(c echo.Context) error {
 	status := h.manager.Status()
-
-	response := StatusResponse{
-		Running:       status.Running,
-		Port:          status.Port,
-		ModelLocation: status.ModelLocation,
-	}
+	response := statusResponse(status)
 
 	// If the instance is running, query the eFLINT server for its status
 	if status.Running {
 		eflintStatus, err := h.manager.GetEflintStatus()
 		if err != nil {
-			h.logger.Warn("failed to get eFLINT server status", zap.Error(err))
+			h.log(c).Warn("failed to get eFLINT server status", zap.Error(err))
 			// Continue without the eFLINT status - the instance might still be starting up
 		} else if json.Valid([]byte(eflintStatus)) {
 			response.EflintStatus = json.RawMessage(eflintStatus)
@@ -180,6 +247,18 @@ func (h *InstanceAPIHandler) GetStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// statusResponse converts an instance status into its response.
+func statusResponse(status InstanceStatus) StatusResponse {
+	return StatusResponse{
+		Running:       status.Running,
+		Port:          status.Port,
+		ModelLocation: status.ModelLocation,
+		ModelFiles:    status.ModelFiles,
+		StartedAt:     status.StartedAt,
+		UptimeSeconds: status.UptimeSeconds,
+	}
+}
+
 // Start starts the eFLINT instance with the given model.
 // If an instance is already running and force=false, returns a conflict error.
 // If force=true, the existing instance is stopped and a new one is started.
@@ -190,8 +269,8 @@ func (h *InstanceAPIHandler) Start(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
 	}
 
-	if req.ModelLocation == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "model_location is required"})
+	if req.ModelLocation == "" && len(req.ModelLocations) == 0 && req.ModelContent == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "model_location, model_locations or model_content is required"})
 	}
 
 	// Check if instance is already running
@@ -199,17 +278,71 @@ func (h *InstanceAPIHandler) Start(c echo.Context) error {
 		return c.JSON(http.StatusConflict, ErrorResponse{Error: "instance already running, use force=true to restart"})
 	}
 
-	if err := h.manager.Start(req.ModelLocation); err != nil {
-		h.logger.Error("failed to start instance", zap.Error(err))
+	var err error
+	if req.ModelContent != "" {
+		err = h.manager.StartWithModelContent("model.eflint", []byte(req.ModelContent))
+	} else if len(req.ModelLocations) > 0 {
+		err = h.manager.StartModels(req.ModelLocations)
+	} else {
+		err = h.manager.Start(req.ModelLocation)
+	}
+	if err != nil {
+		if errors.Is(err, ErrRestartInProgress) {
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrModelURLNotAllowed) {
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		}
+		h.log(c).Error("failed to start instance", zap.Error(err))
+		if errors.Is(err, ErrModelFetchFailed) {
+			return c.JSON(http.StatusBadGateway, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrModelNotFound) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
-	status := h.manager.Status()
-	return c.JSON(http.StatusOK, StatusResponse{
-		Running:       status.Running,
-		Port:          status.Port,
-		ModelLocation: status.ModelLocation,
-	})
+	return c.JSON(http.StatusOK, statusResponse(h.manager.Status()))
+}
+
+// ValidateModel checks that a model parses by running a throwaway eflint-server
+// for it, without affecting the running instance. It responds 200 if the model
+// loaded and 422 with the server's output if it was rejected.
+// POST /eflint/validate-model
+func (h *InstanceAPIHandler) ValidateModel(c echo.Context) error {
+	var req ValidateModelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+
+	if req.ModelLocation == "" && req.ModelContent == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "model_location or model_content is required"})
+	}
+
+	ctx := c.Request().Context()
+	var validation *ModelValidation
+	var err error
+	if req.ModelContent != "" {
+		validation, err = h.manager.ValidateModelContent(ctx, "model.eflint", []byte(req.ModelContent))
+	} else {
+		validation, err = h.manager.ValidateModel(ctx, req.ModelLocation)
+	}
+	if err != nil {
+		if errors.Is(err, ErrModelNotFound) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "eflint-server did not answer in time"})
+		}
+		h.log(c).Error("failed to validate model", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if !validation.Valid {
+		return c.JSON(http.StatusUnprocessableEntity, validation)
+	}
+	return c.JSON(http.StatusOK, validation)
 }
 
 // Stop stops the running eFLINT instance.
@@ -219,19 +352,50 @@ func (h *InstanceAPIHandler) Stop(c echo.Context) error {
 		if err == ErrInstanceNotFound {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "no instance running"})
 		}
-		h.logger.Error("failed to stop instance", zap.Error(err))
+		h.log(c).Error("failed to stop instance", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, StatusResponse{Running: false})
 }
 
+// Reset restarts the eFLINT process with the same model, returning the instance to
+// the pristine initial state of the model.
+// POST /eflint/reset
+//
+// Unlike checkpoint restore, this does not depend on load-export and always works,
+// but it drops all facts and actions added at runtime since the instance started.
+func (h *InstanceAPIHandler) Reset(c echo.Context) error {
+	if err := h.manager.Restart(); err != nil {
+		if err == ErrInstanceNotFound {
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: "no instance configured, start one first"})
+		}
+		if errors.Is(err, ErrRestartInProgress) {
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		}
+		h.log(c).Error("failed to reset instance", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	h.log(c).Info("reset eFLINT instance to initial model state")
+
+	return c.JSON(http.StatusOK, statusResponse(h.manager.Status()))
+}
+
 // SendCommand sends a command to the eFLINT instance.
 // POST /eflint/command
 //
 // The command field can be either:
 //   - A string containing the JSON command: {"command": "{\"command\": \"status\"}"}
 //   - A JSON object that will be serialized: {"command": {"command": "status"}}
+//
+// With ?typed=true, responses to the facts, enabled and status commands are
+// converted into FactsResult, EnabledResult and StatusResult. Other commands, and
+// responses that cannot be converted, are returned raw.
+//
+// Clients preferring text/plain over application/json in the Accept header get
+// the server's response line as is, without the CommandResponse envelope (and
+// without typed conversion). Errors are always JSON.
 func (h *InstanceAPIHandler) SendCommand(c echo.Context) error {
 	var req CommandRequest
 	if err := c.Bind(&req); err != nil {
@@ -244,22 +408,50 @@ func (h *InstanceAPIHandler) SendCommand(c echo.Context) error {
 
 	// Convert the command to a string that can be sent to eFLINT
 	commandStr, err := parseCommandToString(req.Command)
+	if errors.Is(err, ErrEmptyCommand) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "command is empty"})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid command format: " + err.Error()})
 	}
 
-	response, err := h.manager.SendCommand(commandStr)
+	response, err := h.manager.SendCommandContext(c.Request().Context(), commandStr)
 	if err != nil {
+		if errors.Is(err, ErrEmptyCommand) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "command is empty"})
+		}
+		if errors.Is(err, ErrReadOnly) {
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		}
 		if err == ErrInstanceNotFound {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "no instance running"})
 		}
 		if err == ErrInstanceNotRunning {
 			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
 		}
-		h.logger.Error("failed to send command", zap.Error(err))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "command timed out"})
+		}
+		h.log(c).Error("failed to send command", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
+	if prefersText(c.Request().Header.Get(echo.HeaderAccept)) {
+		return c.String(http.StatusOK, response)
+	}
+
+	if typed, _ := strconv.ParseBool(c.QueryParam("typed")); typed {
+		result, err := ParseTypedResponse(commandStr, response)
+		if err != nil {
+			h.log(c).Debug("returning raw response for typed command", zap.Error(err))
+		} else if result != nil {
+			return c.JSON(http.StatusOK, CommandResponse{
+				Parsed: mustMarshal(result),
+				Typed:  true,
+			})
+		}
+	}
+
 	// Parse the response as JSON
 	var parsed json.RawMessage
 	if json.Valid([]byte(response)) {
@@ -273,6 +465,227 @@ func (h *InstanceAPIHandler) SendCommand(c echo.Context) error {
 	})
 }
 
+// prefersText reports whether an Accept header ranks text/plain above
+// application/json. Each type takes the q value of the most specific media range
+// matching it; on equal q the type named more specifically wins (so "text/plain,
+// */*" selects text), and otherwise JSON, the default, wins.
+func prefersText(accept string) bool {
+	textAcc, jsonAcc := acceptance{specificity: -1}, acceptance{specificity: -1}
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/plain":
+			textAcc.match(q, 2)
+		case "text/*":
+			textAcc.match(q, 1)
+		case "application/json":
+			jsonAcc.match(q, 2)
+		case "application/*":
+			jsonAcc.match(q, 1)
+		case "*/*":
+			textAcc.match(q, 0)
+			jsonAcc.match(q, 0)
+		}
+	}
+
+	if textAcc.q <= 0 || textAcc.q < jsonAcc.q {
+		return false
+	}
+	return textAcc.q > jsonAcc.q || textAcc.specificity > jsonAcc.specificity
+}
+
+// acceptance is the q value of the most specific Accept media range matching a type.
+type acceptance struct {
+	q           float64
+	specificity int // 2 = type/subtype, 1 = type/*, 0 = */*, -1 = no match
+}
+
+// match records a matching media range if it is more specific than the previous one.
+func (a *acceptance) match(q float64, specificity int) {
+	if specificity > a.specificity {
+		a.q, a.specificity = q, specificity
+	}
+}
+
+// ImportFacts creates a batch of facts by translating each spec into a `+fact(...)` phrase.
+// Facts are submitted in order; processing stops at the first failure unless
+// continue_on_error is set. The response reports the outcome of every submitted fact.
+// POST /eflint/facts/import
+func (h *InstanceAPIHandler) ImportFacts(c echo.Context) error {
+	var req ImportFactsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+
+	if len(req.Facts) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "facts is required"})
+	}
+	for i, fact := range req.Facts {
+		if fact.FactType == "" {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("facts[%d].fact_type is required", i)})
+		}
+		if err := ValidateTypeName(fact.FactType); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("facts[%d].fact_type: %v", i, err)})
+		}
+	}
+
+	if !h.manager.IsRunning() {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
+	}
+	if h.manager.ReadOnly() {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: ErrReadOnly.Error()})
+	}
+
+	response := ImportFactsResponse{Results: make([]FactImportResult, 0, len(req.Facts))}
+	for _, fact := range req.Facts {
+		phrase, err := BuildFactPhrase(FactCreate, fact.FactType, fact.Arguments)
+		result := FactImportResult{
+			FactType:  fact.FactType,
+			Arguments: fact.Arguments,
+			Phrase:    phrase,
+			Success:   true,
+		}
+
+		if err == nil {
+			_, err = h.manager.SendPhraseContext(c.Request().Context(), phrase)
+		}
+		if err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+
+		response.Results = append(response.Results, result)
+		if result.Success {
+			response.Imported++
+			continue
+		}
+
+		response.Failed++
+		h.log(c).Warn("failed to import fact",
+			zap.String("phrase", phrase),
+			zap.String("error", result.Error),
+		)
+		if !req.ContinueOnError {
+			break
+		}
+	}
+
+	h.log(c).Info("imported facts",
+		zap.Int("imported", response.Imported),
+		zap.Int("failed", response.Failed),
+	)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetHistory returns the most recent commands sent to the eFLINT server.
+// GET /eflint/history?limit=N
+//
+// Entries are returned newest first. Without a limit the whole buffer is returned.
+func (h *InstanceAPIHandler) GetHistory(c echo.Context) error {
+	limit := 0
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive integer"})
+		}
+		limit = n
+	}
+
+	entries := h.manager.History(limit)
+	return c.JSON(http.StatusOK, HistoryResponse{
+		Count:   len(entries),
+		Entries: entries,
+	})
+}
+
+// sseHeartbeatInterval is how often an idle log stream sends a comment, so that
+// proxies do not close the connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// StreamServerLogs streams the eFLINT server output as Server-Sent Events.
+// GET /eflint/logs/stream
+//
+// The stream starts with the buffered output and then follows new lines, including
+// those of instances started after a restart. Each event is named after its stream
+// ("stdout", "stderr" or "event"), carries the line's sequence number as ID and a
+// ServerLogLine as JSON data. Reconnecting clients that send Last-Event-ID only
+// receive lines they have not seen yet.
+func (h *InstanceAPIHandler) StreamServerLogs(c echo.Context) error {
+	backlog, lines, unsubscribe, err := h.manager.SubscribeServerLogs()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	}
+	defer unsubscribe()
+
+	var lastSeq uint64
+	if raw := c.Request().Header.Get("Last-Event-ID"); raw != "" {
+		lastSeq, _ = strconv.ParseUint(raw, 10, 64)
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+
+	send := func(line ServerLogLine) error {
+		// Lines in the backlog may also arrive on the channel; send each only once
+		if line.Seq <= lastSeq {
+			return nil
+		}
+		lastSeq = line.Seq
+		return writeServerLogEvent(resp, line)
+	}
+
+	for _, line := range backlog {
+		if err := send(line); err != nil {
+			return nil
+		}
+	}
+	resp.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			// Client disconnected (or the request timed out)
+			return nil
+		case line := <-lines:
+			if err := send(line); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(resp, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+		}
+		resp.Flush()
+	}
+}
+
+// writeServerLogEvent writes a single server output line as a Server-Sent Event.
+func writeServerLogEvent(w io.Writer, line ServerLogLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", line.Seq, line.Stream, data)
+	return err
+}
+
 // NOTE: GetAllowedArchetypes and similar policy query methods have been moved to
 // the /policy-enforcer API group. This provides a reasoner-agnostic interface that
 // can work with different policy reasoning engines (eFLINT, Symboleo, JSON-based, etc.).
@@ -0,0 +1,56 @@
+package eflint
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestParseCommandToString(t *testing.T) {
+	for raw, want := range map[string]string{
+		`"{\"command\": \"status\"}"`:         `{"command": "status"}`,
+		` {"command": "phrase", "text": "x"}`: `{"command":"phrase","text":"x"}`,
+	} {
+		if got, err := parseCommandToString(json.RawMessage(raw)); err != nil || got != want {
+			t.Errorf("parseCommandToString(%s) = %q, %v, want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{``, " \n\t", `""`, `" \n "`} {
+		if _, err := parseCommandToString(json.RawMessage(raw)); !errors.Is(err, ErrEmptyCommand) {
+			t.Errorf("parseCommandToString(%q) error = %v, want ErrEmptyCommand", raw, err)
+		}
+	}
+}
+
+func TestSendCommandRejectsEmptyCommand(t *testing.T) {
+	manager, server := startManager(t, func(string) string { return `{"status": "ok"}` })
+	commands, accepted := len(server.Commands()), server.Accepted()
+
+	for _, command := range []string{"", "  ", "\n", " \t\r\n"} {
+		if _, err := manager.SendCommand(command); !errors.Is(err, ErrEmptyCommand) {
+			t.Errorf("SendCommand(%q) error = %v, want ErrEmptyCommand", command, err)
+		}
+	}
+
+	// The handler answers a blank command with 400
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/eflint/command", strings.NewReader(`{"command": "   "}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := NewInstanceAPIHandler(manager, zap.NewNop()).SendCommand(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("SendCommand handler error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST of a blank command = %d %s, want 400", rec.Code, rec.Body)
+	}
+
+	if len(server.Commands()) != commands || server.Accepted() != accepted {
+		t.Error("an empty command reached the server")
+	}
+}
@@ -0,0 +1,70 @@
+package eflint
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+func TestMain(m *testing.M) {
+	eflinttest.Main(m)
+}
+
+// testManagerConfig returns a configuration that starts fake eflint-servers. The
+// startup delay gives a restarted fake server time to listen on its port.
+func testManagerConfig() *ManagerConfig {
+	config := DefaultManagerConfig()
+	config.EflintServerPath = eflinttest.Path()
+	config.StartupDelay = 250 * time.Millisecond
+	config.ConnectionTimeout = 5 * time.Second
+	config.KillTimeout = time.Second
+	config.HistorySize = 0
+	config.ServerLogSize = 0
+	return config
+}
+
+// startManager starts a Manager whose eflint-server answers commands with handler.
+func startManager(t testing.TB, handler eflinttest.Handler) (*Manager, *eflinttest.Server) {
+	t.Helper()
+	return startManagerWithConfig(t, testManagerConfig(), handler)
+}
+
+// startManagerWithConfig is like startManager with a custom configuration.
+func startManagerWithConfig(t testing.TB, config *ManagerConfig, handler eflinttest.Handler) (*Manager, *eflinttest.Server) {
+	t.Helper()
+	server := eflinttest.NewServer(t, handler)
+
+	manager := NewManager(config, zap.NewNop())
+	if err := manager.Start(server.Model); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { manager.Stop() })
+
+	eflinttest.WaitReady(t, manager.Ping)
+	return manager, server
+}
+
+// commandOf decodes a command sent to the server.
+func commandOf(command string) (name, text string) {
+	var cmd struct {
+		Command string `json:"command"`
+		Text    string `json:"text"`
+	}
+	json.Unmarshal([]byte(command), &cmd)
+	return cmd.Command, cmd.Text
+}
+
+// phrasesOf returns the texts of the phrase commands among commands.
+func phrasesOf(commands []string) []string {
+	var phrases []string
+	for _, command := range commands {
+		if name, text := commandOf(command); name == "phrase" {
+			phrases = append(phrases, text)
+		}
+	}
+	return phrases
+}
@@ -4,15 +4,21 @@ package eflint
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
-	"net"
+	"io"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
 )
 
 // -----------------------------------------------------------------------------
@@ -23,20 +29,98 @@ import (
 // It defines the parameters for starting and connecting to eFLINT server processes.
 type ManagerConfig struct {
 	EflintServerPath  string        // Path to the eflint-server executable
+	ServerArgs        []string      // Argument template with {model} and {port} placeholders (nil = DefaultServerArgs)
 	MinPort           int           // Minimum port number for random port selection
-	MaxPort           int           // Maximum port number for random port selection
+	MaxPort           int           // Maximum port number for random port selection (inclusive)
+	ExcludedPorts     []PortRange   // Ports never selected, e.g. those of colocated services
+	ModelURLHosts     []string      // Hosts models may be downloaded from, as "host" or "host:port" (empty = model URLs are refused)
 	StartupDelay      time.Duration // Time to wait after starting a process
-	ConnectionTimeout time.Duration // Timeout for TCP connections and commands
+	ConnectionTimeout time.Duration // Default for DialTimeout and CommandTimeout when they are not set
+	DialTimeout       time.Duration // Timeout for establishing a TCP connection (0 = ConnectionTimeout)
+	CommandTimeout    time.Duration // Timeout for writing a command and reading its response (0 = ConnectionTimeout)
+	MaxResponseSize   int64         // Maximum size in bytes of a single server response (0 = default)
+	PoolSize          int           // Maximum idle connections kept per instance (0 = dial per command)
+	PoolMaxOpen       int           // Maximum connections open per instance, in use or idle; further commands wait (0 = unlimited)
+	PoolIdleTimeout   time.Duration // Idle pooled connections older than this are discarded (0 = never)
+	HistorySize       int           // Number of recent commands kept for GET /eflint/history (0 = disabled)
+	RedactPhrases     bool          // Replace phrase text in the command history
+	ServerLogSize     int           // Number of recent server output lines kept for GET /eflint/logs/stream (0 = disabled)
+	KillTimeout       time.Duration // Grace period a stopping process gets to exit after SIGTERM before SIGKILL (0 = kill immediately)
+	PIDFile           string        // File recording started processes, for CleanupOrphans ("" = disabled)
+	ReadyTimeout      time.Duration // Time a (re)started instance gets to answer before Ready reports failure (0 = no readiness gate)
+	ReadOnly          bool          // Refuse commands that change the state, e.g. on a query replica
+	ReadOnlyCommands  []string      // Commands refused in read-only mode (nil = DefaultReadOnlyCommands)
+}
+
+// dialTimeout returns DialTimeout, or ConnectionTimeout if it is not set.
+func (c *ManagerConfig) dialTimeout() time.Duration {
+	if c.DialTimeout > 0 {
+		return c.DialTimeout
+	}
+	return c.ConnectionTimeout
+}
+
+// commandTimeout returns CommandTimeout, or ConnectionTimeout if it is not set.
+func (c *ManagerConfig) commandTimeout() time.Duration {
+	if c.CommandTimeout > 0 {
+		return c.CommandTimeout
+	}
+	return c.ConnectionTimeout
 }
 
+// DefaultMaxResponseSize is the response size limit used when MaxResponseSize is not set.
+// It is deliberately generous so that large execution graphs can still be exported.
+const DefaultMaxResponseSize int64 = 64 << 20 // 64 MiB
+
+// StopDrainTimeout bounds how long stopping an instance waits for in-flight commands.
+const StopDrainTimeout = 5 * time.Second
+
+// Placeholders in ManagerConfig.ServerArgs, replaced by the model location and the
+// port when the server is started. They may be part of a larger argument (e.g.
+// "--port={port}").
+const (
+	ModelPlaceholder = "{model}"
+	PortPlaceholder  = "{port}"
+)
+
+// DefaultServerArgs returns the argument template `eflint-server <model> <port>`.
+func DefaultServerArgs() []string {
+	return []string{ModelPlaceholder, PortPlaceholder}
+}
+
+// ValidateServerArgs checks that an argument template contains both placeholders.
+// An empty template is valid and means DefaultServerArgs.
+func ValidateServerArgs(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	joined := strings.Join(args, " ")
+	for _, placeholder := range []string{ModelPlaceholder, PortPlaceholder} {
+		if !strings.Contains(joined, placeholder) {
+			return fmt.Errorf("%w: missing %s placeholder in %q", ErrInvalidServerArgs, placeholder, args)
+		}
+	}
+	return nil
+}
+
+// DefaultKillTimeout is the KillTimeout used by DefaultManagerConfig.
+const DefaultKillTimeout = 5 * time.Second
+
+// PingTimeout bounds how long Ping waits for the server to answer.
+const PingTimeout = 2 * time.Second
+
 // DefaultManagerConfig returns sensible default configuration values.
 func DefaultManagerConfig() *ManagerConfig {
 	return &ManagerConfig{
 		EflintServerPath:  "eflint-server",
-		MinPort:           1025,
-		MaxPort:           65535,
+		MinPort:           DefaultMinPort,
+		MaxPort:           DefaultMaxPort,
 		StartupDelay:      3 * time.Second,
 		ConnectionTimeout: 60 * time.Second,
+		MaxResponseSize:   DefaultMaxResponseSize,
+		HistorySize:       DefaultHistorySize,
+		ServerLogSize:     DefaultServerLogSize,
+		KillTimeout:       DefaultKillTimeout,
 	}
 }
 
@@ -46,9 +130,12 @@ func DefaultManagerConfig() *ManagerConfig {
 
 // InstanceStatus represents the current status of an eFLINT server instance.
 type InstanceStatus struct {
-	Running       bool   `json:"running"`                  // Whether the instance is running
-	Port          int    `json:"port,omitempty"`           // The TCP port the instance is listening on
-	ModelLocation string `json:"model_location,omitempty"` // Path to the loaded eFLINT model
+	Running       bool       `json:"running"`                  // Whether the instance is running
+	Port          int        `json:"port,omitempty"`           // The TCP port the instance is listening on
+	ModelLocation string     `json:"model_location,omitempty"` // Path to the loaded eFLINT model
+	ModelFiles    []string   `json:"model_files,omitempty"`    // Source files merged into the model, in load order
+	StartedAt     *time.Time `json:"started_at,omitempty"`     // When the instance was last (re)started
+	UptimeSeconds float64    `json:"uptime_seconds,omitempty"` // Seconds since StartedAt, to the millisecond
 }
 
 // -----------------------------------------------------------------------------
@@ -58,10 +145,20 @@ type InstanceStatus struct {
 // Manager manages an eFLINT server instance lifecycle and communication.
 // It handles starting, stopping, and sending commands to the eFLINT server process.
 type Manager struct {
-	instance *Instance
-	mu       sync.RWMutex
-	config   *ManagerConfig
-	logger   *zap.Logger
+	instance   *Instance
+	mu         sync.RWMutex
+	restartMu  sync.Mutex   // Held for the whole of a start, restart or model update; see beginRestart
+	exclusive  sync.RWMutex // Read-locked by every command, write-locked by Exclusive
+	config     *ManagerConfig
+	changes    changeNotifier
+	history    *commandHistory // nil when history is disabled
+	serverLog  *serverLog      // Captured server output; nil when capture is disabled
+	pids       *pidFile        // Started processes, for orphan cleanup; nil when disabled
+	tempModel  string          // Temporary model file owned by the current instance, if any
+	modelFiles []string        // Source files merged into the current model, if several
+	readiness  readinessGate   // Closed by (re)starts until the instance answers; see Ready
+	readOnly   map[string]bool // Commands refused in read-only mode; nil when not read-only
+	logger     *zap.Logger
 }
 
 // NewManager creates a new eFLINT instance Manager with the given configuration.
@@ -71,33 +168,124 @@ func NewManager(config *ManagerConfig, logger *zap.Logger) *Manager {
 	}
 
 	return &Manager{
-		config: config,
-		logger: logger,
+		config:    config,
+		history:   newCommandHistory(config.HistorySize, config.RedactPhrases),
+		serverLog: newServerLog(config.ServerLogSize),
+		pids:      newPIDFile(config.PIDFile),
+		readOnly:  newReadOnlyGuard(config),
+		logger:    logger,
 	}
 }
 
+// beginRestart claims the restart lock, so that only one start, restart or model
+// update runs at a time. A concurrent caller gets ErrRestartInProgress instead of
+// queueing another restart behind it. The returned function releases the lock.
+//
+// The lock is held for the whole operation, including model downloads and merges
+// done before mu is taken, so that near-simultaneous lifecycle requests (e.g. a
+// forced start and a reset) cannot each replace the instance in turn.
+func (m *Manager) beginRestart() (release func(), err error) {
+	if !m.restartMu.TryLock() {
+		return nil, ErrRestartInProgress
+	}
+	return m.restartMu.Unlock, nil
+}
+
+// awaitRestart claims the restart lock like beginRestart, but waits for a running
+// start, restart or model update to finish instead of failing. It is used where
+// giving up is worse than waiting: recovery restarts, which would otherwise leave
+// a crashed server behind, and Stop, which would otherwise miss the instance a
+// concurrent start is creating.
+func (m *Manager) awaitRestart() (release func()) {
+	m.restartMu.Lock()
+	return m.restartMu.Unlock
+}
+
 // Start starts the eFLINT server instance with the given model.
+// The model location is a local file path, a directory or an http(s) URL; URLs are
+// downloaded to a temporary file first, and directories are handled by StartModels.
+// It returns ErrRestartInProgress while another (re)start is running.
 func (m *Manager) Start(modelLocation string) error {
+	release, err := m.beginRestart()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return m.start(modelLocation)
+}
+
+// start implements Start. Caller must hold the restart lock.
+func (m *Manager) start(modelLocation string) error {
+	// Fail fast on a missing binary, before downloading or tearing down anything
+	if _, err := m.resolveServerPath(); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(modelLocation); err == nil && info.IsDir() {
+		return m.startModels([]string{modelLocation})
+	}
+
+	isTemp := false
+	if isModelURL(modelLocation) {
+		path, err := m.fetchModel(modelLocation)
+		if err != nil {
+			return err
+		}
+		modelLocation, isTemp = path, true
+	}
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	err := m.startLocked(modelLocation, isTemp, nil)
+	m.mu.Unlock()
+	m.notifyChange("start")
+
+	return err
+}
+
+// startLocked replaces the running instance with one for the given local model file.
+// If isTemp is set, the Manager takes ownership of the file. modelFiles lists the
+// source files the model was merged from, if any. Caller must hold mu.
+func (m *Manager) startLocked(modelLocation string, isTemp bool, modelFiles []string) error {
+	if _, err := m.resolveServerPath(); err != nil {
+		if isTemp {
+			os.Remove(modelLocation)
+		}
+		return err
+	}
 
 	// Kill existing instance if running
 	if m.instance != nil && m.instance.IsAlive() {
-		if err := m.instance.Kill(); err != nil {
+		if err := m.stopInstance(m.instance); err != nil {
 			m.logger.Warn("failed to kill existing instance", zap.Error(err))
 		}
 	}
 
-	// Generate random port
-	port := m.generateRandomPort()
+	// Pick a random port no other process listens on
+	port, err := m.freePort()
+	if err != nil {
+		if isTemp {
+			os.Remove(modelLocation)
+		}
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
 
 	// Start the eFLINT server process
 	process, err := m.startProcess(modelLocation, port)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrProcessStartFailed, err)
+		if isTemp {
+			os.Remove(modelLocation)
+		}
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
 	}
 
-	m.instance = NewInstance(port, process, modelLocation)
+	m.instance = m.newInstance(port, process, modelLocation)
+	m.modelFiles = modelFiles
+	if isTemp {
+		m.replaceTempModel(modelLocation)
+	} else {
+		m.replaceTempModel("")
+	}
 
 	m.logger.Info("started eFLINT server instance",
 		zap.Int("port", port),
@@ -107,67 +295,102 @@ func (m *Manager) Start(modelLocation string) error {
 	return nil
 }
 
-// Stop stops the running eFLINT server instance.
+// Stop stops the running eFLINT server instance. A start, restart or model update
+// in progress is waited for, so that the instance it creates is stopped too.
 func (m *Manager) Stop() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	defer m.awaitRestart()()
 
+	m.mu.Lock()
 	if m.instance == nil {
+		m.mu.Unlock()
 		return ErrInstanceNotFound
 	}
+	err := m.stopLocked()
+	m.mu.Unlock()
+	m.notifyChange("stop")
 
-	if err := m.instance.Kill(); err != nil {
+	return err
+}
+
+// stopLocked implements Stop. Caller must hold mu and an instance must exist.
+func (m *Manager) stopLocked() error {
+	if err := m.stopInstance(m.instance); err != nil {
 		return err
 	}
 
 	m.logger.Info("stopped eFLINT server instance")
 	m.instance = nil
+	m.modelFiles = nil
+	m.replaceTempModel("")
 
 	return nil
 }
 
 // Restart restarts the eFLINT server instance with the same model.
+// It returns ErrRestartInProgress while another (re)start is running.
 func (m *Manager) Restart() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	release, err := m.beginRestart()
+	if err != nil {
+		return err
+	}
+	defer release()
 
+	m.mu.Lock()
 	if m.instance == nil {
+		m.mu.Unlock()
 		return ErrInstanceNotFound
 	}
+	err = m.restartInternalWithModel(m.instance.GetModelLocation())
+	m.mu.Unlock()
+	m.notifyChange("restart")
 
-	return m.restartInternalWithModel(m.instance.GetModelLocation())
+	return err
 }
 
 // restartWithModel restarts the eFLINT server instance with a specific model.
-// This is used internally when recovering from load-export failures.
-// NOTE: This method does NOT acquire the mutex - caller must handle locking.
+// This is used when recovering from load-export failures, which may leave the
+// server crashed or in an undefined state. Unlike Restart, it waits for another
+// (re)start to finish rather than failing, since the recovery must happen. It takes
+// the restart lock and mu, so callers must not hold mu; the StateManager calls it
+// while holding its own lock, which is safe because the Manager never takes the
+// StateManager's lock.
 func (m *Manager) restartWithModel(modelLocation string) error {
-	// Note: We don't acquire the mutex here because this is called from StateManager
-	// which may already hold a mutex. The caller is responsible for thread safety.
-	return m.restartInternalWithModel(modelLocation)
+	defer m.awaitRestart()()
+
+	m.mu.Lock()
+	err := m.restartInternalWithModel(modelLocation)
+	m.mu.Unlock()
+	m.notifyChange("restart")
+
+	return err
 }
 
 // restartInternalWithModel is the internal implementation of restart.
-// It does NOT acquire the mutex - caller must handle locking appropriately.
+// It does NOT acquire the mutex - caller must handle locking appropriately,
+// and notify change listeners once the mutex is released.
 func (m *Manager) restartInternalWithModel(modelLocation string) error {
 	// Kill existing instance if running
 	if m.instance != nil && m.instance.IsAlive() {
-		if err := m.instance.Kill(); err != nil {
+		if err := m.stopInstance(m.instance); err != nil {
 			m.logger.Warn("failed to kill instance during restart", zap.Error(err))
 		}
 	}
 
-	// Generate new port
-	port := m.generateRandomPort()
+	// Pick a new port no other process listens on
+	port, err := m.freePort()
+	if err != nil {
+		m.instance = nil
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
 
 	// Start new process
 	process, err := m.startProcess(modelLocation, port)
 	if err != nil {
 		m.instance = nil
-		return fmt.Errorf("%w: %v", ErrProcessStartFailed, err)
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
 	}
 
-	m.instance = NewInstance(port, process, modelLocation)
+	m.instance = m.newInstance(port, process, modelLocation)
 
 	m.logger.Info("restarted eFLINT server instance",
 		zap.Int("port", port),
@@ -178,28 +401,48 @@ func (m *Manager) restartInternalWithModel(modelLocation string) error {
 }
 
 // UpdateModel updates the model and restarts the instance.
+// It returns ErrRestartInProgress while another (re)start is running.
 func (m *Manager) UpdateModel(modelLocation string) error {
+	release, err := m.beginRestart()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	err = m.updateModelLocked(modelLocation)
+	m.mu.Unlock()
+	m.notifyChange("update-model")
+
+	return err
+}
 
+// updateModelLocked implements UpdateModel. Caller must hold mu.
+func (m *Manager) updateModelLocked(modelLocation string) error {
 	// Kill existing instance if running
 	if m.instance != nil && m.instance.IsAlive() {
-		if err := m.instance.Kill(); err != nil {
+		if err := m.stopInstance(m.instance); err != nil {
 			m.logger.Warn("failed to kill instance during model update", zap.Error(err))
 		}
 	}
 
-	// Generate new port
-	port := m.generateRandomPort()
+	// Pick a new port no other process listens on
+	port, err := m.freePort()
+	if err != nil {
+		m.instance = nil
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
 
 	// Start new process with new model
 	process, err := m.startProcess(modelLocation, port)
 	if err != nil {
 		m.instance = nil
-		return fmt.Errorf("%w: %v", ErrProcessStartFailed, err)
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
 	}
 
-	m.instance = NewInstance(port, process, modelLocation)
+	m.instance = m.newInstance(port, process, modelLocation)
+	m.modelFiles = nil
+	m.replaceTempModel("")
 
 	m.logger.Info("updated eFLINT server model",
 		zap.Int("port", port),
@@ -218,10 +461,14 @@ func (m *Manager) Status() InstanceStatus {
 		return InstanceStatus{Running: false}
 	}
 
+	startedAt := m.instance.GetStartedAt()
 	return InstanceStatus{
 		Running:       m.instance.IsAlive(),
 		Port:          m.instance.GetPort(),
 		ModelLocation: m.instance.GetModelLocation(),
+		ModelFiles:    m.modelFiles,
+		StartedAt:     &startedAt,
+		UptimeSeconds: time.Since(startedAt).Truncate(time.Millisecond).Seconds(),
 	}
 }
 
@@ -235,6 +482,88 @@ func (m *Manager) IsRunning() bool {
 
 // SendCommand sends a command to the eFLINT server instance.
 func (m *Manager) SendCommand(command string) (string, error) {
+	return m.SendCommandContext(context.Background(), command)
+}
+
+// SendCommandContext sends a command to the eFLINT server instance, honoring the
+// context's deadline and cancellation in addition to the configured DialTimeout and
+// CommandTimeout.
+// Empty or whitespace-only commands return ErrEmptyCommand, and in read-only mode
+// commands that would change the state return ErrReadOnly, without contacting the server.
+func (m *Manager) SendCommandContext(ctx context.Context, command string) (string, error) {
+	return m.send(ctx, command, readString)
+}
+
+// SendCommandDecode sends a command like SendCommandContext, but passes the
+// response to decode while it is read from the connection instead of returning
+// it, so that large responses, such as the facts of a big model, are never held
+// in memory as a whole. decode reads the response from r, which ends with it; the
+// rest of the response is skipped once decode returns. If a pooled connection
+// turns out to be stale, the command is sent again and decode called again, so it
+// must only keep its results once it succeeds. Errors returned by decode are
+// returned as is, unless reading the response failed.
+func (m *Manager) SendCommandDecode(ctx context.Context, command string, decode func(r io.Reader) error) error {
+	_, err := m.send(ctx, command, func(r *responseReader) (string, error) {
+		if err := decode(r); err != nil {
+			if r.err != nil {
+				return "", r.err
+			}
+			return "", &decodeError{err: err}
+		}
+		_, err := io.Copy(io.Discard, r)
+		return "", err
+	})
+	var de *decodeError
+	if errors.As(err, &de) {
+		return de.err
+	}
+	return err
+}
+
+// decodeError wraps an error returned by the decode function of SendCommandDecode.
+// The response was read, so the connection was not stale.
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string { return e.err.Error() }
+
+func (e *decodeError) Unwrap() error { return e.err }
+
+// responseHandler consumes the response to a command from r and returns the
+// response as recorded in the history.
+type responseHandler func(r *responseReader) (string, error)
+
+// readString is the responseHandler returning the whole response, trimmed.
+func readString(r *responseReader) (string, error) {
+	response, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(response)), nil
+}
+
+// send implements SendCommandContext and SendCommandDecode, handling the response
+// with handle.
+func (m *Manager) send(ctx context.Context, command string, handle responseHandler) (response string, err error) {
+	if strings.TrimSpace(command) == "" {
+		return "", ErrEmptyCommand
+	}
+	if err := m.checkReadOnly(command); err != nil {
+		return "", err
+	}
+
+	// Wait while another caller has the instance to itself
+	if ctx.Value(exclusiveKey{}) != m {
+		m.exclusive.RLock()
+		defer m.exclusive.RUnlock()
+	}
+
+	start := time.Now()
+	defer func() {
+		m.history.record(start, requestid.FromContext(ctx), command, response, err)
+	}()
+
 	m.mu.RLock()
 	instance := m.instance
 	m.mu.RUnlock()
@@ -243,41 +572,246 @@ func (m *Manager) SendCommand(command string) (string, error) {
 		return "", ErrInstanceNotFound
 	}
 
+	if !instance.beginCommand() {
+		return "", ErrInstanceNotRunning
+	}
+	defer instance.endCommand()
+
 	if !instance.IsAlive() {
 		return "", ErrInstanceNotRunning
 	}
 
-	// Connect to the instance (use 127.0.0.1 to force IPv4)
-	addr := fmt.Sprintf("127.0.0.1:%d", instance.GetPort())
-	conn, err := net.DialTimeout("tcp", addr, m.config.ConnectionTimeout)
+	pc, err := m.acquireConn(ctx, instance)
 	if err != nil {
+		// A concurrent Stop may have killed the process after it was looked up
+		if !instance.IsAlive() {
+			return "", ErrInstanceNotRunning
+		}
+		// Report cancellation as such, so callers can tell it from a backend failure
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%w: %w", ErrCommandFailed, ctx.Err())
+		}
 		return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
 	}
-	defer conn.Close()
 
+	response, err = m.roundTrip(ctx, pc, command, handle)
+	var de *decodeError
+	if err != nil && pc.reused && ctx.Err() == nil && !errors.As(err, &de) {
+		// A pooled connection may have been closed by the server while idle;
+		// retry once on a fresh connection before giving up.
+		if pc, err = instance.pool.redial(ctx, pc); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		response, err = m.roundTrip(ctx, pc, command, handle)
+	}
+	m.releaseConn(instance, pc, err == nil)
+	if err != nil {
+		if !instance.IsAlive() {
+			return "", fmt.Errorf("%w: %w", ErrInstanceNotRunning, err)
+		}
+		return "", err
+	}
+
+	if name := commandName(command); mutatingCommands[name] {
+		m.notifyChange(name)
+	}
+
+	requestid.Logger(ctx, m.logger).Debug("sent command to eFLINT instance",
+		zap.String("command", command),
+		zap.String("response", response),
+	)
+
+	return response, nil
+}
+
+// exclusiveKey marks the context of an Exclusive call; its value is the Manager.
+type exclusiveKey struct{}
+
+// Exclusive runs fn with the instance to itself, e.g. to change the state
+// temporarily and undo the change: it waits for the commands in flight to finish,
+// and commands of other callers wait until fn returns. Only commands sent with the
+// context passed to fn, or one derived from it, run meanwhile. Lifecycle
+// operations such as Restart are not held back. Calls must not be nested.
+func (m *Manager) Exclusive(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.exclusive.Lock()
+	defer m.exclusive.Unlock()
+	return fn(context.WithValue(ctx, exclusiveKey{}, m))
+}
+
+// roundTrip writes a single command to the connection and handles its response.
+// The operation is bounded by CommandTimeout or the context deadline, whichever is
+// earlier, and is aborted if the context is cancelled.
+func (m *Manager) roundTrip(ctx context.Context, pc *poolConn, command string, handle responseHandler) (string, error) {
 	// Set deadline for the operation
-	if err := conn.SetDeadline(time.Now().Add(m.config.ConnectionTimeout)); err != nil {
+	deadline := time.Now().Add(m.config.commandTimeout())
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := pc.conn.SetDeadline(deadline); err != nil {
 		return "", fmt.Errorf("failed to set deadline: %v", err)
 	}
 
+	// Unblock the read/write as soon as the context is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		pc.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	response, err := m.exchange(pc, command, handle)
+	if err != nil && ctx.Err() != nil {
+		return "", fmt.Errorf("%w: %w", ErrCommandFailed, ctx.Err())
+	}
+	return response, err
+}
+
+// exchange writes the command and handles the response on a prepared connection.
+func (m *Manager) exchange(pc *poolConn, command string, handle responseHandler) (string, error) {
 	// Send command with newline
-	if _, err := conn.Write([]byte(command + "\n")); err != nil {
+	if _, err := pc.conn.Write([]byte(command + "\n")); err != nil {
 		return "", fmt.Errorf("%w: %v", ErrCommandFailed, err)
 	}
 
-	// Read response until newline
-	reader := bufio.NewReader(conn)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %v", err)
+	// Read the response, bounded so a huge response cannot exhaust memory
+	return handle(newResponseReader(pc.reader, m.maxResponseSize()))
+}
+
+// acquireConn returns a connection to the instance, from its pool if pooling is enabled.
+func (m *Manager) acquireConn(ctx context.Context, instance *Instance) (*poolConn, error) {
+	if instance.pool != nil {
+		return instance.pool.get(ctx)
 	}
+	return dialConn(ctx, instance.address(), m.config.dialTimeout())
+}
 
-	m.logger.Debug("sent command to eFLINT instance",
-		zap.String("command", command),
-		zap.String("response", strings.TrimSpace(response)),
-	)
+// releaseConn returns a healthy connection to the instance's pool, or closes it.
+// Connections that saw an error are always closed, since the protocol state is unknown.
+func (m *Manager) releaseConn(instance *Instance, pc *poolConn, healthy bool) {
+	switch {
+	case instance.pool == nil:
+		pc.conn.Close()
+	case healthy:
+		instance.pool.put(pc)
+	default:
+		instance.pool.discard(pc)
+	}
+}
+
+// responseReader streams a single JSON response from a connection, so that large
+// responses can be decoded without holding them in memory as a whole. The server
+// terminates responses with a newline, but a response may contain further
+// newlines, e.g. when pretty-printed or when an error message embeds one. Lines
+// are therefore read until they form a complete JSON value, so that no part of
+// the response is left on the connection to corrupt the next command; then Read
+// returns io.EOF. Newlines inside strings are escaped to keep the result valid
+// JSON. Responses that are not a JSON object, array or string end at the first
+// newline, and a line of only whitespace is an empty response. The whole response
+// is bounded by maxSize bytes.
+type responseReader struct {
+	reader    *bufio.Reader
+	scanner   responseScanner
+	maxSize   int64
+	remaining int64  // Bytes the response may still take
+	buf       []byte // Scanned bytes of the current chunk
+	pending   []byte // Part of buf not yet returned
+	done      bool
+	err       error // Sticky read error
+}
+
+// newResponseReader returns a reader for the next response on reader, bounded by
+// maxSize bytes.
+func newResponseReader(reader *bufio.Reader, maxSize int64) *responseReader {
+	return &responseReader{reader: reader, maxSize: maxSize, remaining: maxSize}
+}
+
+// Read implements io.Reader. Chunks are read up to the next newline at most, so
+// nothing beyond the response is consumed.
+func (r *responseReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		chunk, err := r.reader.ReadSlice('\n')
+		r.remaining -= int64(len(chunk))
+		if r.remaining < 0 {
+			r.err = fmt.Errorf("%w: response exceeds maximum size of %d bytes", ErrInvalidResponse, r.maxSize)
+			return 0, r.err
+		}
+		r.buf = r.scanner.scan(r.buf[:0], chunk)
+		r.pending = r.buf
+		switch {
+		case err == bufio.ErrBufferFull:
+		case err != nil:
+			r.err = fmt.Errorf("failed to read response: %v", err)
+			return 0, r.err
+		default:
+			r.done = r.scanner.complete()
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// responseScanner tracks the nesting of a JSON value split across lines.
+type responseScanner struct {
+	started  bool // Whether the value has begun
+	scalar   bool // Whether the value is a bare scalar (or not JSON at all)
+	depth    int  // Nesting depth of objects and arrays
+	inString bool // Whether the scanner is inside a string literal
+	escaped  bool // Whether the previous character was a backslash inside a string
+}
 
-	return strings.TrimSpace(response), nil
+// scan appends line to dst, escaping raw line breaks inside strings.
+func (s *responseScanner) scan(dst, line []byte) []byte {
+	for _, c := range line {
+		if s.inString {
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+				continue
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+				continue
+			}
+			dst = append(dst, c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+			s.started = true
+		case '{', '[':
+			s.depth++
+			s.started = true
+		case '}', ']':
+			s.depth--
+		case ' ', '\t', '\r', '\n':
+		default:
+			if !s.started {
+				s.started = true
+				s.scalar = true
+			}
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// complete reports whether the scanned lines form a whole response. Lines without
+// a value, such as the bare newline a server sends for an empty response, complete
+// it too; waiting for more would block until the command times out.
+func (s *responseScanner) complete() bool {
+	return !s.started || s.scalar || (!s.inString && s.depth <= 0)
 }
 
 // GetState retrieves the state by sending an export command.
@@ -285,35 +819,99 @@ func (m *Manager) GetState() (string, error) {
 	return m.SendCommand(`{"command": "create-export"}`)
 }
 
+// Ping checks that the eFLINT server actually answers commands, not just that the
+// process is alive. It sends a cheap "status" command bounded by PingTimeout and
+// returns nil only if a valid JSON response came back. Use it for readiness checks;
+// IsRunning remains the liveness check.
+func (m *Manager) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	response, err := m.SendCommandContext(ctx, `{"command": "status"}`)
+	if err != nil {
+		return err
+	}
+
+	if !json.Valid([]byte(response)) {
+		return fmt.Errorf("%w: status response is not valid JSON", ErrInvalidResponse)
+	}
+
+	return nil
+}
+
 // GetEflintStatus retrieves the status from the eFLINT server.
 // This returns detailed information about the current state of the server.
 func (m *Manager) GetEflintStatus() (string, error) {
 	return m.SendCommand(`{"command": "status"}`)
 }
 
-// startProcess starts a new eFLINT server process.
-func (m *Manager) startProcess(modelLocation string, port int) (*exec.Cmd, error) {
-	cmd := exec.Command(m.config.EflintServerPath, modelLocation, fmt.Sprintf("%d", port))
+// stopInstance waits up to StopDrainTimeout for in-flight commands to finish and
+// then kills the instance. Commands arriving meanwhile fail with ErrInstanceNotRunning.
+func (m *Manager) stopInstance(instance *Instance) error {
+	if remaining := instance.drain(StopDrainTimeout); remaining > 0 {
+		m.logger.Warn("stopping eFLINT instance with commands still in flight",
+			zap.Int("in_flight", remaining),
+		)
+	}
+	if err := instance.Kill(m.config.KillTimeout); err != nil {
+		return err
+	}
+	m.forgetProcess(instance.Process)
+	m.logServerEvent(instance.Port, "eflint-server stopped")
+	return nil
+}
 
-	// Capture stderr for debugging
-	cmd.Stderr = nil
-	cmd.Stdout = nil
+// serverArgs fills the configured argument template with the model location and port.
+func (m *Manager) serverArgs(modelLocation string, port int) ([]string, error) {
+	template := m.config.ServerArgs
+	if len(template) == 0 {
+		template = DefaultServerArgs()
+	}
+	if err := ValidateServerArgs(template); err != nil {
+		return nil, err
+	}
 
-	m.logger.Info("starting eflint-server",
-		zap.String("path", m.config.EflintServerPath),
-		zap.String("model", modelLocation),
-		zap.Int("port", port),
-	)
+	replacer := strings.NewReplacer(ModelPlaceholder, modelLocation, PortPlaceholder, strconv.Itoa(port))
+	args := make([]string, len(template))
+	for i, arg := range template {
+		args[i] = replacer.Replace(arg)
+	}
+	return args, nil
+}
 
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start eflint-server: %w", err)
+// resolveServerPath resolves the configured eflint-server executable.
+// Bare names are looked up in PATH; paths are checked for existence and the
+// executable bit. It returns ErrServerBinaryNotFound if the binary is unusable.
+func (m *Manager) resolveServerPath() (string, error) {
+	path, err := exec.LookPath(m.config.EflintServerPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrServerBinaryNotFound, m.config.EflintServerPath, err)
 	}
+	return path, nil
+}
+
+// startProcess starts a new eFLINT server process.
+func (m *Manager) startProcess(modelLocation string, port int) (*exec.Cmd, error) {
+	// Capture output for GET /eflint/logs/stream
+	var stdout, stderr io.Writer
+	if m.serverLog != nil {
+		stdout = m.serverLog.writer(StreamStdout, port)
+		stderr = m.serverLog.writer(StreamStderr, port)
+	}
+
+	cmd, err := m.spawnProcess(modelLocation, port, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logServerEvent(port, "eflint-server started (pid %d, model %s)", cmd.Process.Pid, modelLocation)
 
 	// Wait for the server to start
 	time.Sleep(m.config.StartupDelay)
 
 	// Check if the process is still running
 	if cmd.ProcessState != nil {
+		m.forgetProcess(cmd)
 		return nil, fmt.Errorf("eflint-server process exited immediately")
 	}
 
@@ -325,7 +923,66 @@ func (m *Manager) startProcess(modelLocation string, port int) (*exec.Cmd, error
 	return cmd, nil
 }
 
-// generateRandomPort generates a random port number within the configured range.
-func (m *Manager) generateRandomPort() int {
-	return rand.Intn(m.config.MaxPort-m.config.MinPort) + m.config.MinPort
+// spawnProcess launches eflint-server for a model on a port, sending its output to
+// stdout and stderr (discarded if nil). It returns as soon as the process is running.
+func (m *Manager) spawnProcess(modelLocation string, port int, stdout, stderr io.Writer) (*exec.Cmd, error) {
+	path, err := m.resolveServerPath()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := m.serverArgs(modelLocation, port)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path, args...)
+	// Bound Wait if a child of the server keeps the output pipes open after it exits
+	cmd.WaitDelay = time.Second
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	m.logger.Info("starting eflint-server",
+		zap.String("path", path),
+		zap.String("model", modelLocation),
+		zap.Int("port", port),
+		zap.Strings("args", args),
+	)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start eflint-server: %w", err)
+	}
+	if err := m.pids.add(cmd.Process.Pid, append([]string{path}, args...)); err != nil {
+		m.logger.Warn("failed to record eflint-server process", zap.Error(err))
+	}
+	return cmd, nil
+}
+
+// forgetProcess removes an exited process from the pidfile.
+func (m *Manager) forgetProcess(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	if err := m.pids.remove(cmd.Process.Pid); err != nil {
+		m.logger.Warn("failed to update pidfile", zap.Error(err))
+	}
+}
+
+// newInstance creates an Instance for a freshly started process, attaching a
+// connection pool when pooling or a connection limit is enabled.
+func (m *Manager) newInstance(port int, process *exec.Cmd, modelLocation string) *Instance {
+	instance := NewInstance(port, process, modelLocation)
+	if m.config.PoolSize > 0 || m.config.PoolMaxOpen > 0 {
+		instance.pool = newConnPool(instance.address(), m.config.PoolSize, m.config.PoolMaxOpen,
+			m.config.PoolIdleTimeout, m.config.dialTimeout())
+	}
+	return instance
+}
+
+// maxResponseSize returns the configured response size limit, falling back to the default.
+func (m *Manager) maxResponseSize() int64 {
+	if m.config.MaxResponseSize > 0 {
+		return m.config.MaxResponseSize
+	}
+	return DefaultMaxResponseSize
 }
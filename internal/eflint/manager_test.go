@@ -0,0 +1,298 @@
+package eflint
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+// readResponse reads the next response from reader as a string.
+func readResponse(reader *bufio.Reader, maxSize int64) (string, error) {
+	response, err := io.ReadAll(newResponseReader(reader, maxSize))
+	return string(response), err
+}
+
+func TestReadResponse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single line", "{\"a\": 1}\n{\"next\": true}\n", "{\"a\": 1}\n"},
+		{"pretty-printed", "{\n  \"a\": [1,\n 2]\n}\n{\"next\": true}\n", "{\n  \"a\": [1,\n 2]\n}\n"},
+		{"newline in string", "{\"error\": \"line one\nline two\"}\n{\"next\": true}\n", "{\"error\": \"line one\\nline two\"}\n"},
+		{"scalar", "ok\n{\"next\": true}\n", "ok\n"},
+		{"empty line", "\n{\"next\": true}\n", "\n"},
+		{"whitespace line", " \t\r\n{\"next\": true}\n", " \t\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			response, err := readResponse(reader, 1<<20)
+			if err != nil {
+				t.Fatalf("readResponse() error = %v", err)
+			}
+			if response != tt.want {
+				t.Errorf("readResponse() = %q, want %q", response, tt.want)
+			}
+			// The next response is left on the reader
+			if next, err := readResponse(reader, 1<<20); err != nil || next != "{\"next\": true}\n" {
+				t.Errorf("next readResponse() = %q, %v", next, err)
+			}
+		})
+	}
+}
+
+func TestReadResponseTooLarge(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("{\n\"a\": \"" + strings.Repeat("x", 100) + "\"}\n"))
+	if _, err := readResponse(reader, 64); err == nil {
+		t.Error("readResponse() succeeded for a response beyond the maximum size")
+	}
+}
+
+func TestSendCommandEmptyResponse(t *testing.T) {
+	config := testManagerConfig()
+	config.CommandTimeout = 10 * time.Second
+	config.PoolSize = 1
+	manager, _ := startManagerWithConfig(t, config, func(command string) string {
+		if name, _ := commandOf(command); name == "create-export" {
+			return ""
+		}
+		return `{"status": "ok"}`
+	})
+
+	start := time.Now()
+	response, err := manager.SendCommand(`{"command": "create-export"}`)
+	if err != nil {
+		t.Fatalf("SendCommand() error = %v", err)
+	}
+	if response != "" {
+		t.Errorf("SendCommand() = %q, want an empty response", response)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("SendCommand() took %v, want it to return without waiting for the timeout", elapsed)
+	}
+
+	// The pooled connection is still in step with the server
+	if response, err := manager.SendCommand(`{"command": "status"}`); err != nil || response != `{"status": "ok"}` {
+		t.Errorf("SendCommand(status) = %q, %v", response, err)
+	}
+}
+
+// childProcesses returns the number of live processes the test binary started.
+func childProcesses(t *testing.T) int {
+	t.Helper()
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil || len(stats) == 0 {
+		t.Skip("process list not available")
+	}
+	parent := strconv.Itoa(os.Getpid())
+	count := 0
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // Exited meanwhile
+		}
+		// Fields after the parenthesized command: state, ppid, ...
+		fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+		if len(fields) > 1 && fields[0] != "Z" && fields[1] == parent {
+			count++
+		}
+	}
+	return count
+}
+
+func TestConcurrentRestartsLeaveOneProcess(t *testing.T) {
+	manager, server := startManager(t, func(string) string { return `{}` })
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 9)
+	for i := range 9 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				// Recovery restarts wait for each other instead of failing
+				errs <- manager.restartWithModel(server.Model)
+			case 1:
+				if err := manager.Restart(); !errors.Is(err, ErrRestartInProgress) {
+					errs <- err
+				}
+			case 2:
+				if err := manager.Start(server.Model); !errors.Is(err, ErrRestartInProgress) {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("restart error = %v", err)
+		}
+	}
+
+	if n := childProcesses(t); n != 1 {
+		t.Errorf("%d eflint-server processes running, want 1", n)
+	}
+	if !manager.IsRunning() {
+		t.Error("IsRunning() = false after the restarts")
+	}
+}
+
+func TestStopWaitsForStart(t *testing.T) {
+	eflintServer := eflinttest.NewServer(t, func(string) string { return `{}` })
+	model, err := os.ReadFile(eflintServer.Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloading := make(chan struct{})
+	modelURL, _ := modelServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(downloading)
+		time.Sleep(200 * time.Millisecond)
+		w.Write(model)
+	})
+
+	config := testManagerConfig()
+	config.ModelURLHosts = []string{"127.0.0.1"}
+	manager, _ := startManagerWithConfig(t, config, func(string) string { return `{}` })
+
+	// Stop arrives while the start downloads its model; it must stop the instance
+	// the start creates rather than only the one it replaces
+	started := make(chan error, 1)
+	go func() { started <- manager.Start(modelURL.URL + "/model.eflint") }()
+	<-downloading
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if manager.IsRunning() {
+		t.Error("IsRunning() = true after Stop")
+	}
+	if n := childProcesses(t); n != 0 {
+		t.Errorf("%d eflint-server processes running after Stop, want 0", n)
+	}
+}
+
+func TestStopWaitsForCommandsInFlight(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	manager, _ := startManager(t, func(command string) string {
+		if name, _ := commandOf(command); name == "facts" {
+			close(received)
+			<-release
+		}
+		return `{"values": []}`
+	})
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseOnce)
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := manager.SendCommand(`{"command": "facts"}`)
+		sent <- err
+	}()
+	<-received
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- manager.Stop() }()
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop() = %v while a command was in flight, want it to wait", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	releaseOnce()
+	if err := <-sent; err != nil {
+		t.Errorf("SendCommand() error = %v, want the command in flight to complete", err)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestSendsRacingStopFailCleanly(t *testing.T) {
+	manager, _ := startManager(t, func(string) string { return `{"status": "ok"}` })
+
+	var wg sync.WaitGroup
+	var sent atomic.Int32
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := manager.SendCommand(`{"command": "status"}`); err != nil {
+					errs <- err
+					return
+				}
+				sent.Add(1)
+			}
+		}()
+	}
+	for sent.Load() < 50 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	wg.Wait()
+	close(errs)
+
+	// Sends either reach the instance or find it stopped; none fail with the
+	// connection error of dialing a killed process
+	for err := range errs {
+		if !errors.Is(err, ErrInstanceNotRunning) && !errors.Is(err, ErrInstanceNotFound) {
+			t.Errorf("SendCommand() error = %v, want ErrInstanceNotRunning or ErrInstanceNotFound", err)
+		}
+	}
+}
+
+func TestChangeListenersMayQueryManager(t *testing.T) {
+	manager, server := startManager(t, func(string) string { return `{}` })
+	running := make(chan bool, 1)
+	manager.OnChange(func(string) { running <- manager.Status().Running })
+
+	// A listener asking for the status must not deadlock on the lock the change held
+	for _, tt := range []struct {
+		name    string
+		change  func() error
+		running bool
+	}{
+		{"Restart", manager.Restart, true},
+		{"UpdateModel", func() error { return manager.UpdateModel(server.Model) }, true},
+		{"restartWithModel", func() error { return manager.restartWithModel(server.Model) }, true},
+		{"Start", func() error { return manager.Start(server.Model) }, true},
+		{"Stop", manager.Stop, false},
+	} {
+		done := make(chan error, 1)
+		go func() { done <- tt.change() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s() did not return: a listener deadlocked", tt.name)
+		}
+		if got := <-running; got != tt.running {
+			t.Errorf("listener saw Running = %v after %s(), want %v", got, tt.name, tt.running)
+		}
+	}
+}
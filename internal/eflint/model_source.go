@@ -0,0 +1,317 @@
+package eflint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// -----------------------------------------------------------------------------
+// Model Sources
+// -----------------------------------------------------------------------------
+//
+// The eflint-server binary only accepts a local model file. Models given as a URL
+// or as inline content are written to a temporary file first. The Manager owns
+// that file and removes it when the instance is stopped or switches models.
+
+// maxModelSize bounds the size of a downloaded or inline model.
+const maxModelSize = 16 << 20 // 16 MiB
+
+// ModelFetchTimeout bounds a model download, including redirects and reading the body.
+const ModelFetchTimeout = 30 * time.Second
+
+// maxModelRedirects bounds the redirects followed when downloading a model.
+const maxModelRedirects = 5
+
+// isModelURL reports whether a model location is an http(s) URL.
+func isModelURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// checkModelURL returns ErrModelURLNotAllowed unless u is an http(s) URL without
+// credentials on a host in ModelURLHosts. Model locations come from API callers,
+// so without the allowlist the service could be made to fetch internal URLs.
+func (m *Manager) checkModelURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrModelURLNotAllowed, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: URLs with credentials are not supported", ErrModelURLNotAllowed)
+	}
+	for _, allowed := range m.config.ModelURLHosts {
+		if strings.EqualFold(allowed, u.Host) || strings.EqualFold(allowed, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q is not in the allowed model hosts", ErrModelURLNotAllowed, u.Host)
+}
+
+// modelClient returns the client models are downloaded with. Unlike
+// http.DefaultClient it bounds the whole download and checks every redirect
+// against the allowed hosts.
+func (m *Manager) modelClient() *http.Client {
+	return &http.Client{
+		Timeout: ModelFetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxModelRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxModelRedirects)
+			}
+			return m.checkModelURL(req.URL)
+		},
+	}
+}
+
+// fetchModel downloads a model from a URL into a temporary file and returns its
+// path. The URL must pass checkModelURL.
+func (m *Manager) fetchModel(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrModelFetchFailed, err)
+	}
+	if err := m.checkModelURL(u); err != nil {
+		return "", err
+	}
+
+	resp, err := m.modelClient().Get(u.String())
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrModelFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s returned status %d", ErrModelFetchFailed, location, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxModelSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrModelFetchFailed, err)
+	}
+	if len(content) > maxModelSize {
+		return "", fmt.Errorf("%w: model exceeds %d bytes", ErrModelFetchFailed, maxModelSize)
+	}
+
+	m.logger.Info("downloaded eFLINT model",
+		zap.String("url", location),
+		zap.Int("size", len(content)),
+	)
+
+	return writeTempModel(filepath.Base(u.Path), content)
+}
+
+// writeTempModel writes model content to a new temporary file and returns its path.
+// The name is only used as a suffix to keep the file recognisable.
+func writeTempModel(name string, content []byte) (string, error) {
+	if len(content) > maxModelSize {
+		return "", fmt.Errorf("model exceeds %d bytes", maxModelSize)
+	}
+
+	name = filepath.Base(name)
+	if name == "" || name == "." || name == "/" {
+		name = "model.eflint"
+	}
+
+	file, err := os.CreateTemp("", "eflint-model-*-"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary model file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temporary model file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// replaceTempModel records the temporary model file used by the current instance
+// (empty if the model is a regular file) and removes the previous one.
+// Caller must hold mu.
+func (m *Manager) replaceTempModel(path string) {
+	if m.tempModel != "" && m.tempModel != path {
+		if err := os.Remove(m.tempModel); err != nil && !os.IsNotExist(err) {
+			m.logger.Warn("failed to remove temporary model file",
+				zap.String("path", m.tempModel),
+				zap.Error(err),
+			)
+		}
+	}
+	m.tempModel = path
+}
+
+// StartWithModelContent starts the eFLINT server instance with a model given as
+// content, e.g. from a secret or ConfigMap. The content is written to a temporary
+// file, which is removed when the instance is stopped or started with another model.
+// It returns ErrRestartInProgress while another (re)start is running.
+func (m *Manager) StartWithModelContent(name string, content []byte) error {
+	release, err := m.beginRestart()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	path, err := writeTempModel(name, content)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+
+	m.mu.Lock()
+	err = m.startLocked(path, true, nil)
+	m.mu.Unlock()
+	m.notifyChange("start")
+
+	return err
+}
+
+// -----------------------------------------------------------------------------
+// Modular Models
+// -----------------------------------------------------------------------------
+//
+// Agreements may be split across several model files, e.g. a base model plus
+// organization-specific extensions. The eflint-server binary accepts a single
+// model file, so the files are concatenated into a temporary file first.
+
+// modelFileExt is the extension of the model files picked up from a directory.
+const modelFileExt = ".eflint"
+
+// StartModels starts the eFLINT server instance with a model merged from several
+// local files. Each location is a file or a directory; a directory contributes the
+// *.eflint files it contains (not recursively), sorted by name. Files are merged in
+// the order given, and Status reports them in ModelFiles. A single location that
+// is a file or an http(s) URL is started as with Start. It returns
+// ErrRestartInProgress while another (re)start is running.
+func (m *Manager) StartModels(locations []string) error {
+	release, err := m.beginRestart()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return m.startModels(locations)
+}
+
+// startModels implements StartModels. Caller must hold the restart lock.
+func (m *Manager) startModels(locations []string) error {
+	if len(locations) == 0 {
+		return fmt.Errorf("%w: no model locations given", ErrModelNotFound)
+	}
+	if len(locations) == 1 && isModelURL(locations[0]) {
+		return m.start(locations[0])
+	}
+
+	// Fail fast on a missing binary, before merging anything
+	if _, err := m.resolveServerPath(); err != nil {
+		return err
+	}
+
+	files, err := expandModelFiles(locations)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 1 {
+		m.mu.Lock()
+		err := m.startLocked(files[0], false, files)
+		m.mu.Unlock()
+		m.notifyChange("start")
+
+		return err
+	}
+
+	path, err := mergeModelFiles(files)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+
+	m.logger.Info("merged eFLINT model files",
+		zap.Strings("files", files),
+		zap.String("path", path),
+	)
+
+	m.mu.Lock()
+	err = m.startLocked(path, true, files)
+	m.mu.Unlock()
+	m.notifyChange("start")
+
+	return err
+}
+
+// CheckModelLocations verifies that every local model location exists, so that a
+// configured but missing model can be reported before starting. URLs are not
+// checked. The error wraps ErrModelNotFound and names the absolute path, which
+// exposes relative paths resolved against an unexpected working directory.
+func CheckModelLocations(locations []string) error {
+	for _, location := range locations {
+		if isModelURL(location) {
+			continue
+		}
+		if _, err := os.Stat(location); err != nil {
+			absolute, absErr := filepath.Abs(location)
+			if absErr != nil {
+				absolute = location
+			}
+			return fmt.Errorf("%w: %s (absolute path %s): %v", ErrModelNotFound, location, absolute, err)
+		}
+	}
+	return nil
+}
+
+// expandModelFiles resolves model locations to the list of model files to merge.
+func expandModelFiles(locations []string) ([]string, error) {
+	var files []string
+	for _, location := range locations {
+		if isModelURL(location) {
+			return nil, fmt.Errorf("%w: %s: URLs cannot be merged with other models", ErrModelNotFound, location)
+		}
+
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrModelNotFound, err)
+		}
+		if !info.IsDir() {
+			files = append(files, location)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(location, "*"+modelFileExt))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrModelNotFound, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%w: no %s files in %s", ErrModelNotFound, modelFileExt, location)
+		}
+		slices.Sort(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// mergeModelFiles concatenates model files, in order, into a new temporary file and
+// returns its path. Each file is preceded by a comment naming its source.
+func mergeModelFiles(files []string) (string, error) {
+	var merged bytes.Buffer
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read model file: %w", err)
+		}
+
+		fmt.Fprintf(&merged, "// ---- %s ----\n", file)
+		merged.Write(content)
+		if !bytes.HasSuffix(content, []byte("\n")) {
+			merged.WriteByte('\n')
+		}
+		merged.WriteByte('\n')
+	}
+
+	return writeTempModel("merged"+modelFileExt, merged.Bytes())
+}
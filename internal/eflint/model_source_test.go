@@ -0,0 +1,117 @@
+package eflint
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+// modelServer serves model files over HTTP and counts the requests it answered.
+func modelServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestStartWithModelURL(t *testing.T) {
+	eflintServer := eflinttest.NewServer(t, func(string) string { return `{}` })
+	model, err := os.ReadFile(eflintServer.Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := modelServer(t, func(w http.ResponseWriter, r *http.Request) { w.Write(model) })
+
+	config := testManagerConfig()
+	config.ModelURLHosts = []string{"127.0.0.1"}
+	manager := NewManager(config, zap.NewNop())
+	if err := manager.Start(server.URL + "/agreement.eflint"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer manager.Stop()
+	eflinttest.WaitReady(t, manager.Ping)
+}
+
+func TestFetchModelRefusesHostsNotAllowed(t *testing.T) {
+	server, requests := modelServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("model"))
+	})
+	u, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name     string
+		hosts    []string
+		location string
+	}{
+		{"no hosts", nil, server.URL + "/model.eflint"},
+		{"other host", []string{"models.example.com"}, server.URL + "/model.eflint"},
+		{"other port", []string{"127.0.0.1:1"}, server.URL + "/model.eflint"},
+		{"credentials", []string{"127.0.0.1"}, "http://user:secret@" + u.Host + "/model.eflint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testManagerConfig()
+			config.ModelURLHosts = tt.hosts
+			manager := NewManager(config, zap.NewNop())
+			if _, err := manager.fetchModel(tt.location); !errors.Is(err, ErrModelURLNotAllowed) {
+				t.Errorf("fetchModel() error = %v, want ErrModelURLNotAllowed", err)
+			}
+		})
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("model server got %d requests, want none", n)
+	}
+}
+
+func TestFetchModelChecksRedirects(t *testing.T) {
+	// "localhost" reaches the same server, but is not an allowed host
+	var redirectTo string
+	server, _ := modelServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, redirectTo, http.StatusFound)
+			return
+		}
+		w.Write([]byte("model"))
+	})
+	u, _ := url.Parse(server.URL)
+	redirectTo = "http://localhost:" + u.Port() + "/model.eflint"
+
+	config := testManagerConfig()
+	config.ModelURLHosts = []string{"127.0.0.1"}
+	manager := NewManager(config, zap.NewNop())
+	if _, err := manager.fetchModel(server.URL + "/redirect"); !errors.Is(err, ErrModelURLNotAllowed) {
+		t.Errorf("fetchModel() error = %v, want ErrModelURLNotAllowed", err)
+	}
+
+	path, err := manager.fetchModel(server.URL + "/model.eflint")
+	if err != nil {
+		t.Fatalf("fetchModel() error = %v", err)
+	}
+	os.Remove(path)
+}
+
+func TestFetchModelTooLarge(t *testing.T) {
+	server, _ := modelServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", maxModelSize+1)))
+	})
+
+	config := testManagerConfig()
+	config.ModelURLHosts = []string{"127.0.0.1"}
+	manager := NewManager(config, zap.NewNop())
+	if _, err := manager.fetchModel(server.URL + "/model.eflint"); !errors.Is(err, ErrModelFetchFailed) {
+		t.Errorf("fetchModel() error = %v, want ErrModelFetchFailed", err)
+	}
+}
@@ -0,0 +1,233 @@
+package eflint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// -----------------------------------------------------------------------------
+// Model Validation
+// -----------------------------------------------------------------------------
+//
+// A model is validated by starting a throwaway eflint-server for it on its own
+// port and checking that it comes up and answers "status". The running instance,
+// the command history and the server log are not touched.
+
+// ModelValidationTimeout bounds how long ValidateModel waits for the throwaway
+// server to answer when the context has no earlier deadline.
+const ModelValidationTimeout = 10 * time.Second
+
+// validationPollInterval is how often ValidateModel checks whether the throwaway
+// server has come up.
+const validationPollInterval = 100 * time.Millisecond
+
+// maxValidationOutput bounds the server output kept per stream while validating.
+const maxValidationOutput = 64 << 10 // 64 KiB
+
+// ModelValidation is the outcome of validating a model.
+type ModelValidation struct {
+	Valid      bool   `json:"valid"`            // Whether the server loaded the model and answered "status"
+	Error      string `json:"error,omitempty"`  // Why the model was rejected
+	Stderr     string `json:"stderr,omitempty"` // Server stderr, e.g. parse errors
+	Stdout     string `json:"stdout,omitempty"` // Server stdout
+	DurationMs int64  `json:"duration_ms"`      // Time taken by the validation in milliseconds
+}
+
+// ValidateModel checks that the model at a local path parses. A directory is
+// validated as the merge of its *.eflint files, as with StartModels. The returned
+// error reports problems other than an invalid model (missing model or binary,
+// cancellation); a model the server rejects yields Valid == false.
+func (m *Manager) ValidateModel(ctx context.Context, modelLocation string) (*ModelValidation, error) {
+	if isModelURL(modelLocation) {
+		return nil, fmt.Errorf("%w: %s: only local models can be validated", ErrModelNotFound, modelLocation)
+	}
+
+	files, err := expandModelFiles([]string{modelLocation})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 1 {
+		return m.validateModelFile(ctx, files[0])
+	}
+
+	path, err := mergeModelFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+	defer os.Remove(path)
+
+	return m.validateModelFile(ctx, path)
+}
+
+// ValidateModelContent checks that a model given as content parses, as with ValidateModel.
+func (m *Manager) ValidateModelContent(ctx context.Context, name string, content []byte) (*ModelValidation, error) {
+	path, err := writeTempModel(name, content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+	defer os.Remove(path)
+
+	return m.validateModelFile(ctx, path)
+}
+
+// validateModelFile runs a throwaway eflint-server for a model file until it
+// answers "status", exits or the deadline expires, and then stops it.
+func (m *Manager) validateModelFile(ctx context.Context, modelLocation string) (*ModelValidation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ModelValidationTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	port, err := m.freePort()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+	stdout := &boundedBuffer{limit: maxValidationOutput}
+	stderr := &boundedBuffer{limit: maxValidationOutput}
+
+	cmd, err := m.spawnProcess(modelLocation, port, stdout, stderr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProcessStartFailed, err)
+	}
+
+	exited := make(chan struct{})
+	var waitErr error
+	go func() {
+		waitErr = cmd.Wait()
+		close(exited)
+	}()
+
+	validation := &ModelValidation{}
+	statusErr := m.awaitStatus(ctx, fmt.Sprintf("127.0.0.1:%d", port), exited)
+
+	// Stop the server as Instance.Kill does: SIGTERM first, SIGKILL after KillTimeout
+	select {
+	case <-exited:
+	default:
+		if err := terminate(cmd.Process); err != nil || !waitFor(exited, m.config.KillTimeout) {
+			cmd.Process.Kill()
+			<-exited
+		}
+	}
+	m.forgetProcess(cmd)
+
+	duration := time.Since(start)
+	validation.DurationMs = duration.Milliseconds()
+	validation.Stdout = stdout.String()
+	validation.Stderr = stderr.String()
+
+	switch {
+	case statusErr == nil:
+		validation.Valid = true
+	case errors.Is(statusErr, errServerExited):
+		validation.Error = fmt.Sprintf("eflint-server exited while loading the model: %v", waitErr)
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("%w: eflint-server did not answer: %w", ErrCommandFailed, ctx.Err())
+	default:
+		validation.Error = statusErr.Error()
+	}
+
+	m.logger.Info("validated eFLINT model",
+		zap.String("model", modelLocation),
+		zap.Bool("valid", validation.Valid),
+		zap.Duration("duration", duration),
+	)
+
+	return validation, nil
+}
+
+// errServerExited is returned by awaitStatus when the server process exits.
+var errServerExited = errors.New("eflint-server exited")
+
+// awaitStatus polls the server at addr until it answers "status" with valid JSON,
+// the process exits or ctx is done. An answer only counts while the process is
+// still running; otherwise another server answered on its port.
+func (m *Manager) awaitStatus(ctx context.Context, addr string, exited <-chan struct{}) error {
+	ticker := time.NewTicker(validationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exited:
+			return errServerExited
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		pc, err := dialConn(ctx, addr, validationPollInterval)
+		if err != nil {
+			continue // Not listening yet
+		}
+		response, err := m.roundTrip(ctx, pc, `{"command": "status"}`, readString)
+		pc.conn.Close()
+		if err != nil {
+			continue
+		}
+		select {
+		case <-exited:
+			return errServerExited
+		default:
+		}
+		if !json.Valid([]byte(response)) {
+			return fmt.Errorf("%w: status response is not valid JSON (preview: %q)",
+				ErrInvalidResponse, response[:min(len(response), 200)])
+		}
+		return nil
+	}
+}
+
+// waitFor reports whether done is closed within timeout.
+func waitFor(done <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// boundedBuffer collects process output up to limit bytes; the rest is dropped.
+type boundedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer. It never fails, so the process is not blocked.
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the collected output, marking truncation.
+func (b *boundedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := strings.TrimRight(b.buf.String(), "\n")
+	if b.truncated {
+		s += "\n... (truncated)"
+	}
+	return s
+}
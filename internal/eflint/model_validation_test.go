@@ -0,0 +1,58 @@
+package eflint
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+func TestValidateModel(t *testing.T) {
+	server := eflinttest.NewServer(t, func(string) string { return `{"status": "ok"}` })
+	manager := NewManager(testManagerConfig(), zap.NewNop())
+
+	validation, err := manager.ValidateModel(context.Background(), server.Model)
+	if err != nil {
+		t.Fatalf("ValidateModel() error = %v", err)
+	}
+	if !validation.Valid {
+		t.Errorf("ValidateModel() = %+v, want a valid model", validation)
+	}
+}
+
+func TestValidateModelRejected(t *testing.T) {
+	manager := NewManager(testManagerConfig(), zap.NewNop())
+
+	validation, err := manager.ValidateModel(context.Background(), eflinttest.FailingModel(t, "parse error at line 3"))
+	if err != nil {
+		t.Fatalf("ValidateModel() error = %v", err)
+	}
+	if validation.Valid || !strings.Contains(validation.Stderr, "parse error at line 3") {
+		t.Errorf("ValidateModel() = %+v, want an invalid model with the parse error", validation)
+	}
+}
+
+func TestValidateModelSkipsBusyPorts(t *testing.T) {
+	// Another server on the only port of the range would answer "status" for a
+	// throwaway server that cannot bind it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	config := testManagerConfig()
+	config.MinPort, config.MaxPort = port, port
+	manager := NewManager(config, zap.NewNop())
+
+	server := eflinttest.NewServer(t, func(string) string { return `{"status": "ok"}` })
+	if _, err := manager.ValidateModel(context.Background(), server.Model); !errors.Is(err, ErrProcessStartFailed) {
+		t.Errorf("ValidateModel() error = %v, want ErrProcessStartFailed", err)
+	}
+}
@@ -0,0 +1,196 @@
+package eflint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// -----------------------------------------------------------------------------
+// Phrases
+// -----------------------------------------------------------------------------
+//
+// eFLINT phrases are textual statements (e.g. `+allowed-archetype("VU", "x", "y").`)
+// that are sent to the server with the "phrase" command. These helpers build
+// phrases from structured input and interpret the server's response.
+
+// FactOperation is the prefix used in a fact phrase.
+type FactOperation string
+
+const (
+	// FactCreate creates (asserts) a fact.
+	FactCreate FactOperation = "+"
+	// FactTerminate terminates (retracts) a fact.
+	FactTerminate FactOperation = "-"
+)
+
+// typeNamePattern matches eFLINT type names such as "allowed-archetype".
+var typeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// ValidateTypeName checks that name can be written into a phrase as a fact or act
+// type: a letter followed by letters, digits, '-' or '_'. Anything else could end
+// the statement and inject another one. It returns an error wrapping
+// ErrInvalidTypeName otherwise.
+func ValidateTypeName(name string) error {
+	if !typeNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidTypeName, name)
+	}
+	return nil
+}
+
+// BuildFactPhrase builds a phrase that creates or terminates a fact with the given
+// positional string arguments, e.g. `+allowed-archetype("VU", "x", "computeToData").`
+// It returns an error wrapping ErrInvalidTypeName if factType is not a type name.
+func BuildFactPhrase(op FactOperation, factType string, args []string) (string, error) {
+	if err := ValidateTypeName(factType); err != nil {
+		return "", err
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteString(arg)
+	}
+	return fmt.Sprintf("%s%s(%s).", op, factType, strings.Join(quoted, ", ")), nil
+}
+
+// BuildTypedFactPhrase builds a phrase that creates or terminates a fact with the
+// given arguments, writing each as a literal of its kind, e.g.
+// `+budget("VU", 100).` for a string and an integer argument. It returns an error
+// wrapping ErrInvalidTypeName if factType or the type of a composite argument is
+// not a type name, and ErrInvalidValue if an argument is not a value of its kind.
+func BuildTypedFactPhrase(op FactOperation, factType string, args []FactArgument) (string, error) {
+	if err := ValidateTypeName(factType); err != nil {
+		return "", err
+	}
+	literals, err := literals(args)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s(%s).", op, factType, strings.Join(literals, ", ")), nil
+}
+
+// literals writes each argument as an eFLINT literal of its kind.
+func literals(args []FactArgument) ([]string, error) {
+	literals := make([]string, len(args))
+	for i, arg := range args {
+		literal, err := literal(arg)
+		if err != nil {
+			return nil, err
+		}
+		literals[i] = literal
+	}
+	return literals, nil
+}
+
+// literal writes an argument as an eFLINT literal: strings quoted, integers in
+// decimal and composites as their type applied to their arguments. Integers are
+// parsed and composites rebuilt from their arguments, so Value is never written
+// out verbatim.
+func literal(arg FactArgument) (string, error) {
+	switch arg.Kind {
+	case api.KindString:
+		return quoteString(arg.Value), nil
+	case api.KindInt:
+		n, err := strconv.ParseInt(arg.Value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q is not an integer", ErrInvalidValue, arg.Value)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case api.KindComposite:
+		if err := ValidateTypeName(arg.FactType); err != nil {
+			return "", err
+		}
+		literals, err := literals(arg.Arguments)
+		if err != nil {
+			return "", err
+		}
+		return arg.FactType + "(" + strings.Join(literals, ", ") + ")", nil
+	}
+	return "", fmt.Errorf("%w: unknown kind %q", ErrInvalidValue, arg.Kind)
+}
+
+// BuildActPhrase builds a phrase that performs an act or triggers an event with the
+// given positional string arguments, e.g. `register-requester("VU", "user@example.com").`
+// Unlike a fact phrase it carries no operation prefix: the server executes the act
+// and applies its effects to the state. It returns an error wrapping
+// ErrInvalidTypeName if actType is not a type name.
+func BuildActPhrase(actType string, args []string) (string, error) {
+	if err := ValidateTypeName(actType); err != nil {
+		return "", err
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteString(arg)
+	}
+	return fmt.Sprintf("%s(%s).", actType, strings.Join(quoted, ", ")), nil
+}
+
+// quoteString quotes a value as an eFLINT string literal.
+func quoteString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
+
+// SendPhrase sends a phrase to the eFLINT server and returns the raw response.
+// A non-nil error is returned if the command could not be sent or if the server
+// reports the phrase as invalid or erroneous.
+func (m *Manager) SendPhrase(text string) (string, error) {
+	return m.SendPhraseContext(context.Background(), text)
+}
+
+// SendPhraseContext is like SendPhrase but aborts when ctx is cancelled or its
+// deadline expires.
+func (m *Manager) SendPhraseContext(ctx context.Context, text string) (string, error) {
+	cmdJSON, err := json.Marshal(map[string]string{
+		"command": "phrase",
+		"text":    text,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal phrase command: %w", err)
+	}
+
+	response, err := m.SendCommandContext(ctx, string(cmdJSON))
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkPhraseResponse(response); err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// checkPhraseResponse inspects a phrase response for rejection or errors.
+func checkPhraseResponse(response string) error {
+	var resp struct {
+		Response string `json:"response"`
+		Errors   []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	if strings.HasPrefix(resp.Response, "invalid") {
+		return fmt.Errorf("%w: %s", ErrCommandFailed, resp.Response)
+	}
+
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("%w: %s", ErrCommandFailed, strings.Join(messages, "; "))
+	}
+
+	return nil
+}
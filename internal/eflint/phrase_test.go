@@ -0,0 +1,106 @@
+package eflint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+func TestBuildFactPhrase(t *testing.T) {
+	phrase, err := BuildFactPhrase(FactCreate, "allowed-archetype", []string{"VU", `a"b\c`, "computeToData"})
+	if err != nil {
+		t.Fatalf("BuildFactPhrase() error = %v", err)
+	}
+	want := `+allowed-archetype("VU", "a\"b\\c", "computeToData").`
+	if phrase != want {
+		t.Errorf("BuildFactPhrase() = %s, want %s", phrase, want)
+	}
+}
+
+func TestBuildFactPhraseRejectsInvalidTypeNames(t *testing.T) {
+	for _, factType := range []string{
+		"",
+		`x("a"). +allowed-archetype`,
+		"allowed archetype",
+		"-allowed",
+		"1fact",
+		"fact.",
+		"fact(",
+		`fact"`,
+		"fact\n",
+	} {
+		if _, err := BuildFactPhrase(FactCreate, factType, nil); !errors.Is(err, ErrInvalidTypeName) {
+			t.Errorf("BuildFactPhrase(%q) error = %v, want ErrInvalidTypeName", factType, err)
+		}
+		if _, err := BuildTypedFactPhrase(FactTerminate, factType, nil); !errors.Is(err, ErrInvalidTypeName) {
+			t.Errorf("BuildTypedFactPhrase(%q) error = %v, want ErrInvalidTypeName", factType, err)
+		}
+	}
+}
+
+func TestValidateTypeName(t *testing.T) {
+	for _, name := range []string{"organization", "allowed-archetype", "allowed_data_set", "Fact2"} {
+		if err := ValidateTypeName(name); err != nil {
+			t.Errorf("ValidateTypeName(%q) error = %v", name, err)
+		}
+	}
+}
+
+func TestBuildActPhrase(t *testing.T) {
+	phrase, err := BuildActPhrase("register-requester", []string{"VU", "user@example.com"})
+	if err != nil {
+		t.Fatalf("BuildActPhrase() error = %v", err)
+	}
+	if want := `register-requester("VU", "user@example.com").`; phrase != want {
+		t.Errorf("BuildActPhrase() = %s, want %s", phrase, want)
+	}
+
+	for _, actType := range []string{"", `x("a"). +allowed-archetype`, "act(", "+act"} {
+		if _, err := BuildActPhrase(actType, nil); !errors.Is(err, ErrInvalidTypeName) {
+			t.Errorf("BuildActPhrase(%q) error = %v, want ErrInvalidTypeName", actType, err)
+		}
+	}
+}
+
+func TestBuildTypedFactPhrase(t *testing.T) {
+	args := []FactArgument{
+		{FactType: "organization", Value: `V"U`},
+		{FactType: "amount", Value: "100", Kind: api.KindInt},
+		// A tampered Value must not be written out: the literal is rebuilt from Arguments
+		{FactType: "agreement", Value: `x). +evil(`, Kind: api.KindComposite, Arguments: []FactArgument{
+			{FactType: "organization", Value: "VU"},
+			{FactType: "version", Value: "3", Kind: api.KindInt},
+		}},
+	}
+	phrase, err := BuildTypedFactPhrase(FactCreate, "budget", args)
+	if err != nil {
+		t.Fatalf("BuildTypedFactPhrase() error = %v", err)
+	}
+	if want := `+budget("V\"U", 100, agreement("VU", 3)).`; phrase != want {
+		t.Errorf("BuildTypedFactPhrase() = %s, want %s", phrase, want)
+	}
+}
+
+func TestBuildTypedFactPhraseRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  FactArgument
+		want error
+	}{
+		{"non-integer int", FactArgument{FactType: "x", Value: `1). +x("a"`, Kind: api.KindInt}, ErrInvalidValue},
+		{"empty int", FactArgument{FactType: "x", Kind: api.KindInt}, ErrInvalidValue},
+		{"unknown kind", FactArgument{FactType: "x", Value: "1", Kind: "float"}, ErrInvalidValue},
+		{"composite type", FactArgument{FactType: `x("a"). +y`, Kind: api.KindComposite}, ErrInvalidTypeName},
+		{"nested argument", FactArgument{FactType: "x", Kind: api.KindComposite, Arguments: []FactArgument{
+			{FactType: "y", Value: "2; z", Kind: api.KindInt},
+		}}, ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildTypedFactPhrase(FactCreate, "fact", []FactArgument{tt.arg}); !errors.Is(err, tt.want) {
+				t.Errorf("BuildTypedFactPhrase() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package eflint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// -----------------------------------------------------------------------------
+// Orphan Tracking
+// -----------------------------------------------------------------------------
+//
+// If the service dies without stopping its eflint-server processes, they keep
+// running and hold their ports. With ManagerConfig.PIDFile set, the Manager
+// records every process it starts, and CleanupOrphans kills the ones a previous
+// run left behind. A process is only killed if its command line still matches
+// the recorded one, so a pid reused by an unrelated process is left alone.
+
+// pidEntry is a process recorded in the pidfile.
+type pidEntry struct {
+	PID  int      `json:"pid"`  // Process ID
+	Args []string `json:"args"` // Full command line (executable path and arguments)
+}
+
+// pidFile records the running eflint-server processes in a JSON file. A nil
+// *pidFile records nothing.
+type pidFile struct {
+	path    string
+	mu      sync.Mutex
+	entries map[int][]string // pid -> command line
+}
+
+// newPIDFile returns a pidfile at path, or nil if path is empty.
+func newPIDFile(path string) *pidFile {
+	if path == "" {
+		return nil
+	}
+	return &pidFile{path: path, entries: make(map[int][]string)}
+}
+
+// add records a started process.
+func (f *pidFile) add(pid int, args []string) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.entries[pid] = args
+	return f.write()
+}
+
+// remove forgets a process that has exited.
+func (f *pidFile) remove(pid int) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.entries[pid]; !ok {
+		return nil
+	}
+	delete(f.entries, pid)
+	return f.write()
+}
+
+// write replaces the file with the current entries; without entries the file is
+// removed. Caller must hold mu.
+func (f *pidFile) write() error {
+	if len(f.entries) == 0 {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove pidfile: %w", err)
+		}
+		return nil
+	}
+
+	entries := make([]pidEntry, 0, len(f.entries))
+	for pid, args := range f.entries {
+		entries = append(entries, pidEntry{PID: pid, Args: args})
+	}
+	slices.SortFunc(entries, func(a, b pidEntry) int { return a.PID - b.PID })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pidfile: %w", err)
+	}
+
+	// Write to a temporary file first, so that a crash never leaves a truncated pidfile
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return nil
+}
+
+// readPIDFile returns the processes recorded at path; a missing file has none.
+func readPIDFile(path string) ([]pidEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pidfile: %w", err)
+	}
+
+	var entries []pidEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse pidfile %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// orphanPollInterval is how often CleanupOrphans checks whether a process exited.
+const orphanPollInterval = 50 * time.Millisecond
+
+// CleanupOrphans kills the eflint-server processes recorded in the pidfile by a
+// previous run that did not stop them, and clears the pidfile. Call it once at
+// startup, before starting an instance. A recorded process is only killed if it
+// is still running with the recorded command line (for interpreted servers, the
+// interpreter may precede it). Orphans get KillTimeout to exit after SIGTERM
+// before they are killed with SIGKILL.
+//
+// It returns the number of processes killed. Without a PIDFile, or on platforms
+// where command lines cannot be inspected, nothing is killed.
+func (m *Manager) CleanupOrphans() (int, error) {
+	if m.pids == nil {
+		return 0, nil
+	}
+
+	entries, err := readPIDFile(m.pids.path)
+	if err != nil {
+		return 0, err
+	}
+
+	killed := 0
+	for _, entry := range entries {
+		if !m.isRecordedProcess(entry) {
+			m.logger.Debug("recorded eflint-server process is no longer running",
+				zap.Int("pid", entry.PID),
+			)
+			continue
+		}
+
+		m.logger.Warn("killing orphaned eflint-server process from a previous run",
+			zap.Int("pid", entry.PID),
+			zap.Strings("args", entry.Args),
+		)
+		if err := m.killOrphan(entry); err != nil {
+			m.logger.Error("failed to kill orphaned eflint-server process",
+				zap.Int("pid", entry.PID),
+				zap.Error(err),
+			)
+			continue
+		}
+		killed++
+	}
+
+	m.pids.mu.Lock()
+	defer m.pids.mu.Unlock()
+	clear(m.pids.entries)
+	return killed, m.pids.write()
+}
+
+// isRecordedProcess reports whether the process with the entry's pid is still
+// running the recorded command line.
+func (m *Manager) isRecordedProcess(entry pidEntry) bool {
+	if entry.PID <= 0 || entry.PID == os.Getpid() || len(entry.Args) == 0 {
+		return false
+	}
+
+	args, err := processArgs(entry.PID)
+	if errors.Is(err, errProcessInspectionUnsupported) {
+		m.logger.Warn("cannot verify orphaned eflint-server process on this platform; leaving it running",
+			zap.Int("pid", entry.PID),
+		)
+		return false
+	}
+	if err != nil {
+		return false // Exited, or not ours to inspect
+	}
+
+	// An interpreter running a script server precedes the recorded command line
+	return len(args) >= len(entry.Args) && slices.Equal(args[len(args)-len(entry.Args):], entry.Args)
+}
+
+// killOrphan stops a process that is not a child of this process, as Instance.Kill
+// does: SIGTERM first, SIGKILL after KillTimeout.
+func (m *Manager) killOrphan(entry pidEntry) error {
+	process, err := os.FindProcess(entry.PID)
+	if err != nil {
+		return err
+	}
+
+	if m.config.KillTimeout > 0 && terminate(process) == nil {
+		deadline := time.Now().Add(m.config.KillTimeout)
+		for time.Now().Before(deadline) {
+			if !m.isRecordedProcess(entry) {
+				return nil
+			}
+			time.Sleep(orphanPollInterval)
+		}
+	}
+
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package eflint
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// child is a process started by a test, standing in for an orphaned eflint-server.
+type child struct {
+	cmd    *exec.Cmd
+	exited chan struct{}
+	err    error // Result of Wait, once exited is closed
+}
+
+// startChild starts a process that is killed when the test ends.
+func startChild(t *testing.T, name string, args ...string) *child {
+	t.Helper()
+	if _, err := processArgs(os.Getpid()); errors.Is(err, errProcessInspectionUnsupported) {
+		t.Skip("process command lines cannot be inspected on this platform")
+	}
+
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start %s: %v", name, err)
+	}
+	c := &child{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		c.err = cmd.Wait()
+		close(c.exited)
+	}()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		<-c.exited
+	})
+	return c
+}
+
+// running reports whether the process is still running.
+func (c *child) running() bool {
+	select {
+	case <-c.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// orphanManager returns a Manager using a pidfile that records entries.
+func orphanManager(t *testing.T, entries ...pidEntry) (*Manager, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "eflint.pids")
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := testManagerConfig()
+	config.PIDFile = path
+	return NewManager(config, zap.NewNop()), path
+}
+
+func TestCleanupOrphansKillsRecordedProcesses(t *testing.T) {
+	sleep := startChild(t, "sleep", "60")
+
+	// A script server runs under its interpreter, which precedes the recorded command line
+	script := filepath.Join(t.TempDir(), "eflint-server.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nwhile :; do sleep 0.1; done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	interpreted := startChild(t, script)
+
+	manager, path := orphanManager(t,
+		pidEntry{PID: sleep.cmd.Process.Pid, Args: []string{"sleep", "60"}},
+		pidEntry{PID: interpreted.cmd.Process.Pid, Args: []string{script}},
+	)
+	killed, err := manager.CleanupOrphans()
+	if err != nil || killed != 2 {
+		t.Fatalf("CleanupOrphans() = %d, %v, want 2 processes killed", killed, err)
+	}
+
+	for name, c := range map[string]*child{"sleep": sleep, "script": interpreted} {
+		select {
+		case <-c.exited:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s still running after CleanupOrphans", name)
+		}
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("pidfile still exists after CleanupOrphans: %v", err)
+	}
+}
+
+func TestCleanupOrphansLeavesOtherProcesses(t *testing.T) {
+	sleep := startChild(t, "sleep", "60")
+	pid := sleep.cmd.Process.Pid
+
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		entry pidEntry
+	}{
+		{"reused pid", pidEntry{PID: pid, Args: []string{"/usr/local/bin/eflint-server", "model.eflint", "9000"}}},
+		{"different arguments", pidEntry{PID: pid, Args: []string{"sleep", "61"}}},
+		{"longer command line", pidEntry{PID: pid, Args: []string{"/bin/sh", "sleep", "60"}}},
+		{"no command line", pidEntry{PID: pid}},
+		{"own process", pidEntry{PID: os.Getpid(), Args: os.Args}},
+		{"exited process", pidEntry{PID: exited.Process.Pid, Args: []string{"true"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, path := orphanManager(t, tt.entry)
+			killed, err := manager.CleanupOrphans()
+			if err != nil || killed != 0 {
+				t.Errorf("CleanupOrphans() = %d, %v, want nothing killed", killed, err)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("pidfile still exists after CleanupOrphans: %v", err)
+			}
+		})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if !sleep.running() {
+		t.Errorf("a process not matching its recorded command line was killed: %v", sleep.err)
+	}
+}
+
+func TestCleanupOrphansWithoutPIDFile(t *testing.T) {
+	manager := NewManager(testManagerConfig(), zap.NewNop())
+	if killed, err := manager.CleanupOrphans(); err != nil || killed != 0 {
+		t.Errorf("CleanupOrphans() = %d, %v without a pidfile, want 0, nil", killed, err)
+	}
+}
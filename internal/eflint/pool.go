@@ -0,0 +1,175 @@
+package eflint
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Connection Pool
+// -----------------------------------------------------------------------------
+
+// poolConn is a TCP connection to an eFLINT instance together with its buffered
+// reader. The reader must stay with the connection because it may hold bytes
+// that were already read from the socket.
+type poolConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	lastUsed time.Time
+	reused   bool // Whether the connection was handed out from the idle set
+}
+
+// errPoolClosed is returned by connPool.get once the pool is closed.
+var errPoolClosed = errors.New("connection pool is closed")
+
+// connPool keeps idle connections to a single eFLINT instance for reuse, and
+// bounds the number of connections open to it. Each connection is used by one
+// command at a time, so the line protocol is never interleaved on a socket. The
+// pool belongs to an Instance and is closed when the instance is killed, so
+// connections never outlive the process they were opened against.
+// Thread-safe for concurrent access.
+type connPool struct {
+	addr        string        // Address of the eFLINT instance
+	maxIdle     int           // Maximum number of idle connections kept
+	idleTimeout time.Duration // Idle connections older than this are discarded (0 = never)
+	dialTimeout time.Duration // Timeout for establishing new connections
+
+	// Connections in use each hold a slot; nil if the number is unlimited. New
+	// connections are only dialed when no idle one is left, so the connections
+	// open, in use or idle, never outnumber the slots.
+	slots chan struct{}
+	done  chan struct{} // Closed by close, to release callers waiting for a slot
+
+	idle   []*poolConn
+	closed bool
+	mu     sync.Mutex
+}
+
+// newConnPool creates an empty pool for the given address that keeps up to
+// maxIdle idle connections and has up to maxOpen connections open (0 = unlimited).
+func newConnPool(addr string, maxIdle, maxOpen int, idleTimeout, dialTimeout time.Duration) *connPool {
+	p := &connPool{
+		addr:        addr,
+		maxIdle:     maxIdle,
+		idleTimeout: idleTimeout,
+		dialTimeout: dialTimeout,
+		done:        make(chan struct{}),
+	}
+	if maxOpen > 0 {
+		p.slots = make(chan struct{}, maxOpen)
+	}
+	return p
+}
+
+// get returns an idle connection if one is available, or dials a new one. If the
+// maximum number of connections is open, it waits until one is returned or
+// discarded, the context is done or the pool is closed. Every connection must be
+// handed back with put or discard.
+func (p *connPool) get(ctx context.Context) (*poolConn, error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.done:
+			return nil, errPoolClosed
+		}
+	}
+
+	p.mu.Lock()
+	for len(p.idle) > 0 {
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+
+		if p.idleTimeout > 0 && time.Since(pc.lastUsed) > p.idleTimeout {
+			pc.conn.Close()
+			continue
+		}
+
+		p.mu.Unlock()
+		pc.reused = true
+		return pc, nil
+	}
+	p.mu.Unlock()
+
+	pc, err := dialConn(ctx, p.addr, p.dialTimeout)
+	if err != nil {
+		p.release()
+		return nil, err
+	}
+	return pc, nil
+}
+
+// put returns a healthy connection to the pool. The connection is closed instead
+// if the pool is closed or already holds the maximum number of idle connections.
+func (p *connPool) put(pc *poolConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.release()
+
+	if p.closed || len(p.idle) >= p.maxIdle {
+		pc.conn.Close()
+		return
+	}
+
+	pc.lastUsed = time.Now()
+	pc.reused = false
+	p.idle = append(p.idle, pc)
+}
+
+// discard closes a connection taken from the pool, e.g. one that saw an error.
+func (p *connPool) discard(pc *poolConn) {
+	pc.conn.Close()
+	p.release()
+}
+
+// redial closes a connection taken from the pool and dials a fresh one in its
+// place, e.g. when the server closed the connection while it was idle.
+func (p *connPool) redial(ctx context.Context, pc *poolConn) (*poolConn, error) {
+	pc.conn.Close()
+	fresh, err := dialConn(ctx, p.addr, p.dialTimeout)
+	if err != nil {
+		p.release()
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// release frees the slot of a connection that is no longer in use.
+func (p *connPool) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+// close closes all idle connections and rejects future returns.
+func (p *connPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed {
+		close(p.done)
+	}
+	p.closed = true
+	for _, pc := range p.idle {
+		pc.conn.Close()
+	}
+	p.idle = nil
+}
+
+// dialConn opens a new connection to an eFLINT instance.
+func dialConn(ctx context.Context, addr string, timeout time.Duration) (*poolConn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &poolConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package eflint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingHandler answers "facts" commands once release is closed, counting how
+// many it is answering at once; other commands are answered immediately.
+func blockingHandler(release <-chan struct{}, active, peak *atomic.Int32) func(string) string {
+	return func(command string) string {
+		if name, _ := commandOf(command); name != "facts" {
+			return `{"status": "ok"}`
+		}
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		active.Add(-1)
+		return `{"values": []}`
+	}
+}
+
+func TestPoolLimitsOpenConnections(t *testing.T) {
+	config := testManagerConfig()
+	config.PoolSize = 1
+	config.PoolMaxOpen = 2
+	release := make(chan struct{})
+	var active, peak atomic.Int32
+	manager, server := startManagerWithConfig(t, config, blockingHandler(release, &active, &peak))
+	before := server.Accepted()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := manager.SendCommandContext(context.Background(), `{"command": "facts"}`)
+			errs <- err
+		}()
+	}
+
+	// Two commands reach the server; the others wait for their connections
+	deadline := time.Now().Add(5 * time.Second)
+	for active.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := active.Load(); n != 2 {
+		t.Errorf("%d commands in flight at the server, want 2", n)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("SendCommandContext() error = %v", err)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("up to %d commands in flight, want at most 2", p)
+	}
+	if opened := server.Accepted() - before; opened > 2 {
+		t.Errorf("opened %d connections, want at most 2", opened)
+	}
+}
+
+func TestPoolWaitEndsWithContext(t *testing.T) {
+	config := testManagerConfig()
+	config.PoolMaxOpen = 1
+	release := make(chan struct{})
+	defer close(release)
+	var active, peak atomic.Int32
+	manager, _ := startManagerWithConfig(t, config, blockingHandler(release, &active, &peak))
+
+	go manager.SendCommandContext(context.Background(), `{"command": "facts"}`)
+	for active.Load() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := manager.SendCommandContext(ctx, `{"command": "status"}`)
+	if !errors.Is(err, ErrCommandFailed) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendCommandContext() error = %v, want ErrCommandFailed wrapping DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SendCommandContext() returned after %v, want it to stop waiting at the deadline", elapsed)
+	}
+}
+
+func TestPoolCloseReleasesWaiters(t *testing.T) {
+	pool := newConnPool("127.0.0.1:1", 1, 1, 0, time.Second)
+	pool.slots <- struct{}{} // The only connection is in use
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.get(context.Background())
+		done <- err
+	}()
+	pool.close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errPoolClosed) {
+			t.Errorf("get() error = %v, want errPoolClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("get() still waiting after close")
+	}
+}
+
+// BenchmarkSendCommandParallel measures commands sent concurrently over pools with
+// and without a limit on open connections.
+func BenchmarkSendCommandParallel(b *testing.B) {
+	for _, maxOpen := range []int{0, 4} {
+		b.Run(fmt.Sprintf("max_open=%d", maxOpen), func(b *testing.B) {
+			config := testManagerConfig()
+			config.PoolSize = 4
+			config.PoolMaxOpen = maxOpen
+			manager, server := startManagerWithConfig(b, config, func(string) string { return `{"status": "ok"}` })
+			before := server.Accepted()
+
+			b.SetParallelism(16)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := manager.SendCommandContext(context.Background(), `{"command": "status"}`); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(server.Accepted()-before), "conns")
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package eflint
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Port Selection
+// -----------------------------------------------------------------------------
+
+// Default port range for eFLINT servers: the IANA dynamic (ephemeral) range, which
+// services are not registered in, so colocated services rarely collide with it.
+const (
+	DefaultMinPort = 49152
+	DefaultMaxPort = 65535
+)
+
+// PortRange is an inclusive range of ports; a single port has Min == Max.
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// contains reports whether the range includes the port.
+func (r PortRange) contains(port int) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// String returns the range as "5432" or "8000-8100".
+func (r PortRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParsePortRanges parses ports and port ranges such as "5432" or "8000-8100".
+func ParsePortRanges(specs []string) ([]PortRange, error) {
+	ranges := make([]PortRange, 0, len(specs))
+	for _, spec := range specs {
+		low, high, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+		if !isRange {
+			high = low
+		}
+
+		first, errFirst := strconv.Atoi(strings.TrimSpace(low))
+		last, errLast := strconv.Atoi(strings.TrimSpace(high))
+		if errFirst != nil || errLast != nil || first < 1 || last > 65535 || first > last {
+			return nil, fmt.Errorf("%w: %q is not a port or port range", ErrInvalidPortRange, spec)
+		}
+		ranges = append(ranges, PortRange{Min: first, Max: last})
+	}
+	return ranges, nil
+}
+
+// ValidatePortRange checks that first..last is a valid port range and that at
+// least one port in it is not excluded.
+func ValidatePortRange(first, last int, excluded []PortRange) error {
+	if first < 1 || last > 65535 || first > last {
+		return fmt.Errorf("%w: %d-%d", ErrInvalidPortRange, first, last)
+	}
+	if availablePorts(first, last, excluded) == 0 {
+		return fmt.Errorf("%w: every port in %d-%d is excluded", ErrInvalidPortRange, first, last)
+	}
+	return nil
+}
+
+// availablePorts returns the number of ports in first..last that are not excluded.
+func availablePorts(first, last int, excluded []PortRange) int {
+	n := 0
+	for port := first; port <= last; port++ {
+		if !isExcluded(port, excluded) {
+			n++
+		}
+	}
+	return n
+}
+
+// isExcluded reports whether any of the ranges contains the port.
+func isExcluded(port int, excluded []PortRange) bool {
+	for _, r := range excluded {
+		if r.contains(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateRandomPort picks a random port in MinPort..MaxPort (inclusive) that is
+// not in ExcludedPorts. It returns ErrInvalidPortRange if no port can be picked.
+func (m *Manager) generateRandomPort() (int, error) {
+	first, last, excluded := m.config.MinPort, m.config.MaxPort, m.config.ExcludedPorts
+	if err := ValidatePortRange(first, last, excluded); err != nil {
+		return 0, err
+	}
+
+	// Pick the n-th non-excluded port, so every allowed port is equally likely
+	n := rand.Intn(availablePorts(first, last, excluded))
+	for port := first; ; port++ {
+		if isExcluded(port, excluded) {
+			continue
+		}
+		if n == 0 {
+			return port, nil
+		}
+		n--
+	}
+}
+
+// maxFreePortAttempts bounds how many random ports freePort tries.
+const maxFreePortAttempts = 20
+
+// freePort picks a random port as generateRandomPort does, but only one that no
+// process listens on, checked by binding it briefly. A throwaway server on a busy
+// port would fail to start while another server, possibly the running instance,
+// answers in its place; an instance would fail to start while the default range
+// overlaps the ephemeral ports of outgoing connections.
+func (m *Manager) freePort() (int, error) {
+	var lastErr error
+	for range maxFreePortAttempts {
+		port, err := m.generateRandomPort()
+		if err != nil {
+			return 0, err
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		listener.Close()
+		return port, nil
+	}
+	return 0, fmt.Errorf("no free port found in %d attempts: %w", maxFreePortAttempts, lastErr)
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package eflint
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+)
+
+// errProcessInspectionUnsupported is returned by processArgs on platforms where
+// the command line of another process cannot be read.
+var errProcessInspectionUnsupported = errors.New("process inspection is not supported on this platform")
+
+// processArgs returns the command line of a running process.
+func processArgs(pid int) ([]string, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("process has no command line") // Zombie or kernel thread
+	}
+
+	fields := bytes.Split(bytes.TrimSuffix(data, []byte{0}), []byte{0})
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = string(field)
+	}
+	return args, nil
+}
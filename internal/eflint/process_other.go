@@ -0,0 +1,14 @@
+//go:build !linux
+
+package eflint
+
+import "errors"
+
+// errProcessInspectionUnsupported is returned by processArgs on platforms where
+// the command line of another process cannot be read.
+var errProcessInspectionUnsupported = errors.New("process inspection is not supported on this platform")
+
+// processArgs is unsupported without /proc; orphans are then left running.
+func processArgs(int) ([]string, error) {
+	return nil, errProcessInspectionUnsupported
+}
@@ -0,0 +1,127 @@
+package eflint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// -----------------------------------------------------------------------------
+// Readiness Gate
+// -----------------------------------------------------------------------------
+//
+// While a (re)started instance loads its model, commands fail and a readiness probe
+// that only pings would flap. With ManagerConfig.ReadyTimeout set, every start,
+// restart and model update closes a gate that Ready reports as not ready until the
+// new instance answers a Ping. If it never does within ReadyTimeout, Ready reports
+// the failure until a later Ping succeeds.
+
+// readyPollInterval is how often a closed readiness gate pings the new instance.
+const readyPollInterval = 200 * time.Millisecond
+
+// readinessGate tracks whether the latest (re)start has become ready.
+type readinessGate struct {
+	mu         sync.Mutex
+	generation uint64 // Incremented on every (re)start, so stale waits are ignored
+	pending    bool   // Waiting for the instance to answer a Ping
+	err        error  // Why the instance did not become ready; nil if it did
+}
+
+// close marks a new (re)start as pending and returns its generation.
+func (g *readinessGate) close() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.generation++
+	g.pending = true
+	g.err = nil
+	return g.generation
+}
+
+// open ends the wait of generation with its outcome (nil = ready). It does nothing
+// if a later (re)start closed the gate again.
+func (g *readinessGate) open(generation uint64, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.generation != generation {
+		return
+	}
+	g.pending = false
+	g.err = err
+}
+
+// reset opens the gate without an outcome, e.g. once the instance is stopped.
+func (g *readinessGate) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.generation++
+	g.pending = false
+	g.err = nil
+}
+
+// state returns the generation, whether a wait is pending and its outcome.
+func (g *readinessGate) state() (uint64, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generation, g.pending, g.err
+}
+
+// gateReadiness closes the readiness gate after a (re)start and waits, in the
+// background, for the new instance to answer. It only starts a goroutine, so it is
+// safe to call while holding mu.
+func (m *Manager) gateReadiness() {
+	if m.config.ReadyTimeout <= 0 {
+		return
+	}
+	generation := m.readiness.close()
+	go m.awaitReady(generation, m.config.ReadyTimeout)
+}
+
+// awaitReady pings the instance until it answers or timeout elapses, and opens the
+// readiness gate of generation with the outcome.
+func (m *Manager) awaitReady(generation uint64, timeout time.Duration) {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	for {
+		err := m.Ping(context.Background())
+		if err == nil {
+			m.readiness.open(generation, nil)
+			m.logger.Info("eFLINT server ready", zap.Duration("after", time.Since(start)))
+			return
+		}
+		if current, _, _ := m.readiness.state(); current != generation {
+			return // Superseded by a later (re)start or a stop
+		}
+		if time.Now().After(deadline) {
+			err = fmt.Errorf("%w: no answer within %s: %w", ErrNotReady, timeout, err)
+			m.readiness.open(generation, err)
+			m.logger.Error("eFLINT server did not become ready", zap.Error(err))
+			return
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// Ready reports whether the instance can serve queries, for readiness probes. After
+// a start, restart or model update it returns ErrNotReady until the new instance
+// has answered a Ping (see ManagerConfig.ReadyTimeout); otherwise it is Ping.
+func (m *Manager) Ready(ctx context.Context) error {
+	generation, pending, gateErr := m.readiness.state()
+	if pending {
+		return fmt.Errorf("%w: waiting for the eFLINT server to load its model", ErrNotReady)
+	}
+
+	err := m.Ping(ctx)
+	if gateErr == nil {
+		return err
+	}
+	if err != nil {
+		return errors.Join(gateErr, err)
+	}
+	// The instance became ready after the gate gave up on it
+	m.readiness.open(generation, nil)
+	return nil
+}
@@ -0,0 +1,50 @@
+package eflint
+
+import "fmt"
+
+// -----------------------------------------------------------------------------
+// Read-Only Mode
+// -----------------------------------------------------------------------------
+//
+// A manager in read-only mode refuses the commands that would change the policy
+// state, so that a replica can serve queries while a primary owns the writes.
+// Commands are refused in SendCommandContext, which every higher-level API (raw
+// commands, phrases, fact imports, state restores, revocations) goes through.
+// Starting, restarting and stopping the instance remain possible.
+
+// DefaultReadOnlyCommands are the commands refused in read-only mode when
+// ManagerConfig.ReadOnlyCommands is not set: the commands that can change the state.
+var DefaultReadOnlyCommands = []string{"phrase", "create", "terminate", "load-export", "revert", "kill"}
+
+// newReadOnlyGuard returns the set of commands refused by a manager with config,
+// or nil if it is not read-only.
+func newReadOnlyGuard(config *ManagerConfig) map[string]bool {
+	if !config.ReadOnly {
+		return nil
+	}
+	commands := config.ReadOnlyCommands
+	if commands == nil {
+		commands = DefaultReadOnlyCommands
+	}
+	refused := make(map[string]bool, len(commands))
+	for _, name := range commands {
+		refused[name] = true
+	}
+	return refused
+}
+
+// ReadOnly reports whether the manager refuses commands that change the state.
+func (m *Manager) ReadOnly() bool {
+	return m.readOnly != nil
+}
+
+// checkReadOnly returns ErrReadOnly if the manager refuses a command.
+func (m *Manager) checkReadOnly(command string) error {
+	if m.readOnly == nil {
+		return nil
+	}
+	if name := commandName(command); m.readOnly[name] {
+		return fmt.Errorf("%w: %q commands are refused", ErrReadOnly, name)
+	}
+	return nil
+}
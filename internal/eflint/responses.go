@@ -0,0 +1,225 @@
+package eflint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// -----------------------------------------------------------------------------
+// Typed Responses
+// -----------------------------------------------------------------------------
+//
+// The eFLINT server answers every command with a JSON object whose shape depends
+// on the command. These types describe the responses of the commands this package
+// understands, so clients do not need to parse the raw output themselves.
+
+// Fact is a fact instance as reported by the eFLINT "facts" command.
+type Fact = api.Fact
+
+// FactArgument is a single argument of a Fact.
+type FactArgument = api.FactArgument
+
+// Diagnostic is an error or violation reported by the eFLINT server.
+type Diagnostic = api.Diagnostic
+
+// Duty is a duty created by an act (see ParseNewDuties).
+type Duty = api.Duty
+
+// ErrorCategory classifies an error reported by the eFLINT server (see ClassifyError).
+type ErrorCategory = api.ErrorCategory
+
+// ClassifyError returns the category of an eFLINT error from its "type" field.
+// Parse and syntax errors are ErrorCategoryParse, type and compilation errors are
+// ErrorCategoryType, and any other error is ErrorCategoryRuntime.
+func ClassifyError(errorType string) ErrorCategory {
+	t := strings.ToLower(errorType)
+	switch {
+	case strings.Contains(t, "parse"), strings.Contains(t, "syntax"):
+		return api.ErrorCategoryParse
+	case strings.Contains(t, "type"), strings.Contains(t, "compil"):
+		return api.ErrorCategoryType
+	}
+	return api.ErrorCategoryRuntime
+}
+
+// ClassifyErrors returns the most client-attributable category among errs: parse
+// before type before runtime. It returns "" if errs is empty.
+func ClassifyErrors(errs []Diagnostic) ErrorCategory {
+	var category ErrorCategory
+	for _, d := range errs {
+		switch c := ClassifyError(d.Type); {
+		case c == api.ErrorCategoryParse:
+			return c
+		case c == api.ErrorCategoryType, category == "":
+			category = c
+		}
+	}
+	return category
+}
+
+// FactsResult is the typed response of the "facts" command.
+type FactsResult struct {
+	Facts []Fact `json:"facts"` // All facts that currently hold
+}
+
+// EnabledResult is the typed response of the "enabled" command.
+type EnabledResult struct {
+	Enabled    bool         `json:"enabled"`              // Whether the queried act or event is enabled
+	Violations []Diagnostic `json:"violations,omitempty"` // Violations the act or event would cause
+	Errors     []Diagnostic `json:"errors,omitempty"`     // Errors reported while evaluating the query
+}
+
+// StatusResult is the typed response of the "status" command.
+type StatusResult struct {
+	Success    bool         `json:"success"`              // Whether the server reported success
+	Violations []Diagnostic `json:"violations,omitempty"` // Violations in the current state
+	Errors     []Diagnostic `json:"errors,omitempty"`     // Errors reported by the server
+}
+
+// PhraseResult is the outcome of a phrase that changed the state, such as an
+// executed act.
+type PhraseResult struct {
+	NewFacts     []Fact       `json:"new-facts"`     // Facts created by the phrase
+	RemovedFacts []Fact       `json:"removed-facts"` // Facts terminated by the phrase
+	Violations   []Diagnostic `json:"violations"`    // Violations caused, e.g. performing a disabled act
+}
+
+// ParsePhraseResult extracts the state changes and violations from the response to
+// a phrase. Responses that cannot be decoded return an error wrapping ErrInvalidResponse.
+func ParsePhraseResult(response string) (*PhraseResult, error) {
+	var result PhraseResult
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("%w: phrase response: %v", ErrInvalidResponse, err)
+	}
+	return &result, nil
+}
+
+// Transition is an act or event listed in the "all-enabled-transitions" of a
+// response, with its arguments in order.
+type Transition struct {
+	Type      string         // The act or event (e.g., "register-requester")
+	Arguments []FactArgument // Positional arguments; values of unknown kinds are kept as JSON
+}
+
+// valueInstance is an instance of a composite type in a response, such as an
+// enabled transition or a duty.
+type valueInstance struct {
+	FactType string            `json:"fact-type"`
+	Value    []json.RawMessage `json:"value"`
+}
+
+// arguments returns the positional arguments of the instance.
+func (v valueInstance) arguments() []FactArgument {
+	args := make([]FactArgument, 0, len(v.Value))
+	for _, raw := range v.Value {
+		var arg FactArgument
+		if err := json.Unmarshal(raw, &arg); err != nil {
+			// Not a string, integer or composite: keep the value as raw JSON
+			var unknown struct {
+				FactType string          `json:"fact-type"`
+				Value    json.RawMessage `json:"value"`
+			}
+			_ = json.Unmarshal(raw, &unknown)
+			arg = FactArgument{FactType: unknown.FactType, Value: string(unknown.Value)}
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// ParseEnabledTransitions extracts the acts and events that are enabled in the
+// current state from the response to a command such as "status". It reports false
+// if the response does not list enabled transitions, which older server builds
+// omit. Responses that cannot be decoded return an error wrapping ErrInvalidResponse.
+func ParseEnabledTransitions(response string) ([]Transition, bool, error) {
+	var resp struct {
+		Transitions *[]valueInstance `json:"all-enabled-transitions"`
+	}
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return nil, false, fmt.Errorf("%w: enabled transitions: %v", ErrInvalidResponse, err)
+	}
+	if resp.Transitions == nil {
+		return nil, false, nil
+	}
+
+	transitions := make([]Transition, 0, len(*resp.Transitions))
+	for _, t := range *resp.Transitions {
+		transitions = append(transitions, Transition{Type: t.FactType, Arguments: t.arguments()})
+	}
+	return transitions, true, nil
+}
+
+// ParseNewDuties extracts the duties listed in the "new-duties" member of a
+// response, i.e. the duties the queried or performed act creates. By eFLINT
+// convention the first two arguments of a duty are its holder and claimant.
+// Responses without "new-duties" yield no duties. Responses that cannot be decoded
+// return an error wrapping ErrInvalidResponse.
+func ParseNewDuties(response string) ([]Duty, error) {
+	var resp struct {
+		Duties []valueInstance `json:"new-duties"`
+	}
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return nil, fmt.Errorf("%w: new duties: %v", ErrInvalidResponse, err)
+	}
+
+	var duties []Duty
+	for _, d := range resp.Duties {
+		duty := Duty{Type: d.FactType, Arguments: d.arguments()}
+		if len(duty.Arguments) > 0 {
+			duty.Holder = duty.Arguments[0].Value
+		}
+		if len(duty.Arguments) > 1 {
+			duty.Claimant = duty.Arguments[1].Value
+		}
+		duties = append(duties, duty)
+	}
+	return duties, nil
+}
+
+// ParseTypedResponse converts the raw response to a JSON command into the typed
+// structure for that command. It returns nil and no error for commands that have
+// no typed representation; callers should then use the raw response. Responses
+// that cannot be decoded return an error wrapping ErrInvalidResponse.
+func ParseTypedResponse(command, response string) (interface{}, error) {
+	var resp struct {
+		Response     string       `json:"response"`
+		Values       *[]Fact      `json:"values"`
+		QueryResults []string     `json:"query-results"`
+		Violations   []Diagnostic `json:"violations"`
+		Errors       []Diagnostic `json:"errors"`
+	}
+
+	name := commandName(command)
+	switch name {
+	case "facts", "enabled", "status":
+	default:
+		return nil, nil
+	}
+
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return nil, fmt.Errorf("%w: %s response: %v", ErrInvalidResponse, name, err)
+	}
+
+	switch name {
+	case "facts":
+		if resp.Values == nil {
+			return nil, fmt.Errorf("%w: facts response has no \"values\"", ErrInvalidResponse)
+		}
+		return &FactsResult{Facts: *resp.Values}, nil
+	case "enabled":
+		return &EnabledResult{
+			Enabled:    len(resp.QueryResults) > 0 && strings.EqualFold(resp.QueryResults[0], "success"),
+			Violations: resp.Violations,
+			Errors:     resp.Errors,
+		}, nil
+	default:
+		return &StatusResult{
+			Success:    resp.Response == "success",
+			Violations: resp.Violations,
+			Errors:     resp.Errors,
+		}, nil
+	}
+}
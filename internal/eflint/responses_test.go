@@ -0,0 +1,106 @@
+package eflint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+func TestParsePhraseResult(t *testing.T) {
+	result, err := ParsePhraseResult(`{
+		"response": "success",
+		"new-facts": [{"fact-type": "duty-to-report", "arguments": [{"fact-type": "requester", "value": "user@example.com"}]}],
+		"removed-facts": [{"fact-type": "pending", "arguments": [{"fact-type": "count", "value": 3}]}],
+		"violations": [{"type": "act", "message": "not enabled"}]
+	}`)
+	if err != nil {
+		t.Fatalf("ParsePhraseResult() error = %v", err)
+	}
+	if len(result.NewFacts) != 1 || result.NewFacts[0].FactType != "duty-to-report" {
+		t.Errorf("NewFacts = %+v", result.NewFacts)
+	}
+	if len(result.RemovedFacts) != 1 || result.RemovedFacts[0].Arguments[0].Value != "3" {
+		t.Errorf("RemovedFacts = %+v", result.RemovedFacts)
+	}
+	if len(result.Violations) != 1 || result.Violations[0].Message != "not enabled" {
+		t.Errorf("Violations = %+v", result.Violations)
+	}
+}
+
+func TestParsePhraseResultInvalid(t *testing.T) {
+	for _, response := range []string{"", "not json", `{"new-facts": {}}`} {
+		if _, err := ParsePhraseResult(response); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("ParsePhraseResult(%q) error = %v, want ErrInvalidResponse", response, err)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	for errorType, want := range map[string]ErrorCategory{
+		"parse error":       api.ErrorCategoryParse,
+		"SyntaxError":       api.ErrorCategoryParse,
+		"type error":        api.ErrorCategoryType,
+		"compilation error": api.ErrorCategoryType,
+		"runtime error":     api.ErrorCategoryRuntime,
+		"":                  api.ErrorCategoryRuntime,
+	} {
+		if got := ClassifyError(errorType); got != want {
+			t.Errorf("ClassifyError(%q) = %q, want %q", errorType, got, want)
+		}
+	}
+}
+
+func TestClassifyErrors(t *testing.T) {
+	tests := []struct {
+		types []string
+		want  ErrorCategory
+	}{
+		{nil, ""},
+		{[]string{"runtime error"}, api.ErrorCategoryRuntime},
+		{[]string{"runtime error", "type error"}, api.ErrorCategoryType},
+		{[]string{"type error", "runtime error"}, api.ErrorCategoryType},
+		{[]string{"runtime error", "type error", "parse error"}, api.ErrorCategoryParse},
+	}
+	for _, tt := range tests {
+		var errs []Diagnostic
+		for _, errorType := range tt.types {
+			errs = append(errs, Diagnostic{Type: errorType, Message: "failed"})
+		}
+		if got := ClassifyErrors(errs); got != tt.want {
+			t.Errorf("ClassifyErrors(%v) = %q, want %q", tt.types, got, tt.want)
+		}
+	}
+}
+
+func TestParseNewDuties(t *testing.T) {
+	duties, err := ParseNewDuties(`{
+		"response": "success",
+		"query-results": ["success"],
+		"new-duties": [{"fact-type": "report-results", "value": [
+			{"fact-type": "requester", "value": "user@example.com"},
+			{"fact-type": "organization", "value": "VU"},
+			{"fact-type": "deadline", "value": 30}
+		]}]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseNewDuties() error = %v", err)
+	}
+	if len(duties) != 1 {
+		t.Fatalf("ParseNewDuties() = %+v, want one duty", duties)
+	}
+	duty := duties[0]
+	if duty.Type != "report-results" || duty.Holder != "user@example.com" || duty.Claimant != "VU" {
+		t.Errorf("duty = %+v, want report-results owed by user@example.com to VU", duty)
+	}
+	if len(duty.Arguments) != 3 || duty.Arguments[2].FactType != "deadline" || duty.Arguments[2].Value != "30" {
+		t.Errorf("Arguments = %+v", duty.Arguments)
+	}
+
+	if duties, err := ParseNewDuties(`{"response": "success", "query-results": ["success"]}`); err != nil || duties != nil {
+		t.Errorf("ParseNewDuties() without new-duties = %+v, %v, want none", duties, err)
+	}
+	if _, err := ParseNewDuties(`{"new-duties": {}}`); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("ParseNewDuties() error = %v, want ErrInvalidResponse", err)
+	}
+}
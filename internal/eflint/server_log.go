@@ -0,0 +1,197 @@
+package eflint
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Server Output
+// -----------------------------------------------------------------------------
+//
+// The Manager captures the stdout and stderr of the eflint-server process line by
+// line. The most recent lines are kept in a ring buffer that outlives individual
+// instances, so output from before a restart is still available, and new lines
+// are fanned out to subscribers such as GET /eflint/logs/stream.
+
+// DefaultServerLogSize is the number of recent server output lines kept by default.
+const DefaultServerLogSize = 500
+
+// serverLogLineLimit is the maximum number of bytes kept per line; longer lines
+// are split.
+const serverLogLineLimit = 4096
+
+// serverLogSubscriberBuffer is the number of lines buffered per subscriber. Lines
+// are dropped for subscribers that fall further behind.
+const serverLogSubscriberBuffer = 256
+
+// Streams of a ServerLogLine.
+const (
+	StreamStdout = "stdout" // Standard output of the eflint-server process
+	StreamStderr = "stderr" // Standard error of the eflint-server process
+	StreamEvent  = "event"  // Lifecycle events recorded by the Manager (start, stop)
+)
+
+// ServerLogLine is a single line of eflint-server output.
+type ServerLogLine struct {
+	Seq       uint64    `json:"seq"`       // Sequence number, increasing across restarts
+	Timestamp time.Time `json:"timestamp"` // When the line was captured
+	Stream    string    `json:"stream"`    // StreamStdout, StreamStderr or StreamEvent
+	Port      int       `json:"port"`      // Port of the instance that produced the line
+	Line      string    `json:"line"`      // The line, without trailing newline
+}
+
+// serverLog is a bounded ring buffer of server output with live subscribers.
+// Thread-safe for concurrent access.
+type serverLog struct {
+	lines       []ServerLogLine
+	next        int  // Index of the slot to write next
+	full        bool // Whether the buffer has wrapped around
+	seq         uint64
+	subscribers map[chan ServerLogLine]struct{}
+	mu          sync.Mutex
+}
+
+// newServerLog creates a server log of the given size, or nil if size <= 0.
+func newServerLog(size int) *serverLog {
+	if size <= 0 {
+		return nil
+	}
+	return &serverLog{
+		lines:       make([]ServerLogLine, size),
+		subscribers: make(map[chan ServerLogLine]struct{}),
+	}
+}
+
+// append records a line and delivers it to all subscribers.
+func (l *serverLog) append(stream string, port int, line string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	entry := ServerLogLine{
+		Seq:       l.seq,
+		Timestamp: time.Now(),
+		Stream:    stream,
+		Port:      port,
+		Line:      line,
+	}
+
+	l.lines[l.next] = entry
+	l.next = (l.next + 1) % len(l.lines)
+	if l.next == 0 {
+		l.full = true
+	}
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- entry:
+		default:
+			// Slow subscriber; it misses this line rather than blocking the process
+		}
+	}
+}
+
+// snapshotLocked returns the buffered lines, oldest first. Caller must hold mu.
+func (l *serverLog) snapshotLocked() []ServerLogLine {
+	if !l.full {
+		return append([]ServerLogLine(nil), l.lines[:l.next]...)
+	}
+	result := make([]ServerLogLine, 0, len(l.lines))
+	result = append(result, l.lines[l.next:]...)
+	return append(result, l.lines[:l.next]...)
+}
+
+// subscribe returns the buffered lines and a channel receiving every line appended
+// afterwards. unsubscribe must be called when the subscriber is done.
+func (l *serverLog) subscribe() (backlog []ServerLogLine, lines <-chan ServerLogLine, unsubscribe func()) {
+	ch := make(chan ServerLogLine, serverLogSubscriberBuffer)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.subscribers[ch] = struct{}{}
+	var once sync.Once
+	return l.snapshotLocked(), ch, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			delete(l.subscribers, ch)
+		})
+	}
+}
+
+// writer returns an io.Writer that splits process output into lines of stream.
+func (l *serverLog) writer(stream string, port int) *serverLogWriter {
+	return &serverLogWriter{log: l, stream: stream, port: port}
+}
+
+// serverLogWriter splits written bytes into lines and appends them to a serverLog.
+// exec copies each process stream from a single goroutine, so it needs no locking.
+type serverLogWriter struct {
+	log    *serverLog
+	stream string
+	port   int
+	buf    []byte // Incomplete line carried over between writes
+}
+
+// Write appends each complete line in p; a trailing partial line is kept until
+// its newline arrives.
+func (w *serverLogWriter) Write(p []byte) (int, error) {
+	data := append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(data[:i])
+		data = data[i+1:]
+	}
+	for len(data) > serverLogLineLimit {
+		w.emit(data[:serverLogLineLimit])
+		data = data[serverLogLineLimit:]
+	}
+	w.buf = append(w.buf[:0], data...)
+	return len(p), nil
+}
+
+// emit appends a single line, dropping a trailing carriage return.
+func (w *serverLogWriter) emit(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	w.log.append(w.stream, w.port, string(line))
+}
+
+// ServerLogs returns the buffered server output, oldest first. The result is
+// empty if output capture is disabled.
+func (m *Manager) ServerLogs() []ServerLogLine {
+	if m.serverLog == nil {
+		return []ServerLogLine{}
+	}
+
+	m.serverLog.mu.Lock()
+	defer m.serverLog.mu.Unlock()
+	return m.serverLog.snapshotLocked()
+}
+
+// SubscribeServerLogs returns the buffered server output and a channel that
+// receives every line captured afterwards, including output of instances started
+// later. Callers must call unsubscribe when done. It returns ErrServerLogDisabled
+// if output capture is disabled.
+func (m *Manager) SubscribeServerLogs() (backlog []ServerLogLine, lines <-chan ServerLogLine, unsubscribe func(), err error) {
+	if m.serverLog == nil {
+		return nil, nil, nil, ErrServerLogDisabled
+	}
+	backlog, lines, unsubscribe = m.serverLog.subscribe()
+	return backlog, lines, unsubscribe, nil
+}
+
+// logServerEvent records a lifecycle event in the server output, if captured.
+func (m *Manager) logServerEvent(port int, format string, args ...interface{}) {
+	m.serverLog.append(StreamEvent, port, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package eflint
+
+import (
+	"errors"
+	"os"
+)
+
+// terminate is unsupported without Unix signals; callers fall back to Kill.
+func terminate(*os.Process) error {
+	return errors.New("graceful termination is not supported on this platform")
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package eflint
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminate asks the process to exit gracefully with SIGTERM.
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
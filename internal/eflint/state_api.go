@@ -2,11 +2,17 @@ package eflint
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
 )
 
 // -----------------------------------------------------------------------------
@@ -29,15 +35,23 @@ func NewStateAPIHandler(stateManager *StateManager, logger *zap.Logger) *StateAP
 	}
 }
 
+// log returns the logger for a request, tagged with its request ID.
+func (h *StateAPIHandler) log(c echo.Context) *zap.Logger {
+	return requestid.Logger(c.Request().Context(), h.logger)
+}
+
 // RegisterRoutes registers all state management API routes on the given Echo group.
 // Routes are registered under the group prefix (e.g., /eflint/state).
 func (h *StateAPIHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("", h.GetState)            // GET /eflint/state - get current state
+	g.GET("/clean", h.GetCleanState) // GET /eflint/state/clean - current state in importable form
 	g.POST("/export", h.ExportState) // POST /eflint/state/export - export for persistence
 	g.POST("/import", h.ImportState) // POST /eflint/state/import - import saved state
 	g.POST("/checkpoint", h.CreateCheckpoint)
 	g.POST("/checkpoint/restore", h.RestoreCheckpoint)
 	g.GET("/checkpoints", h.ListCheckpoints)
+	g.GET("/checkpoints/export", h.ExportArchive)  // All saved states as one .tar.gz
+	g.POST("/checkpoints/import", h.ImportArchive) // Restore states from such an archive
 	g.DELETE("/checkpoint/:name", h.DeleteCheckpoint)
 }
 
@@ -59,7 +73,16 @@ type ImportStateRequest struct {
 
 // CheckpointRequest represents a request for checkpoint operations.
 type CheckpointRequest struct {
-	Name string `json:"name" validate:"required"` // Name of the checkpoint
+	Name     string `json:"name" validate:"required"` // Name of the checkpoint
+	Strategy string `json:"strategy,omitempty"`       // Restore only: RestoreAuto (default), RestoreReplay or RestoreLoadExport
+}
+
+// CheckpointCreatedResponse represents the response for a created checkpoint.
+type CheckpointCreatedResponse struct {
+	Success    bool      `json:"success"`    // Always true
+	Checkpoint string    `json:"checkpoint"` // Name of the checkpoint
+	StateID    string    `json:"state_id"`   // ID of the saved state
+	SavedAt    time.Time `json:"saved_at"`   // Timestamp when the state was saved
 }
 
 // CheckpointListResponse represents the list of available checkpoints.
@@ -88,11 +111,7 @@ type StateResponse struct {
 func (h *StateAPIHandler) GetState(c echo.Context) error {
 	response, err := h.stateManager.GetState()
 	if err != nil {
-		if err == ErrInstanceNotRunning {
-			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
-		}
-		h.logger.Error("failed to get state", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	// Parse the response as JSON
@@ -108,16 +127,26 @@ func (h *StateAPIHandler) GetState(c echo.Context) error {
 	})
 }
 
+// GetCleanState retrieves the current execution graph with the known export/import
+// asymmetries fixed, so it can be passed to load-export unchanged.
+// GET /eflint/state/clean
+func (h *StateAPIHandler) GetCleanState(c echo.Context) error {
+	state, err := h.stateManager.GetCleanState()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, StateResponse{
+		State: state,
+	})
+}
+
 // ExportState exports the current eFLINT state for persistence.
 // POST /eflint/state/export
 func (h *StateAPIHandler) ExportState(c echo.Context) error {
 	state, err := h.stateManager.ExportState()
 	if err != nil {
-		if err == ErrInstanceNotRunning {
-			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
-		}
-		h.logger.Error("failed to export state", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, ExportStateResponse{
@@ -131,19 +160,15 @@ func (h *StateAPIHandler) ExportState(c echo.Context) error {
 func (h *StateAPIHandler) ImportState(c echo.Context) error {
 	var req ImportStateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return apperr.BadRequest("invalid request body")
 	}
 
 	if req.State == nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "state is required"})
+		return apperr.BadRequest("state is required")
 	}
 
 	if err := h.stateManager.ImportState(req.State); err != nil {
-		if err == ErrInstanceNotRunning {
-			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
-		}
-		h.logger.Error("failed to import state", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -157,69 +182,104 @@ func (h *StateAPIHandler) ImportState(c echo.Context) error {
 func (h *StateAPIHandler) CreateCheckpoint(c echo.Context) error {
 	var req CheckpointRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return apperr.BadRequest("invalid request body")
 	}
 
 	if req.Name == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required"})
+		return apperr.BadRequest("name is required")
 	}
 
 	state, err := h.stateManager.CreateCheckpoint(req.Name)
 	if err != nil {
-		if err == ErrInstanceNotRunning {
-			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
-		}
-		h.logger.Error("failed to create checkpoint", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success":    true,
-		"checkpoint": req.Name,
-		"state_id":   state.ID,
-		"saved_at":   state.SavedAt,
+	return c.JSON(http.StatusOK, CheckpointCreatedResponse{
+		Success:    true,
+		Checkpoint: req.Name,
+		StateID:    state.ID,
+		SavedAt:    state.SavedAt,
 	})
 }
 
 // RestoreCheckpoint restores a previously created checkpoint
 // POST /eflint/state/checkpoint/restore
-// NOTE: Due to a bug in the eFLINT server, full state restoration may not work.
-// In that case, the instance will be restarted to the initial model state.
+//
+// By default, checkpoints that recorded their facts are restored by restarting the
+// instance and replaying the facts, and older checkpoints with load-export.
+// NOTE: Due to a bug in the eFLINT server, load-export may not work. In that case,
+// the instance will be restarted to the initial model state.
 func (h *StateAPIHandler) RestoreCheckpoint(c echo.Context) error {
 	var req CheckpointRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return apperr.BadRequest("invalid request body")
 	}
 
 	if req.Name == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required"})
+		return apperr.BadRequest("name is required")
+	}
+
+	strategy := req.Strategy
+	switch strategy {
+	case "", RestoreAuto, RestoreReplay, RestoreLoadExport:
+	default:
+		return apperr.BadRequest("strategy must be %q, %q or %q", RestoreAuto, RestoreReplay, RestoreLoadExport)
 	}
 
-	if err := h.stateManager.RestoreCheckpoint(req.Name); err != nil {
-		if err == ErrInstanceNotRunning {
-			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "instance is not running"})
+	state, err := h.stateManager.LoadCheckpoint(req.Name)
+	if err != nil {
+		if errors.Is(err, ErrStateNotFound) {
+			return apperr.Wrap(err, http.StatusNotFound, CodeStateNotFound, "checkpoint not found")
 		}
+		return err
+	}
 
+	if strategy == "" || strategy == RestoreAuto {
+		strategy = RestoreLoadExport
+		if state.Facts != nil {
+			strategy = RestoreReplay
+		}
+	}
+
+	if strategy == RestoreReplay {
+		result, err := h.stateManager.RestoreByReplay(state)
+		if err != nil {
+			return err
+		}
+		if len(result.Failed) > 0 {
+			h.log(c).Warn("some facts could not be replayed", zap.Int("failed", len(result.Failed)))
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success":  len(result.Failed) == 0,
+			"message":  "checkpoint restored by replaying facts",
+			"restored": req.Name,
+			"strategy": RestoreReplay,
+			"replay":   result,
+		})
+	}
+
+	if err := h.stateManager.ImportState(state); err != nil {
 		// Check if the error indicates the instance was restarted
 		errStr := err.Error()
 		if strings.Contains(errStr, "restarted to initial state") {
-			h.logger.Warn("checkpoint restore failed, instance restarted to initial state", zap.Error(err))
+			h.log(c).Warn("checkpoint restore failed, instance restarted to initial state", zap.Error(err))
 			return c.JSON(http.StatusOK, map[string]interface{}{
 				"success":  false,
 				"warning":  "eFLINT server does not support load-export; instance was restarted to initial model state instead",
 				"restored": "initial",
+				"strategy": RestoreLoadExport,
 				"note":     "This is a limitation of the eFLINT server's load-export functionality",
 			})
 		}
 
-		h.logger.Error("failed to restore checkpoint", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success":  true,
 		"message":  "checkpoint restored successfully",
 		"restored": req.Name,
+		"strategy": RestoreLoadExport,
 	})
 }
 
@@ -228,8 +288,7 @@ func (h *StateAPIHandler) RestoreCheckpoint(c echo.Context) error {
 func (h *StateAPIHandler) ListCheckpoints(c echo.Context) error {
 	states, err := h.stateManager.ListSavedStates()
 	if err != nil {
-		h.logger.Error("failed to list checkpoints", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	// Filter only checkpoints
@@ -245,17 +304,56 @@ func (h *StateAPIHandler) ListCheckpoints(c echo.Context) error {
 	})
 }
 
+// ExportArchive streams all saved states and checkpoints as a gzip-compressed tar
+// archive with one <name>.json entry per state.
+// GET /eflint/state/checkpoints/export
+func (h *StateAPIHandler) ExportArchive(c echo.Context) error {
+	filename := fmt.Sprintf("eflint-states-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/gzip")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	count, err := h.stateManager.ExportArchive(res)
+	if err != nil {
+		if !res.Committed {
+			return err
+		}
+		// The status is already sent; the client gets a truncated archive
+		h.log(c).Error("state archive export failed", zap.Int("states_written", count), zap.Error(err))
+		return nil
+	}
+	return nil
+}
+
+// ImportArchive stores the saved states of a tar archive (optionally gzip-compressed),
+// as produced by ExportArchive. States with the same name are replaced.
+// POST /eflint/state/checkpoints/import
+func (h *StateAPIHandler) ImportArchive(c echo.Context) error {
+	if c.Request().Body == nil {
+		return apperr.BadRequest("archive is required")
+	}
+
+	result, err := h.stateManager.ImportArchive(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
 // DeleteCheckpoint deletes a checkpoint
 // DELETE /eflint/state/checkpoint/:name
 func (h *StateAPIHandler) DeleteCheckpoint(c echo.Context) error {
 	name := c.Param("name")
 	if name == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required"})
+		return apperr.BadRequest("name is required")
 	}
 
 	if err := h.stateManager.DeleteSavedState("checkpoint-" + name); err != nil {
-		h.logger.Error("failed to delete checkpoint", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		if errors.Is(err, ErrStateNotFound) {
+			return apperr.Wrap(err, http.StatusNotFound, CodeStateNotFound, "checkpoint not found")
+		}
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
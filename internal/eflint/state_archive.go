@@ -0,0 +1,177 @@
+package eflint
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// -----------------------------------------------------------------------------
+// State Archives
+// -----------------------------------------------------------------------------
+//
+// All saved states and checkpoints can be exported as a single gzip-compressed tar
+// archive with one <name>.json entry per state, and restored from one, e.g. to back
+// up a deployment. States are streamed one at a time in both directions, so the
+// archive is never held in memory as a whole.
+
+// maxArchiveEntrySize bounds the size of a single state in an imported archive.
+// A state holds one export of the server, so the response size limit applies.
+const maxArchiveEntrySize = DefaultMaxResponseSize
+
+// ArchiveImportResult reports the outcome of importing a state archive.
+type ArchiveImportResult struct {
+	Imported []string         `json:"imported"` // Names of the states stored
+	Failed   []ArchiveFailure `json:"failed"`   // Entries that were skipped
+}
+
+// ArchiveFailure describes an archive entry that could not be imported.
+type ArchiveFailure struct {
+	Entry string `json:"entry"` // Path of the entry in the archive
+	Error string `json:"error"` // Why it was skipped
+}
+
+// ExportArchive writes all saved states to w as a gzip-compressed tar archive and
+// returns the number of states written. Each state is loaded from the store just
+// before it is written. Once writing has started, an error leaves w with a
+// truncated archive.
+func (sm *StateManager) ExportArchive(w io.Writer) (int, error) {
+	if sm.store == nil {
+		return 0, stateError(errNoStateStore)
+	}
+
+	names, err := sm.store.List()
+	if err != nil {
+		return 0, stateError(err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	written := 0
+	for _, name := range names {
+		data, err := sm.store.Load(name)
+		if errors.Is(err, ErrStateNotFound) {
+			continue // Deleted since it was listed
+		}
+		if err != nil {
+			return written, fmt.Errorf("loading state %q: %w", name, err)
+		}
+
+		header := &tar.Header{
+			Name:    name + stateFileExt,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return written, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return written, err
+		}
+		written++
+	}
+	if err := tw.Close(); err != nil {
+		return written, err
+	}
+	if err := gz.Close(); err != nil {
+		return written, err
+	}
+
+	sm.logger.Info("exported state archive", zap.Int("states", written))
+	return written, nil
+}
+
+// ImportArchive stores the states of a tar archive, gzip-compressed or not, as
+// written by ExportArchive. Existing states with the same name are replaced.
+// Entries that are not *.json files, have an invalid name or are not a JSON saved
+// state are skipped and reported in Failed; the running instance is not touched.
+// An unreadable archive returns an error wrapping ErrInvalidState.
+func (sm *StateManager) ImportArchive(r io.Reader) (_ *ArchiveImportResult, err error) {
+	defer func() { err = stateError(err) }()
+
+	if sm.store == nil {
+		return nil, errNoStateStore
+	}
+
+	// gzip streams start with the magic bytes 0x1f 0x8b
+	br := bufio.NewReader(r)
+	var archive io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: archive: %v", ErrInvalidState, err)
+		}
+		defer gz.Close()
+		archive = gz
+	}
+
+	result := &ArchiveImportResult{Imported: []string{}, Failed: []ArchiveFailure{}}
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("%w: archive: %v", ErrInvalidState, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, err := sm.importArchiveEntry(header, tr)
+		if err != nil {
+			result.Failed = append(result.Failed, ArchiveFailure{Entry: header.Name, Error: err.Error()})
+			continue
+		}
+		result.Imported = append(result.Imported, name)
+	}
+
+	sm.logger.Info("imported state archive",
+		zap.Int("imported", len(result.Imported)),
+		zap.Int("failed", len(result.Failed)),
+	)
+	return result, nil
+}
+
+// importArchiveEntry stores the state in an archive entry and returns its name.
+func (sm *StateManager) importArchiveEntry(header *tar.Header, r io.Reader) (string, error) {
+	name, ok := strings.CutSuffix(path.Base(header.Name), stateFileExt)
+	if !ok {
+		return "", fmt.Errorf("not a %s file", stateFileExt)
+	}
+	if err := validateStateName(name); err != nil {
+		return "", err
+	}
+	if header.Size > maxArchiveEntrySize {
+		return "", fmt.Errorf("state exceeds %d bytes", maxArchiveEntrySize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveEntrySize))
+	if err != nil {
+		return "", err
+	}
+	// The graph is checked when the state is restored, as for any stored state
+	var state SavedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	unlock := sm.names.lock(name)
+	defer unlock()
+	if err := sm.store.Save(name, data); err != nil {
+		return "", err
+	}
+	return name, nil
+}
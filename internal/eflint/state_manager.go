@@ -1,15 +1,21 @@
 package eflint
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
 )
 
 // -----------------------------------------------------------------------------
@@ -24,35 +30,92 @@ import (
 // full state restoration may not work in all cases.
 type StateManager struct {
 	instanceManager *Manager     // The instance manager to operate on
-	stateDir        string       // Directory for persisting state files
+	store           StateStore   // Storage for saved states and checkpoints (nil = not configured)
+	facts           FactsSource  // Records the facts with every exported state (nil = not recorded)
 	logger          *zap.Logger  // Logger for operations
 	mu              sync.RWMutex // Protects concurrent access
+	names           nameLocks    // Serializes store operations per state name; taken before mu
+}
+
+// nameLocks hands out one mutex per state name, so that concurrent saves, loads
+// and deletes of the same state run one after the other while operations on
+// different states do not wait for each other.
+type nameLocks struct {
+	mu    sync.Mutex
+	locks map[string]*nameLock
+}
+
+// nameLock is the mutex of one name, with the number of callers holding or
+// waiting for it, so that it can be dropped once unused.
+type nameLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks name and returns the function that unlocks it.
+func (l *nameLocks) lock(name string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*nameLock)
+	}
+	nl, ok := l.locks[name]
+	if !ok {
+		nl = &nameLock{}
+		l.locks[name] = nl
+	}
+	nl.refs++
+	l.mu.Unlock()
+
+	nl.Lock()
+	return func() {
+		nl.Unlock()
+		l.mu.Lock()
+		if nl.refs--; nl.refs == 0 {
+			delete(l.locks, name)
+		}
+		l.mu.Unlock()
+	}
 }
 
+// FactsSource lists the facts that currently hold, e.g. the FetchFacts method of
+// a reasoner that knows the server's facts dialect.
+type FactsSource func(ctx context.Context) ([]Fact, error)
+
 // SavedState represents a saved eFLINT execution graph state.
 // It captures the complete state of an eFLINT instance at a point in time.
 type SavedState struct {
-	ID            string          `json:"id"`             // Unique identifier for this saved state
-	ModelLocation string          `json:"model_location"` // Path to the model when state was saved
-	Graph         json.RawMessage `json:"graph"`          // The eFLINT execution graph
-	SavedAt       time.Time       `json:"saved_at"`       // Timestamp when state was saved
+	SchemaVersion int             `json:"schema_version"`       // Format version of this saved state (see StateSchemaVersion)
+	ID            string          `json:"id"`                   // Unique identifier for this saved state
+	ModelLocation string          `json:"model_location"`       // Path to the model when state was saved
+	ModelHash     string          `json:"model_hash,omitempty"` // SHA-256 of the model file when state was saved
+	Graph         json.RawMessage `json:"graph"`                // The eFLINT execution graph
+	Facts         []Fact          `json:"facts"`                // Facts that held when state was saved (nil if not recorded)
+	SavedAt       time.Time       `json:"saved_at"`             // Timestamp when state was saved
 }
 
-// NewStateManager creates a new StateManager with the given instance manager and configuration.
-// The stateDir is created if it doesn't exist.
-func NewStateManager(instanceManager *Manager, stateDir string, logger *zap.Logger) *StateManager {
-	// Create state directory if it doesn't exist
-	if stateDir != "" {
-		os.MkdirAll(stateDir, 0755)
-	}
+// StateSchemaVersion is the SavedState format version written by ExportState.
+// Bump it whenever the export format or the import transform changes incompatibly.
+// States without a version (0) predate versioning and share the format of version 1.
+const StateSchemaVersion = 1
 
+// NewStateManager creates a new StateManager with the given instance manager and state store.
+// If store is nil, export and import still work but saved states and checkpoints cannot be persisted.
+func NewStateManager(instanceManager *Manager, store StateStore, logger *zap.Logger) *StateManager {
 	return &StateManager{
 		instanceManager: instanceManager,
-		stateDir:        stateDir,
+		store:           store,
 		logger:          logger,
 	}
 }
 
+// SetFactsSource makes ExportState record the facts that hold in every exported
+// state, so that checkpoints can later be compared with the current facts.
+func (sm *StateManager) SetFactsSource(source FactsSource) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.facts = source
+}
+
 // -----------------------------------------------------------------------------
 // Export/Import Operations
 // -----------------------------------------------------------------------------
@@ -64,15 +127,46 @@ func (sm *StateManager) GetState() (string, error) {
 	defer sm.mu.RUnlock()
 
 	if !sm.instanceManager.IsRunning() {
-		return "", ErrInstanceNotRunning
+		return "", stateError(ErrInstanceNotRunning)
 	}
 
-	return sm.instanceManager.GetState()
+	state, err := sm.instanceManager.GetState()
+	return state, stateError(err)
+}
+
+// GetCleanState retrieves the current execution graph already run through
+// TransformGraphForImport, so it can be stored and later imported as-is.
+func (sm *StateManager) GetCleanState() (_ json.RawMessage, err error) {
+	defer func() { err = stateError(err) }()
+
+	response, err := sm.GetState()
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid([]byte(response)) {
+		return nil, fmt.Errorf("%w: export response is not valid JSON (preview: %q)",
+			ErrStateExportFailed, response[:min(len(response), 200)])
+	}
+
+	graph, err := TransformGraphForImport(json.RawMessage(response))
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform graph: %w", err)
+	}
+	return graph, nil
 }
 
 // ExportState exports the current state of the eFLINT instance.
 // Returns a SavedState containing the execution graph that can be imported later.
 func (sm *StateManager) ExportState() (*SavedState, error) {
+	return sm.ExportStateContext(context.Background())
+}
+
+// ExportStateContext is like ExportState but sends its commands with ctx, e.g.
+// within Manager.Exclusive.
+func (sm *StateManager) ExportStateContext(ctx context.Context) (_ *SavedState, err error) {
+	defer func() { err = stateError(err) }()
+
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -81,31 +175,59 @@ func (sm *StateManager) ExportState() (*SavedState, error) {
 	}
 
 	// Send create-export command
-	response, err := sm.instanceManager.SendCommand(`{"command": "create-export"}`)
+	response, err := sm.instanceManager.SendCommandContext(ctx, `{"command": "create-export"}`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to export state: %w", err)
 	}
 
+	// An empty response usually means the server crashed or closed the connection
+	// mid-export; never build a SavedState from it.
+	if response == "" {
+		return nil, fmt.Errorf("%w: empty response from eFLINT server", ErrStateExportFailed)
+	}
+
 	sm.logger.Debug("raw export response", zap.String("response_preview", response[:min(len(response), 200)]))
 
 	// The eFLINT server returns: {"current": N, "edges": [...], "nodes": [...]}
 	// The entire response is the graph
 	if !json.Valid([]byte(response)) {
-		return nil, fmt.Errorf("export response is not valid JSON")
+		return nil, fmt.Errorf("%w: export response is not valid JSON (preview: %q)",
+			ErrStateExportFailed, response[:min(len(response), 200)])
 	}
 
 	status := sm.instanceManager.Status()
 
+	modelHash, err := hashModelFile(status.ModelLocation)
+	if err != nil {
+		// The state is still usable; only the model check on import is lost
+		sm.logger.Warn("failed to hash model file", zap.String("model", status.ModelLocation), zap.Error(err))
+	}
+
 	savedState := &SavedState{
+		SchemaVersion: StateSchemaVersion,
 		ID:            fmt.Sprintf("state-%d", time.Now().UnixNano()),
 		ModelLocation: status.ModelLocation,
+		ModelHash:     modelHash,
 		Graph:         json.RawMessage(response),
 		SavedAt:       time.Now(),
 	}
 
+	if sm.facts != nil {
+		facts, err := sm.facts(ctx)
+		if err != nil {
+			// The graph alone can still be restored; only fact comparisons are lost
+			sm.logger.Warn("failed to record facts with exported state", zap.Error(err))
+		} else if facts == nil {
+			savedState.Facts = []Fact{} // Recorded, but none held
+		} else {
+			savedState.Facts = facts
+		}
+	}
+
 	sm.logger.Info("exported eFLINT state",
 		zap.String("id", savedState.ID),
 		zap.String("model", savedState.ModelLocation),
+		zap.Int("facts", len(savedState.Facts)),
 	)
 
 	return savedState, nil
@@ -113,19 +235,40 @@ func (sm *StateManager) ExportState() (*SavedState, error) {
 
 // ImportState imports a previously saved state into the eFLINT instance
 // NOTE: Due to a bug in the eFLINT server, load-export may crash the server.
-// This implementation attempts the load-export, and if it fails, restarts the instance.
+// This implementation attempts the load-export, and if it fails, restarts the instance
+// with the model it was running, so that it is left in its initial state.
 func (sm *StateManager) ImportState(savedState *SavedState) error {
+	return sm.ImportStateContext(context.Background(), savedState)
+}
+
+// ImportStateContext is like ImportState but sends its commands with ctx, e.g.
+// within Manager.Exclusive.
+func (sm *StateManager) ImportStateContext(ctx context.Context, savedState *SavedState) (err error) {
+	defer func() { err = stateError(err) }()
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	// Reject structurally invalid graphs before they reach the fragile load-export path
+	if err := validateSavedState(savedState); err != nil {
+		return err
+	}
+	if err := sm.checkCompatibility(savedState); err != nil {
+		return err
+	}
+
 	// Ensure instance is running
 	if !sm.instanceManager.IsRunning() {
 		return ErrInstanceNotRunning
 	}
+	// Refuse before a failed load-export could restart the instance
+	if sm.instanceManager.ReadOnly() {
+		return fmt.Errorf("%w: states cannot be imported", ErrReadOnly)
+	}
 
 	// Transform the graph to fix the field name mismatch in eFLINT server
 	// The eFLINT server exports edges with "program" but expects "label" when importing
-	transformedGraph, err := transformGraphForImport(savedState.Graph)
+	transformedGraph, err := TransformGraphForImport(savedState.Graph)
 	if err != nil {
 		return fmt.Errorf("failed to transform graph: %w", err)
 	}
@@ -162,14 +305,27 @@ func (sm *StateManager) ImportState(savedState *SavedState) error {
 		zap.String("command_preview", cmdStr[:min(len(cmdStr), 500)]),
 	)
 
+	// Remember the running model, so that a failed import can fall back to it
+	modelLocation := sm.instanceManager.Status().ModelLocation
+
 	// Send load-export command
-	response, err := sm.instanceManager.SendCommand(cmdStr)
+	response, err := sm.instanceManager.SendCommandContext(ctx, cmdStr)
 	if err != nil {
 		// The eFLINT server may have crashed due to a bug in its load-export handling
 		// Try to restart the instance with the same model
 		sm.logger.Warn("load-export failed, attempting to restart instance",
 			zap.Error(err),
-			zap.String("model", savedState.ModelLocation),
+			zap.String("model", modelLocation),
+		)
+		if restartErr := sm.instanceManager.restartWithModel(modelLocation); restartErr != nil {
+			sm.logger.Error("failed to restart instance after load-export failure",
+				zap.Error(restartErr),
+				zap.String("model", modelLocation),
+			)
+			return fmt.Errorf("load-export failed and instance restart failed (%v): %w", restartErr, err)
+		}
+		sm.logger.Info("restarted eFLINT instance after load-export failure",
+			zap.String("model", modelLocation),
 		)
 		return fmt.Errorf("load-export failed and instance was restarted to initial state: %w", err)
 	}
@@ -190,12 +346,228 @@ func (sm *StateManager) ImportState(savedState *SavedState) error {
 	return nil
 }
 
-// transformGraphForImport transforms the exported graph to be compatible with load-export
+// -----------------------------------------------------------------------------
+// Facts Replay
+// -----------------------------------------------------------------------------
+
+// Restore strategies for checkpoints.
+const (
+	// RestoreAuto replays the facts if the checkpoint recorded them, and uses
+	// load-export otherwise.
+	RestoreAuto = "auto"
+	// RestoreReplay restarts the instance and replays the recorded facts.
+	RestoreReplay = "replay"
+	// RestoreLoadExport loads the execution graph with load-export.
+	RestoreLoadExport = "load-export"
+)
+
+// ReplayResult reports how a saved state was reconstructed by RestoreByReplay.
+type ReplayResult struct {
+	Asserted   int             `json:"asserted"`   // Facts created because they held in the saved state only
+	Terminated int             `json:"terminated"` // Model facts terminated because they did not hold in the saved state
+	Failed     []ReplayFailure `json:"failed"`     // Phrases the server rejected
+}
+
+// ReplayFailure is a fact phrase that could not be replayed.
+type ReplayFailure struct {
+	Phrase string `json:"phrase"` // The phrase sent, e.g. `+allowed-archetype("VU", "x", "y").`
+	Error  string `json:"error"`  // Why the server rejected it
+}
+
+// RestoreByReplay restores a saved state without load-export, which is known to
+// crash the eFLINT server: the instance is restarted with its model, and the facts
+// recorded in the saved state are then created (and model facts that did not hold
+// terminated) with fact phrases. Execution history is not restored, only the facts.
+//
+// The saved state must have recorded its facts (see SetFactsSource); without a
+// FactsSource, the facts of the restarted model cannot be compared and every
+// recorded fact is created. Phrases the server rejects are reported in the result
+// rather than aborting the replay.
+func (sm *StateManager) RestoreByReplay(savedState *SavedState) (_ *ReplayResult, err error) {
+	defer func() { err = stateError(err) }()
+
+	if savedState == nil || savedState.Facts == nil {
+		return nil, fmt.Errorf("%w: state has no recorded facts to replay", ErrInvalidState)
+	}
+	// Reject the state before the instance is restarted if a fact cannot be replayed
+	for i, fact := range savedState.Facts {
+		if _, err := BuildTypedFactPhrase(FactCreate, fact.FactType, fact.Arguments); err != nil {
+			return nil, fmt.Errorf("%w: facts[%d]: %w", ErrInvalidState, i, err)
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.checkCompatibility(savedState); err != nil {
+		return nil, err
+	}
+	if !sm.instanceManager.IsRunning() {
+		return nil, ErrInstanceNotRunning
+	}
+	if sm.instanceManager.ReadOnly() {
+		return nil, fmt.Errorf("%w: states cannot be restored", ErrReadOnly)
+	}
+
+	modelLocation := sm.instanceManager.Status().ModelLocation
+	if err := sm.instanceManager.restartWithModel(modelLocation); err != nil {
+		return nil, fmt.Errorf("%w: restarting instance: %w", ErrStateImportFailed, err)
+	}
+
+	var initial []Fact
+	if sm.facts != nil {
+		if initial, err = sm.facts(context.Background()); err != nil {
+			return nil, fmt.Errorf("%w: fetching facts of the restarted instance: %w", ErrStateImportFailed, err)
+		}
+	}
+
+	saved := factKeys(savedState.Facts)
+	current := factKeys(initial)
+	result := &ReplayResult{Failed: []ReplayFailure{}}
+
+	replay := func(op FactOperation, fact Fact) bool {
+		phrase, err := BuildTypedFactPhrase(op, fact.FactType, fact.Arguments)
+		if err == nil {
+			_, err = sm.instanceManager.SendPhrase(phrase)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, ReplayFailure{Phrase: phrase, Error: err.Error()})
+			return false
+		}
+		return true
+	}
+
+	for _, fact := range initial {
+		if _, ok := saved[factKey(fact)]; !ok && replay(FactTerminate, fact) {
+			result.Terminated++
+		}
+	}
+	for _, fact := range savedState.Facts {
+		if _, ok := current[factKey(fact)]; !ok && replay(FactCreate, fact) {
+			result.Asserted++
+		}
+	}
+
+	sm.logger.Info("restored eFLINT state by replaying facts",
+		zap.String("id", savedState.ID),
+		zap.Int("asserted", result.Asserted),
+		zap.Int("terminated", result.Terminated),
+		zap.Int("failed", len(result.Failed)),
+	)
+	return result, nil
+}
+
+// factKey identifies a fact by its type and argument values. The value kind is
+// part of the key, so that the string "1" and the integer 1 differ.
+func factKey(fact Fact) string {
+	var b strings.Builder
+	b.WriteString(fact.FactType)
+	for _, arg := range fact.Arguments {
+		b.WriteByte(0)
+		b.WriteString(arg.FactType)
+		b.WriteByte(0)
+		b.WriteString(string(arg.Kind))
+		b.WriteByte(0)
+		b.WriteString(arg.Value)
+	}
+	return b.String()
+}
+
+// factKeys returns the set of keys of facts.
+func factKeys(facts []Fact) map[string]struct{} {
+	keys := make(map[string]struct{}, len(facts))
+	for _, fact := range facts {
+		keys[factKey(fact)] = struct{}{}
+	}
+	return keys
+}
+
+// validateSavedState checks that a saved state contains an execution graph with the
+// shape produced by create-export: a "nodes" array, an "edges" array of objects and a
+// numeric "current" node. It returns an error wrapping ErrInvalidState otherwise.
+func validateSavedState(savedState *SavedState) error {
+	if savedState == nil || len(savedState.Graph) == 0 {
+		return fmt.Errorf("%w: graph is missing", ErrInvalidState)
+	}
+
+	var graph map[string]json.RawMessage
+	if err := json.Unmarshal(savedState.Graph, &graph); err != nil {
+		return fmt.Errorf("%w: graph is not a JSON object: %v", ErrInvalidState, err)
+	}
+
+	var nodes []json.RawMessage
+	if raw, ok := graph["nodes"]; !ok {
+		return fmt.Errorf("%w: graph has no \"nodes\" field", ErrInvalidState)
+	} else if err := json.Unmarshal(raw, &nodes); err != nil || nodes == nil {
+		return fmt.Errorf("%w: graph \"nodes\" must be an array", ErrInvalidState)
+	}
+
+	var edges []map[string]json.RawMessage
+	if raw, ok := graph["edges"]; !ok {
+		return fmt.Errorf("%w: graph has no \"edges\" field", ErrInvalidState)
+	} else if err := json.Unmarshal(raw, &edges); err != nil || edges == nil {
+		return fmt.Errorf("%w: graph \"edges\" must be an array of objects", ErrInvalidState)
+	}
+
+	var current float64
+	if raw, ok := graph["current"]; !ok {
+		return fmt.Errorf("%w: graph has no \"current\" field", ErrInvalidState)
+	} else if err := json.Unmarshal(raw, &current); err != nil {
+		return fmt.Errorf("%w: graph \"current\" must be a number", ErrInvalidState)
+	}
+
+	return nil
+}
+
+// checkCompatibility verifies that a saved state can be imported into the running
+// instance: its schema version must be supported and, when both hashes are known,
+// it must have been exported against the same model. Caller must hold mu.
+func (sm *StateManager) checkCompatibility(savedState *SavedState) error {
+	switch {
+	case savedState.SchemaVersion == 0:
+		// Legacy state from before versioning; the format is identical to version 1
+		sm.logger.Warn("importing unversioned state, assuming schema version 1",
+			zap.String("id", savedState.ID),
+		)
+	case savedState.SchemaVersion > StateSchemaVersion:
+		return fmt.Errorf("%w: schema version %d is newer than supported version %d",
+			ErrStateIncompatible, savedState.SchemaVersion, StateSchemaVersion)
+	}
+
+	if savedState.ModelHash == "" {
+		return nil
+	}
+
+	status := sm.instanceManager.Status()
+	currentHash, err := hashModelFile(status.ModelLocation)
+	if err != nil {
+		sm.logger.Warn("cannot verify state model hash", zap.String("model", status.ModelLocation), zap.Error(err))
+		return nil
+	}
+	if currentHash != savedState.ModelHash {
+		return fmt.Errorf("%w: state was exported against model %q (%s), but %q (%s) is loaded",
+			ErrStateIncompatible, savedState.ModelLocation, savedState.ModelHash, status.ModelLocation, currentHash)
+	}
+
+	return nil
+}
+
+// hashModelFile returns the SHA-256 of a model file as "sha256:<hex>".
+func hashModelFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// TransformGraphForImport transforms the exported graph to be compatible with load-export.
 // The eFLINT server has multiple asymmetric JSON encoding bugs:
 //  1. ToJSON outputs "program" field in edges, but FromJSON expects "label" field
 //  2. The pretty printer outputs "Type extension of X" lines which are not valid eFLINT syntax
 //     and cannot be parsed by the FromJSON instance (causes server crash)
-func transformGraphForImport(graph json.RawMessage) (json.RawMessage, error) {
+func TransformGraphForImport(graph json.RawMessage) (json.RawMessage, error) {
 	var graphData map[string]interface{}
 	if err := json.Unmarshal(graph, &graphData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal graph: %w", err)
@@ -237,85 +609,146 @@ func stripTypeExtensionLines(program string) string {
 	return strings.Join(result, "\n")
 }
 
-// SaveStateToFile saves the current state to a file
-func (sm *StateManager) SaveStateToFile(filename string) (*SavedState, error) {
+// errNoStateStore is returned by persistence operations when no store is configured.
+var errNoStateStore = errors.New("state storage not configured")
+
+// Error codes for failures specific to state management.
+const (
+	CodeInstanceNotRunning = "instance_not_running"
+	CodeInvalidState       = "invalid_state"
+	CodeStateNotFound      = "state_not_found"
+	CodeReadOnly           = "read_only"
+)
+
+// stateError converts an error into an *apperr.Error, so that the state API can
+// return it as is. The original error stays available via errors.Is. Errors that
+// are already converted are returned as is.
+func stateError(err error) error {
+	if _, ok := err.(*apperr.Error); ok || err == nil {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, ErrInstanceNotRunning):
+		return apperr.Wrap(err, http.StatusServiceUnavailable, CodeInstanceNotRunning, "instance is not running")
+	case errors.Is(err, ErrInvalidState), errors.Is(err, ErrStateIncompatible):
+		return apperr.Wrap(err, http.StatusUnprocessableEntity, CodeInvalidState, err.Error())
+	case errors.Is(err, ErrStateNotFound):
+		return apperr.Wrap(err, http.StatusNotFound, CodeStateNotFound, err.Error())
+	case errors.Is(err, ErrReadOnly):
+		return apperr.Wrap(err, http.StatusForbidden, CodeReadOnly, err.Error())
+	}
+	return apperr.Internal(err)
+}
+
+// SaveStateToFile saves the current state to the state store under the given name.
+// Concurrent saves, loads and deletes of the same name run one after the other, so
+// the stored state is always one complete export.
+func (sm *StateManager) SaveStateToFile(filename string) (_ *SavedState, err error) {
+	defer func() { err = stateError(err) }()
+
+	if sm.store == nil {
+		return nil, errNoStateStore
+	}
+
+	unlock := sm.names.lock(filename)
+	defer unlock()
+
 	state, err := sm.ExportState()
 	if err != nil {
 		return nil, err
 	}
 
-	filePath := filepath.Join(sm.stateDir, filename+".json")
-
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write state file: %w", err)
+	if err := sm.store.Save(filename, data); err != nil {
+		return nil, err
 	}
 
-	sm.logger.Info("saved state to file",
-		zap.String("file", filePath),
+	sm.logger.Info("saved state",
+		zap.String("name", filename),
 		zap.String("id", state.ID),
 	)
 
 	return state, nil
 }
 
-// LoadStateFromFile loads a state from a file and imports it
+// LoadStateFromFile loads a state from the state store and imports it. A save of
+// the same name waits until the import is done.
 func (sm *StateManager) LoadStateFromFile(filename string) error {
-	filePath := filepath.Join(sm.stateDir, filename+".json")
+	unlock := sm.names.lock(filename)
+	defer unlock()
 
-	data, err := os.ReadFile(filePath)
+	state, err := sm.loadSavedState(filename)
 	if err != nil {
-		return fmt.Errorf("failed to read state file: %w", err)
+		return err
 	}
 
-	var state SavedState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return fmt.Errorf("failed to unmarshal state: %w", err)
-	}
-
-	sm.logger.Info("loading state from file",
-		zap.String("file", filePath),
+	sm.logger.Info("loading saved state",
+		zap.String("name", filename),
 		zap.String("id", state.ID),
 	)
 
-	return sm.ImportState(&state)
+	return sm.ImportState(state)
 }
 
-// ListSavedStates lists all saved state files
-func (sm *StateManager) ListSavedStates() ([]string, error) {
-	if sm.stateDir == "" {
-		return nil, fmt.Errorf("state directory not configured")
+// LoadSavedState reads a state from the state store without importing it.
+func (sm *StateManager) LoadSavedState(filename string) (*SavedState, error) {
+	unlock := sm.names.lock(filename)
+	defer unlock()
+
+	return sm.loadSavedState(filename)
+}
+
+// loadSavedState implements LoadSavedState. Caller must hold the lock of filename.
+func (sm *StateManager) loadSavedState(filename string) (_ *SavedState, err error) {
+	defer func() { err = stateError(err) }()
+
+	if sm.store == nil {
+		return nil, errNoStateStore
 	}
 
-	files, err := os.ReadDir(sm.stateDir)
+	data, err := sm.store.Load(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read state directory: %w", err)
+		return nil, err
 	}
 
-	var states []string
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
-			states = append(states, file.Name()[:len(file.Name())-5]) // Remove .json extension
-		}
+	var state SavedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 	}
+	return &state, nil
+}
 
-	return states, nil
+// ListSavedStates lists the names of all saved states
+func (sm *StateManager) ListSavedStates() ([]string, error) {
+	if sm.store == nil {
+		return nil, stateError(errNoStateStore)
+	}
+	names, err := sm.store.List()
+	return names, stateError(err)
 }
 
-// DeleteSavedState deletes a saved state file
-func (sm *StateManager) DeleteSavedState(filename string) error {
-	filePath := filepath.Join(sm.stateDir, filename+".json")
+// DeleteSavedState deletes a saved state
+func (sm *StateManager) DeleteSavedState(filename string) (err error) {
+	defer func() { err = stateError(err) }()
 
-	if err := os.Remove(filePath); err != nil {
-		return fmt.Errorf("failed to delete state file: %w", err)
+	if sm.store == nil {
+		return errNoStateStore
 	}
 
-	sm.logger.Info("deleted state file",
-		zap.String("file", filePath),
+	unlock := sm.names.lock(filename)
+	defer unlock()
+
+	if err := sm.store.Delete(filename); err != nil {
+		return err
+	}
+
+	sm.logger.Info("deleted saved state",
+		zap.String("name", filename),
 	)
 
 	return nil
@@ -331,3 +764,8 @@ func (sm *StateManager) CreateCheckpoint(name string) (*SavedState, error) {
 func (sm *StateManager) RestoreCheckpoint(name string) error {
 	return sm.LoadStateFromFile("checkpoint-" + name)
 }
+
+// LoadCheckpoint reads a previously created checkpoint without restoring it.
+func (sm *StateManager) LoadCheckpoint(name string) (*SavedState, error) {
+	return sm.LoadSavedState("checkpoint-" + name)
+}
@@ -0,0 +1,190 @@
+package eflint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+// phraseOK answers phrases with success and every other command with an empty object.
+func phraseOK(command string) string {
+	if name, _ := commandOf(command); name == "phrase" {
+		return `{"response": "success"}`
+	}
+	return `{}`
+}
+
+func TestRestoreByReplayQuotesStringValues(t *testing.T) {
+	manager, server := startManager(t, phraseOK)
+	sm := NewStateManager(manager, nil, zap.NewNop())
+
+	// The kind claims an integer, but the value is a JSON string
+	var state SavedState
+	checkpoint := `{"id": "x", "graph": {}, "facts": [{"fact-type": "x", "arguments": [{"fact-type": "y", "value": "1). +x(\"a\"", "kind": "int"}]}]}`
+	if err := json.Unmarshal([]byte(checkpoint), &state); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := sm.RestoreByReplay(&state)
+	if err != nil {
+		t.Fatalf("RestoreByReplay() error = %v", err)
+	}
+	if result.Asserted != 1 {
+		t.Errorf("Asserted = %d, want 1", result.Asserted)
+	}
+	want := `+x("1). +x(\"a\"").`
+	if phrases := phrasesOf(server.Commands()); !slices.Equal(phrases, []string{want}) {
+		t.Errorf("sent phrases %q, want %q", phrases, want)
+	}
+}
+
+func TestRestoreByReplayRejectsInvalidFacts(t *testing.T) {
+	manager, server := startManager(t, phraseOK)
+	sm := NewStateManager(manager, nil, zap.NewNop())
+	startedAt := manager.Status().StartedAt
+
+	for _, facts := range []string{
+		`[{"fact-type": "x(\"a\"). +allowed-archetype", "arguments": []}]`,
+		`[{"fact-type": "x", "arguments": [{"fact-type": "y(\"a\"). +z", "value": [{"fact-type": "w", "value": 1}]}]}]`,
+	} {
+		var state SavedState
+		if err := json.Unmarshal([]byte(`{"id": "x", "graph": {}, "facts": `+facts+`}`), &state); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sm.RestoreByReplay(&state); !errors.Is(err, ErrInvalidState) || !errors.Is(err, ErrInvalidTypeName) {
+			t.Errorf("RestoreByReplay(%s) error = %v, want ErrInvalidState wrapping ErrInvalidTypeName", facts, err)
+		}
+	}
+
+	if phrases := phrasesOf(server.Commands()); len(phrases) != 0 {
+		t.Errorf("sent phrases %q for invalid facts", phrases)
+	}
+	if !manager.Status().StartedAt.Equal(*startedAt) {
+		t.Error("instance was restarted for an invalid state")
+	}
+}
+
+func TestExportStateRejectsInvalidResponses(t *testing.T) {
+	for name, response := range map[string]string{
+		"empty":      "",
+		"whitespace": "   ",
+		"not JSON":   "internal error: graph unavailable",
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager, _ := startManager(t, func(command string) string {
+				if name, _ := commandOf(command); name == "create-export" {
+					return response
+				}
+				return `{}`
+			})
+			sm := NewStateManager(manager, nil, zap.NewNop())
+
+			if state, err := sm.ExportState(); !errors.Is(err, ErrStateExportFailed) {
+				t.Errorf("ExportState() = %v, %v, want ErrStateExportFailed", state, err)
+			}
+			if _, err := sm.GetCleanState(); !errors.Is(err, ErrStateExportFailed) {
+				t.Errorf("GetCleanState() error = %v, want ErrStateExportFailed", err)
+			}
+		})
+	}
+}
+
+func TestImportStateRestartsAfterLoadExportFailure(t *testing.T) {
+	// The server crashes on load-export, as eflint-server does for some graphs
+	var manager *Manager
+	manager, server := startManager(t, func(command string) string {
+		if name, _ := commandOf(command); name == "load-export" {
+			manager.mu.RLock()
+			manager.instance.Process.Process.Kill()
+			manager.mu.RUnlock()
+		}
+		return `{"status": "ok"}`
+	})
+	sm := NewStateManager(manager, nil, zap.NewNop())
+	startedAt := manager.Status().StartedAt
+
+	state := &SavedState{SchemaVersion: StateSchemaVersion, ID: "x", Graph: json.RawMessage(`{"current": 0, "nodes": [], "edges": []}`)}
+	err := sm.ImportState(state)
+	if err == nil || !strings.Contains(err.Error(), "instance was restarted") {
+		t.Fatalf("ImportState() error = %v, want the load-export failure after a restart", err)
+	}
+
+	status := manager.Status()
+	if !manager.IsRunning() || status.ModelLocation != server.Model {
+		t.Fatalf("after the failed import, running = %v with model %q, want the original model %q",
+			manager.IsRunning(), status.ModelLocation, server.Model)
+	}
+	if status.StartedAt.Equal(*startedAt) {
+		t.Error("instance was not restarted")
+	}
+	eflinttest.WaitReady(t, manager.Ping)
+	if n := childProcesses(t); n != 1 {
+		t.Errorf("%d eflint-server processes running, want 1", n)
+	}
+}
+
+func TestConcurrentSavesOfOneName(t *testing.T) {
+	// Every export is a distinct graph, large enough that interleaved writes of
+	// two saves would show
+	var exports atomic.Int32
+	manager, _ := startManager(t, func(command string) string {
+		if name, _ := commandOf(command); name == "create-export" {
+			n := exports.Add(1)
+			nodes := strings.Repeat(fmt.Sprintf(`{"id": %d}, `, n), 5000)
+			return fmt.Sprintf(`{"current": %d, "nodes": [%s{"id": 0}], "edges": []}`, n, nodes)
+		}
+		return `{}`
+	})
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStateStore() error = %v", err)
+	}
+	sm := NewStateManager(manager, store, zap.NewNop())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := sm.CreateCheckpoint("same")
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			// A concurrent read sees no state or a complete one
+			if _, err := sm.LoadSavedState("checkpoint-same"); err != nil && !errors.Is(err, ErrStateNotFound) {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent save or load error = %v", err)
+		}
+	}
+
+	state, err := sm.LoadSavedState("checkpoint-same")
+	if err != nil {
+		t.Fatalf("LoadSavedState() error = %v", err)
+	}
+	if err := validateSavedState(state); err != nil {
+		t.Errorf("saved state is corrupt: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("state directory holds %d files, want only the saved state", len(entries))
+	}
+}
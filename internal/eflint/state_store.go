@@ -0,0 +1,241 @@
+package eflint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// State Storage
+// -----------------------------------------------------------------------------
+//
+// A StateStore persists saved states and checkpoints for the StateManager. States
+// are addressed by name (e.g. "checkpoint-before-update") and stored as the JSON
+// encoding of a SavedState. Implementations are provided for the local filesystem,
+// for memory (tests and throwaway instances) and for S3-compatible object storage.
+
+// StateStore persists saved states by name.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	// Save stores data under name, replacing any existing state with that name.
+	Save(name string, data []byte) error
+
+	// Load returns the data stored under name, or an error wrapping ErrStateNotFound.
+	Load(name string) ([]byte, error)
+
+	// List returns the names of all stored states, sorted.
+	List() ([]string, error)
+
+	// Delete removes the state stored under name, or returns an error wrapping
+	// ErrStateNotFound if there is none.
+	Delete(name string) error
+}
+
+// StateStoreConfig selects and configures the state storage backend.
+type StateStoreConfig struct {
+	Type string   // Store type: "filesystem" (default), "memory" or "s3"
+	Dir  string   // Directory for the filesystem store
+	S3   S3Config // Settings for the S3 store
+}
+
+// NewStateStore creates the state store selected by config.Type.
+func NewStateStore(config StateStoreConfig) (StateStore, error) {
+	switch config.Type {
+	case "", "filesystem":
+		return NewFileStateStore(config.Dir)
+	case "memory":
+		return NewMemoryStateStore(), nil
+	case "s3":
+		return NewS3StateStore(config.S3)
+	}
+	return nil, fmt.Errorf("unknown state store type %q", config.Type)
+}
+
+// validateStateName rejects names that cannot be stored safely, e.g. names that
+// would escape the state directory.
+func validateStateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid state name %q", name)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Filesystem Store
+// -----------------------------------------------------------------------------
+
+// stateFileExt is the extension of state files in the filesystem store.
+const stateFileExt = ".json"
+
+// FileStateStore stores each state as <dir>/<name>.json.
+type FileStateStore struct {
+	dir string // Directory holding the state files
+}
+
+// NewFileStateStore creates a filesystem store in dir, creating the directory if
+// it doesn't exist.
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("state directory not configured")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %q: %w", dir, err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// path returns the file path for a state name.
+func (s *FileStateStore) path(name string) (string, error) {
+	if err := validateStateName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, name+stateFileExt), nil
+}
+
+// Save writes the state file for name. The data is written to a temporary file
+// that then replaces the state file, so readers and crashes never see a partially
+// written state.
+func (s *FileStateStore) Save(name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+name+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Load reads the state file for name.
+func (s *FileStateStore) Load(name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return data, nil
+}
+
+// List returns the names of all state files in the directory.
+func (s *FileStateStore) List() ([]string, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == stateFileExt {
+			names = append(names, strings.TrimSuffix(file.Name(), stateFileExt))
+		}
+	}
+	return names, nil
+}
+
+// Delete removes the state file for name.
+func (s *FileStateStore) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete state file: %w", err)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// In-Memory Store
+// -----------------------------------------------------------------------------
+
+// MemoryStateStore keeps states in memory. States are lost when the process exits.
+type MemoryStateStore struct {
+	states map[string][]byte
+	mu     sync.RWMutex
+}
+
+// NewMemoryStateStore creates an empty in-memory store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string][]byte)}
+}
+
+// Save stores a copy of data under name.
+func (s *MemoryStateStore) Save(name string, data []byte) error {
+	if err := validateStateName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[name] = slices.Clone(data)
+	return nil
+}
+
+// Load returns a copy of the data stored under name.
+func (s *MemoryStateStore) Load(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.states[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	}
+	return slices.Clone(data), nil
+}
+
+// List returns the names of all stored states.
+func (s *MemoryStateStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.states))
+	for name := range s.states {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Delete removes the state stored under name.
+func (s *MemoryStateStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.states[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	}
+	delete(s.states, name)
+	return nil
+}
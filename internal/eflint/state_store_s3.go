@@ -0,0 +1,357 @@
+package eflint
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// S3 Store
+// -----------------------------------------------------------------------------
+//
+// S3StateStore stores states as objects <prefix><name>.json in an S3 bucket, so
+// checkpoints survive ephemeral containers. It speaks the S3 REST API directly
+// (path-style addressing, AWS Signature Version 4) and therefore also works with
+// S3-compatible services such as MinIO.
+
+// S3Config holds configuration for the S3 state store.
+type S3Config struct {
+	Endpoint        string        // Base URL of the S3 service; defaults to https://s3.<region>.amazonaws.com
+	Region          string        // Region used for request signing (e.g., "eu-west-1")
+	Bucket          string        // Bucket holding the states
+	Prefix          string        // Key prefix for state objects (e.g., "eflint-states/")
+	AccessKeyID     string        // Access key; defaults to the AWS_ACCESS_KEY_ID environment variable
+	SecretAccessKey string        // Secret key; defaults to the AWS_SECRET_ACCESS_KEY environment variable
+	SessionToken    string        // Token of temporary credentials; defaults to AWS_SESSION_TOKEN if the access key does
+	Timeout         time.Duration // Timeout for a single request to the service
+}
+
+// S3StateStore stores states in an S3 bucket.
+type S3StateStore struct {
+	config S3Config
+	client *http.Client
+}
+
+// NewS3StateStore creates an S3 store. Bucket and Region are required.
+func NewS3StateStore(config S3Config) (*S3StateStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 state store requires a bucket")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("s3 state store requires a region")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		// A session token only belongs to the key pair it was issued with
+		if config.SessionToken == "" {
+			config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	if config.SecretAccessKey == "" {
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 state store requires credentials")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &S3StateStore{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// key returns the object key for a state name.
+func (s *S3StateStore) key(name string) (string, error) {
+	if err := validateStateName(name); err != nil {
+		return "", err
+	}
+	return s.config.Prefix + name + stateFileExt, nil
+}
+
+// Save uploads the state object for name.
+func (s *S3StateStore) Save(name string, data []byte) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodPut, key, nil, data)
+	if err != nil {
+		return fmt.Errorf("failed to upload state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload state: %s", s3Error(resp))
+	}
+	return nil
+}
+
+// Load downloads the state object for name.
+func (s *S3StateStore) Load(name string) ([]byte, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	default:
+		return nil, fmt.Errorf("failed to download state: %s", s3Error(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download state: %w", err)
+	}
+	return data, nil
+}
+
+// List returns the names of all state objects under the prefix.
+func (s *S3StateStore) List() ([]string, error) {
+	var names []string
+	query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix}}
+
+	for {
+		resp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list states: %w", err)
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("failed to list states: %s", s3Error(resp))
+		} else if decodeErr := xml.NewDecoder(resp.Body).Decode(&result); decodeErr != nil {
+			err = fmt.Errorf("failed to parse state listing: %w", decodeErr)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, s.config.Prefix)
+			// Objects in "subdirectories" of the prefix are not states of this store
+			if strings.HasSuffix(name, stateFileExt) && !strings.Contains(name, "/") {
+				names = append(names, strings.TrimSuffix(name, stateFileExt))
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// Delete removes the state object for name.
+func (s *S3StateStore) Delete(name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+
+	// S3 deletes are idempotent, so check existence first to report missing states
+	head, err := s.do(http.MethodHead, key, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	head.Body.Close()
+	if head.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrStateNotFound, name)
+	}
+
+	resp, err := s.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete state: %s", s3Error(resp))
+	}
+	return nil
+}
+
+// do sends a signed request for an object key (or the bucket, if key is empty).
+func (s *S3StateStore) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+
+	path := "/" + awsURIEncode(s.config.Bucket, false)
+	if key != "" {
+		path += "/" + awsURIEncode(key, false)
+	}
+	rawQuery := canonicalQuery(query)
+
+	target := s.config.Endpoint + path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.sign(req, path, rawQuery, body, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to a request. With temporary
+// credentials, the session token is sent and signed as X-Amz-Security-Token.
+func (s *S3StateStore) sign(req *http.Request, path, rawQuery string, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + s.config.SessionToken + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		rawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// cancelOnClose releases a request's context when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// s3Error describes a failed S3 response, including the error code if present.
+func s3Error(resp *http.Response) string {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err == nil && body.Code != "" {
+		return fmt.Sprintf("status %d: %s: %s", resp.StatusCode, body.Code, body.Message)
+	}
+	return fmt.Sprintf("status %d", resp.StatusCode)
+}
+
+// canonicalQuery encodes query parameters sorted by key, as required for signing.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes everything except unreserved characters, as
+// required by Signature Version 4. Slashes are kept unless encodeSlash is set.
+func awsURIEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
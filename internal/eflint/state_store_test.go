@@ -0,0 +1,231 @@
+package eflint
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestStateStores runs the StateStore contract against every store.
+func TestStateStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) StateStore{
+		"filesystem": func(t *testing.T) StateStore {
+			store, err := NewFileStateStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFileStateStore() error = %v", err)
+			}
+			return store
+		},
+		"memory": func(t *testing.T) StateStore {
+			return NewMemoryStateStore()
+		},
+		"s3": func(t *testing.T) StateStore {
+			return newFakeS3(t).store(t, "states/")
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			testStateStore(t, newStore(t))
+		})
+	}
+}
+
+// testStateStore checks that a store behaves as documented by StateStore.
+func testStateStore(t *testing.T, store StateStore) {
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Errorf("List() of an empty store = %v, %v, want none", names, err)
+	}
+	if _, err := store.Load("missing"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Load(missing) error = %v, want ErrStateNotFound", err)
+	}
+	if err := store.Delete("missing"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Delete(missing) error = %v, want ErrStateNotFound", err)
+	}
+
+	for _, name := range []string{"b", "a", "c d", "v1.2"} {
+		if err := store.Save(name, []byte(`{"name": "`+name+`"}`)); err != nil {
+			t.Fatalf("Save(%q) error = %v", name, err)
+		}
+	}
+	if err := store.Save("a", []byte(`{"name": "a2"}`)); err != nil {
+		t.Fatalf("Save(a) error = %v", err)
+	}
+
+	data, err := store.Load("a")
+	if err != nil || string(data) != `{"name": "a2"}` {
+		t.Errorf("Load(a) = %s, %v, want the replaced state", data, err)
+	}
+	// The store keeps its own copy
+	data[0] = 'x'
+	if data, _ := store.Load("a"); string(data) != `{"name": "a2"}` {
+		t.Errorf("Load(a) = %s after modifying a loaded copy", data)
+	}
+	if data, err := store.Load("c d"); err != nil || string(data) != `{"name": "c d"}` {
+		t.Errorf("Load(c d) = %s, %v", data, err)
+	}
+
+	names, err := store.List()
+	if want := []string{"a", "b", "c d", "v1.2"}; err != nil || !slices.Equal(names, want) {
+		t.Errorf("List() = %v, %v, want %v", names, err, want)
+	}
+
+	if err := store.Delete("b"); err != nil {
+		t.Fatalf("Delete(b) error = %v", err)
+	}
+	if _, err := store.Load("b"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Load(b) after Delete error = %v, want ErrStateNotFound", err)
+	}
+	if names, _ := store.List(); !slices.Equal(names, []string{"a", "c d", "v1.2"}) {
+		t.Errorf("List() after Delete = %v", names)
+	}
+
+	for _, name := range []string{"", ".", "..", "../escape", `a\b`, "a/b"} {
+		if err := store.Save(name, []byte("{}")); err == nil {
+			t.Errorf("Save(%q) succeeded for an invalid name", name)
+		}
+	}
+}
+
+func TestS3StateStoreIgnoresOtherObjects(t *testing.T) {
+	s3 := newFakeS3(t)
+	store := s3.store(t, "states/")
+	if err := store.Save("kept", []byte("{}")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	s3.put("states/nested/other.json", nil)
+	s3.put("states/notes.txt", nil)
+	s3.put("elsewhere/state.json", nil)
+
+	if names, err := store.List(); err != nil || !slices.Equal(names, []string{"kept"}) {
+		t.Errorf("List() = %v, %v, want [kept]", names, err)
+	}
+}
+
+func TestS3StateStoreReportsErrors(t *testing.T) {
+	s3 := newFakeS3(t)
+	s3.fail = true
+	store := s3.store(t, "")
+
+	if err := store.Save("a", []byte("{}")); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Save() error = %v, want the S3 error code", err)
+	}
+	if _, err := store.List(); err == nil {
+		t.Error("List() succeeded for a failing service")
+	}
+}
+
+// fakeS3 is a minimal S3 service for one bucket: object PUT, GET, HEAD and DELETE,
+// and ListObjectsV2 in pages of two keys.
+type fakeS3 struct {
+	server  *httptest.Server
+	objects map[string][]byte
+	fail    bool // Answer every request with AccessDenied
+	mu      sync.Mutex
+}
+
+const fakeS3Bucket = "test-bucket"
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	s := &fakeS3{objects: make(map[string][]byte)}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// store returns an S3StateStore using the fake service.
+func (s *fakeS3) store(t *testing.T, prefix string) *S3StateStore {
+	store, err := NewS3StateStore(S3Config{
+		Endpoint:        s.server.URL,
+		Region:          "eu-west-1",
+		Bucket:          fakeS3Bucket,
+		Prefix:          prefix,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3StateStore() error = %v", err)
+	}
+	return store
+}
+
+func (s *fakeS3) put(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+}
+
+func (s *fakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" || s.fail {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+fakeS3Bucket)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[key] = data
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list answers ListObjectsV2, two keys per page.
+func (s *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	type object struct {
+		Key string `xml:"Key"`
+	}
+	var result struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []object `xml:"Contents"`
+		IsTruncated           bool     `xml:"IsTruncated"`
+		NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+	}
+	for i, key := range keys {
+		if i == 2 {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[i-1]
+			break
+		}
+		result.Contents = append(result.Contents, object{Key: key})
+	}
+	xml.NewEncoder(w).Encode(result)
+}
@@ -15,8 +15,25 @@ import (
 // Handler processes incoming RequestApproval messages from RabbitMQ
 // and validates them against the eFLINT policy engine.
 type Handler struct {
-	manager *eflint.Manager
-	logger  *zap.Logger
+	manager     *eflint.Manager
+	config      *HandlerConfig
+	redelivered *redeliveryTracker
+	logger      *zap.Logger
+}
+
+// HandlerConfig holds configuration for the message handler.
+type HandlerConfig struct {
+	// MaxRedeliveries is the number of failed attempts after which a message is
+	// rejected without requeue, so that the broker dead-letters it (if a DLX is
+	// configured on the queue) instead of redelivering it forever.
+	MaxRedeliveries int
+}
+
+// DefaultHandlerConfig returns the default handler configuration.
+func DefaultHandlerConfig() *HandlerConfig {
+	return &HandlerConfig{
+		MaxRedeliveries: 5,
+	}
 }
 
 // RequestApproval represents an incoming policy validation request message.
@@ -38,10 +55,17 @@ type ValidationResponse struct {
 }
 
 // NewHandler creates a new request handler with the given eFLINT manager.
-func NewHandler(manager *eflint.Manager, logger *zap.Logger) *Handler {
+// If config is nil, default configuration is used.
+func NewHandler(manager *eflint.Manager, config *HandlerConfig, logger *zap.Logger) *Handler {
+	if config == nil {
+		config = DefaultHandlerConfig()
+	}
+
 	return &Handler{
-		manager: manager,
-		logger:  logger,
+		manager:     manager,
+		config:      config,
+		redelivered: newRedeliveryTracker(),
+		logger:      logger,
 	}
 }
 
@@ -68,7 +92,23 @@ func (h *Handler) Handle(msg amqp.Delivery) error {
 	// Query eFLINT server
 	approved, reason, err := h.queryEFlint(request)
 	if err != nil {
-		h.logger.Error("failed to query eFLINT", zap.Error(err))
+		attempts := h.redelivered.recordFailure(msg)
+		if attempts >= h.config.MaxRedeliveries {
+			// Poison message: stop redelivering so it cannot starve the queue
+			h.logger.Error("dead-lettering message after repeated failures",
+				zap.String("request_id", request.RequestID),
+				zap.Int("attempts", attempts),
+				zap.Error(err),
+			)
+			h.redelivered.forget(msg)
+			msg.Nack(false, false)
+			return err
+		}
+
+		h.logger.Error("failed to query eFLINT",
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
 		msg.Nack(false, true) // Requeue on error
 		return err
 	}
@@ -86,6 +126,7 @@ func (h *Handler) Handle(msg amqp.Delivery) error {
 	}
 
 	msg.Ack(false)
+	h.redelivered.forget(msg)
 	h.logger.Info("successfully processed request",
 		zap.String("request_id", request.RequestID),
 		zap.Bool("approved", approved),
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// -----------------------------------------------------------------------------
+// Redelivery Tracking
+// -----------------------------------------------------------------------------
+
+// maxTrackedMessages bounds the local failure map. Entries are removed when a
+// message succeeds or is dead-lettered; the bound only protects against messages
+// that fail once and are then consumed elsewhere.
+const maxTrackedMessages = 10000
+
+// redeliveryTracker counts failed processing attempts per message.
+// Thread-safe for concurrent access.
+type redeliveryTracker struct {
+	failures map[string]int
+	mu       sync.Mutex
+}
+
+// newRedeliveryTracker creates an empty tracker.
+func newRedeliveryTracker() *redeliveryTracker {
+	return &redeliveryTracker{failures: make(map[string]int)}
+}
+
+// recordFailure records a failed attempt for msg and returns the total number of
+// failed attempts so far. The broker's x-death count is used when it is higher,
+// e.g. when messages cycle through a dead-letter exchange with a TTL.
+func (t *redeliveryTracker) recordFailure(msg amqp.Delivery) int {
+	key := messageKey(msg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.failures) >= maxTrackedMessages {
+		t.failures = make(map[string]int)
+	}
+	t.failures[key]++
+
+	return max(t.failures[key], xDeathCount(msg)+1)
+}
+
+// forget removes msg from the tracker after it was acked or dead-lettered.
+func (t *redeliveryTracker) forget(msg amqp.Delivery) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, messageKey(msg))
+}
+
+// messageKey identifies a message across redeliveries: the message ID if set,
+// otherwise the correlation ID, otherwise a hash of the body.
+func messageKey(msg amqp.Delivery) string {
+	if msg.MessageId != "" {
+		return "id:" + msg.MessageId
+	}
+	if msg.CorrelationId != "" {
+		return "correlation:" + msg.CorrelationId
+	}
+	sum := sha256.Sum256(msg.Body)
+	return "body:" + hex.EncodeToString(sum[:])
+}
+
+// xDeathCount returns the total dead-letter count from the x-death header.
+func xDeathCount(msg amqp.Delivery) int {
+	deaths, ok := msg.Headers["x-death"].([]interface{})
+	if !ok {
+		return 0
+	}
+
+	total := 0
+	for _, d := range deaths {
+		table, ok := d.(amqp.Table)
+		if !ok {
+			continue
+		}
+		if count, ok := table["count"].(int64); ok {
+			total += int(count)
+		}
+	}
+	return total
+}
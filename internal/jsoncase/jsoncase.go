@@ -0,0 +1,421 @@
+// Package jsoncase provides an Echo middleware that lets clients use camelCase
+// JSON keys instead of the API's snake_case ones. Clients opt in per request;
+// responses to everyone else are passed through untouched. Only the keys of the
+// API's own fields are converted, never those of data held in maps.
+package jsoncase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// QueryParam is the query parameter selecting the key casing ("camel" or "snake").
+	QueryParam = "case"
+	// MediaTypeParam is the Accept / Content-Type parameter selecting the key casing.
+	MediaTypeParam = "case"
+	// Camel is the value that selects camelCase keys.
+	Camel = "camel"
+)
+
+// Middleware returns a middleware that converts JSON keys for clients asking for
+// camelCase, via `Accept: application/json; case=camel`, a `case=camel` parameter
+// on the request's Content-Type, or the `?case=camel` query parameter.
+//
+// For such requests, JSON response keys are converted from snake_case to
+// camelCase, and camelCase keys in a JSON request body and in query parameter
+// names are converted to snake_case, so either casing is accepted on input. Only
+// keys in fields are converted, wherever they occur in the document; other keys,
+// such as those of eFLINT responses or configuration setting names, are kept.
+// Non-JSON responses (e.g. event streams) are not touched.
+func Middleware(fields Fields) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !wantsCamel(req) {
+				return next(c)
+			}
+
+			if err := convertRequest(req, fields); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			}
+
+			original := c.Response().Writer
+			w := &responseWriter{ResponseWriter: original, fields: fields}
+			c.Response().Writer = w
+			defer func() {
+				w.finish()
+				c.Response().Writer = original
+			}()
+
+			// Render errors here so that error responses are converted too
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			return nil
+		}
+	}
+}
+
+// wantsCamel reports whether the request opts into camelCase keys.
+func wantsCamel(req *http.Request) bool {
+	if strings.EqualFold(req.URL.Query().Get(QueryParam), Camel) {
+		return true
+	}
+	if caseParam(req.Header.Get(echo.HeaderContentType)) == Camel {
+		return true
+	}
+	for _, mediaRange := range strings.Split(req.Header.Get(echo.HeaderAccept), ",") {
+		if caseParam(mediaRange) == Camel {
+			return true
+		}
+	}
+	return false
+}
+
+// caseParam returns the lower-cased case parameter of a JSON media type, if any.
+func caseParam(value string) string {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil || !isJSON(mediaType) {
+		return ""
+	}
+	return strings.ToLower(params[MediaTypeParam])
+}
+
+// isJSON reports whether a media type (without parameters) is JSON.
+func isJSON(mediaType string) bool {
+	return mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// convertRequest renames camelCase query parameters and JSON body keys in fields
+// to snake_case.
+func convertRequest(req *http.Request, fields Fields) error {
+	query := req.URL.Query()
+	renamed := make(url.Values, len(query))
+	for name, values := range query {
+		key := fields.toSnake(name)
+		renamed[key] = append(renamed[key], values...)
+	}
+	req.URL.RawQuery = renamed.Encode()
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if req.Body == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if body, err = ConvertKeys(body, fields.toSnake); err != nil {
+			return err
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	return nil
+}
+
+// responseWriter buffers a JSON response so its keys can be converted once the
+// handler is done. Other responses are written through as they are produced.
+type responseWriter struct {
+	http.ResponseWriter
+	fields  Fields
+	decided bool // Whether the response has been classified as JSON or not
+	buffer  bool // Whether the response is JSON and being buffered
+	status  int
+	body    bytes.Buffer
+}
+
+// decide classifies the response by its Content-Type when the header is written.
+func (w *responseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get(echo.HeaderContentType))
+	w.buffer = isJSON(mediaType)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseWriter) WriteHeader(status int) {
+	w.decide()
+	if w.buffer {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.decide()
+	if w.buffer {
+		return w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher. Buffered responses are only written by finish.
+func (w *responseWriter) Flush() {
+	if w.buffer {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("jsoncase: response writer does not support hijacking")
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes a buffered response with its keys in fields converted to
+// camelCase. A body that is not valid JSON is written unchanged.
+func (w *responseWriter) finish() {
+	if !w.buffer {
+		return
+	}
+
+	body := w.body.Bytes()
+	if converted, err := ConvertKeys(body, w.fields.toCamel); err == nil {
+		body = converted
+	}
+	if w.Header().Get(echo.HeaderContentLength) != "" {
+		w.Header().Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	w.ResponseWriter.Write(body)
+}
+
+// Fields is a set of snake_case keys, the names of the fields of the API's
+// requests and responses.
+type Fields map[string]struct{}
+
+// fieldTags are the struct tags naming a field in JSON, in a query string or form,
+// or in the config file.
+var fieldTags = []string{"json", "query", "form", "mapstructure"}
+
+// FieldsOf returns the names of the fields of the types of values, and of the
+// struct types these hold in fields, pointers, slices, arrays and map values, as
+// given by their json, query, form and mapstructure tags. Map keys are data, so
+// their names are not included.
+func FieldsOf(values ...any) Fields {
+	fields := make(Fields)
+	seen := make(map[reflect.Type]bool)
+	for _, v := range values {
+		fields.addType(reflect.TypeOf(v), seen)
+	}
+	return fields
+}
+
+// Add adds names, e.g. of query parameters read without binding, and returns f.
+func (f Fields) Add(names ...string) Fields {
+	for _, name := range names {
+		f[name] = struct{}{}
+	}
+	return f
+}
+
+// addType adds the field names of t and of the struct types it holds.
+func (f Fields) addType(t reflect.Type, seen map[reflect.Type]bool) {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice ||
+		t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		for _, tag := range fieldTags {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				f[name] = struct{}{}
+			}
+		}
+		f.addType(field.Type, seen)
+	}
+}
+
+// toCamel converts key to camelCase if it is a field name.
+func (f Fields) toCamel(key string) string {
+	if _, ok := f[key]; ok {
+		return ToCamel(key)
+	}
+	return key
+}
+
+// toSnake converts key to snake_case if that is a field name.
+func (f Fields) toSnake(key string) string {
+	snake := ToSnake(key)
+	if _, ok := f[snake]; ok {
+		return snake
+	}
+	return key
+}
+
+// ConvertKeys returns data with every object key passed through convert. Key
+// order, values and the layout of trailing whitespace are kept; the document is
+// otherwise re-encoded compactly.
+func ConvertKeys(data []byte, convert func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	// Per open container: whether it is an object and how many tokens it holds
+	type container struct {
+		object bool
+		n      int
+	}
+	var stack []container
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			out.WriteByte(byte(d))
+			continue
+		}
+
+		isKey := false
+		if len(stack) == 0 {
+			if out.Len() > 0 {
+				out.WriteByte('\n') // Separate top-level values
+			}
+		} else {
+			top := &stack[len(stack)-1]
+			switch {
+			case top.object && top.n%2 == 0:
+				isKey = true
+				if top.n > 0 {
+					out.WriteByte(',')
+				}
+			case top.object:
+				out.WriteByte(':')
+			case top.n > 0:
+				out.WriteByte(',')
+			}
+			top.n++
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			out.WriteByte(byte(v))
+			stack = append(stack, container{object: v == '{'})
+		case json.Number:
+			out.WriteString(v.String())
+		case string:
+			if isKey {
+				v = convert(v)
+			}
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		default: // bool or nil
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// ToCamel converts a snake_case key to camelCase ("duration_ms" becomes
+// "durationMs"). Leading underscores and keys without underscores are kept.
+func ToCamel(key string) string {
+	trimmed := strings.TrimLeft(key, "_")
+	if !strings.Contains(trimmed, "_") {
+		return key
+	}
+
+	var b strings.Builder
+	b.Grow(len(key))
+	b.WriteString(key[:len(key)-len(trimmed)])
+	upper := false
+	for _, r := range trimmed {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ToSnake converts a camelCase key to snake_case ("requestType" becomes
+// "request_type", "modelURL" becomes "model_url"). snake_case keys are kept.
+func ToSnake(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
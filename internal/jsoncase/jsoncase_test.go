@@ -0,0 +1,170 @@
+package jsoncase
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type testArgument struct {
+	FactType string `json:"fact_type"`
+}
+
+type testRequest struct {
+	RequestType string            `json:"request_type" query:"request_type"`
+	Labels      map[string]string `json:"labels"`
+}
+
+type testResponse struct {
+	DurationMS int                      `json:"duration_ms"`
+	Arguments  []testArgument           `json:"arguments"`
+	ByName     map[string]*testArgument `json:"by_name"`
+	Raw        json.RawMessage          `json:"raw"`
+	Setting    struct {
+		MaxSize int `mapstructure:"max_size"`
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+	fields := FieldsOf(testRequest{}, &testResponse{}).Add("http_status")
+	for _, name := range []string{"request_type", "labels", "duration_ms", "arguments", "fact_type", "by_name", "raw", "max_size", "http_status"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("FieldsOf() lacks %q", name)
+		}
+	}
+	if len(fields) != 9 {
+		t.Errorf("FieldsOf() = %v, want 9 names", fields)
+	}
+}
+
+func TestConvertKeys(t *testing.T) {
+	in := `{"duration_ms": 5, "arguments": [{"fact_type": "x"}], "by_name": {"data_set": {"fact_type": "y"}}, "raw": {"new_facts": []}}` + "\n"
+	fields := FieldsOf(testResponse{})
+
+	got, err := ConvertKeys([]byte(in), fields.toCamel)
+	if err != nil {
+		t.Fatalf("ConvertKeys() error = %v", err)
+	}
+	want := `{"durationMs":5,"arguments":[{"factType":"x"}],"byName":{"data_set":{"factType":"y"}},"raw":{"new_facts":[]}}` + "\n"
+	if string(got) != want {
+		t.Errorf("ConvertKeys() = %s, want %s", got, want)
+	}
+
+	back, err := ConvertKeys(got, fields.toSnake)
+	if err != nil {
+		t.Fatalf("ConvertKeys() error = %v", err)
+	}
+	if want := `{"duration_ms":5,"arguments":[{"fact_type":"x"}],"by_name":{"data_set":{"fact_type":"y"}},"raw":{"new_facts":[]}}` + "\n"; string(back) != want {
+		t.Errorf("ConvertKeys() = %s, want %s", back, want)
+	}
+
+	if _, err := ConvertKeys([]byte(`{"a": [1, 2}`), ToCamel); err == nil {
+		t.Error("ConvertKeys() succeeded for malformed JSON")
+	}
+}
+
+func TestToCamel(t *testing.T) {
+	for key, want := range map[string]string{
+		"duration_ms":  "durationMs",
+		"request_type": "requestType",
+		"organization": "organization",
+		"_private_key": "_privateKey",
+	} {
+		if got := ToCamel(key); got != want {
+			t.Errorf("ToCamel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestToSnake(t *testing.T) {
+	for key, want := range map[string]string{
+		"durationMs":   "duration_ms",
+		"requestType":  "request_type",
+		"modelURL":     "model_url",
+		"organization": "organization",
+		"data_set":     "data_set",
+	} {
+		if got := ToSnake(key); got != want {
+			t.Errorf("ToSnake(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(FieldsOf(testRequest{}, testResponse{})))
+	e.POST("/echo", func(c echo.Context) error {
+		var req testRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string]any{
+			"request_type": req.RequestType,
+			"labels":       req.Labels,
+			"query":        c.QueryParam("request_type"),
+		})
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		body   string
+		want   string
+	}{
+		{
+			name:   "camel",
+			target: "/echo?case=camel&requestType=q",
+			body:   `{"requestType": "sqlDataRequest", "labels": {"cost_center": "a", "dataSet": "b"}}`,
+			want:   `{"labels":{"cost_center":"a","dataSet":"b"},"query":"q","requestType":"sqlDataRequest"}`,
+		},
+		{
+			name:   "camel via Accept",
+			target: "/echo",
+			accept: "application/json; case=camel",
+			body:   `{"request_type": "sqlDataRequest"}`,
+			want:   `{"labels":null,"query":"","requestType":"sqlDataRequest"}`,
+		},
+		{
+			name:   "snake",
+			target: "/echo?request_type=q",
+			body:   `{"request_type": "sqlDataRequest", "labels": {"cost_center": "a"}}`,
+			want:   `{"labels":{"cost_center":"a"},"query":"q","request_type":"sqlDataRequest"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			body, _ := io.ReadAll(rec.Body)
+			if rec.Code != http.StatusOK || strings.TrimSpace(string(body)) != tt.want {
+				t.Errorf("response = %d %s, want 200 %s", rec.Code, body, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRejectsInvalidBody(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(Fields{}))
+	e.POST("/echo", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodPost, "/echo?case=camel", strings.NewReader(`{"a":`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
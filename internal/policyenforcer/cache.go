@@ -0,0 +1,145 @@
+package policyenforcer
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Allowed Clauses Cache
+// -----------------------------------------------------------------------------
+
+// CacheConfig configures the LRU+TTL cache for allowed-clause queries.
+type CacheConfig struct {
+	Size int           // Maximum number of cached (organization, requester) entries; 0 disables the cache
+	TTL  time.Duration // Maximum age of a cached entry
+}
+
+// DefaultCacheConfig returns the default cache settings (disabled by default).
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Size: 0,
+		TTL:  30 * time.Second,
+	}
+}
+
+// cacheMetrics exposes cache hits and misses via expvar (served at /debug/vars).
+var cacheMetrics = expvar.NewMap("policy_enforcer_clause_cache")
+
+func init() {
+	cacheMetrics.Set("hit_ratio", expvar.Func(func() any {
+		hits := metricValue(cacheMetrics, "hits")
+		total := hits + metricValue(cacheMetrics, "misses")
+		if total == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(total)
+	}))
+}
+
+// metricValue returns the value of an integer metric in an expvar map.
+func metricValue(m *expvar.Map, key string) int64 {
+	if v, ok := m.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// cacheEntry is a single cached AllAllowedClausesResponse.
+type cacheEntry struct {
+	key      string
+	value    *AllAllowedClausesResponse
+	storedAt time.Time
+}
+
+// clauseCache is an LRU cache with a TTL for AllAllowedClausesResponse values,
+// keyed by (organization, requester). A generation counter prevents results
+// fetched before an invalidation from being stored afterwards.
+// Thread-safe for concurrent access.
+type clauseCache struct {
+	config     CacheConfig
+	entries    map[string]*list.Element
+	order      *list.List // Front is most recently used
+	generation uint64
+	mu         sync.Mutex
+}
+
+// newClauseCache creates a cache, or returns nil if the cache is disabled.
+func newClauseCache(config CacheConfig) *clauseCache {
+	if config.Size <= 0 {
+		return nil
+	}
+	return &clauseCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// cacheKey builds the cache key for an (organization, requester) pair.
+func cacheKey(organization, requester string) string {
+	return organization + "\x00" + requester
+}
+
+// get returns a cached response and the current generation.
+// The generation must be passed to put when storing a freshly fetched value.
+func (c *clauseCache) get(key string) (*AllAllowedClausesResponse, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok {
+		entry := elem.Value.(*cacheEntry)
+		if time.Since(entry.storedAt) < c.config.TTL {
+			c.order.MoveToFront(elem)
+			cacheMetrics.Add("hits", 1)
+			return entry.value, c.generation, true
+		}
+		c.removeElement(elem)
+	}
+
+	cacheMetrics.Add("misses", 1)
+	return nil, c.generation, false
+}
+
+// put stores a response unless the cache was invalidated since generation was obtained.
+func (c *clauseCache) put(key string, value *AllAllowedClausesResponse, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.storedAt = time.Now()
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, storedAt: time.Now()})
+	for c.order.Len() > c.config.Size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// invalidate removes all entries and starts a new generation.
+func (c *clauseCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	cacheMetrics.Add("invalidations", 1)
+}
+
+// removeElement removes an element from the cache. Caller must hold mu.
+func (c *clauseCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,115 @@
+package policyenforcer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+func TestClauseCacheCountsHitsAndMisses(t *testing.T) {
+	cache := newClauseCache(CacheConfig{Size: 2, TTL: time.Minute})
+	hits, misses := metricValue(cacheMetrics, "hits"), metricValue(cacheMetrics, "misses")
+
+	key := cacheKey("VU", "user@example.com")
+	_, generation, ok := cache.get(key)
+	if ok {
+		t.Fatal("get() hit in an empty cache")
+	}
+	value := &AllAllowedClausesResponse{Organization: "VU"}
+	cache.put(key, value, generation)
+	if got, _, ok := cache.get(key); !ok || got != value {
+		t.Errorf("get() = %v, %v after put, want the stored value", got, ok)
+	}
+
+	if n := metricValue(cacheMetrics, "hits") - hits; n != 1 {
+		t.Errorf("%d hits counted, want 1", n)
+	}
+	if n := metricValue(cacheMetrics, "misses") - misses; n != 1 {
+		t.Errorf("%d misses counted, want 1", n)
+	}
+}
+
+func TestClauseCacheExpiresEntries(t *testing.T) {
+	cache := newClauseCache(CacheConfig{Size: 2, TTL: 20 * time.Millisecond})
+	key := cacheKey("VU", "user@example.com")
+	cache.put(key, &AllAllowedClausesResponse{}, 0)
+
+	time.Sleep(40 * time.Millisecond)
+	if _, _, ok := cache.get(key); ok {
+		t.Error("get() hit an entry older than the TTL")
+	}
+	if len(cache.entries) != 0 || cache.order.Len() != 0 {
+		t.Error("an expired entry was kept after get()")
+	}
+}
+
+func TestClauseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newClauseCache(CacheConfig{Size: 2, TTL: time.Minute})
+	for _, key := range []string{"a", "b"} {
+		cache.put(key, &AllAllowedClausesResponse{}, 0)
+	}
+	cache.get("a") // b is now the least recently used
+	cache.put("c", &AllAllowedClausesResponse{}, 0)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, _, ok := cache.get(key); ok != want {
+			t.Errorf("get(%s) hit = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestClauseCacheDropsResultsFetchedBeforeInvalidation(t *testing.T) {
+	cache := newClauseCache(CacheConfig{Size: 2, TTL: time.Minute})
+	_, generation, _ := cache.get("a")
+	cache.invalidate()
+
+	cache.put("a", &AllAllowedClausesResponse{}, generation)
+	if _, _, ok := cache.get("a"); ok {
+		t.Error("get() hit a result fetched before the invalidation")
+	}
+}
+
+// notifyingReasoner is a fakeReasoner that counts allowed-clause fetches and
+// reports state changes to the callback registered with OnChange.
+type notifyingReasoner struct {
+	fakeReasoner
+	fetches  atomic.Int32
+	onChange func()
+}
+
+func (r *notifyingReasoner) GetAllAllowedClauses(ctx context.Context, organization, requester string) (*reasoner.AllAllowedClauses, error) {
+	r.fetches.Add(1)
+	return r.fakeReasoner.GetAllAllowedClauses(ctx, organization, requester)
+}
+
+func (r *notifyingReasoner) OnChange(callback func()) func() {
+	r.onChange = callback
+	return func() {}
+}
+
+func TestEnforcerCacheInvalidatedOnChange(t *testing.T) {
+	r := &notifyingReasoner{}
+	e := NewEnforcer(r, &EnforcerConfig{Cache: CacheConfig{Size: 10, TTL: time.Minute}}, zap.NewNop())
+
+	for range 2 {
+		if _, err := e.GetAllAllowedClauses(context.Background(), "VU", "user@example.com"); err != nil {
+			t.Fatalf("GetAllAllowedClauses() error = %v", err)
+		}
+	}
+	if n := r.fetches.Load(); n != 1 {
+		t.Fatalf("%d fetches for two identical queries, want 1", n)
+	}
+
+	r.onChange()
+	if _, err := e.GetAllAllowedClauses(context.Background(), "VU", "user@example.com"); err != nil {
+		t.Fatalf("GetAllAllowedClauses() error = %v", err)
+	}
+	if n := r.fetches.Load(); n != 2 {
+		t.Errorf("%d fetches after a state change, want the query fetched again", n)
+	}
+}
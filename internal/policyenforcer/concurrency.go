@@ -0,0 +1,110 @@
+package policyenforcer
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+)
+
+// -----------------------------------------------------------------------------
+// Concurrency Limit
+// -----------------------------------------------------------------------------
+
+// ConcurrencyConfig limits how many validations and facts fetches run against the
+// reasoner at once. The eFLINT server handles one command at a time, so letting
+// every request through only makes them all slower.
+type ConcurrencyConfig struct {
+	MaxInFlight  int           // Operations running at once; 0 disables the limit
+	QueueSize    int           // Operations waiting for a slot; further ones get 503
+	QueueTimeout time.Duration // How long an operation waits for a slot before it gets 503
+}
+
+// DefaultConcurrencyConfig returns a conservative limit for a single eFLINT server.
+func DefaultConcurrencyConfig() ConcurrencyConfig {
+	return ConcurrencyConfig{
+		MaxInFlight:  4,
+		QueueSize:    32,
+		QueueTimeout: 2 * time.Second,
+	}
+}
+
+// CodeOverloaded is returned when the concurrency limit rejects an operation.
+const CodeOverloaded = "overloaded"
+
+// concurrencyMetrics exposes in-flight and queued operations via expvar (served at /debug/vars).
+var concurrencyMetrics = expvar.NewMap("policy_enforcer_concurrency")
+
+// concurrencyLimiter is a semaphore with a bounded wait queue.
+// Thread-safe for concurrent access.
+type concurrencyLimiter struct {
+	config  ConcurrencyConfig
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+// newConcurrencyLimiter creates a limiter, or returns nil if the limit is disabled.
+func newConcurrencyLimiter(config ConcurrencyConfig) *concurrencyLimiter {
+	if config.MaxInFlight <= 0 {
+		return nil
+	}
+	return &concurrencyLimiter{
+		config: config,
+		slots:  make(chan struct{}, config.MaxInFlight),
+	}
+}
+
+// acquire takes a slot, waiting up to QueueTimeout if all slots are taken. The
+// returned function releases the slot. A nil limiter always succeeds.
+func (l *concurrencyLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.acquired(), nil
+	default:
+	}
+
+	if l.waiting.Add(1) > int64(l.config.QueueSize) {
+		l.waiting.Add(-1)
+		return nil, l.overloaded("too many concurrent reasoner operations")
+	}
+	concurrencyMetrics.Add("queued", 1)
+	defer func() {
+		l.waiting.Add(-1)
+		concurrencyMetrics.Add("queued", -1)
+	}()
+
+	timer := time.NewTimer(l.config.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.acquired(), nil
+	case <-timer.C:
+		return nil, l.overloaded("timed out waiting for a free reasoner slot")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// acquired records a taken slot and returns the function that releases it.
+func (l *concurrencyLimiter) acquired() func() {
+	concurrencyMetrics.Add("in_flight", 1)
+	return func() {
+		concurrencyMetrics.Add("in_flight", -1)
+		<-l.slots
+	}
+}
+
+// overloaded returns a 503 telling the client to retry after the queue timeout.
+func (l *concurrencyLimiter) overloaded(message string) error {
+	concurrencyMetrics.Add("rejected", 1)
+	return apperr.New(http.StatusServiceUnavailable, CodeOverloaded, message).
+		WithRetryAfter(max(l.config.QueueTimeout, time.Second))
+}
@@ -0,0 +1,78 @@
+package policyenforcer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+)
+
+// blockingReasoner is a fakeReasoner whose first allowed-archetypes query closes
+// started and holds its caller until release is closed, and which can look up
+// requesters.
+type blockingReasoner struct {
+	fakeReasoner
+	held    atomic.Bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingReasoner) GetAllowedArchetypes(context.Context, string, string) ([]string, error) {
+	if r.held.CompareAndSwap(false, true) {
+		close(r.started)
+		<-r.release
+	}
+	return nil, nil
+}
+
+func (r *blockingReasoner) GetRequestersAllowed(context.Context, string, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func TestFactsFetchesShareTheConcurrencyLimit(t *testing.T) {
+	r := &blockingReasoner{fakeReasoner: fakeReasoner{validate: permitAll}, started: make(chan struct{}), release: make(chan struct{})}
+	releaseOnce := sync.OnceFunc(func() { close(r.release) })
+	t.Cleanup(releaseOnce)
+	e, _ := newTestServerWithEnforcer(t, r, &EnforcerConfig{
+		Concurrency: ConcurrencyConfig{MaxInFlight: 1, QueueSize: 0, QueueTimeout: time.Second},
+	}, nil)
+
+	// The only slot is held by an allowed-archetypes query
+	done := make(chan int, 1)
+	go func() {
+		done <- serve(e, http.MethodGet, "/policy-enforcer/allowed-archetypes?organization=VU&requester=user@example.com", "", nil).Code
+	}()
+	<-r.started
+
+	for _, target := range []string{
+		"/policy-enforcer/allowed-archetypes?organization=VU&requester=user@example.com",
+		"/policy-enforcer/allowed-request-types?organization=VU&requester=user@example.com",
+		"/policy-enforcer/allowed-clauses?organization=VU&requester=user@example.com",
+		"/policy-enforcer/who-can?organization=VU&type=archetype&value=computeToData",
+	} {
+		rec := serve(e, http.MethodGet, target, "", nil)
+		var resp apperr.Response
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusServiceUnavailable || resp.Code != CodeOverloaded {
+			t.Errorf("GET %s = %d %s while the limit is reached, want 503 %s", target, rec.Code, rec.Body, CodeOverloaded)
+		}
+		if rec.Header().Get(echo.HeaderRetryAfter) == "" {
+			t.Errorf("GET %s has no Retry-After header", target)
+		}
+	}
+
+	releaseOnce()
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("GET /allowed-archetypes holding the slot = %d, want 200", code)
+	}
+	if rec := serve(e, http.MethodGet, "/policy-enforcer/who-can?organization=VU&type=archetype&value=computeToData", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /who-can after the slot was released = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
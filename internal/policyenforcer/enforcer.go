@@ -2,11 +2,18 @@ package policyenforcer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
 )
 
 // -----------------------------------------------------------------------------
@@ -18,22 +25,102 @@ import (
 // making it independent of the underlying reasoning engine (eFLINT, Symboleo, etc.).
 type Enforcer struct {
 	reasoner reasoner.Reasoner
+	cache    *clauseCache        // nil when caching is disabled
+	limiter  *concurrencyLimiter // nil when concurrency is not limited
 	logger   *zap.Logger
 }
 
+// EnforcerConfig holds configuration for the policy enforcer.
+type EnforcerConfig struct {
+	Cache       CacheConfig       // Cache for allowed-clause queries
+	Concurrency ConcurrencyConfig // Limit on concurrent validations and facts fetches
+}
+
+// DefaultEnforcerConfig returns the default enforcer configuration.
+func DefaultEnforcerConfig() *EnforcerConfig {
+	return &EnforcerConfig{
+		Cache:       DefaultCacheConfig(),
+		Concurrency: DefaultConcurrencyConfig(),
+	}
+}
+
 // NewEnforcer creates a new policy enforcer with the given reasoner.
-func NewEnforcer(r reasoner.Reasoner, logger *zap.Logger) *Enforcer {
-	return &Enforcer{
+// If config is nil, default configuration is used.
+//
+// When the cache is enabled and the reasoner implements reasoner.ChangeNotifier,
+// the cache is invalidated whenever the reasoner state changes. Otherwise cached
+// entries only expire through their TTL.
+func NewEnforcer(r reasoner.Reasoner, config *EnforcerConfig, logger *zap.Logger) *Enforcer {
+	if config == nil {
+		config = DefaultEnforcerConfig()
+	}
+
+	e := &Enforcer{
 		reasoner: r,
+		cache:    newClauseCache(config.Cache),
+		limiter:  newConcurrencyLimiter(config.Concurrency),
 		logger:   logger,
 	}
+
+	if e.cache != nil {
+		if notifier, ok := r.(reasoner.ChangeNotifier); ok {
+			notifier.OnChange(e.cache.invalidate)
+		} else {
+			logger.Warn("reasoner does not report state changes; cached clauses expire by TTL only",
+				zap.Duration("ttl", config.Cache.TTL),
+			)
+		}
+	}
+
+	return e
+}
+
+// log returns the logger for a call, tagged with the request ID if ctx carries one.
+func (e *Enforcer) log(ctx context.Context) *zap.Logger {
+	return requestid.Logger(ctx, e.logger)
 }
 
 // GetReasonerInfo returns information about the active reasoner.
 func (e *Enforcer) GetReasonerInfo() ReasonerInfoResponse {
+	readOnly, _ := e.reasoner.(reasoner.ReadOnlyReporter)
 	return ReasonerInfoResponse{
-		Name:    e.reasoner.Name(),
-		Running: e.reasoner.IsRunning(),
+		Name:         e.reasoner.Name(),
+		Running:      e.reasoner.IsRunning(),
+		ReadOnly:     readOnly != nil && readOnly.ReadOnly(),
+		Capabilities: capabilitiesOf(e.reasoner),
+	}
+}
+
+// capabilitiesOf reports which optional reasoner interfaces r implements.
+func capabilitiesOf(r reasoner.Reasoner) ReasonerCapabilities {
+	_, availability := r.(reasoner.AvailabilityProvider)
+	_, stateManagement := r.(reasoner.StateManager)
+	_, factsIntrospection := r.(reasoner.FactsProvider)
+	_, revocation := r.(reasoner.ClauseRevoker)
+	_, requesterLookup := r.(reasoner.RequesterLookup)
+	_, policyExport := r.(reasoner.PolicyExporter)
+	_, batchQueries := r.(reasoner.BatchClauseProvider)
+	_, changeNotifications := r.(reasoner.ChangeNotifier)
+	_, changeSimulation := r.(reasoner.ChangeSimulator)
+	_, actExecution := r.(reasoner.ActExecutor)
+	_, enabledActs := r.(reasoner.EnabledActsProvider)
+	_, changeTracking := r.(reasoner.ChangeTracker)
+	_, clauseTypes := r.(reasoner.ClauseTypeRegistry)
+
+	return ReasonerCapabilities{
+		Availability:        availability,
+		StateManagement:     stateManagement,
+		FactsIntrospection:  factsIntrospection,
+		Revocation:          revocation,
+		RequesterLookup:     requesterLookup,
+		PolicyExport:        policyExport,
+		BatchQueries:        batchQueries,
+		ChangeNotifications: changeNotifications,
+		ChangeSimulation:    changeSimulation,
+		ActExecution:        actExecution,
+		EnabledActs:         enabledActs,
+		ChangeTracking:      changeTracking,
+		ClauseTypes:         clauseTypes,
 	}
 }
 
@@ -42,6 +129,68 @@ func (e *Enforcer) IsRunning() bool {
 	return e.reasoner.IsRunning()
 }
 
+// Error codes for failures specific to the policy enforcer.
+const (
+	CodeReasonerNotRunning = "reasoner_not_running"
+	CodeReasonerError      = "reasoner_error"
+	CodeUnknownClauseType  = "unknown_clause_type"
+	CodeCheckpointNotFound = "checkpoint_not_found"
+	CodeCheckpointNoFacts  = "checkpoint_without_facts"
+	CodeInvalidQuery       = "invalid_query"
+	CodeReasonerRuntime    = "reasoner_runtime_error"
+	CodeReadOnly           = eflint.CodeReadOnly
+)
+
+// appError converts an error from the reasoner into an *apperr.Error, so that
+// callers on the HTTP layer can return it as is. The original error stays
+// available via errors.Is. Errors that are already converted are returned as is.
+func (e *Enforcer) appError(err error) error {
+	if _, ok := err.(*apperr.Error); ok || err == nil {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, reasoner.ErrNotSupported):
+		return apperr.Wrap(err, http.StatusNotImplemented, apperr.CodeNotImplemented,
+			fmt.Sprintf("%v; see capabilities in GET /policy-enforcer/info", err))
+	case errors.Is(err, reasoner.ErrReasonerNotRunning), !e.reasoner.IsRunning():
+		return apperr.Wrap(err, http.StatusServiceUnavailable, CodeReasonerNotRunning, "reasoner is not running")
+	case errors.Is(err, context.DeadlineExceeded):
+		// The caller's per-request timeout (or the global one) expired
+		return apperr.Wrap(err, http.StatusGatewayTimeout, apperr.CodeTimeout, "reasoner did not answer in time")
+	case errors.Is(err, eflint.ErrReadOnly):
+		return apperr.Wrap(err, http.StatusForbidden, CodeReadOnly, err.Error())
+	case errors.Is(err, reasoner.ErrUnknownClauseType):
+		return apperr.Wrap(err, http.StatusBadRequest, CodeUnknownClauseType, err.Error())
+	case errors.Is(err, eflint.ErrInvalidTypeName):
+		return apperr.Wrap(err, http.StatusBadRequest, apperr.CodeBadRequest, err.Error())
+	case errors.Is(err, reasoner.ErrCheckpointNotFound):
+		return apperr.Wrap(err, http.StatusNotFound, CodeCheckpointNotFound, err.Error())
+	case errors.Is(err, reasoner.ErrCheckpointWithoutFacts):
+		return apperr.Wrap(err, http.StatusUnprocessableEntity, CodeCheckpointNoFacts, err.Error())
+	case errors.Is(err, reasoner.ErrFactsFetchFailed), errors.Is(err, reasoner.ErrValidationFailed),
+		errors.Is(err, reasoner.ErrRevokeFailed), errors.Is(err, reasoner.ErrAssertFailed),
+		errors.Is(err, reasoner.ErrCheckpointFailed), errors.Is(err, reasoner.ErrActFailed),
+		errors.Is(err, reasoner.ErrEnabledActsFailed):
+		// The reasoner backend failed to answer; this is an upstream problem
+		return apperr.Wrap(err, http.StatusBadGateway, CodeReasonerError, err.Error())
+	}
+	return apperr.Internal(err)
+}
+
+// validationError converts a validation result in which the reasoner reported an
+// error into an *apperr.Error: parse and type errors mean the request did not fit
+// the model (400), runtime errors mean evaluating it failed (500).
+func validationError(result *reasoner.RequestValidationResult) error {
+	switch result.ErrorCategory {
+	case reasoner.ErrorCategoryParse, reasoner.ErrorCategoryType:
+		return apperr.New(http.StatusBadRequest, CodeInvalidQuery,
+			fmt.Sprintf("reasoner rejected the request (%s error): %s", result.ErrorCategory, result.Reason))
+	}
+	return apperr.New(http.StatusInternalServerError, CodeReasonerRuntime,
+		fmt.Sprintf("reasoner failed to evaluate the request: %s", result.Reason))
+}
+
 // -----------------------------------------------------------------------------
 // Allowed Clauses Retrieval
 // -----------------------------------------------------------------------------
@@ -49,17 +198,23 @@ func (e *Enforcer) IsRunning() bool {
 // GetAllowedRequestTypes returns all request types allowed for a requester at an organization.
 func (e *Enforcer) GetAllowedRequestTypes(ctx context.Context, organization, requester string) (*AllowedClausesResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
 	}
+	defer release()
 
 	values, err := e.reasoner.GetAllowedRequestTypes(ctx, organization, requester)
 	if err != nil {
-		e.logger.Error("failed to get allowed request types",
+		e.log(ctx).Error("failed to get allowed request types",
 			zap.String("organization", organization),
 			zap.String("requester", requester),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, e.appError(err)
 	}
 
 	return &AllowedClausesResponse{
@@ -72,17 +227,23 @@ func (e *Enforcer) GetAllowedRequestTypes(ctx context.Context, organization, req
 // GetAllowedDataSets returns all datasets allowed for a requester at an organization.
 func (e *Enforcer) GetAllowedDataSets(ctx context.Context, organization, requester string) (*AllowedClausesResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
 	}
+	defer release()
 
 	values, err := e.reasoner.GetAllowedDataSets(ctx, organization, requester)
 	if err != nil {
-		e.logger.Error("failed to get allowed data sets",
+		e.log(ctx).Error("failed to get allowed data sets",
 			zap.String("organization", organization),
 			zap.String("requester", requester),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, e.appError(err)
 	}
 
 	return &AllowedClausesResponse{
@@ -95,17 +256,23 @@ func (e *Enforcer) GetAllowedDataSets(ctx context.Context, organization, request
 // GetAllowedArchetypes returns all archetypes allowed for a requester at an organization.
 func (e *Enforcer) GetAllowedArchetypes(ctx context.Context, organization, requester string) (*AllowedClausesResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
 	}
+	defer release()
 
 	values, err := e.reasoner.GetAllowedArchetypes(ctx, organization, requester)
 	if err != nil {
-		e.logger.Error("failed to get allowed archetypes",
+		e.log(ctx).Error("failed to get allowed archetypes",
 			zap.String("organization", organization),
 			zap.String("requester", requester),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, e.appError(err)
 	}
 
 	return &AllowedClausesResponse{
@@ -118,17 +285,23 @@ func (e *Enforcer) GetAllowedArchetypes(ctx context.Context, organization, reque
 // GetAllowedComputeProviders returns all compute providers allowed for a requester at an organization.
 func (e *Enforcer) GetAllowedComputeProviders(ctx context.Context, organization, requester string) (*AllowedClausesResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
 	}
 
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
 	values, err := e.reasoner.GetAllowedComputeProviders(ctx, organization, requester)
 	if err != nil {
-		e.logger.Error("failed to get allowed compute providers",
+		e.log(ctx).Error("failed to get allowed compute providers",
 			zap.String("organization", organization),
 			zap.String("requester", requester),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, e.appError(err)
 	}
 
 	return &AllowedClausesResponse{
@@ -138,32 +311,413 @@ func (e *Enforcer) GetAllowedComputeProviders(ctx context.Context, organization,
 	}, nil
 }
 
+// GetAllowedClausesOfType returns the values of a clause type allowed for a requester
+// at an organization. Clause types are selected by name, so types registered by
+// configuration can be queried without a dedicated method.
+// This only works if the underlying reasoner supports the ClauseTypeRegistry interface.
+func (e *Enforcer) GetAllowedClausesOfType(ctx context.Context, organization, requester, clauseType string) (*AllowedClausesResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	registry, ok := e.reasoner.(reasoner.ClauseTypeRegistry)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: clause types by name", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	values, err := registry.GetAllowedClauses(ctx, organization, requester, clauseType)
+	if errors.Is(err, reasoner.ErrUnknownClauseType) {
+		var names []string
+		for _, t := range registry.ClauseTypes() {
+			names = append(names, t.Name)
+		}
+		return nil, e.appError(fmt.Errorf("%w (registered: %s)", err, strings.Join(names, ", ")))
+	}
+	if err != nil {
+		e.log(ctx).Error("failed to get allowed clauses of type",
+			zap.String("organization", organization),
+			zap.String("requester", requester),
+			zap.String("clause_type", clauseType),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	return &AllowedClausesResponse{
+		Organization: organization,
+		Requester:    requester,
+		Type:         clauseType,
+		Values:       values,
+	}, nil
+}
+
 // GetAllAllowedClauses returns all allowed clauses for a requester at an organization.
 // This is more efficient than calling individual methods because it fetches facts
 // from the reasoner only once.
 func (e *Enforcer) GetAllAllowedClauses(ctx context.Context, organization, requester string) (*AllAllowedClausesResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
 	}
 
+	var key string
+	var generation uint64
+	if e.cache != nil {
+		key = cacheKey(organization, requester)
+		cached, gen, ok := e.cache.get(key)
+		if ok {
+			return cached, nil
+		}
+		generation = gen
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
 	// Use the optimized method that fetches facts once
 	clauses, err := e.reasoner.GetAllAllowedClauses(ctx, organization, requester)
 	if err != nil {
-		e.logger.Error("failed to get all allowed clauses",
+		e.log(ctx).Error("failed to get all allowed clauses",
 			zap.String("organization", organization),
 			zap.String("requester", requester),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, e.appError(err)
 	}
 
-	return &AllAllowedClausesResponse{
+	response := &AllAllowedClausesResponse{
 		Organization:     organization,
 		Requester:        requester,
 		RequestTypes:     clauses.RequestTypes,
 		DataSets:         clauses.DataSets,
 		Archetypes:       clauses.Archetypes,
 		ComputeProviders: clauses.ComputeProviders,
+	}
+
+	if e.cache != nil {
+		e.cache.put(key, response, generation)
+	}
+
+	return response, nil
+}
+
+// GetAllAllowedClausesBatch returns all allowed clauses for many (organization, requester)
+// pairs. Cached pairs are served from the cache; for the rest, reasoners implementing
+// reasoner.BatchClauseProvider are queried once (for eFLINT: a single facts fetch).
+// Other reasoners fall back to one GetAllAllowedClauses call per pair.
+func (e *Enforcer) GetAllAllowedClausesBatch(ctx context.Context, pairs []reasoner.OrgRequester) (map[reasoner.OrgRequester]*AllAllowedClausesResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	results := make(map[reasoner.OrgRequester]*AllAllowedClausesResponse, len(pairs))
+	generations := make(map[reasoner.OrgRequester]uint64)
+	var missing []reasoner.OrgRequester
+	for _, pair := range pairs {
+		if _, seen := results[pair]; seen {
+			continue
+		}
+		if _, seen := generations[pair]; seen {
+			continue
+		}
+		if e.cache != nil {
+			cached, gen, ok := e.cache.get(cacheKey(pair.Organization, pair.Requester))
+			if ok {
+				results[pair] = cached
+				continue
+			}
+			generations[pair] = gen
+		} else {
+			generations[pair] = 0
+		}
+		missing = append(missing, pair)
+	}
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	batcher, ok := e.reasoner.(reasoner.BatchClauseProvider)
+	if !ok {
+		for _, pair := range missing {
+			response, err := e.GetAllAllowedClauses(ctx, pair.Organization, pair.Requester)
+			if err != nil {
+				return nil, e.appError(err)
+			}
+			results[pair] = response
+		}
+		return results, nil
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	clauses, err := batcher.GetAllAllowedClausesBatch(ctx, missing)
+	release()
+	if err != nil {
+		e.log(ctx).Error("failed to get allowed clauses batch",
+			zap.Int("pairs", len(missing)),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	for _, pair := range missing {
+		c := clauses[pair]
+		if c == nil {
+			c = &reasoner.AllAllowedClauses{}
+		}
+		response := &AllAllowedClausesResponse{
+			Organization:     pair.Organization,
+			Requester:        pair.Requester,
+			RequestTypes:     c.RequestTypes,
+			DataSets:         c.DataSets,
+			Archetypes:       c.Archetypes,
+			ComputeProviders: c.ComputeProviders,
+		}
+		if e.cache != nil {
+			e.cache.put(cacheKey(pair.Organization, pair.Requester), response, generations[pair])
+		}
+		results[pair] = response
+	}
+
+	return results, nil
+}
+
+// IsClauseAllowed reports whether a requester at an organization is allowed a single
+// clause value, e.g. whether archetype "computeToData" is allowed. It is answered
+// from GetAllAllowedClauses, so it is served from the cache when enabled.
+func (e *Enforcer) IsClauseAllowed(ctx context.Context, organization, requester, clauseType, value string) (bool, error) {
+	// Reject unknown clause types before querying the reasoner
+	if _, err := clauseValues(&AllAllowedClausesResponse{}, clauseType); err != nil {
+		return false, e.appError(err)
+	}
+
+	clauses, err := e.GetAllAllowedClauses(ctx, organization, requester)
+	if err != nil {
+		return false, e.appError(err)
+	}
+
+	values, err := clauseValues(clauses, clauseType)
+	if err != nil {
+		return false, e.appError(err)
+	}
+	return slices.Contains(values, value), nil
+}
+
+// clauseValues returns the allowed values of a single clause type.
+func clauseValues(clauses *AllAllowedClausesResponse, clauseType string) ([]string, error) {
+	switch clauseType {
+	case reasoner.ClauseRequestType:
+		return clauses.RequestTypes, nil
+	case reasoner.ClauseDataSet:
+		return clauses.DataSets, nil
+	case reasoner.ClauseArchetype:
+		return clauses.Archetypes, nil
+	case reasoner.ClauseComputeProvider:
+		return clauses.ComputeProviders, nil
+	}
+	return nil, fmt.Errorf("%w: %q", reasoner.ErrUnknownClauseType, clauseType)
+}
+
+// getAllowedValues returns the allowed values for a single clause type.
+func (e *Enforcer) getAllowedValues(ctx context.Context, organization, requester, clauseType string) ([]string, error) {
+	switch clauseType {
+	case reasoner.ClauseRequestType:
+		return e.reasoner.GetAllowedRequestTypes(ctx, organization, requester)
+	case reasoner.ClauseDataSet:
+		return e.reasoner.GetAllowedDataSets(ctx, organization, requester)
+	case reasoner.ClauseArchetype:
+		return e.reasoner.GetAllowedArchetypes(ctx, organization, requester)
+	case reasoner.ClauseComputeProvider:
+		return e.reasoner.GetAllowedComputeProviders(ctx, organization, requester)
+	}
+	return nil, fmt.Errorf("%w: %q", reasoner.ErrUnknownClauseType, clauseType)
+}
+
+// -----------------------------------------------------------------------------
+// Reverse Lookup (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// GetRequestersAllowed returns all requesters at an organization that are allowed a
+// specific clause value (e.g. "who is allowed archetype computeToData at VU?").
+// This only works if the underlying reasoner supports the RequesterLookup interface.
+func (e *Enforcer) GetRequestersAllowed(ctx context.Context, organization, clauseType, value string) (*RequestersAllowedResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	rl, ok := e.reasoner.(reasoner.RequesterLookup)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: requester lookups", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	requesters, err := rl.GetRequestersAllowed(ctx, organization, clauseType, value)
+	if err != nil {
+		e.log(ctx).Error("failed to get requesters allowed",
+			zap.String("organization", organization),
+			zap.String("type", clauseType),
+			zap.String("value", value),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	return &RequestersAllowedResponse{
+		Organization: organization,
+		Type:         clauseType,
+		Value:        value,
+		Requesters:   requesters,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Revocation (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// RevokeAllowedClause revokes a single allowed clause and confirms, by re-querying
+// the reasoner, that the clause no longer holds.
+// This only works if the underlying reasoner supports the ClauseRevoker interface.
+func (e *Enforcer) RevokeAllowedClause(ctx context.Context, organization, requester, clauseType, value string) (*RevokeClauseResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	rv, ok := e.reasoner.(reasoner.ClauseRevoker)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: revocation", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	if err := rv.RevokeAllowedClause(ctx, organization, requester, clauseType, value); err != nil {
+		e.log(ctx).Error("failed to revoke allowed clause",
+			zap.String("organization", organization),
+			zap.String("requester", requester),
+			zap.String("type", clauseType),
+			zap.String("value", value),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	// Confirm the revocation took effect
+	values, err := e.getAllowedValues(ctx, organization, requester, clauseType)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+
+	stillAllowed := false
+	for _, v := range values {
+		if v == value {
+			stillAllowed = true
+			break
+		}
+	}
+
+	e.log(ctx).Info("revoked allowed clause",
+		zap.String("organization", organization),
+		zap.String("requester", requester),
+		zap.String("type", clauseType),
+		zap.String("value", value),
+		zap.Bool("still_allowed", stillAllowed),
+	)
+
+	return &RevokeClauseResponse{
+		Organization: organization,
+		Requester:    requester,
+		Type:         clauseType,
+		Value:        value,
+		Revoked:      !stillAllowed,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Policy Export / Import (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// ExportOrgPolicy returns everything an organization has granted, for all requesters,
+// plus its available resources, as a document that ImportOrgPolicy accepts.
+// This only works if the underlying reasoner supports the PolicyExporter interface.
+func (e *Enforcer) ExportOrgPolicy(ctx context.Context, organization string) (*reasoner.OrgPolicy, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	pe, ok := e.reasoner.(reasoner.PolicyExporter)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: policy export", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	policy, err := pe.ExportOrgPolicy(ctx, organization)
+	if err != nil {
+		e.log(ctx).Error("failed to export organization policy",
+			zap.String("organization", organization),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	return policy, nil
+}
+
+// ImportOrgPolicy grants every clause and availability in a previously exported
+// policy document. Import is additive: existing grants are not revoked.
+// This only works if the underlying reasoner supports the PolicyExporter interface.
+func (e *Enforcer) ImportOrgPolicy(ctx context.Context, policy *reasoner.OrgPolicy) (*ImportOrgPolicyResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	pe, ok := e.reasoner.(reasoner.PolicyExporter)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: policy import", reasoner.ErrNotSupported))
+	}
+
+	asserted, err := pe.ImportOrgPolicy(ctx, policy)
+	if err != nil {
+		e.log(ctx).Error("failed to import organization policy",
+			zap.String("organization", policy.Organization),
+			zap.Int("facts_asserted", asserted),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	e.log(ctx).Info("imported organization policy",
+		zap.String("organization", policy.Organization),
+		zap.Int("grants", len(policy.Grants)),
+		zap.Int("facts_asserted", asserted),
+	)
+
+	return &ImportOrgPolicyResponse{
+		Organization:  policy.Organization,
+		FactsAsserted: asserted,
 	}, nil
 }
 
@@ -172,12 +726,14 @@ func (e *Enforcer) GetAllAllowedClauses(ctx context.Context, organization, reque
 // -----------------------------------------------------------------------------
 
 // ValidateRequest checks if a specific request is allowed according to the policy.
-func (e *Enforcer) ValidateRequest(ctx context.Context, params *ValidateRequestParams) (*ValidationResponse, error) {
+// If suggest is set and the request is denied, the response also lists the allowed
+// values for each field that is not among the requester's allowed clauses.
+func (e *Enforcer) ValidateRequest(ctx context.Context, params *ValidateRequestParams, suggest bool) (*ValidationResponse, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
 	}
 
-	e.logger.Info("validating request",
+	e.log(ctx).Info("validating request",
 		zap.String("organization", params.Organization),
 		zap.String("requester", params.Requester),
 		zap.String("request_type", params.RequestType),
@@ -186,14 +742,28 @@ func (e *Enforcer) ValidateRequest(ctx context.Context, params *ValidateRequestP
 		zap.String("compute_provider", params.ComputeProvider),
 	)
 
-	result, err := e.reasoner.IsRequestAllowed(ctx, params.ToReasonerParams())
+	// The slot is released before suggest, which takes its own
+	release, err := e.limiter.acquire(ctx)
 	if err != nil {
-		e.logger.Error("failed to validate request", zap.Error(err))
-		return nil, err
+		return nil, e.appError(err)
+	}
+	result, err := e.reasoner.IsRequestAllowed(ctx, toReasonerParams(params))
+	release()
+	if err != nil {
+		e.log(ctx).Error("failed to validate request", zap.Error(err))
+		return nil, e.appError(err)
+	}
+	if result.ErrorCategory != "" {
+		e.log(ctx).Warn("reasoner reported an error while validating request",
+			zap.String("error_category", string(result.ErrorCategory)),
+			zap.String("reason", result.Reason),
+		)
+		return nil, validationError(result)
 	}
 
 	response := &ValidationResponse{
 		Allowed:         result.Allowed,
+		ReasonCode:      result.ReasonCode,
 		Reason:          result.Reason,
 		Organization:    params.Organization,
 		Requester:       params.Requester,
@@ -201,16 +771,160 @@ func (e *Enforcer) ValidateRequest(ctx context.Context, params *ValidateRequestP
 		DataSet:         params.DataSet,
 		Archetype:       params.Archetype,
 		ComputeProvider: params.ComputeProvider,
+		Duties:          result.Duties,
+	}
+
+	if suggest && !response.Allowed {
+		response.Suggestions = e.suggest(ctx, params)
 	}
 
-	e.logger.Info("request validation complete",
+	e.log(ctx).Info("request validation complete",
 		zap.Bool("allowed", response.Allowed),
+		zap.String("reason_code", string(response.ReasonCode)),
 		zap.String("reason", response.Reason),
 	)
 
 	return response, nil
 }
 
+// suggest returns, for each field of a denied request that is not among the
+// requester's allowed clauses, the values that are allowed instead. Failures are
+// logged and yield no suggestions, since the validation itself already succeeded.
+func (e *Enforcer) suggest(ctx context.Context, params *ValidateRequestParams) map[string][]string {
+	clauses, err := e.GetAllAllowedClauses(ctx, params.Organization, params.Requester)
+	if err != nil {
+		e.log(ctx).Warn("failed to compute suggestions for denied request", zap.Error(err))
+		return nil
+	}
+
+	suggestions := make(map[string][]string)
+	for _, check := range clauseChecks(params, clauses) {
+		if !slices.Contains(check.allowed, check.value) {
+			suggestions[check.clauseType] = check.allowed
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	return suggestions
+}
+
+// clauseCheck pairs a request field with the values allowed for its clause type.
+type clauseCheck struct {
+	clauseType string
+	value      string
+	allowed    []string
+}
+
+// clauseChecks lists the clause checks for every field of a request.
+func clauseChecks(params *ValidateRequestParams, clauses *AllAllowedClausesResponse) []clauseCheck {
+	return []clauseCheck{
+		{reasoner.ClauseRequestType, params.RequestType, clauses.RequestTypes},
+		{reasoner.ClauseDataSet, params.DataSet, clauses.DataSets},
+		{reasoner.ClauseArchetype, params.Archetype, clauses.Archetypes},
+		{reasoner.ClauseComputeProvider, params.ComputeProvider, clauses.ComputeProviders},
+	}
+}
+
+// QuickCheck approximates ValidateRequest by checking that each field of the request
+// is among the requester's allowed clauses, using a single GetAllAllowedClauses call
+// (which is served from the cache when enabled).
+//
+// This is a heuristic for fast UI gating only: it does not evaluate the agreement's
+// enabled query, so duties, violations and constraints on combinations of clauses
+// are not taken into account. ValidateRequest remains authoritative.
+func (e *Enforcer) QuickCheck(ctx context.Context, params *ValidateRequestParams) (*QuickCheckResponse, error) {
+	clauses, err := e.GetAllAllowedClauses(ctx, params.Organization, params.Requester)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+
+	var denied []string
+	for _, check := range clauseChecks(params, clauses) {
+		if !slices.Contains(check.allowed, check.value) {
+			denied = append(denied, check.clauseType)
+		}
+	}
+
+	return &QuickCheckResponse{
+		Allowed:         len(denied) == 0,
+		Approximate:     true,
+		DeniedClauses:   denied,
+		Organization:    params.Organization,
+		Requester:       params.Requester,
+		RequestType:     params.RequestType,
+		DataSet:         params.DataSet,
+		Archetype:       params.Archetype,
+		ComputeProvider: params.ComputeProvider,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Change Simulation (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// SimulateChange applies the phrases, validates the request and rolls the phrases
+// back, answering "would this request pass if these changes were made?". The
+// response reports whether the rollback restored the previous state.
+// This only works if the underlying reasoner supports the ChangeSimulator interface.
+func (e *Enforcer) SimulateChange(ctx context.Context, req *SimulateChangeRequest) (*SimulateChangeResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	cs, ok := e.reasoner.(reasoner.ChangeSimulator)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: change simulation", reasoner.ErrNotSupported))
+	}
+
+	params := &req.ValidateRequestParams
+	sim, err := cs.SimulateChange(ctx, req.Phrases, toReasonerParams(params))
+	if err != nil {
+		e.log(ctx).Error("failed to simulate change", zap.Error(err))
+		return nil, e.appError(err)
+	}
+
+	response := &SimulateChangeResponse{
+		PhrasesApplied: sim.Applied,
+		RolledBack:     sim.Rollback == reasoner.RollbackRestored,
+		Rollback:       sim.Rollback,
+	}
+	if sim.ChangeError != nil {
+		response.Error = sim.ChangeError.Error()
+	}
+	if sim.RollbackError != nil {
+		response.RollbackError = sim.RollbackError.Error()
+	}
+	if sim.Result != nil {
+		response.Validation = &ValidationResponse{
+			Allowed:         sim.Result.Allowed,
+			ReasonCode:      sim.Result.ReasonCode,
+			Reason:          sim.Result.Reason,
+			ErrorCategory:   sim.Result.ErrorCategory,
+			Organization:    params.Organization,
+			Requester:       params.Requester,
+			RequestType:     params.RequestType,
+			DataSet:         params.DataSet,
+			Archetype:       params.Archetype,
+			ComputeProvider: params.ComputeProvider,
+			Duties:          sim.Result.Duties,
+		}
+	}
+
+	logger := e.log(ctx).With(
+		zap.Int("phrases", len(req.Phrases)),
+		zap.Int("phrases_applied", sim.Applied),
+		zap.String("rollback", sim.Rollback),
+	)
+	if sim.Rollback == reasoner.RollbackFailed {
+		logger.Error("change simulation could not be rolled back", zap.Error(sim.RollbackError))
+	} else {
+		logger.Info("change simulation complete", zap.Bool("allowed", sim.Result != nil && sim.Result.Allowed))
+	}
+
+	return response, nil
+}
+
 // -----------------------------------------------------------------------------
 // Availability (if supported by the reasoner)
 // -----------------------------------------------------------------------------
@@ -219,28 +933,309 @@ func (e *Enforcer) ValidateRequest(ctx context.Context, params *ValidateRequestP
 // This only works if the underlying reasoner supports the AvailabilityProvider interface.
 func (e *Enforcer) GetAvailableArchetypes(ctx context.Context, organization string) ([]string, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
 	}
 
 	ap, ok := e.reasoner.(reasoner.AvailabilityProvider)
 	if !ok {
-		return nil, fmt.Errorf("reasoner does not support availability queries")
+		return nil, e.appError(fmt.Errorf("%w: availability queries", reasoner.ErrNotSupported))
 	}
 
-	return ap.GetAvailableArchetypes(ctx, organization)
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	values, err := ap.GetAvailableArchetypes(ctx, organization)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	return values, nil
 }
 
 // GetAvailableComputeProviders returns compute providers available at an organization (not requester-specific).
 // This only works if the underlying reasoner supports the AvailabilityProvider interface.
 func (e *Enforcer) GetAvailableComputeProviders(ctx context.Context, organization string) ([]string, error) {
 	if !e.reasoner.IsRunning() {
-		return nil, fmt.Errorf("reasoner is not running")
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
 	}
 
 	ap, ok := e.reasoner.(reasoner.AvailabilityProvider)
 	if !ok {
-		return nil, fmt.Errorf("reasoner does not support availability queries")
+		return nil, e.appError(fmt.Errorf("%w: availability queries", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	values, err := ap.GetAvailableComputeProviders(ctx, organization)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	return values, nil
+}
+
+// -----------------------------------------------------------------------------
+// Act Execution (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// ExecuteAct performs an act and reports the facts it created and terminated and
+// the violations it caused. The changes are applied to the live reasoner state.
+// This only works if the underlying reasoner supports the ActExecutor interface.
+func (e *Enforcer) ExecuteAct(ctx context.Context, req *ExecuteActRequest) (*ExecuteActResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	ax, ok := e.reasoner.(reasoner.ActExecutor)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: act execution", reasoner.ErrNotSupported))
 	}
 
-	return ap.GetAvailableComputeProviders(ctx, organization)
+	execution, err := ax.ExecuteAct(ctx, req.ActType, req.Arguments)
+	if err != nil {
+		e.log(ctx).Error("failed to execute act",
+			zap.String("act_type", req.ActType),
+			zap.Strings("arguments", req.Arguments),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	response := &ExecuteActResponse{
+		Phrase:          execution.Phrase,
+		CreatedFacts:    execution.Created,
+		TerminatedFacts: execution.Terminated,
+		Violations:      execution.Violations,
+	}
+	if response.CreatedFacts == nil {
+		response.CreatedFacts = []eflint.Fact{}
+	}
+	if response.TerminatedFacts == nil {
+		response.TerminatedFacts = []eflint.Fact{}
+	}
+	if response.Violations == nil {
+		response.Violations = []eflint.Diagnostic{}
+	}
+
+	e.log(ctx).Info("executed act",
+		zap.String("phrase", execution.Phrase),
+		zap.Int("created", len(response.CreatedFacts)),
+		zap.Int("terminated", len(response.TerminatedFacts)),
+		zap.Int("violations", len(response.Violations)),
+	)
+	return response, nil
+}
+
+// -----------------------------------------------------------------------------
+// Enabled Acts (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// GetEnabledActs returns the acts a requester could currently perform at an
+// organization: a "what can I do" view complementing ValidateRequest.
+// This only works if the underlying reasoner supports the EnabledActsProvider interface.
+func (e *Enforcer) GetEnabledActs(ctx context.Context, organization, requester string) (*EnabledActsResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	ep, ok := e.reasoner.(reasoner.EnabledActsProvider)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: enabled acts", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	enabled, err := ep.GetEnabledActs(ctx, reasoner.OrgRequester{Organization: organization, Requester: requester})
+	if err != nil {
+		e.log(ctx).Error("failed to get enabled acts",
+			zap.String("organization", organization),
+			zap.String("requester", requester),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	return &EnabledActsResponse{
+		Organization: organization,
+		Requester:    requester,
+		Acts:         enabled.Acts,
+		Count:        len(enabled.Acts),
+		Source:       enabled.Source,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Change Tracking (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// ChangesSince returns the allowed clauses granted and revoked since a checkpoint
+// was created: "what did I change?" in terms of clauses rather than graph nodes.
+// This only works if the underlying reasoner supports the ChangeTracker interface.
+func (e *Enforcer) ChangesSince(ctx context.Context, checkpoint string) (*ChangesSinceResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	ct, ok := e.reasoner.(reasoner.ChangeTracker)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: change tracking", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	changes, err := ct.ChangesSince(ctx, checkpoint)
+	if err != nil {
+		e.log(ctx).Error("failed to compare with checkpoint",
+			zap.String("checkpoint", checkpoint),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	return &ChangesSinceResponse{
+		Checkpoint:     checkpoint,
+		CheckpointedAt: changes.CheckpointedAt,
+		Added:          changes.Added,
+		Removed:        changes.Removed,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Fact Queries (if supported by the reasoner)
+// -----------------------------------------------------------------------------
+
+// QueryFacts returns the facts matching a query, e.g. all allowed archetypes of a
+// requester at any organization. It is meant for debugging and admin tools.
+// This only works if the underlying reasoner supports the FactsProvider interface.
+func (e *Enforcer) QueryFacts(ctx context.Context, query *FactQuery) (*QueryFactsResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	fp, ok := e.reasoner.(reasoner.FactsProvider)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: fact queries", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	facts, err := fp.FetchFacts(ctx)
+	if err != nil {
+		e.log(ctx).Error("failed to fetch facts",
+			zap.String("fact_type", query.FactType),
+			zap.Error(err),
+		)
+		return nil, e.appError(err)
+	}
+
+	matches := filterFacts(facts, query)
+	return &QueryFactsResponse{Facts: matches, Count: len(matches)}, nil
+}
+
+// GetStats summarizes the facts that currently hold: the number of facts per type,
+// of distinct organizations and requesters, and of allowed-clause grants. The facts
+// are fetched once; if scope is not nil, only the facts it returns are counted.
+// This only works if the underlying reasoner supports the FactsProvider interface.
+func (e *Enforcer) GetStats(ctx context.Context, scope func([]eflint.Fact) []eflint.Fact) (*PolicyStatsResponse, error) {
+	if !e.reasoner.IsRunning() {
+		return nil, e.appError(reasoner.ErrReasonerNotRunning)
+	}
+
+	fp, ok := e.reasoner.(reasoner.FactsProvider)
+	if !ok {
+		return nil, e.appError(fmt.Errorf("%w: policy statistics", reasoner.ErrNotSupported))
+	}
+
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return nil, e.appError(err)
+	}
+	defer release()
+
+	facts, err := fp.FetchFacts(ctx)
+	if err != nil {
+		e.log(ctx).Error("failed to fetch facts for statistics", zap.Error(err))
+		return nil, e.appError(err)
+	}
+	if scope != nil {
+		facts = scope(facts)
+	}
+
+	return policyStats(facts), nil
+}
+
+// policyStats computes the statistics of GetStats. This is a pure function that
+// doesn't make any network calls.
+func policyStats(facts []eflint.Fact) *PolicyStatsResponse {
+	stats := &PolicyStatsResponse{
+		TotalFacts:           len(facts),
+		FactTypes:            make(map[string]int),
+		AllowedClausesByType: make(map[string]int),
+	}
+
+	organizations := make(map[string]struct{})
+	requesters := make(map[string]struct{})
+	for _, fact := range facts {
+		stats.FactTypes[fact.FactType]++
+		if clauseType, ok := reasoner.ClauseTypeOfFact(fact.FactType); ok {
+			stats.AllowedClauses++
+			stats.AllowedClausesByType[clauseType]++
+		}
+		for _, arg := range fact.Arguments {
+			switch arg.FactType {
+			case "organization":
+				organizations[arg.Value] = struct{}{}
+			case "requester":
+				requesters[arg.Value] = struct{}{}
+			}
+		}
+	}
+	stats.Organizations = len(organizations)
+	stats.Requesters = len(requesters)
+
+	return stats
+}
+
+// filterFacts returns the facts matching a query. A fact matches if it has the
+// query's fact type and, for every argument filter, an argument at the same
+// position whose non-empty fields equal the filter's. This is a pure function
+// that doesn't make any network calls.
+func filterFacts(facts []eflint.Fact, query *FactQuery) []eflint.Fact {
+	matches := []eflint.Fact{}
+	for _, fact := range facts {
+		if fact.FactType != query.FactType || len(fact.Arguments) < len(query.Arguments) {
+			continue
+		}
+
+		match := true
+		for i, filter := range query.Arguments {
+			arg := fact.Arguments[i]
+			if (filter.FactType != "" && arg.FactType != filter.FactType) ||
+				(filter.Value != "" && arg.Value != filter.Value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			matches = append(matches, fact)
+		}
+	}
+	return matches
 }
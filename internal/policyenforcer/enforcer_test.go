@@ -0,0 +1,83 @@
+package policyenforcer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+func TestValidateMapsErrorCategoriesToStatus(t *testing.T) {
+	tests := []struct {
+		category reasoner.ErrorCategory
+		status   int
+		code     string
+	}{
+		{reasoner.ErrorCategoryParse, http.StatusBadRequest, CodeInvalidQuery},
+		{reasoner.ErrorCategoryType, http.StatusBadRequest, CodeInvalidQuery},
+		{reasoner.ErrorCategoryRuntime, http.StatusInternalServerError, CodeReasonerRuntime},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			e, _ := newTestServer(t, &fakeReasoner{validate: func(context.Context, reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+				return &reasoner.RequestValidationResult{
+					ReasonCode:    reasoner.ReasonError,
+					Reason:        "failed",
+					ErrorCategory: tt.category,
+				}, nil
+			}}, nil)
+
+			body := `{"organization": "VU", "requester": "user@example.com", "request_type": "sqlDataRequest", "data_set": "wageGap", "archetype": "computeToData", "compute_provider": "surf"}`
+			rec := serve(e, http.MethodPost, "/policy-enforcer/validate", body, nil)
+			var resp apperr.Response
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != tt.status || resp.Code != tt.code {
+				t.Errorf("POST /validate = %d %s, want %d with code %s", rec.Code, rec.Body, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateReportsDuties(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: func(ctx context.Context, params reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+		result, _ := permitAll(ctx, params)
+		result.Duties = []reasoner.Duty{{Type: "report-results", Holder: params.Requester, Claimant: params.Organization}}
+		return result, nil
+	}}, nil)
+
+	body := `{"organization": "VU", "requester": "user@example.com", "request_type": "sqlDataRequest", "data_set": "wageGap", "archetype": "computeToData", "compute_provider": "surf"}`
+	rec := serve(e, http.MethodPost, "/policy-enforcer/validate", body, nil)
+	var resp ValidationResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || len(resp.Duties) != 1 {
+		t.Fatalf("POST /validate = %d %s, want 200 with one duty", rec.Code, rec.Body)
+	}
+	if duty := resp.Duties[0]; duty.Type != "report-results" || duty.Holder != "user@example.com" || duty.Claimant != "VU" {
+		t.Errorf("duty = %+v, want report-results owed by user@example.com to VU", duty)
+	}
+}
+
+// factsReasoner is a fakeReasoner that can list its facts.
+type factsReasoner struct {
+	fakeReasoner
+	facts []reasoner.Fact
+}
+
+func (r *factsReasoner) FetchFacts(context.Context) ([]reasoner.Fact, error) {
+	return slices.Clone(r.facts), nil
+}
+
+func TestReasonerInfoAdvertisesFactsIntrospection(t *testing.T) {
+	if info := NewEnforcer(&fakeReasoner{}, nil, zap.NewNop()).GetReasonerInfo(); info.Capabilities.FactsIntrospection {
+		t.Error("FactsIntrospection = true for a reasoner without FetchFacts")
+	}
+	if info := NewEnforcer(&factsReasoner{}, nil, zap.NewNop()).GetReasonerInfo(); !info.Capabilities.FactsIntrospection {
+		t.Error("FactsIntrospection = false for a FactsProvider")
+	}
+}
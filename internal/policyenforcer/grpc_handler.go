@@ -0,0 +1,333 @@
+package policyenforcer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	policyenforcerv1 "github.com/nielsarts/dynamos-policy-enforcer/pkg/proto/policyenforcer/v1"
+)
+
+// -----------------------------------------------------------------------------
+// gRPC Handler
+// -----------------------------------------------------------------------------
+//
+// GRPCHandler serves the PolicyEnforcer service of pkg/proto/policyenforcer/v1,
+// a gRPC mirror of the core /policy-enforcer endpoints for internal callers.
+// Requests get the same defaults, required-field checks and org scope as over
+// HTTP (see checkRequest); with org scoping, the principal header is read from
+// the call's metadata. Errors are returned as gRPC status errors whose code
+// corresponds to the HTTP status of the apperr error (see grpcCode).
+
+// GRPCHandlerConfig holds configuration for the policy enforcer gRPC handler.
+type GRPCHandlerConfig struct {
+	OrgScope OrgScopeConfig // Restricts principals to their own organizations
+
+	DefaultOrganization string // Organization used when a request omits it ("" = required)
+	DefaultRequester    string // Requester used when a request omits it ("" = required)
+}
+
+// GRPCHandler implements the PolicyEnforcer gRPC service on top of an Enforcer.
+type GRPCHandler struct {
+	policyenforcerv1.UnimplementedPolicyEnforcerServer
+
+	enforcer  *Enforcer
+	config    *GRPCHandlerConfig
+	orgScoper *orgScoper // nil when org scoping is disabled
+	logger    *zap.Logger
+}
+
+// NewGRPCHandler creates a new gRPC handler for the policy enforcer.
+func NewGRPCHandler(enforcer *Enforcer, config *GRPCHandlerConfig, logger *zap.Logger) *GRPCHandler {
+	if config == nil {
+		config = &GRPCHandlerConfig{}
+	}
+
+	h := &GRPCHandler{
+		enforcer: enforcer,
+		config:   config,
+		logger:   logger,
+	}
+	if config.OrgScope.Enabled {
+		h.orgScoper = newOrgScoper(config.OrgScope)
+	}
+	return h
+}
+
+// Register registers the PolicyEnforcer service on a gRPC server.
+func (h *GRPCHandler) Register(s grpc.ServiceRegistrar) {
+	policyenforcerv1.RegisterPolicyEnforcerServer(s, h)
+}
+
+// ValidateRequest checks whether a request is allowed. A denial is a successful
+// response with allowed = false, as over HTTP with the default denial status.
+func (h *GRPCHandler) ValidateRequest(ctx context.Context, req *policyenforcerv1.ValidateRequestParams) (*policyenforcerv1.ValidationResponse, error) {
+	scope, err := h.scope(ctx)
+	if err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	result, err := h.validate(ctx, scope, req)
+	if err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+	return result, nil
+}
+
+// ValidateRequests validates a stream of requests, answering each in order. The
+// principal's scope is resolved once per stream; the first request that fails
+// ends the stream with its error.
+func (h *GRPCHandler) ValidateRequests(stream grpc.BidiStreamingServer[policyenforcerv1.ValidateRequestParams, policyenforcerv1.ValidationResponse]) error {
+	ctx := stream.Context()
+	scope, err := h.scope(ctx)
+	if err != nil {
+		return h.grpcError(ctx, err)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := h.validate(ctx, scope, req)
+		if err != nil {
+			return h.grpcError(ctx, err)
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// validate checks and validates a single request.
+func (h *GRPCHandler) validate(ctx context.Context, scope *orgScope, req *policyenforcerv1.ValidateRequestParams) (*policyenforcerv1.ValidationResponse, error) {
+	params := ValidateRequestParams{
+		Organization:    req.GetOrganization(),
+		Requester:       req.GetRequester(),
+		RequestType:     req.GetRequestType(),
+		DataSet:         req.GetDataSet(),
+		Archetype:       req.GetArchetype(),
+		ComputeProvider: req.GetComputeProvider(),
+	}
+	if err := checkRequest(&params, h.config.DefaultOrganization, h.config.DefaultRequester, scope); err != nil {
+		return nil, err
+	}
+
+	result, err := h.enforcer.ValidateRequest(ctx, &params, req.GetSuggest())
+	if err != nil {
+		return nil, err
+	}
+	return toProtoValidation(result), nil
+}
+
+// GetAllAllowedClauses returns all allowed clauses for a requester at an organization.
+func (h *GRPCHandler) GetAllAllowedClauses(ctx context.Context, req *policyenforcerv1.OrgRequester) (*policyenforcerv1.AllAllowedClausesResponse, error) {
+	params := AllowedClausesRequest{Organization: req.GetOrganization(), Requester: req.GetRequester()}
+	if err := h.check(ctx, &params); err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	result, err := h.enforcer.GetAllAllowedClauses(ctx, params.Organization, params.Requester)
+	if err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	return &policyenforcerv1.AllAllowedClausesResponse{
+		Organization:     result.Organization,
+		Requester:        result.Requester,
+		RequestTypes:     result.RequestTypes,
+		DataSets:         result.DataSets,
+		Archetypes:       result.Archetypes,
+		ComputeProviders: result.ComputeProviders,
+	}, nil
+}
+
+// GetAllowedRequestTypes returns all request types allowed for a requester at an organization.
+func (h *GRPCHandler) GetAllowedRequestTypes(ctx context.Context, req *policyenforcerv1.OrgRequester) (*policyenforcerv1.AllowedClausesResponse, error) {
+	return h.allowedClauses(ctx, req, h.enforcer.GetAllowedRequestTypes)
+}
+
+// GetAllowedDataSets returns all datasets allowed for a requester at an organization.
+func (h *GRPCHandler) GetAllowedDataSets(ctx context.Context, req *policyenforcerv1.OrgRequester) (*policyenforcerv1.AllowedClausesResponse, error) {
+	return h.allowedClauses(ctx, req, h.enforcer.GetAllowedDataSets)
+}
+
+// GetAllowedArchetypes returns all archetypes allowed for a requester at an organization.
+func (h *GRPCHandler) GetAllowedArchetypes(ctx context.Context, req *policyenforcerv1.OrgRequester) (*policyenforcerv1.AllowedClausesResponse, error) {
+	return h.allowedClauses(ctx, req, h.enforcer.GetAllowedArchetypes)
+}
+
+// GetAllowedComputeProviders returns all compute providers allowed for a requester at an organization.
+func (h *GRPCHandler) GetAllowedComputeProviders(ctx context.Context, req *policyenforcerv1.OrgRequester) (*policyenforcerv1.AllowedClausesResponse, error) {
+	return h.allowedClauses(ctx, req, h.enforcer.GetAllowedComputeProviders)
+}
+
+// allowedClauses serves the RPCs returning the allowed values of one clause type
+// with the given enforcer method.
+func (h *GRPCHandler) allowedClauses(ctx context.Context, req *policyenforcerv1.OrgRequester,
+	get func(ctx context.Context, organization, requester string) (*AllowedClausesResponse, error)) (*policyenforcerv1.AllowedClausesResponse, error) {
+	params := AllowedClausesRequest{Organization: req.GetOrganization(), Requester: req.GetRequester()}
+	if err := h.check(ctx, &params); err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	result, err := get(ctx, params.Organization, params.Requester)
+	if err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	return &policyenforcerv1.AllowedClausesResponse{
+		Organization: result.Organization,
+		Requester:    result.Requester,
+		Values:       result.Values,
+	}, nil
+}
+
+// GetAvailableArchetypes returns archetypes available at an organization (not requester-specific).
+func (h *GRPCHandler) GetAvailableArchetypes(ctx context.Context, req *policyenforcerv1.OrganizationRequest) (*policyenforcerv1.AvailableResponse, error) {
+	return h.available(ctx, req, h.enforcer.GetAvailableArchetypes)
+}
+
+// GetAvailableComputeProviders returns compute providers available at an organization (not requester-specific).
+func (h *GRPCHandler) GetAvailableComputeProviders(ctx context.Context, req *policyenforcerv1.OrganizationRequest) (*policyenforcerv1.AvailableResponse, error) {
+	return h.available(ctx, req, h.enforcer.GetAvailableComputeProviders)
+}
+
+// available serves the RPCs returning the resources available at an organization
+// with the given enforcer method.
+func (h *GRPCHandler) available(ctx context.Context, req *policyenforcerv1.OrganizationRequest,
+	get func(ctx context.Context, organization string) ([]string, error)) (*policyenforcerv1.AvailableResponse, error) {
+	params := OrganizationRequest{Organization: req.GetOrganization()}
+	if err := h.check(ctx, &params); err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	values, err := get(ctx, params.Organization)
+	if err != nil {
+		return nil, h.grpcError(ctx, err)
+	}
+
+	return &policyenforcerv1.AvailableResponse{Organization: params.Organization, Values: values}, nil
+}
+
+// check resolves the principal's scope and checks the request like bindRequest.
+func (h *GRPCHandler) check(ctx context.Context, req interface{}) error {
+	scope, err := h.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return checkRequest(req, h.config.DefaultOrganization, h.config.DefaultRequester, scope)
+}
+
+// scope resolves the scope of the call's principal, reading the principal header
+// from the incoming metadata. Without org scoping it returns nil.
+func (h *GRPCHandler) scope(ctx context.Context) (*orgScope, error) {
+	if h.orgScoper == nil {
+		return nil, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	return h.orgScoper.resolve(ctx, func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// grpcError converts an error into a gRPC status error with the code matching its
+// HTTP status. Server errors are logged like apperr.HTTPErrorHandler logs them,
+// and a Retry-After duration is sent as retry-after header metadata (in seconds).
+func (h *GRPCHandler) grpcError(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	appErr := apperr.From(err)
+	code := grpcCode(appErr.Status)
+	if appErr.Status >= http.StatusInternalServerError {
+		logAt := h.logger.Error
+		if appErr.Status == http.StatusNotImplemented || appErr.Status == http.StatusServiceUnavailable {
+			logAt = h.logger.Warn // Expected while a backend is down or lacks a capability
+		}
+		method, _ := grpc.Method(ctx)
+		logAt("gRPC call failed",
+			zap.String("method", method),
+			zap.String("grpc_code", code.String()),
+			zap.String("code", appErr.Code),
+			zap.String("error", appErr.Message),
+			zap.NamedError("cause", appErr.Err),
+		)
+	}
+
+	if appErr.RetryAfter > 0 {
+		seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	}
+	return status.Error(code, appErr.Message)
+}
+
+// grpcCode returns the gRPC status code corresponding to an HTTP status.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.InvalidArgument
+}
+
+// toProtoValidation converts a validation response to its gRPC message.
+func toProtoValidation(r *ValidationResponse) *policyenforcerv1.ValidationResponse {
+	resp := &policyenforcerv1.ValidationResponse{
+		Allowed:         r.Allowed,
+		ReasonCode:      string(r.ReasonCode),
+		Reason:          r.Reason,
+		ErrorCategory:   string(r.ErrorCategory),
+		Organization:    r.Organization,
+		Requester:       r.Requester,
+		RequestType:     r.RequestType,
+		DataSet:         r.DataSet,
+		Archetype:       r.Archetype,
+		ComputeProvider: r.ComputeProvider,
+	}
+	if len(r.Suggestions) > 0 {
+		resp.Suggestions = make(map[string]*policyenforcerv1.Values, len(r.Suggestions))
+		for clauseType, values := range r.Suggestions {
+			resp.Suggestions[clauseType] = &policyenforcerv1.Values{Values: values}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,178 @@
+package policyenforcer
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+	policyenforcerv1 "github.com/nielsarts/dynamos-policy-enforcer/pkg/proto/policyenforcer/v1"
+)
+
+// newTestGRPCClient serves the PolicyEnforcer gRPC service for r in memory and
+// returns a client connected to it.
+func newTestGRPCClient(t *testing.T, r reasoner.Reasoner, config *GRPCHandlerConfig) policyenforcerv1.PolicyEnforcerClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	NewGRPCHandler(NewEnforcer(r, nil, zap.NewNop()), config, zap.NewNop()).Register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return policyenforcerv1.NewPolicyEnforcerClient(conn)
+}
+
+// denyArchetypes permits every request except those for the "computeToData" archetype.
+func denyArchetypes(_ context.Context, params reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+	if params.Archetype == "computeToData" {
+		return &reasoner.RequestValidationResult{ReasonCode: reasoner.ReasonNotPermitted, Reason: "archetype not allowed"}, nil
+	}
+	return permitAll(context.Background(), params)
+}
+
+func TestGRPCValidateRequest(t *testing.T) {
+	client := newTestGRPCClient(t, &fakeReasoner{validate: denyArchetypes}, &GRPCHandlerConfig{DefaultRequester: "user@example.com"})
+	ctx := context.Background()
+
+	req := &policyenforcerv1.ValidateRequestParams{
+		Organization: "VU", RequestType: "sqlDataRequest", DataSet: "wageGap", Archetype: "dataThroughTtp", ComputeProvider: "surf",
+	}
+	resp, err := client.ValidateRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("ValidateRequest() error = %v", err)
+	}
+	if !resp.Allowed || resp.ReasonCode != string(reasoner.ReasonPermitted) || resp.Requester != "user@example.com" {
+		t.Errorf("ValidateRequest() = %v, want a permission for the default requester", resp)
+	}
+
+	req.Archetype = "computeToData"
+	if resp, err := client.ValidateRequest(ctx, req); err != nil || resp.Allowed || resp.ReasonCode != string(reasoner.ReasonNotPermitted) {
+		t.Errorf("ValidateRequest(denied) = %v, %v, want a successful response with allowed = false", resp, err)
+	}
+
+	_, err = client.ValidateRequest(ctx, &policyenforcerv1.ValidateRequestParams{Organization: "VU"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ValidateRequest(incomplete) error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCValidateRequests(t *testing.T) {
+	client := newTestGRPCClient(t, &fakeReasoner{validate: denyArchetypes}, nil)
+	stream, err := client.ValidateRequests(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archetypes := []string{"dataThroughTtp", "computeToData", "reproducibleScience"}
+	for _, archetype := range archetypes {
+		err := stream.Send(&policyenforcerv1.ValidateRequestParams{
+			Organization: "VU", Requester: "user@example.com", RequestType: "sqlDataRequest",
+			DataSet: "wageGap", Archetype: archetype, ComputeProvider: "surf",
+		})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	for _, archetype := range archetypes {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if resp.Archetype != archetype || resp.Allowed != (archetype != "computeToData") {
+			t.Errorf("response for %s = %v, want the answers in request order", archetype, resp)
+		}
+	}
+
+	// A failing request ends the stream with its error
+	stream, err = client.ValidateRequests(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&policyenforcerv1.ValidateRequestParams{Organization: "VU"})
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Recv() after an incomplete request error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCOrgScope(t *testing.T) {
+	client := newTestGRPCClient(t, &fakeReasoner{validate: permitAll}, &GRPCHandlerConfig{
+		OrgScope: OrgScopeConfig{Enabled: true, Organizations: map[string][]string{"alice": {"VU"}}},
+	})
+	as := func(principal string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), DefaultPrincipalHeader, principal)
+	}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		organization string
+		want         codes.Code
+	}{
+		{"own organization", as("alice"), "VU", codes.OK},
+		{"other organization", as("alice"), "UvA", codes.PermissionDenied},
+		{"no principal", context.Background(), "VU", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetAllowedArchetypes(tt.ctx, &policyenforcerv1.OrgRequester{Organization: tt.organization, Requester: "user"})
+			if status.Code(err) != tt.want {
+				t.Errorf("GetAllowedArchetypes() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	stream, err := client.ValidateRequests(as("alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&policyenforcerv1.ValidateRequestParams{
+		Organization: "UvA", Requester: "user", RequestType: "sqlDataRequest", DataSet: "wageGap", Archetype: "dataThroughTtp", ComputeProvider: "surf",
+	})
+	if _, err := stream.Recv(); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("streamed validation at another organization error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	// The fake reasoner cannot report availability
+	client := newTestGRPCClient(t, &fakeReasoner{validate: permitAll}, nil)
+	_, err := client.GetAvailableComputeProviders(context.Background(), &policyenforcerv1.OrganizationRequest{Organization: "VU"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("GetAvailableComputeProviders() error = %v, want Unimplemented", err)
+	}
+
+	for httpStatus, want := range map[int]codes.Code{
+		400: codes.InvalidArgument,
+		401: codes.Unauthenticated,
+		403: codes.PermissionDenied,
+		404: codes.NotFound,
+		429: codes.ResourceExhausted,
+		500: codes.Internal,
+		501: codes.Unimplemented,
+		502: codes.Unavailable,
+		503: codes.Unavailable,
+		504: codes.DeadlineExceeded,
+	} {
+		if got := grpcCode(httpStatus); got != want {
+			t.Errorf("grpcCode(%d) = %s, want %s", httpStatus, got, want)
+		}
+	}
+}
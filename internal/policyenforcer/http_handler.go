@@ -1,50 +1,199 @@
 package policyenforcer
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
 )
 
 // -----------------------------------------------------------------------------
 // HTTP Handler
 // -----------------------------------------------------------------------------
 
+// HTTPHandlerConfig holds configuration for the policy enforcer HTTP handler.
+type HTTPHandlerConfig struct {
+	RateLimit RateLimitConfig // Rate limiting for validation and allowed-clause endpoints
+	OrgScope  OrgScopeConfig  // Restricts principals to their own organizations
+	Async     AsyncConfig     // Asynchronous validation jobs
+
+	DefaultOrganization string // Organization used when a request omits it ("" = required)
+	DefaultRequester    string // Requester used when a request omits it ("" = required)
+
+	ExecuteActs  bool         // Register POST /execute-act, which changes the reasoner state
+	StrictJSON   bool         // Reject validate and import bodies with unknown fields (400)
+	DenialStatus DenialStatus // HTTP status of denied validations; overridable with ?http_status=
+}
+
+// DenialStatus selects the HTTP status of a denied validation. The JSON body is the
+// same either way.
+type DenialStatus string
+
+const (
+	// DenialStatusOK answers denials with 200 and allowed: false (the default).
+	DenialStatusOK DenialStatus = "ok"
+	// DenialStatusSemantic answers denials with 403, for gateways that enforce on the
+	// status code.
+	DenialStatusSemantic DenialStatus = "semantic"
+)
+
+// DefaultHTTPHandlerConfig returns sensible default configuration values.
+func DefaultHTTPHandlerConfig() *HTTPHandlerConfig {
+	return &HTTPHandlerConfig{
+		RateLimit:  DefaultRateLimitConfig(),
+		Async:      DefaultAsyncConfig(),
+		StrictJSON: true,
+	}
+}
+
 // HTTPHandler handles HTTP requests for the policy enforcer API.
 // It provides REST endpoints for querying allowed clauses and validating requests.
 type HTTPHandler struct {
-	enforcer *Enforcer
-	logger   *zap.Logger
+	enforcer    *Enforcer
+	config      *HTTPHandlerConfig
+	idempotency *idempotencyCache
+	rateLimiter *rateLimiter
+	orgScoper   *orgScoper // nil when org scoping is disabled
+	jobs        *jobQueue  // nil when asynchronous validation is disabled
+	logger      *zap.Logger
 }
 
 // NewHTTPHandler creates a new HTTP handler for the policy enforcer.
-func NewHTTPHandler(enforcer *Enforcer, logger *zap.Logger) *HTTPHandler {
-	return &HTTPHandler{
-		enforcer: enforcer,
-		logger:   logger,
+func NewHTTPHandler(enforcer *Enforcer, config *HTTPHandlerConfig, logger *zap.Logger) *HTTPHandler {
+	if config == nil {
+		config = DefaultHTTPHandlerConfig()
+	}
+
+	h := &HTTPHandler{
+		enforcer:    enforcer,
+		config:      config,
+		idempotency: newIdempotencyCache(defaultIdempotencyTTL),
+		logger:      logger,
+	}
+	if config.RateLimit.Enabled {
+		h.rateLimiter = newRateLimiter(config.RateLimit)
+	}
+	if config.OrgScope.Enabled {
+		h.orgScoper = newOrgScoper(config.OrgScope)
 	}
+	if config.Async.Enabled {
+		h.jobs = newJobQueue(config.Async, func(ctx context.Context, job *validationJob) (*ValidationResponse, error) {
+			return h.enforcer.ValidateRequest(ctx, &job.params, job.suggest)
+		})
+	}
+	return h
+}
+
+// Shutdown stops accepting asynchronous validations and waits until the queued
+// and running ones have finished, or ctx is done; then the running ones are
+// cancelled and ctx's error is returned. Call it after the HTTP server stopped
+// accepting requests and before the reasoner goes away.
+func (h *HTTPHandler) Shutdown(ctx context.Context) error {
+	if h.jobs == nil {
+		return nil
+	}
+	return h.jobs.shutdown(ctx)
+}
+
+// SetRateLimit changes the rate limits while requests are served, e.g. on a config
+// reload. Switching rate limiting on or off requires a restart, since it decides
+// which middleware the routes are registered with.
+func (h *HTTPHandler) SetRateLimit(config RateLimitConfig) error {
+	if config.Enabled != (h.rateLimiter != nil) {
+		return errors.New("switching rate limiting on or off requires a restart")
+	}
+	if h.rateLimiter != nil {
+		h.rateLimiter.setLimits(config)
+	}
+	return nil
 }
 
 // RegisterRoutes registers all policy enforcer API routes on the given Echo group.
 // Routes are registered under the group prefix (e.g., /policy-enforcer).
 func (h *HTTPHandler) RegisterRoutes(g *echo.Group) {
+	// Org scoping (if enabled) applies to every route, so handlers can rely on it
+	if h.orgScoper != nil {
+		g.Use(h.orgScoper.middleware())
+	}
+
+	// Rate limiting (if enabled) protects the reasoner from query-heavy endpoints
+	var limited []echo.MiddlewareFunc
+	if h.rateLimiter != nil {
+		limited = append(limited, h.rateLimiter.middleware())
+	}
+
+	// Strict decoding (if enabled) reports misspelled fields of validate and import bodies
+	strict := func(newRequest func() any, middleware ...echo.MiddlewareFunc) []echo.MiddlewareFunc {
+		if !h.config.StrictJSON {
+			return middleware
+		}
+		return append([]echo.MiddlewareFunc{strictJSON(newRequest)}, middleware...)
+	}
+	validateParams := func() any { return &ValidateRequestParams{} }
+
 	// Reasoner info
 	g.GET("/info", h.GetReasonerInfo)
 
 	// Allowed clauses endpoints
-	g.GET("/allowed-request-types", h.GetAllowedRequestTypes)
-	g.GET("/allowed-data-sets", h.GetAllowedDataSets)
-	g.GET("/allowed-archetypes", h.GetAllowedArchetypes)
-	g.GET("/allowed-compute-providers", h.GetAllowedComputeProviders)
-	g.GET("/allowed-clauses", h.GetAllAllowedClauses)
+	g.GET("/allowed-request-types", h.GetAllowedRequestTypes, limited...)
+	g.GET("/allowed-data-sets", h.GetAllowedDataSets, limited...)
+	g.GET("/allowed-archetypes", h.GetAllowedArchetypes, limited...)
+	g.GET("/allowed-compute-providers", h.GetAllowedComputeProviders, limited...)
+	g.GET("/allowed-clauses", h.GetAllAllowedClauses, limited...)
+	g.GET("/allowed", h.GetAllowedClausesOfType, limited...)
+	g.POST("/allowed-clauses-batch", h.GetAllAllowedClausesBatch, limited...)
+	g.DELETE("/allowed-clauses", h.RevokeAllowedClause)
 
-	// Request validation endpoint
-	g.POST("/validate", h.ValidateRequest)
+	// Acts a requester could currently perform ("what can I do")
+	g.GET("/enabled-acts", h.GetEnabledActs, limited...)
+
+	// Single clause check: is one value allowed for a requester
+	g.GET("/is-allowed", h.IsClauseAllowed, limited...)
+
+	// Reverse lookup: which requesters are allowed a clause value
+	g.GET("/who-can", h.GetRequestersAllowed)
+
+	// Request validation endpoint (?async=true returns a job to poll)
+	g.POST("/validate", h.ValidateRequest, strict(validateParams, limited...)...)
+	g.GET("/jobs/:id", h.GetValidationJob)
+
+	// Approximate pre-check against the allowed clauses (not authoritative)
+	g.POST("/quick-check", h.QuickCheck, strict(validateParams, limited...)...)
+
+	// What-if validation against changes that are rolled back afterwards
+	g.POST("/simulate-change", h.SimulateChange, strict(func() any { return &SimulateChangeRequest{} }, limited...)...)
 
 	// Availability endpoints (organization-level, not requester-specific)
 	g.GET("/available-archetypes", h.GetAvailableArchetypes)
 	g.GET("/available-compute-providers", h.GetAvailableComputeProviders)
+
+	// Portable export/import of everything an organization has granted
+	g.GET("/export", h.ExportOrgPolicy)
+	g.POST("/import", h.ImportOrgPolicy, strict(func() any { return &reasoner.OrgPolicy{} })...)
+
+	// Allowed clauses granted and revoked since a checkpoint of the eFLINT state API
+	g.GET("/changes-since", h.ChangesSince, limited...)
+
+	// Raw facts by type and argument, for debugging and admin tools
+	g.POST("/query-facts", h.QueryFacts, limited...)
+
+	// Aggregate counts of facts, organizations, requesters and grants
+	g.GET("/stats", h.GetStats, limited...)
+
+	// Act execution changes the reasoner state, so it must be enabled explicitly
+	if h.config.ExecuteActs {
+		g.POST("/execute-act", h.ExecuteAct)
+	}
 }
 
 // -----------------------------------------------------------------------------
@@ -61,78 +210,204 @@ func (h *HTTPHandler) GetReasonerInfo(c echo.Context) error {
 // GetAllowedRequestTypes returns all request types allowed for a requester at an organization.
 // GET /policy-enforcer/allowed-request-types?organization=VU&requester=user@example.com
 func (h *HTTPHandler) GetAllowedRequestTypes(c echo.Context) error {
-	organization, requester, err := h.parseOrgRequester(c)
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.GetAllowedRequestTypes(c.Request().Context(), req.Organization, req.Requester)
 	if err != nil {
 		return err
 	}
 
-	result, err := h.enforcer.GetAllowedRequestTypes(c.Request().Context(), organization, requester)
+	return c.JSON(http.StatusOK, checkValues(c, result))
+}
+
+// GetAllowedClausesOfType returns the values of any registered clause type allowed for
+// a requester at an organization.
+// GET /policy-enforcer/allowed?organization=VU&requester=user@example.com&type=archetype
+func (h *HTTPHandler) GetAllowedClausesOfType(c echo.Context) error {
+	var req AllowedOfTypeRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.GetAllowedClausesOfType(c.Request().Context(), req.Organization, req.Requester, req.ClauseType)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, checkValues(c, result))
 }
 
 // GetAllowedDataSets returns all datasets allowed for a requester at an organization.
 // GET /policy-enforcer/allowed-data-sets?organization=VU&requester=user@example.com
 func (h *HTTPHandler) GetAllowedDataSets(c echo.Context) error {
-	organization, requester, err := h.parseOrgRequester(c)
-	if err != nil {
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
 		return err
 	}
 
-	result, err := h.enforcer.GetAllowedDataSets(c.Request().Context(), organization, requester)
+	result, err := h.enforcer.GetAllowedDataSets(c.Request().Context(), req.Organization, req.Requester)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, checkValues(c, result))
 }
 
 // GetAllowedArchetypes returns all archetypes allowed for a requester at an organization.
 // GET /policy-enforcer/allowed-archetypes?organization=VU&requester=user@example.com
 func (h *HTTPHandler) GetAllowedArchetypes(c echo.Context) error {
-	organization, requester, err := h.parseOrgRequester(c)
-	if err != nil {
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
 		return err
 	}
 
-	result, err := h.enforcer.GetAllowedArchetypes(c.Request().Context(), organization, requester)
+	result, err := h.enforcer.GetAllowedArchetypes(c.Request().Context(), req.Organization, req.Requester)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, checkValues(c, result))
 }
 
 // GetAllowedComputeProviders returns all compute providers allowed for a requester at an organization.
 // GET /policy-enforcer/allowed-compute-providers?organization=VU&requester=user@example.com
 func (h *HTTPHandler) GetAllowedComputeProviders(c echo.Context) error {
-	organization, requester, err := h.parseOrgRequester(c)
-	if err != nil {
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
 		return err
 	}
 
-	result, err := h.enforcer.GetAllowedComputeProviders(c.Request().Context(), organization, requester)
+	result, err := h.enforcer.GetAllowedComputeProviders(c.Request().Context(), req.Organization, req.Requester)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, checkValues(c, result))
 }
 
 // GetAllAllowedClauses returns all allowed clauses for a requester at an organization.
 // GET /policy-enforcer/allowed-clauses?organization=VU&requester=user@example.com
 func (h *HTTPHandler) GetAllAllowedClauses(c echo.Context) error {
-	organization, requester, err := h.parseOrgRequester(c)
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.GetAllAllowedClauses(c.Request().Context(), req.Organization, req.Requester)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// maxBatchPairs bounds the number of pairs accepted by a single batch request.
+const maxBatchPairs = 500
+
+// GetAllAllowedClausesBatch returns all allowed clauses for many (organization, requester) pairs.
+// POST /policy-enforcer/allowed-clauses-batch
+func (h *HTTPHandler) GetAllAllowedClausesBatch(c echo.Context) error {
+	var req BatchAllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if len(req.Pairs) > maxBatchPairs {
+		return apperr.BadRequest("at most %d pairs are allowed", maxBatchPairs)
+	}
+	for _, pair := range req.Pairs {
+		if pair.Organization == "" || pair.Requester == "" {
+			return apperr.BadRequest("each pair requires organization and requester")
+		}
+	}
+
+	results, err := h.enforcer.GetAllAllowedClausesBatch(c.Request().Context(), req.Pairs)
+	if err != nil {
+		return err
+	}
+
+	response := BatchAllowedClausesResponse{Results: make([]*AllAllowedClausesResponse, 0, len(results))}
+	seen := make(map[reasoner.OrgRequester]bool, len(results))
+	for _, pair := range req.Pairs {
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		response.Results = append(response.Results, results[pair])
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetEnabledActs returns the acts a requester could currently perform at an organization.
+// GET /policy-enforcer/enabled-acts?organization=VU&requester=user@example.com
+func (h *HTTPHandler) GetEnabledActs(c echo.Context) error {
+	var req AllowedClausesRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.GetEnabledActs(c.Request().Context(), req.Organization, req.Requester)
 	if err != nil {
 		return err
 	}
 
-	result, err := h.enforcer.GetAllAllowedClauses(c.Request().Context(), organization, requester)
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetRequestersAllowed returns all requesters at an organization allowed a specific clause value.
+// GET /policy-enforcer/who-can?organization=VU&type=archetype&value=computeToData
+func (h *HTTPHandler) GetRequestersAllowed(c echo.Context) error {
+	var req RequestersAllowedRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.GetRequestersAllowed(c.Request().Context(), req.Organization, req.ClauseType, req.Value)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// IsClauseAllowed reports whether a requester is allowed a single clause value.
+// GET /policy-enforcer/is-allowed?organization=VU&requester=user@example.com&type=archetype&value=computeToData
+func (h *HTTPHandler) IsClauseAllowed(c echo.Context) error {
+	var req ClauseAllowedRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	allowed, err := h.enforcer.IsClauseAllowed(c.Request().Context(), req.Organization, req.Requester, req.ClauseType, req.Value)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ClauseAllowedResponse{
+		Allowed:      allowed,
+		Organization: req.Organization,
+		Requester:    req.Requester,
+		Type:         req.ClauseType,
+		Value:        req.Value,
+	})
+}
+
+// RevokeAllowedClause revokes a single allowed clause for a requester at an organization.
+// The response confirms whether the clause still holds after revocation.
+// DELETE /policy-enforcer/allowed-clauses?organization=VU&requester=user@example.com&type=archetype&value=computeToData
+func (h *HTTPHandler) RevokeAllowedClause(c echo.Context) error {
+	var req RevokeClauseRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.RevokeAllowedClause(c.Request().Context(), req.Organization, req.Requester, req.ClauseType, req.Value)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, result)
@@ -141,51 +416,157 @@ func (h *HTTPHandler) GetAllAllowedClauses(c echo.Context) error {
 // ValidateRequest checks if a specific request is allowed.
 // POST /policy-enforcer/validate
 // Body: { "organization": "VU", "requester": "user@example.com", "request_type": "sqlDataRequest", ... }
+//
+// If an Idempotency-Key header is present, the result is cached for a short TTL and
+// returned directly when the same key is retried with the same body. Reusing a key
+// with a different body returns 422. With ?async=true, the job is cached instead,
+// and a retry returns it.
+//
+// With ?suggest=true, a denied response lists the allowed values for each field
+// that is not among the requester's allowed clauses.
 func (h *HTTPHandler) ValidateRequest(c echo.Context) error {
+	suggest, _ := strconv.ParseBool(c.QueryParam("suggest"))
+	async, _ := strconv.ParseBool(c.QueryParam("async"))
+
+	idempotencyKey := c.Request().Header.Get(IdempotencyKeyHeader)
+	var bodyHash string
+	if idempotencyKey != "" {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return apperr.BadRequest("invalid request body")
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+		// Responses with and without suggestions differ, so suggest is part of the
+		// key, and so is async, since an asynchronous validation returns a job
+		if suggest {
+			body = append(body, "?suggest=true"...)
+		}
+		if async {
+			body = append(body, "?async=true"...)
+		}
+		bodyHash = hashBody(body)
+	}
+
+	// Bind first, so that replayed results are also subject to the org scope
 	var params ValidateRequestParams
-	if err := c.Bind(&params); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	if err := h.bindRequest(c, &params); err != nil {
+		return err
+	}
+
+	if idempotencyKey != "" {
+		if entry, ok := h.idempotency.get(idempotencyKey); ok {
+			if entry.bodyHash != bodyHash {
+				return apperr.Unprocessable("idempotency key was already used with a different request body")
+			}
+			if entry.jobID == "" {
+				return c.JSON(h.validationStatus(c, entry.response), entry.response)
+			}
+			// A job that has expired meanwhile is submitted again below
+			if job, _, ok := h.jobs.get(entry.jobID); ok {
+				return h.acceptedJob(c, job)
+			}
+		}
+	}
+
+	if async {
+		return h.submitValidation(c, params, suggest, idempotencyKey, bodyHash)
+	}
+
+	result, err := h.enforcer.ValidateRequest(c.Request().Context(), &params, suggest)
+	if err != nil {
+		return err
+	}
+
+	if idempotencyKey != "" {
+		h.idempotency.put(idempotencyKey, bodyHash, result)
 	}
 
-	// Validate required fields
-	if params.Organization == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization is required"})
+	return c.JSON(h.validationStatus(c, result), result)
+}
+
+// GetValidationJob returns the status of an asynchronous validation and, once it
+// has finished, its result or error. Finished jobs expire after the configured TTL.
+// GET /policy-enforcer/jobs/:id
+func (h *HTTPHandler) GetValidationJob(c echo.Context) error {
+	if h.jobs == nil {
+		return apperr.New(http.StatusNotImplemented, apperr.CodeNotImplemented, "asynchronous validation is disabled")
 	}
-	if params.Requester == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "requester is required"})
+
+	job, organization, ok := h.jobs.get(c.Param("id"))
+	if !ok {
+		return apperr.NotFound("job not found")
 	}
-	if params.RequestType == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "request_type is required"})
+	if err := h.checkOrgScope(c, organization); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// QuickCheck approximates a validation using only the allowed clauses.
+// POST /policy-enforcer/quick-check
+//
+// The result is a heuristic for UI gating; use POST /policy-enforcer/validate
+// for the authoritative decision.
+func (h *HTTPHandler) QuickCheck(c echo.Context) error {
+	var params ValidateRequestParams
+	if err := h.bindRequest(c, &params); err != nil {
+		return err
 	}
-	if params.DataSet == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "data_set is required"})
+
+	result, err := h.enforcer.QuickCheck(c.Request().Context(), &params)
+	if err != nil {
+		return err
 	}
-	if params.Archetype == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "archetype is required"})
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SimulateChange validates a request as if the given phrases had been applied,
+// then rolls them back.
+// POST /policy-enforcer/simulate-change
+//
+// Responds 200 with the validation result, 403 unless the principal may access
+// every organization, 422 if a phrase was rejected or the validation failed, and
+// 500 if the changes could not be rolled back. Every response describes the
+// rollback.
+func (h *HTTPHandler) SimulateChange(c echo.Context) error {
+	var req SimulateChangeRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
 	}
-	if params.ComputeProvider == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "compute_provider is required"})
+	// Phrases are untyped, so only principals with access to every organization
+	// may simulate changes
+	if err := h.checkAllOrgScope(c); err != nil {
+		return err
 	}
 
-	result, err := h.enforcer.ValidateRequest(c.Request().Context(), &params)
+	result, err := h.enforcer.SimulateChange(c.Request().Context(), &req)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
+	switch {
+	case result.Rollback == reasoner.RollbackFailed:
+		return c.JSON(http.StatusInternalServerError, result)
+	case result.Error != "":
+		return c.JSON(http.StatusUnprocessableEntity, result)
+	}
 	return c.JSON(http.StatusOK, result)
 }
 
 // GetAvailableArchetypes returns archetypes available at an organization (not requester-specific).
 // GET /policy-enforcer/available-archetypes?organization=VU
 func (h *HTTPHandler) GetAvailableArchetypes(c echo.Context) error {
-	organization := c.QueryParam("organization")
-	if organization == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization parameter is required"})
+	var req OrganizationRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
 	}
+	organization := req.Organization
 
 	values, err := h.enforcer.GetAvailableArchetypes(c.Request().Context(), organization)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -197,14 +578,15 @@ func (h *HTTPHandler) GetAvailableArchetypes(c echo.Context) error {
 // GetAvailableComputeProviders returns compute providers available at an organization (not requester-specific).
 // GET /policy-enforcer/available-compute-providers?organization=VU
 func (h *HTTPHandler) GetAvailableComputeProviders(c echo.Context) error {
-	organization := c.QueryParam("organization")
-	if organization == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization parameter is required"})
+	var req OrganizationRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
 	}
+	organization := req.Organization
 
 	values, err := h.enforcer.GetAvailableComputeProviders(c.Request().Context(), organization)
 	if err != nil {
-		return h.handleError(c, err)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -213,32 +595,196 @@ func (h *HTTPHandler) GetAvailableComputeProviders(c echo.Context) error {
 	})
 }
 
+// ExportOrgPolicy returns all permissions granted by an organization as a portable document.
+// GET /policy-enforcer/export?organization=VU
+func (h *HTTPHandler) ExportOrgPolicy(c echo.Context) error {
+	var req OrganizationRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+	organization := req.Organization
+
+	result, err := h.enforcer.ExportOrgPolicy(c.Request().Context(), organization)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// QueryFacts returns the facts of a type whose arguments match the given filters.
+// With org scoping, facts of organizations outside the principal's scope are omitted.
+// POST /policy-enforcer/query-facts
+func (h *HTTPHandler) QueryFacts(c echo.Context) error {
+	var req FactQuery
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.QueryFacts(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	result.Facts = h.scopeFacts(c, result.Facts)
+	result.Count = len(result.Facts)
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetStats returns aggregate counts of the policy's facts.
+// With org scoping, only facts at organizations in the principal's scope are counted.
+// GET /policy-enforcer/stats
+func (h *HTTPHandler) GetStats(c echo.Context) error {
+	result, err := h.enforcer.GetStats(c.Request().Context(), func(facts []eflint.Fact) []eflint.Fact {
+		return h.scopeFacts(c, facts)
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ChangesSince returns the allowed clauses granted and revoked since a checkpoint.
+// With org scoping, changes at organizations outside the principal's scope are omitted.
+// GET /policy-enforcer/changes-since?checkpoint=before-review
+func (h *HTTPHandler) ChangesSince(c echo.Context) error {
+	var req ChangesSinceRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.ChangesSince(c.Request().Context(), req.Checkpoint)
+	if err != nil {
+		return err
+	}
+
+	result.Added = h.scopeClauseChanges(c, result.Added)
+	result.Removed = h.scopeClauseChanges(c, result.Removed)
+	return c.JSON(http.StatusOK, result)
+}
+
+// ExecuteAct performs an eFLINT act, creating and terminating facts in the live
+// reasoner state. Only registered when HTTPHandlerConfig.ExecuteActs is set.
+// POST /policy-enforcer/execute-act
+//
+// Responds 200 with the created and terminated facts, also when the act was not
+// enabled; its violation is then listed and the effects are applied regardless.
+func (h *HTTPHandler) ExecuteAct(c echo.Context) error {
+	var req ExecuteActRequest
+	if err := h.bindRequest(c, &req); err != nil {
+		return err
+	}
+	if err := eflint.ValidateTypeName(req.ActType); err != nil {
+		return apperr.BadRequest("act_type: %v", err).WithFields("act_type")
+	}
+	// Act arguments are untyped, so only principals with access to every
+	// organization may change the state
+	if err := h.checkAllOrgScope(c); err != nil {
+		return err
+	}
+
+	result, err := h.enforcer.ExecuteAct(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ImportOrgPolicy grants everything in a document produced by ExportOrgPolicy.
+// POST /policy-enforcer/import
+func (h *HTTPHandler) ImportOrgPolicy(c echo.Context) error {
+	var policy reasoner.OrgPolicy
+	if err := h.bindRequest(c, &policy); err != nil {
+		return err
+	}
+
+	for _, grant := range policy.Grants {
+		if grant.Requester == "" {
+			return apperr.BadRequest("each grant requires a requester")
+		}
+	}
+
+	result, err := h.enforcer.ImportOrgPolicy(c.Request().Context(), &policy)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
 // -----------------------------------------------------------------------------
 // Helper Methods
 // -----------------------------------------------------------------------------
 
-// parseOrgRequester extracts and validates organization and requester query parameters.
-func (h *HTTPHandler) parseOrgRequester(c echo.Context) (organization, requester string, err error) {
-	organization = c.QueryParam("organization")
-	requester = c.QueryParam("requester")
+// checkValues narrows an allowed-clauses result to the values given as repeated
+// value= query parameters, reporting for each whether it is allowed. Without value
+// parameters the result is returned as is.
+func checkValues(c echo.Context, result *AllowedClausesResponse) *AllowedClausesResponse {
+	candidates := c.QueryParams()["value"]
+	if len(candidates) == 0 {
+		return result
+	}
 
-	if organization == "" {
-		return "", "", c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization parameter is required"})
+	allowed := make(map[string]bool, len(result.Values))
+	for _, value := range result.Values {
+		allowed[value] = true
 	}
-	if requester == "" {
-		return "", "", c.JSON(http.StatusBadRequest, ErrorResponse{Error: "requester parameter is required"})
+	result.Values = []string{}
+	result.Checked = make(map[string]bool, len(candidates))
+	for _, value := range candidates {
+		if _, seen := result.Checked[value]; seen {
+			continue
+		}
+		result.Checked[value] = allowed[value]
+		if allowed[value] {
+			result.Values = append(result.Values, value)
+		}
 	}
+	return result
+}
 
-	return organization, requester, nil
+// validationStatus returns the HTTP status of a validation result: 200, or 403 for
+// a denial when the ?http_status= parameter, or else the configuration, asks for
+// semantic status codes.
+func (h *HTTPHandler) validationStatus(c echo.Context, result *ValidationResponse) int {
+	if result.Allowed {
+		return http.StatusOK
+	}
+	mode := DenialStatus(c.QueryParam("http_status"))
+	if mode == "" {
+		mode = h.config.DenialStatus
+	}
+	if mode == DenialStatusSemantic {
+		return http.StatusForbidden
+	}
+	return http.StatusOK
 }
 
-// handleError converts service errors to appropriate HTTP responses.
-func (h *HTTPHandler) handleError(c echo.Context, err error) error {
-	// Check if reasoner is not running
-	if !h.enforcer.IsRunning() {
-		return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "reasoner is not running"})
+// submitValidation queues a validation and answers 202 with the job, whose URL is
+// also given in the Location header. With an idempotency key, the job is
+// remembered for the key, so that a retry returns it instead of queueing another.
+func (h *HTTPHandler) submitValidation(c echo.Context, params ValidateRequestParams, suggest bool, idempotencyKey, bodyHash string) error {
+	if h.jobs == nil {
+		return apperr.New(http.StatusNotImplemented, apperr.CodeNotImplemented, "asynchronous validation is disabled")
 	}
 
-	h.logger.Error("policy enforcer error", zap.Error(err))
-	return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	job, err := h.jobs.submit(c.Request().Context(), params, suggest)
+	if errors.Is(err, errJobQueueClosed) {
+		return apperr.Wrap(err, http.StatusServiceUnavailable, apperr.CodeUnavailable, "the service is shutting down")
+	}
+	if err != nil {
+		return apperr.Wrap(err, http.StatusServiceUnavailable, apperr.CodeUnavailable, "too many pending validations; retry later")
+	}
+
+	if idempotencyKey != "" {
+		h.idempotency.putJob(idempotencyKey, bodyHash, job.JobID)
+	}
+	return h.acceptedJob(c, job)
+}
+
+// acceptedJob answers 202 with a job, whose URL is also given in the Location header.
+func (h *HTTPHandler) acceptedJob(c echo.Context, job *ValidationJobResponse) error {
+	location := strings.TrimSuffix(c.Request().URL.Path, "/validate") + "/jobs/" + job.JobID
+	c.Response().Header().Set(echo.HeaderLocation, location)
+	return c.JSON(http.StatusAccepted, job)
 }
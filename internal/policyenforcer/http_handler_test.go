@@ -0,0 +1,34 @@
+package policyenforcer
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+)
+
+func TestImportOrgPolicyRequiresOrganization(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, nil)
+	rec := serve(e, http.MethodPost, "/policy-enforcer/import", `{"grants": []}`, nil)
+	var resp apperr.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusBadRequest || !slices.Equal(resp.Fields, []string{"organization"}) {
+		t.Errorf("POST /import = %d %s, want 400 naming the organization field", rec.Code, rec.Body)
+	}
+
+	// A default organization fills the field; the fake reasoner cannot import
+	config := DefaultHTTPHandlerConfig()
+	config.DefaultOrganization = "VU"
+	e, _ = newTestServer(t, &fakeReasoner{validate: permitAll}, config)
+	rec = serve(e, http.MethodPost, "/policy-enforcer/import", `{"grants": []}`, nil)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("POST /import with a default organization = %d %s, want 501", rec.Code, rec.Body)
+	}
+
+	rec = serve(e, http.MethodPost, "/policy-enforcer/import", `{"organization": "VU", "grants": [{"archetypes": ["computeToData"]}]}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /import with a grant lacking a requester = %d %s, want 400", rec.Code, rec.Body)
+	}
+}
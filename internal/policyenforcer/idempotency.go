@@ -0,0 +1,93 @@
+package policyenforcer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Idempotency Cache
+// -----------------------------------------------------------------------------
+
+// IdempotencyKeyHeader is the request header clients use to make retries of
+// POST /policy-enforcer/validate safe and deterministic.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// defaultIdempotencyTTL is how long a validation result is remembered for a key.
+const defaultIdempotencyTTL = 5 * time.Minute
+
+// idempotencyEntry is a cached validation result for a single idempotency key, or
+// the asynchronous job computing it.
+type idempotencyEntry struct {
+	bodyHash  string              // Hash of the request body the result was computed for
+	response  *ValidationResponse // The cached result; nil for an asynchronous validation
+	jobID     string              // The job of an asynchronous validation
+	expiresAt time.Time           // When the entry stops being valid
+}
+
+// idempotencyCache remembers validation results per idempotency key for a short TTL.
+// Thread-safe for concurrent access.
+type idempotencyCache struct {
+	ttl     time.Duration
+	entries map[string]idempotencyEntry
+	mu      sync.Mutex
+}
+
+// newIdempotencyCache creates an empty cache with the given TTL.
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+// get returns the cached entry for key, if one exists and has not expired.
+func (c *idempotencyCache) get(key string) (idempotencyEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return idempotencyEntry{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return idempotencyEntry{}, false
+	}
+	return entry, true
+}
+
+// put stores a result for key and evicts any expired entries.
+func (c *idempotencyCache) put(key, bodyHash string, response *ValidationResponse) {
+	c.store(key, idempotencyEntry{bodyHash: bodyHash, response: response})
+}
+
+// putJob stores the job of an asynchronous validation for key, so that a retry
+// returns the same job instead of queueing another one.
+func (c *idempotencyCache) putJob(key, bodyHash, jobID string) {
+	c.store(key, idempotencyEntry{bodyHash: bodyHash, jobID: jobID})
+}
+
+// store sets the expiry of entry, stores it for key and evicts any expired entries.
+func (c *idempotencyCache) store(key string, entry idempotencyEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	entry.expiresAt = now.Add(c.ttl)
+	c.entries[key] = entry
+}
+
+// hashBody returns a hex-encoded SHA-256 hash of a request body.
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,277 @@
+package policyenforcer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// -----------------------------------------------------------------------------
+// Asynchronous Validation Jobs
+// -----------------------------------------------------------------------------
+//
+// On large models a validation can take seconds. With ?async=true,
+// POST /policy-enforcer/validate enqueues the validation and answers 202 with a
+// job ID right away; the client polls GET /policy-enforcer/jobs/:id for the
+// result. Jobs are run by a fixed pool of workers and kept in memory: a finished
+// job can be fetched until its TTL expires, and all jobs are lost on restart. On
+// shutdown, the queue stops accepting jobs and the workers finish the queued ones.
+
+// AsyncConfig configures asynchronous validation jobs.
+type AsyncConfig struct {
+	Enabled   bool          // Whether ?async=true is accepted
+	Workers   int           // Validations run concurrently
+	QueueSize int           // Jobs waiting for a worker; further jobs are rejected with 503
+	TTL       time.Duration // How long a finished job can be fetched
+	Timeout   time.Duration // Maximum duration of a single validation (0 = no limit)
+}
+
+// DefaultAsyncConfig returns default settings for asynchronous validation (disabled).
+func DefaultAsyncConfig() AsyncConfig {
+	return AsyncConfig{
+		Enabled:   false,
+		Workers:   4,
+		QueueSize: 100,
+		TTL:       10 * time.Minute,
+		Timeout:   5 * time.Minute,
+	}
+}
+
+// JobStatus is the state of an asynchronous validation job.
+type JobStatus = api.JobStatus
+
+const (
+	JobPending   = api.JobPending   // Waiting for a worker
+	JobRunning   = api.JobRunning   // Being validated
+	JobSucceeded = api.JobSucceeded // Done; the result is available
+	JobFailed    = api.JobFailed    // Done; the validation returned an error
+)
+
+// errJobQueueFull is returned by submit when no more jobs can be queued.
+var errJobQueueFull = errors.New("validation job queue is full")
+
+// errJobQueueClosed is returned by submit once the queue is shutting down.
+var errJobQueueClosed = errors.New("validation job queue is shut down")
+
+// minJobEvictInterval bounds how often expired jobs are evicted for short TTLs.
+const minJobEvictInterval = time.Second
+
+// validationJob is a queued or finished asynchronous validation.
+type validationJob struct {
+	id      string
+	ctx     context.Context // Request context without its cancellation, for request-scoped logging
+	params  ValidateRequestParams
+	suggest bool
+
+	// Guarded by jobQueue.mu
+	status     JobStatus
+	createdAt  time.Time
+	finishedAt time.Time
+	result     *ValidationResponse
+	err        error
+}
+
+// view returns the job as returned to clients. Caller must hold jobQueue.mu.
+func (j *validationJob) view() *ValidationJobResponse {
+	response := &ValidationJobResponse{
+		JobID:     j.id,
+		Status:    j.status,
+		CreatedAt: j.createdAt,
+		Result:    j.result,
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		response.FinishedAt = &finishedAt
+	}
+	if j.err != nil {
+		appErr := apperr.From(j.err)
+		response.Error = &apperr.Response{Error: appErr.Message, Code: appErr.Code}
+	}
+	return response
+}
+
+// jobRunner validates a job; it is called by the workers.
+type jobRunner func(ctx context.Context, job *validationJob) (*ValidationResponse, error)
+
+// jobQueue holds the asynchronous validation jobs and runs them on a fixed pool
+// of workers until shutdown is called. Thread-safe for concurrent access.
+type jobQueue struct {
+	config  AsyncConfig
+	run     jobRunner
+	queue   chan *validationJob
+	jobs    map[string]*validationJob
+	mu      sync.Mutex
+	closed  bool               // Set by shutdown; guarded by mu
+	done    chan struct{}      // Closed by shutdown, stopping the eviction loop
+	workers sync.WaitGroup     // Workers still running
+	ctx     context.Context    // Parent of the running validations' cancellation
+	stop    context.CancelFunc // Cancels ctx when shutdown gives up waiting
+}
+
+// newJobQueue creates a job queue and starts its workers.
+func newJobQueue(config AsyncConfig, run jobRunner) *jobQueue {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+
+	q := &jobQueue{
+		config: config,
+		run:    run,
+		queue:  make(chan *validationJob, config.QueueSize),
+		jobs:   make(map[string]*validationJob),
+		done:   make(chan struct{}),
+	}
+	q.ctx, q.stop = context.WithCancel(context.Background())
+	for i := 0; i < config.Workers; i++ {
+		q.workers.Add(1)
+		go q.worker()
+	}
+	go q.evictLoop()
+	return q
+}
+
+// shutdown stops accepting jobs and waits until the workers have finished the
+// queued and running ones. If ctx is done first, the running validations are
+// cancelled, so the remaining jobs fail quickly, and ctx's error is returned.
+func (q *jobQueue) shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+		close(q.done)
+	}
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		q.stop()
+		return ctx.Err()
+	}
+}
+
+// submit queues a job and returns its initial view, or errJobQueueFull if all
+// workers are busy and the queue is full and errJobQueueClosed after shutdown.
+func (q *jobQueue) submit(ctx context.Context, params ValidateRequestParams, suggest bool) (*ValidationJobResponse, error) {
+	job := &validationJob{
+		id:        newJobID(),
+		ctx:       context.WithoutCancel(ctx),
+		params:    params,
+		suggest:   suggest,
+		status:    JobPending,
+		createdAt: time.Now(),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, errJobQueueClosed
+	}
+	select {
+	case q.queue <- job:
+	default:
+		return nil, errJobQueueFull
+	}
+	q.jobs[job.id] = job
+	return job.view(), nil
+}
+
+// get returns the view and organization of a job, if it exists and has not expired.
+func (q *jobQueue) get(id string) (*ValidationJobResponse, string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok || q.expired(job, time.Now()) {
+		return nil, "", false
+	}
+	return job.view(), job.params.Organization, true
+}
+
+// worker runs queued jobs until the queue is shut down and empty.
+func (q *jobQueue) worker() {
+	defer q.workers.Done()
+	for job := range q.queue {
+		q.mu.Lock()
+		job.status = JobRunning
+		q.mu.Unlock()
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if q.config.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(job.ctx, q.config.Timeout)
+		} else {
+			ctx, cancel = context.WithCancel(job.ctx)
+		}
+		stop := context.AfterFunc(q.ctx, cancel)
+		result, err := q.run(ctx, job)
+		stop()
+		cancel()
+
+		q.mu.Lock()
+		job.status = JobSucceeded
+		if err != nil {
+			job.status = JobFailed
+		}
+		job.result = result
+		job.err = err
+		job.finishedAt = time.Now()
+		q.mu.Unlock()
+	}
+}
+
+// expired reports whether a finished job has outlived its TTL. Caller must hold mu.
+func (q *jobQueue) expired(job *validationJob, now time.Time) bool {
+	return !job.finishedAt.IsZero() && now.Sub(job.finishedAt) > q.config.TTL
+}
+
+// evictLoop periodically removes the finished jobs that have outlived their TTL,
+// so that their results do not stay in memory when no further jobs are submitted.
+func (q *jobQueue) evictLoop() {
+	ticker := time.NewTicker(max(q.config.TTL/2, minJobEvictInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case now := <-ticker.C:
+			q.mu.Lock()
+			q.evictExpired(now)
+			q.mu.Unlock()
+		}
+	}
+}
+
+// evictExpired removes the finished jobs that have outlived their TTL. Caller must hold mu.
+func (q *jobQueue) evictExpired(now time.Time) {
+	for id, job := range q.jobs {
+		if q.expired(job, now) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random, unguessable job ID.
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
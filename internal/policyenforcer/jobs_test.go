@@ -0,0 +1,169 @@
+package policyenforcer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+func TestJobQueueShutdownDrainsQueuedJobs(t *testing.T) {
+	release := make(chan struct{})
+	q := newJobQueue(AsyncConfig{Workers: 1, QueueSize: 3, TTL: time.Minute}, func(ctx context.Context, job *validationJob) (*ValidationResponse, error) {
+		<-release
+		return &ValidationResponse{Allowed: true}, nil
+	})
+
+	var ids []string
+	for range 3 {
+		job, err := q.submit(context.Background(), ValidateRequestParams{Organization: "VU"}, false)
+		if err != nil {
+			t.Fatalf("submit() error = %v", err)
+		}
+		ids = append(ids, job.JobID)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- q.shutdown(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := q.submit(context.Background(), ValidateRequestParams{}, false); !errors.Is(err, errJobQueueClosed) {
+		t.Errorf("submit() during shutdown error = %v, want errJobQueueClosed", err)
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	for _, id := range ids {
+		if job, _, ok := q.get(id); !ok || job.Status != JobSucceeded {
+			t.Errorf("job %s = %+v, want it finished by the drain", id, job)
+		}
+	}
+}
+
+func TestJobQueueShutdownCancelsRunningJobs(t *testing.T) {
+	running := make(chan struct{})
+	q := newJobQueue(AsyncConfig{Workers: 1, QueueSize: 1, TTL: time.Minute}, func(ctx context.Context, job *validationJob) (*ValidationResponse, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	job, err := q.submit(context.Background(), ValidateRequestParams{}, false)
+	if err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The cancelled validation finishes the job
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		view, _, _ := q.get(job.JobID)
+		if view.Status == JobFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job = %+v, want it failed after the cancellation", view)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobQueueEvictsExpiredJobs(t *testing.T) {
+	q := newJobQueue(AsyncConfig{Workers: 1, QueueSize: 1, TTL: time.Millisecond}, func(ctx context.Context, job *validationJob) (*ValidationResponse, error) {
+		return &ValidationResponse{}, nil
+	})
+	defer q.shutdown(context.Background())
+
+	if _, err := q.submit(context.Background(), ValidateRequestParams{}, false); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+
+	// No further submissions: the eviction loop alone must remove the job
+	deadline := time.Now().Add(3 * minJobEvictInterval)
+	for {
+		q.mu.Lock()
+		n := len(q.jobs)
+		q.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d expired jobs kept in memory", n)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestAsyncValidationIsIdempotent(t *testing.T) {
+	var validations atomic.Int32
+	config := DefaultHTTPHandlerConfig()
+	config.Async.Enabled = true
+	e, _ := newTestServer(t, &fakeReasoner{validate: func(ctx context.Context, params reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+		validations.Add(1)
+		return permitAll(ctx, params)
+	}}, config)
+
+	body := `{"organization": "VU", "requester": "user@example.com", "request_type": "sqlDataRequest", "data_set": "wageGap", "archetype": "computeToData", "compute_provider": "surf"}`
+	header := http.Header{IdempotencyKeyHeader: {"key-1"}}
+
+	first := serve(e, http.MethodPost, "/policy-enforcer/validate?async=true", body, header)
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("first POST = %d %s, want 202", first.Code, first.Body)
+	}
+	retry := serve(e, http.MethodPost, "/policy-enforcer/validate?async=true", body, header)
+	if retry.Code != http.StatusAccepted {
+		t.Fatalf("retried POST = %d %s, want 202", retry.Code, retry.Body)
+	}
+
+	var firstJob, retriedJob ValidationJobResponse
+	json.Unmarshal(first.Body.Bytes(), &firstJob)
+	json.Unmarshal(retry.Body.Bytes(), &retriedJob)
+	if firstJob.JobID == "" || retriedJob.JobID != firstJob.JobID {
+		t.Errorf("retry returned job %q, want %q", retriedJob.JobID, firstJob.JobID)
+	}
+	if loc := retry.Header().Get("Location"); loc != "/policy-enforcer/jobs/"+firstJob.JobID {
+		t.Errorf("Location = %q", loc)
+	}
+
+	// The same key without async names a different request
+	if rec := serve(e, http.MethodPost, "/policy-enforcer/validate", body, header); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("synchronous POST with the key = %d, want 422", rec.Code)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := serve(e, http.MethodGet, "/policy-enforcer/jobs/"+firstJob.JobID, "", nil)
+		var job ValidationJobResponse
+		json.Unmarshal(rec.Body.Bytes(), &job)
+		if job.Status == JobSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job = %s, want it to succeed", rec.Body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := validations.Load(); n != 1 {
+		t.Errorf("validated %d times, want once", n)
+	}
+}
+
+func TestAsyncValidationDisabledByDefault(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, nil)
+
+	body := `{"organization": "VU", "requester": "user@example.com", "request_type": "sqlDataRequest", "data_set": "wageGap", "archetype": "computeToData", "compute_provider": "surf"}`
+	if rec := serve(e, http.MethodPost, "/policy-enforcer/validate?async=true", body, nil); rec.Code != http.StatusNotImplemented {
+		t.Errorf("POST ?async=true = %d, want 501", rec.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package policyenforcer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+// fakeReasoner is a reasoner that answers validations with validate and has no
+// allowed clauses.
+type fakeReasoner struct {
+	validate func(ctx context.Context, params reasoner.RequestParams) (*reasoner.RequestValidationResult, error)
+}
+
+func (r *fakeReasoner) GetAllowedRequestTypes(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeReasoner) GetAllowedDataSets(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeReasoner) GetAllowedArchetypes(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeReasoner) GetAllowedComputeProviders(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeReasoner) GetAllAllowedClauses(context.Context, string, string) (*reasoner.AllAllowedClauses, error) {
+	return &reasoner.AllAllowedClauses{}, nil
+}
+
+func (r *fakeReasoner) IsRequestAllowed(ctx context.Context, params reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+	return r.validate(ctx, params)
+}
+
+func (r *fakeReasoner) IsRunning() bool { return true }
+
+func (r *fakeReasoner) Name() string { return "fake" }
+
+// permitAll answers every validation with a permission.
+func permitAll(context.Context, reasoner.RequestParams) (*reasoner.RequestValidationResult, error) {
+	return &reasoner.RequestValidationResult{Allowed: true, ReasonCode: reasoner.ReasonPermitted}, nil
+}
+
+// newTestServer serves the policy enforcer API for r with the given handler
+// configuration, rendering errors as the service does.
+func newTestServer(t *testing.T, r reasoner.Reasoner, config *HTTPHandlerConfig) (*echo.Echo, *HTTPHandler) {
+	t.Helper()
+	return newTestServerWithEnforcer(t, r, nil, config)
+}
+
+// newTestServerWithEnforcer is like newTestServer with a custom enforcer
+// configuration.
+func newTestServerWithEnforcer(t *testing.T, r reasoner.Reasoner, enforcerConfig *EnforcerConfig, config *HTTPHandlerConfig) (*echo.Echo, *HTTPHandler) {
+	t.Helper()
+	e := echo.New()
+	e.HTTPErrorHandler = apperr.HTTPErrorHandler(zap.NewNop())
+
+	enforcer := NewEnforcer(r, enforcerConfig, zap.NewNop())
+	h := NewHTTPHandler(enforcer, config, zap.NewNop())
+	h.RegisterRoutes(e.Group("/policy-enforcer"))
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+	return e, h
+}
+
+// serve sends a request to e and returns the recorded response. A body is sent
+// as JSON.
+func serve(e *echo.Echo, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
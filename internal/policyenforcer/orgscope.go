@@ -0,0 +1,239 @@
+package policyenforcer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/auth"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// -----------------------------------------------------------------------------
+// Organization Scope
+// -----------------------------------------------------------------------------
+//
+// In a shared deployment each caller may only query the organizations it belongs
+// to. When org scoping is enabled, every request must identify its principal:
+// with JWT authentication it is the token's PrincipalClaim, otherwise it is set
+// by an authenticating proxy in PrincipalHeader (gRPC metadata of the same name
+// for GRPCHandler). The principal's allowed organizations are looked up once per
+// request, and every organization named by the request is checked against them;
+// cross-organization queries get 403.
+
+// DefaultPrincipalHeader is the header carrying the authenticated principal.
+const DefaultPrincipalHeader = "X-Principal"
+
+// DefaultPrincipalClaim is the JWT claim naming the principal.
+const DefaultPrincipalClaim = "sub"
+
+// AllOrganizations grants a principal access to every organization.
+const AllOrganizations = "*"
+
+// orgScopeContextKey is the Echo context key holding the request's *orgScope.
+const orgScopeContextKey = "policyenforcer.org_scope"
+
+// OrgScopeConfig configures organization scoping for the policy enforcer API.
+type OrgScopeConfig struct {
+	Enabled         bool                // Whether requests are restricted to the principal's organizations
+	PrincipalHeader string              // Header carrying the principal (default: X-Principal)
+	PrincipalClaim  string              // JWT claim naming the principal when authenticated (default: sub)
+	Organizations   map[string][]string // Static mapping of principal to allowed organizations ("*" = all)
+	Authorizer      OrgAuthorizer       // Custom principal lookup; overrides Organizations if set
+}
+
+// OrgAuthorizer decides which organizations a principal may access. Implement it
+// to look principals up in an external directory instead of static configuration.
+type OrgAuthorizer interface {
+	// AllowedOrganizations returns the organizations the principal may access.
+	// An AllOrganizations entry grants access to every organization.
+	AllowedOrganizations(ctx context.Context, principal string) ([]string, error)
+}
+
+// StaticOrgAuthorizer maps principals to organizations from configuration.
+// Principals are matched case-insensitively.
+type StaticOrgAuthorizer map[string][]string
+
+// NewStaticOrgAuthorizer creates an authorizer from a principal → organizations mapping.
+func NewStaticOrgAuthorizer(mapping map[string][]string) StaticOrgAuthorizer {
+	a := make(StaticOrgAuthorizer, len(mapping))
+	for principal, orgs := range mapping {
+		key := strings.ToLower(principal)
+		a[key] = append(a[key], orgs...)
+	}
+	return a
+}
+
+// AllowedOrganizations returns the configured organizations of the principal.
+// Unknown principals may access no organization.
+func (a StaticOrgAuthorizer) AllowedOrganizations(_ context.Context, principal string) ([]string, error) {
+	return a[strings.ToLower(principal)], nil
+}
+
+// orgScope is the set of organizations the current principal may access.
+type orgScope struct {
+	principal string
+	all       bool
+	orgs      map[string]bool
+}
+
+// allows reports whether the scope includes the organization.
+func (s *orgScope) allows(organization string) bool {
+	return s.all || s.orgs[organization]
+}
+
+// check verifies that the scope includes every given organization and returns a
+// 403 error otherwise. A nil scope (org scoping disabled) includes every organization.
+func (s *orgScope) check(organizations ...string) error {
+	if s == nil {
+		return nil
+	}
+	for _, org := range organizations {
+		if !s.allows(org) {
+			return apperr.Forbidden("%s is not authorized for organization %q", s.principal, org)
+		}
+	}
+	return nil
+}
+
+// orgScoper resolves the scope of each request.
+type orgScoper struct {
+	header     string
+	claim      string
+	authorizer OrgAuthorizer
+}
+
+// newOrgScoper creates an org scoper from the given configuration.
+func newOrgScoper(config OrgScopeConfig) *orgScoper {
+	s := &orgScoper{
+		header:     config.PrincipalHeader,
+		claim:      config.PrincipalClaim,
+		authorizer: config.Authorizer,
+	}
+	if s.header == "" {
+		s.header = DefaultPrincipalHeader
+	}
+	if s.claim == "" {
+		s.claim = DefaultPrincipalClaim
+	}
+	if s.authorizer == nil {
+		s.authorizer = NewStaticOrgAuthorizer(config.Organizations)
+	}
+	return s
+}
+
+// middleware returns Echo middleware that resolves the principal's scope and stores
+// it in the context. Requests without a principal are rejected with 401.
+func (s *orgScoper) middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scope, err := s.resolve(c.Request().Context(), c.Request().Header.Get)
+			if err != nil {
+				return err
+			}
+			c.Set(orgScopeContextKey, scope)
+
+			return next(c)
+		}
+	}
+}
+
+// resolve looks up the scope of the request's principal. header returns the
+// value of a request header (or gRPC metadata key). Requests without a principal
+// get a 401 error.
+func (s *orgScoper) resolve(ctx context.Context, header func(name string) string) (*orgScope, error) {
+	principal, err := s.principal(ctx, header)
+	if err != nil {
+		return nil, apperr.Unauthorized("%v", err)
+	}
+
+	orgs, err := s.authorizer.AllowedOrganizations(ctx, principal)
+	if err != nil {
+		return nil, apperr.Wrap(err, http.StatusInternalServerError, apperr.CodeInternal, "failed to resolve organization scope")
+	}
+
+	scope := &orgScope{principal: principal, orgs: make(map[string]bool, len(orgs))}
+	for _, org := range orgs {
+		if org == AllOrganizations {
+			scope.all = true
+		}
+		scope.orgs[org] = true
+	}
+	return scope, nil
+}
+
+// principal returns the request's principal. Authenticated requests are identified
+// by their token only, so a client cannot pick another principal via the header.
+func (s *orgScoper) principal(ctx context.Context, header func(name string) string) (string, error) {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		principal := claims.String(s.claim)
+		if principal == "" {
+			return "", fmt.Errorf("token claim %q is required", s.claim)
+		}
+		return principal, nil
+	}
+
+	principal := header(s.header)
+	if principal == "" {
+		return "", fmt.Errorf("%s header is required", s.header)
+	}
+	return principal, nil
+}
+
+// checkOrgScope verifies that the principal may access every given organization
+// and returns a 403 error otherwise. Without org scoping every organization is allowed.
+func (h *HTTPHandler) checkOrgScope(c echo.Context, organizations ...string) error {
+	scope, _ := c.Get(orgScopeContextKey).(*orgScope)
+	return scope.check(organizations...)
+}
+
+// checkAllOrgScope verifies that the principal may access every organization and
+// returns a 403 error otherwise. Without org scoping it always succeeds.
+func (h *HTTPHandler) checkAllOrgScope(c echo.Context) error {
+	scope, ok := c.Get(orgScopeContextKey).(*orgScope)
+	if !ok || scope.all {
+		return nil
+	}
+	return apperr.Forbidden("%s is not authorized for all organizations", scope.principal)
+}
+
+// scopeFacts drops the facts the principal may not see: those naming an
+// organization outside its scope, and, unless it may access every organization,
+// those naming no organization at all. Without org scoping all facts are kept.
+func (h *HTTPHandler) scopeFacts(c echo.Context, facts []eflint.Fact) []eflint.Fact {
+	scope, ok := c.Get(orgScopeContextKey).(*orgScope)
+	if !ok || scope.all {
+		return facts
+	}
+
+	return slices.DeleteFunc(facts, func(fact eflint.Fact) bool {
+		named := false
+		for _, arg := range fact.Arguments {
+			if arg.FactType != "organization" {
+				continue
+			}
+			if !scope.allows(arg.Value) {
+				return true
+			}
+			named = true
+		}
+		return !named
+	})
+}
+
+// scopeClauseChanges removes the changes at organizations outside the principal's scope.
+func (h *HTTPHandler) scopeClauseChanges(c echo.Context, changes []ClauseChange) []ClauseChange {
+	scope, ok := c.Get(orgScopeContextKey).(*orgScope)
+	if !ok || scope.all {
+		return changes
+	}
+
+	return slices.DeleteFunc(changes, func(change ClauseChange) bool {
+		return !scope.allows(change.Organization)
+	})
+}
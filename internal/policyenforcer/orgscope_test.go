@@ -0,0 +1,165 @@
+package policyenforcer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/auth"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// orgScopeConfig returns a handler configuration scoping alice to VU and admin
+// to every organization.
+func orgScopeConfig() *HTTPHandlerConfig {
+	config := DefaultHTTPHandlerConfig()
+	config.OrgScope = OrgScopeConfig{
+		Enabled: true,
+		Organizations: map[string][]string{
+			"alice": {"VU"},
+			"admin": {AllOrganizations},
+		},
+	}
+	return config
+}
+
+// principal returns the header identifying the principal of a request.
+func principal(name string) http.Header {
+	return http.Header{DefaultPrincipalHeader: {name}}
+}
+
+// errorCode returns the error code of a JSON error response.
+func errorCode(body []byte) string {
+	var resp apperr.Response
+	json.Unmarshal(body, &resp)
+	return resp.Code
+}
+
+func TestOrgScopeRejectsCrossOrganizationQueries(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, orgScopeConfig())
+
+	tests := []struct {
+		name      string
+		principal string
+		target    string
+		want      int
+	}{
+		{"own organization", "alice", "/policy-enforcer/allowed-archetypes?organization=VU&requester=user", http.StatusOK},
+		{"other organization", "alice", "/policy-enforcer/allowed-archetypes?organization=UvA&requester=user", http.StatusForbidden},
+		{"principal matched case-insensitively", "Alice", "/policy-enforcer/allowed-archetypes?organization=VU&requester=user", http.StatusOK},
+		{"unknown principal", "mallory", "/policy-enforcer/allowed-archetypes?organization=VU&requester=user", http.StatusForbidden},
+		{"all organizations", "admin", "/policy-enforcer/allowed-archetypes?organization=UvA&requester=user", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, tt.target, "", principal(tt.principal))
+			if rec.Code != tt.want {
+				t.Fatalf("GET %s as %s = %d %s, want %d", tt.target, tt.principal, rec.Code, rec.Body, tt.want)
+			}
+			if tt.want == http.StatusForbidden {
+				if code := errorCode(rec.Body.Bytes()); code != apperr.CodeForbidden {
+					t.Errorf("error code = %q, want %q", code, apperr.CodeForbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestOrgScopeRequiresPrincipal(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, orgScopeConfig())
+
+	rec := serve(e, http.MethodGet, "/policy-enforcer/allowed-archetypes?organization=VU&requester=user", "", nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("GET without a principal = %d %s, want 401", rec.Code, rec.Body)
+	}
+	if code := errorCode(rec.Body.Bytes()); code != apperr.CodeUnauthorized {
+		t.Errorf("error code = %q, want %q", code, apperr.CodeUnauthorized)
+	}
+}
+
+func TestOrgScopeChecksEveryBatchPair(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, orgScopeConfig())
+
+	own := `{"pairs": [{"organization": "VU", "requester": "a"}, {"organization": "VU", "requester": "b"}]}`
+	if rec := serve(e, http.MethodPost, "/policy-enforcer/allowed-clauses-batch", own, principal("alice")); rec.Code != http.StatusOK {
+		t.Errorf("batch within the scope = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	spanning := `{"pairs": [{"organization": "VU", "requester": "a"}, {"organization": "UvA", "requester": "b"}]}`
+	rec := serve(e, http.MethodPost, "/policy-enforcer/allowed-clauses-batch", spanning, principal("alice"))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "UvA") {
+		t.Errorf("batch spanning organizations = %d %s, want 403 naming UvA", rec.Code, rec.Body)
+	}
+	if rec := serve(e, http.MethodPost, "/policy-enforcer/allowed-clauses-batch", spanning, principal("admin")); rec.Code != http.StatusOK {
+		t.Errorf("batch spanning organizations for all organizations = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
+
+func TestOrgScopeUsesTokenClaims(t *testing.T) {
+	e, _ := newTestServer(t, &fakeReasoner{validate: permitAll}, orgScopeConfig())
+	// Stands in for the JWT middleware, storing the claims of a validated token
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var claims auth.Claims
+			if err := json.Unmarshal([]byte(c.Request().Header.Get("X-Test-Claims")), &claims); err != nil {
+				return next(c)
+			}
+			c.SetRequest(c.Request().WithContext(auth.NewContext(c.Request().Context(), claims)))
+			return next(c)
+		}
+	})
+	request := func(organization, claims, header string) int {
+		h := principal(header)
+		h.Set("X-Test-Claims", claims)
+		return serve(e, http.MethodGet, "/policy-enforcer/allowed-archetypes?organization="+organization+"&requester=user", "", h).Code
+	}
+
+	// The header cannot widen the scope of an authenticated principal
+	if code := request("UvA", `{"sub": "alice"}`, "admin"); code != http.StatusForbidden {
+		t.Errorf("token for alice with header admin = %d, want 403", code)
+	}
+	if code := request("VU", `{"sub": "alice"}`, "admin"); code != http.StatusOK {
+		t.Errorf("token for alice = %d, want 200", code)
+	}
+	if code := request("VU", `{"iss": "idp"}`, "alice"); code != http.StatusUnauthorized {
+		t.Errorf("token without the principal claim = %d, want 401", code)
+	}
+}
+
+// grant returns an allowed-archetype fact of the organization.
+func grant(organization string) eflint.Fact {
+	return eflint.Fact{FactType: "allowed-archetype", Arguments: []eflint.FactArgument{
+		{FactType: "organization", Value: organization},
+		{FactType: "requester", Value: "user"},
+		{FactType: "archetype", Value: "computeToData"},
+	}}
+}
+
+func TestOrgScopeFiltersFacts(t *testing.T) {
+	r := &factsReasoner{facts: []eflint.Fact{
+		grant("VU"),
+		grant("UvA"),
+		{FactType: "archetype", Arguments: []eflint.FactArgument{{FactType: "archetype", Value: "computeToData"}}},
+	}}
+	e, _ := newTestServer(t, r, orgScopeConfig())
+
+	rec := serve(e, http.MethodPost, "/policy-enforcer/query-facts", `{"fact_type": "allowed-archetype"}`, principal("alice"))
+	var facts struct{ Count int }
+	json.Unmarshal(rec.Body.Bytes(), &facts)
+	if rec.Code != http.StatusOK || facts.Count != 1 || strings.Contains(rec.Body.String(), "UvA") {
+		t.Errorf("POST /query-facts as alice = %d %s, want only the VU grant", rec.Code, rec.Body)
+	}
+
+	for name, want := range map[string]int{"alice": 1, "admin": 3} {
+		rec := serve(e, http.MethodGet, "/policy-enforcer/stats", "", principal(name))
+		var stats PolicyStatsResponse
+		json.Unmarshal(rec.Body.Bytes(), &stats)
+		if rec.Code != http.StatusOK || stats.TotalFacts != want {
+			t.Errorf("GET /stats as %s = %d %s, want %d facts counted", name, rec.Code, rec.Body, want)
+		}
+	}
+}
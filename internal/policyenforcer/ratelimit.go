@@ -0,0 +1,190 @@
+package policyenforcer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+)
+
+// -----------------------------------------------------------------------------
+// Rate Limiting
+// -----------------------------------------------------------------------------
+
+// RateLimitConfig configures token-bucket rate limiting for the policy enforcer API.
+// Limits apply both per requester and globally across all requesters.
+type RateLimitConfig struct {
+	Enabled        bool    // Whether rate limiting is applied
+	RequesterRate  float64 // Sustained requests per second allowed per requester
+	RequesterBurst int     // Maximum burst size per requester
+	GlobalRate     float64 // Sustained requests per second allowed in total
+	GlobalBurst    int     // Maximum burst size in total
+}
+
+// DefaultRateLimitConfig returns generous default rate limits (disabled by default).
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Enabled:        false,
+		RequesterRate:  20,
+		RequesterBurst: 40,
+		GlobalRate:     200,
+		GlobalBurst:    400,
+	}
+}
+
+// requesterIdleTTL is how long an unused per-requester bucket is kept.
+const requesterIdleTTL = 10 * time.Minute
+
+// requesterLimiter is a token bucket for a single requester.
+type requesterLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter holds the global and per-requester token buckets.
+// Thread-safe for concurrent access.
+type rateLimiter struct {
+	config     RateLimitConfig
+	global     *rate.Limiter
+	requesters map[string]*requesterLimiter
+	lastSweep  time.Time
+	mu         sync.Mutex
+}
+
+// newRateLimiter creates a rate limiter from the given configuration.
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:     config,
+		global:     rate.NewLimiter(rate.Limit(config.GlobalRate), config.GlobalBurst),
+		requesters: make(map[string]*requesterLimiter),
+		lastSweep:  time.Now(),
+	}
+}
+
+// setLimits changes the rates and bursts of the global and all requester buckets.
+// Tokens already in the buckets are kept.
+func (l *rateLimiter) setLimits(config RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.config = config
+	l.global.SetLimit(rate.Limit(config.GlobalRate))
+	l.global.SetBurst(config.GlobalBurst)
+	for _, rl := range l.requesters {
+		rl.limiter.SetLimit(rate.Limit(config.RequesterRate))
+		rl.limiter.SetBurst(config.RequesterBurst)
+	}
+}
+
+// reserve takes a token for the requester and globally. It returns zero if the
+// request may proceed, or how long the client should wait before retrying.
+func (l *rateLimiter) reserve(requester string) time.Duration {
+	now := time.Now()
+
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) > requesterIdleTTL {
+		for key, rl := range l.requesters {
+			if now.Sub(rl.lastSeen) > requesterIdleTTL {
+				delete(l.requesters, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	rl, ok := l.requesters[requester]
+	if !ok {
+		rl = &requesterLimiter{
+			limiter: rate.NewLimiter(rate.Limit(l.config.RequesterRate), l.config.RequesterBurst),
+		}
+		l.requesters[requester] = rl
+	}
+	rl.lastSeen = now
+	l.mu.Unlock()
+
+	requesterRes := rl.limiter.ReserveN(now, 1)
+	if delay := requesterRes.DelayFrom(now); !requesterRes.OK() || delay > 0 {
+		requesterRes.CancelAt(now)
+		return retryDelay(requesterRes, delay)
+	}
+
+	globalRes := l.global.ReserveN(now, 1)
+	if delay := globalRes.DelayFrom(now); !globalRes.OK() || delay > 0 {
+		globalRes.CancelAt(now)
+		requesterRes.CancelAt(now)
+		return retryDelay(globalRes, delay)
+	}
+
+	return 0
+}
+
+// retryDelay returns the delay to report for a rejected reservation.
+func retryDelay(r *rate.Reservation, delay time.Duration) time.Duration {
+	if !r.OK() || delay <= 0 {
+		return time.Second
+	}
+	return delay
+}
+
+// middleware returns Echo middleware that rejects over-limit requests with 429.
+// The requester is taken from the "requester" query parameter or, for JSON bodies,
+// the "requester" field; requests without a requester share an anonymous bucket.
+func (l *rateLimiter) middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			delay := l.reserve(requesterFromRequest(c))
+			if delay > 0 {
+				seconds := int(math.Ceil(delay.Seconds()))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+				return apperr.New(http.StatusTooManyRequests, apperr.CodeRateLimited, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+// requesterFromRequest extracts the requester from the query string, or from a
+// JSON or form-encoded body. The body is restored so that downstream handlers can
+// still bind it.
+func requesterFromRequest(c echo.Context) string {
+	if requester := c.QueryParam("requester"); requester != "" {
+		return requester
+	}
+
+	req := c.Request()
+	if req.Body == nil || req.Method == http.MethodGet {
+		return ""
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		return form.Get("requester")
+	}
+
+	var payload struct {
+		Requester string `json:"requester"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.Requester
+}
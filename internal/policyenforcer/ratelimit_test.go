@@ -0,0 +1,48 @@
+package policyenforcer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequesterFromRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"query", http.MethodGet, "/?requester=alice", "", "", "alice"},
+		{"query before body", http.MethodPost, "/?requester=alice", echo.MIMEApplicationJSON, `{"requester": "bob"}`, "alice"},
+		{"JSON", http.MethodPost, "/", echo.MIMEApplicationJSON, `{"requester": "bob"}`, "bob"},
+		{"form", http.MethodPost, "/", echo.MIMEApplicationForm, "organization=VU&requester=carol%40example.com", "carol@example.com"},
+		{"form with charset", http.MethodPost, "/", echo.MIMEApplicationForm + "; charset=utf-8", "requester=dave", "dave"},
+		{"malformed JSON", http.MethodPost, "/", echo.MIMEApplicationJSON, `{"requester":`, ""},
+		{"GET body", http.MethodGet, "/", echo.MIMEApplicationJSON, `{"requester": "bob"}`, ""},
+	}
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			if got := requesterFromRequest(c); got != tt.want {
+				t.Errorf("requesterFromRequest() = %q, want %q", got, tt.want)
+			}
+			// The body is left for the handler to bind
+			if body, _ := io.ReadAll(c.Request().Body); string(body) != tt.body {
+				t.Errorf("body after requesterFromRequest() = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package policyenforcer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+)
+
+// -----------------------------------------------------------------------------
+// Strict JSON Bodies
+// -----------------------------------------------------------------------------
+
+// strictJSON returns middleware that rejects JSON bodies with fields that the
+// request type created by newRequest does not declare, e.g. a client sending
+// "requestType" instead of "request_type". Without it, Echo ignores such fields and
+// the client gets a confusing "missing required fields" error instead.
+//
+// Only the field names are checked; the body is restored for the handler to bind.
+func strictJSON(newRequest func() any) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				return apperr.BadRequest("invalid request body")
+			}
+			if len(bytes.TrimSpace(body)) == 0 {
+				return next(c)
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(newRequest()); err != nil {
+				if field, ok := unknownField(err); ok {
+					return apperr.BadRequest("unknown field %q", field).WithFields(field)
+				}
+				// Other decoding errors are reported by the handler's own binding
+			}
+			return next(c)
+		}
+	}
+}
+
+// unknownField returns the field named by an encoding/json "unknown field" error.
+func unknownField(err error) (string, bool) {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	field, unquoteErr := strconv.Unquote(quoted)
+	if unquoteErr != nil {
+		return quoted, true
+	}
+	return field, true
+}
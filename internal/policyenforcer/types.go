@@ -3,30 +3,68 @@
 // This allows the policy enforcer to work with different reasoning backends.
 package policyenforcer
 
-import "github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+import (
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// The request and response types are defined in pkg/api, so that clients in other
+// modules can share them; the aliases below keep the names used in this package.
 
 // -----------------------------------------------------------------------------
 // Request Types
 // -----------------------------------------------------------------------------
 
-// AllowedClausesRequest represents a request to get allowed clauses for a requester.
-type AllowedClausesRequest struct {
-	Organization string `json:"organization" validate:"required"` // The organization/steward
-	Requester    string `json:"requester" validate:"required"`    // The user/requester
-}
+type (
+	AllowedClausesRequest      = api.AllowedClausesRequest
+	AllowedOfTypeRequest       = api.AllowedOfTypeRequest
+	OrganizationRequest        = api.OrganizationRequest
+	RequestersAllowedRequest   = api.RequestersAllowedRequest
+	ClauseAllowedRequest       = api.ClauseAllowedRequest
+	RevokeClauseRequest        = api.RevokeClauseRequest
+	BatchAllowedClausesRequest = api.BatchAllowedClausesRequest
+	ValidateRequestParams      = api.ValidateRequestParams
+	SimulateChangeRequest      = api.SimulateChangeRequest
+	ExecuteActRequest          = api.ExecuteActRequest
+	ChangesSinceRequest        = api.ChangesSinceRequest
+	FactQuery                  = api.FactQuery
+	FactArgumentFilter         = api.FactArgumentFilter
+)
 
-// ValidateRequestParams represents a request to validate if a specific operation is allowed.
-type ValidateRequestParams struct {
-	Organization    string `json:"organization" validate:"required"`     // The data steward organization
-	Requester       string `json:"requester" validate:"required"`        // The user making the request
-	RequestType     string `json:"request_type" validate:"required"`     // Type of request (e.g., "sqlDataRequest")
-	DataSet         string `json:"data_set" validate:"required"`         // The dataset being requested
-	Archetype       string `json:"archetype" validate:"required"`        // The processing archetype
-	ComputeProvider string `json:"compute_provider" validate:"required"` // Where the computation runs
+// requestOrganizations returns every organization a request accesses, for the org
+// scope check. Requests that name no organization return nil.
+func requestOrganizations(req interface{}) []string {
+	switch r := req.(type) {
+	case *AllowedClausesRequest:
+		return []string{r.Organization}
+	case *AllowedOfTypeRequest:
+		return []string{r.Organization}
+	case *OrganizationRequest:
+		return []string{r.Organization}
+	case *RequestersAllowedRequest:
+		return []string{r.Organization}
+	case *RevokeClauseRequest:
+		return []string{r.Organization}
+	case *ClauseAllowedRequest:
+		return []string{r.Organization}
+	case *ValidateRequestParams:
+		return []string{r.Organization}
+	case *SimulateChangeRequest:
+		return []string{r.Organization}
+	case *reasoner.OrgPolicy:
+		return []string{r.Organization}
+	case *BatchAllowedClausesRequest:
+		orgs := make([]string, 0, len(r.Pairs))
+		for _, pair := range r.Pairs {
+			orgs = append(orgs, pair.Organization)
+		}
+		return orgs
+	}
+	return nil
 }
 
-// ToReasonerParams converts the request to reasoner.RequestParams.
-func (r *ValidateRequestParams) ToReasonerParams() reasoner.RequestParams {
+// toReasonerParams converts a validation request to reasoner.RequestParams.
+func toReasonerParams(r *ValidateRequestParams) reasoner.RequestParams {
 	return reasoner.RequestParams{
 		Organization:    r.Organization,
 		Requester:       r.Requester,
@@ -41,43 +79,41 @@ func (r *ValidateRequestParams) ToReasonerParams() reasoner.RequestParams {
 // Response Types
 // -----------------------------------------------------------------------------
 
-// AllowedClausesResponse represents the response containing allowed clauses.
-type AllowedClausesResponse struct {
-	Organization string   `json:"organization"` // The organization/steward
-	Requester    string   `json:"requester"`    // The user/requester
-	Values       []string `json:"values"`       // List of allowed values
-}
-
-// AllAllowedClausesResponse contains all allowed clauses for a requester at an organization.
-type AllAllowedClausesResponse struct {
-	Organization     string   `json:"organization"`      // The organization/steward
-	Requester        string   `json:"requester"`         // The user/requester
-	RequestTypes     []string `json:"request_types"`     // Allowed request types
-	DataSets         []string `json:"data_sets"`         // Allowed datasets
-	Archetypes       []string `json:"archetypes"`        // Allowed archetypes
-	ComputeProviders []string `json:"compute_providers"` // Allowed compute providers
-}
+type (
+	AllowedClausesResponse      = api.AllowedClausesResponse
+	AllAllowedClausesResponse   = api.AllAllowedClausesResponse
+	BatchAllowedClausesResponse = api.BatchAllowedClausesResponse
+	ValidationResponse          = api.ValidationResponse
+	ValidationJobResponse       = api.ValidationJobResponse
+	QuickCheckResponse          = api.QuickCheckResponse
+	RequestersAllowedResponse   = api.RequestersAllowedResponse
+	ClauseAllowedResponse       = api.ClauseAllowedResponse
+	RevokeClauseResponse        = api.RevokeClauseResponse
+	SimulateChangeResponse      = api.SimulateChangeResponse
+	ExecuteActResponse          = api.ExecuteActResponse
+	EnabledActsResponse         = api.EnabledActsResponse
+	ChangesSinceResponse        = api.ChangesSinceResponse
+	ClauseChange                = api.ClauseChange
+	QueryFactsResponse          = api.QueryFactsResponse
+	PolicyStatsResponse         = api.PolicyStatsResponse
+	ImportOrgPolicyResponse     = api.ImportOrgPolicyResponse
+	ReasonerInfoResponse        = api.ReasonerInfoResponse
+	ReasonerCapabilities        = api.ReasonerCapabilities
+)
 
-// ValidationResponse represents the response from validating a request.
-type ValidationResponse struct {
-	Allowed         bool   `json:"allowed"`                    // Whether the request is permitted
-	Reason          string `json:"reason,omitempty"`           // Explanation for the decision
-	Organization    string `json:"organization"`               // The organization checked
-	Requester       string `json:"requester"`                  // The requester checked
-	RequestType     string `json:"request_type,omitempty"`     // The request type checked
-	DataSet         string `json:"data_set,omitempty"`         // The dataset checked
-	Archetype       string `json:"archetype,omitempty"`        // The archetype checked
-	ComputeProvider string `json:"compute_provider,omitempty"` // The compute provider checked
-	DebugResponse   string `json:"debug_response,omitempty"`   // DEBUG: Raw response from the reasoner (temporary)
-}
-
-// ErrorResponse represents an error response.
-type ErrorResponse struct {
-	Error string `json:"error"` // Human-readable error message
-}
-
-// ReasonerInfoResponse provides information about the active reasoner.
-type ReasonerInfoResponse struct {
-	Name    string `json:"name"`    // Name/type of the reasoner (e.g., "eflint", "symboleo")
-	Running bool   `json:"running"` // Whether the reasoner is operational
+// APITypes returns a value of each request and response type of the policy
+// enforcer API, e.g. to derive their field names with jsoncase.FieldsOf.
+func APITypes() []any {
+	return []any{
+		AllowedClausesRequest{}, AllowedOfTypeRequest{}, OrganizationRequest{},
+		RequestersAllowedRequest{}, ClauseAllowedRequest{}, RevokeClauseRequest{},
+		BatchAllowedClausesRequest{}, ValidateRequestParams{}, SimulateChangeRequest{},
+		ExecuteActRequest{}, ChangesSinceRequest{}, FactQuery{},
+		AllowedClausesResponse{}, AllAllowedClausesResponse{}, BatchAllowedClausesResponse{},
+		ValidationResponse{}, ValidationJobResponse{}, QuickCheckResponse{},
+		RequestersAllowedResponse{}, ClauseAllowedResponse{}, RevokeClauseResponse{},
+		SimulateChangeResponse{}, ExecuteActResponse{}, EnabledActsResponse{},
+		ChangesSinceResponse{}, QueryFactsResponse{}, PolicyStatsResponse{},
+		ImportOrgPolicyResponse{}, ReasonerInfoResponse{}, api.OrgPolicy{}, api.ErrorResponse{},
+	}
 }
@@ -1,25 +1,47 @@
 package rabbitmq
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+// ConnectConfig controls how the initial connection to the broker is retried, so
+// that the service can start before RabbitMQ is up.
+type ConnectConfig struct {
+	ReconnectDelay    time.Duration // Wait before the first retry; doubled after every failed attempt
+	MaxReconnectDelay time.Duration // Upper bound for the wait between attempts (0 = no bound)
+	Timeout           time.Duration // Give up connecting after this long (0 = keep trying until ctx is done)
+}
+
+// DefaultConnectConfig returns default settings for connecting to the broker.
+func DefaultConnectConfig() ConnectConfig {
+	return ConnectConfig{
+		ReconnectDelay:    5 * time.Second,
+		MaxReconnectDelay: time.Minute,
+		Timeout:           2 * time.Minute,
+	}
+}
+
 // Consumer handles RabbitMQ message consumption
 type Consumer struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	queue   string
+	tag     string // Consumer tag, used to cancel the consumer on shutdown
 	logger  *zap.Logger
 }
 
-// NewConsumer creates a new RabbitMQ consumer
-func NewConsumer(amqpURL string, queue string, prefetchCount int, logger *zap.Logger) (*Consumer, error) {
-	logger.Info("connecting to RabbitMQ", zap.String("url", amqpURL))
-
-	conn, err := amqp.Dial(amqpURL)
+// NewConsumer creates a new RabbitMQ consumer. If the broker cannot be reached,
+// the connection is retried with exponential backoff until connect.Timeout
+// expires or ctx is cancelled.
+func NewConsumer(ctx context.Context, amqpURL string, queue string, prefetchCount int, connect ConnectConfig, logger *zap.Logger) (*Consumer, error) {
+	conn, err := dial(ctx, amqpURL, connect, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -58,15 +80,68 @@ func NewConsumer(amqpURL string, queue string, prefetchCount int, logger *zap.Lo
 		conn:    conn,
 		channel: channel,
 		queue:   queue,
+		tag:     fmt.Sprintf("policy-enforcer-%d", os.Getpid()),
 		logger:  logger,
 	}, nil
 }
 
+// dial connects to the broker, retrying failed attempts with exponential backoff.
+// It returns the last connection error once the timeout expires or ctx is done.
+func dial(ctx context.Context, amqpURL string, connect ConnectConfig, logger *zap.Logger) (*amqp.Connection, error) {
+	if connect.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, connect.Timeout)
+		defer cancel()
+	}
+	logger = logger.With(zap.String("url", redactURL(amqpURL)))
+
+	delay := connect.ReconnectDelay
+	for attempt := 1; ; attempt++ {
+		logger.Info("connecting to RabbitMQ", zap.Int("attempt", attempt))
+
+		conn, err := amqp.Dial(amqpURL)
+		if err == nil {
+			return conn, nil
+		}
+		if delay <= 0 {
+			return nil, err
+		}
+
+		logger.Warn("failed to connect to RabbitMQ, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if connect.MaxReconnectDelay > 0 && delay > connect.MaxReconnectDelay {
+			delay = connect.MaxReconnectDelay
+		}
+	}
+}
+
+// redactURL returns the URL with its password masked, for logging.
+func redactURL(amqpURL string) string {
+	u, err := url.Parse(amqpURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Redacted()
+}
+
 // Consume starts consuming messages from the queue
 func (c *Consumer) Consume() (<-chan amqp.Delivery, error) {
 	msgs, err := c.channel.Consume(
 		c.queue, // queue
-		"",      // consumer tag
+		c.tag,   // consumer tag
 		false,   // auto-ack
 		false,   // exclusive
 		false,   // no-local
@@ -0,0 +1,71 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// HandlerFunc processes a single delivery. It is responsible for acking or nacking it.
+type HandlerFunc func(msg amqp.Delivery) error
+
+// Process consumes messages from the queue with a pool of workers, each calling
+// handle for one delivery at a time. The number of workers should not exceed the
+// prefetch count, since extra workers would never receive messages.
+//
+// Process blocks until ctx is cancelled or the deliveries channel closes. On
+// cancellation it stops the consumer and waits for every worker to finish its
+// current message before returning; unacked prefetched messages are requeued by
+// the broker when the channel is closed.
+func (c *Consumer) Process(ctx context.Context, workers int, handle HandlerFunc) error {
+	if workers < 1 {
+		return fmt.Errorf("worker count must be at least 1, got %d", workers)
+	}
+
+	msgs, err := c.Consume()
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for msg := range msgs {
+				if err := handle(msg); err != nil {
+					c.logger.Error("failed to handle message",
+						zap.Int("worker", worker),
+						zap.Error(err),
+					)
+				}
+			}
+		}(i)
+	}
+
+	c.logger.Info("started message workers", zap.Int("workers", workers))
+
+	// Stop delivering new messages on shutdown; the deliveries channel is closed
+	// by the library once the cancellation is processed, which ends the workers
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		c.logger.Info("stopping message workers")
+		if err := c.channel.Cancel(c.tag, false); err != nil {
+			c.logger.Error("failed to cancel consumer", zap.Error(err))
+		}
+		<-done
+	case <-done:
+	}
+
+	c.logger.Info("message workers stopped")
+	return nil
+}
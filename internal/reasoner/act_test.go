@@ -0,0 +1,87 @@
+package reasoner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+func TestExecuteAct(t *testing.T) {
+	r, server := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		if commandName(command) != "phrase" {
+			return `{"success": true}`
+		}
+		return `{
+			"response": "success",
+			"new-facts": [{"fact-type": "requester", "tagged-type": "requester", "arguments": [{"fact-type": "organization", "value": "VU"}, {"fact-type": "user", "value": "user@example.com"}]}],
+			"removed-facts": [],
+			"violations": [{"type": "act", "message": "register-requester was not enabled"}]
+		}`
+	})
+
+	execution, err := r.ExecuteAct(context.Background(), "register-requester", []string{"VU", "user@example.com"})
+	if err != nil {
+		t.Fatalf("ExecuteAct() error = %v", err)
+	}
+
+	var phrases []string
+	for _, command := range server.Commands() {
+		var cmd struct {
+			Command string `json:"command"`
+			Text    string `json:"text"`
+		}
+		json.Unmarshal([]byte(command), &cmd)
+		if cmd.Command == "phrase" {
+			phrases = append(phrases, cmd.Text)
+		}
+	}
+	wantPhrase := `register-requester("VU", "user@example.com").`
+	if execution.Phrase != wantPhrase || len(phrases) != 1 || phrases[0] != wantPhrase {
+		t.Errorf("ExecuteAct() sent %q (phrase %q), want %q", phrases, execution.Phrase, wantPhrase)
+	}
+	if len(execution.Created) != 1 || execution.Created[0].FactType != "requester" ||
+		execution.Created[0].Arguments[1].Value != "user@example.com" {
+		t.Errorf("Created = %+v, want the requester fact", execution.Created)
+	}
+	if len(execution.Terminated) != 0 {
+		t.Errorf("Terminated = %+v, want none", execution.Terminated)
+	}
+	if len(execution.Violations) != 1 || execution.Violations[0].Type != "act" {
+		t.Errorf("Violations = %+v, want the act violation", execution.Violations)
+	}
+}
+
+func TestExecuteActRejectsInvalidActType(t *testing.T) {
+	r, server := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		return `{"success": true}`
+	})
+
+	for _, actType := range []string{"", `x("a"). +allowed-archetype`, "act(", "act."} {
+		_, err := r.ExecuteAct(context.Background(), actType, []string{"VU"})
+		if !errors.Is(err, eflint.ErrInvalidTypeName) || !errors.Is(err, ErrActFailed) {
+			t.Errorf("ExecuteAct(%q) error = %v, want ErrActFailed wrapping ErrInvalidTypeName", actType, err)
+		}
+	}
+	for _, command := range server.Commands() {
+		if commandName(command) == "phrase" {
+			t.Errorf("sent %s for an invalid act type", command)
+		}
+	}
+}
+
+func TestExecuteActReportsInvalidResponse(t *testing.T) {
+	r, _ := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		if commandName(command) == "phrase" {
+			return `{"response": "success", "new-facts": "not a list"}`
+		}
+		return `{"success": true}`
+	})
+
+	_, err := r.ExecuteAct(context.Background(), "register-requester", []string{"VU", "user@example.com"})
+	if !errors.Is(err, ErrActFailed) || !errors.Is(err, eflint.ErrInvalidResponse) {
+		t.Errorf("ExecuteAct() error = %v, want ErrActFailed wrapping ErrInvalidResponse", err)
+	}
+}
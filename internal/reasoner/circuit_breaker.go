@@ -0,0 +1,172 @@
+package reasoner
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// -----------------------------------------------------------------------------
+// Circuit Breaker
+// -----------------------------------------------------------------------------
+//
+// The circuit breaker stops the eFLINT reasoner from sending commands to a
+// backend that fails every call. After FailureThreshold consecutive failures the
+// circuit opens and calls fail fast with ErrReasonerNotRunning. Once Cooldown has
+// passed the circuit half-opens and lets a single probe through: a success closes
+// the circuit, a failure opens it again.
+
+// CircuitBreakerConfig configures the circuit breaker around reasoner backend calls.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the circuit; 0 disables the breaker
+	Cooldown         time.Duration // How long the circuit stays open before a probe is let through
+}
+
+// DefaultCircuitBreakerConfig returns the default circuit breaker settings (disabled by default).
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 0,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// Circuit states, as reported in the "state" metric.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// breakerMetrics exposes circuit state transitions via expvar (served at /debug/vars).
+var breakerMetrics = expvar.NewMap("reasoner_circuit_breaker")
+
+// circuitBreaker tracks consecutive backend failures.
+// Thread-safe for concurrent access.
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	state    string
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the circuit last opened
+	probing  bool      // Whether the half-open probe is in flight
+	mu       sync.Mutex
+	logger   *zap.Logger
+}
+
+// newCircuitBreaker creates a circuit breaker. It returns nil if the breaker is disabled.
+func newCircuitBreaker(config CircuitBreakerConfig, logger *zap.Logger) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		return nil
+	}
+
+	b := &circuitBreaker{
+		config: config,
+		state:  circuitClosed,
+		logger: logger,
+	}
+	breakerMetrics.Set("state", expvar.Func(func() any {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.state
+	}))
+	return b
+}
+
+// allow reports whether a call may be sent to the backend. While the circuit is
+// open it returns an error wrapping ErrReasonerNotRunning.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.config.Cooldown {
+			breakerMetrics.Add("rejected", 1)
+			return fmt.Errorf("%w: circuit breaker is open", ErrReasonerNotRunning)
+		}
+		b.transition(circuitHalfOpen)
+		b.probing = true
+		return nil
+	case circuitHalfOpen:
+		if b.probing {
+			breakerMetrics.Add("rejected", 1)
+			return fmt.Errorf("%w: circuit breaker is half-open, probe in flight", ErrReasonerNotRunning)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record reports the outcome of a call admitted by allow.
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up; this says nothing about the backend
+	case !isBackendFailure(err):
+		b.failures = 0
+		if b.state != circuitClosed {
+			b.transition(circuitClosed)
+		}
+	case b.state == circuitHalfOpen:
+		b.openedAt = time.Now()
+		b.transition(circuitOpen)
+	case b.state == circuitOpen:
+		// A call admitted before the circuit opened; the cooldown already runs
+	default:
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.openedAt = time.Now()
+			b.transition(circuitOpen)
+		}
+	}
+}
+
+// reset closes the circuit, e.g. after the backend was (re)started.
+func (b *circuitBreaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != circuitClosed {
+		b.transition(circuitClosed)
+	}
+}
+
+// transition changes the circuit state, logging and counting the change.
+// Caller must hold mu.
+func (b *circuitBreaker) transition(state string) {
+	b.logger.Warn("reasoner circuit breaker state changed",
+		zap.String("from", b.state),
+		zap.String("to", state),
+		zap.Int("consecutive_failures", b.failures),
+	)
+	b.state = state
+	if state != circuitClosed {
+		b.failures = 0
+	}
+	breakerMetrics.Add("transitions_"+state, 1)
+}
+
+// isBackendFailure reports whether an error means the backend failed to answer.
+// Errors reported by a backend that did answer (e.g. a rejected phrase) do not count.
+func isBackendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return !errors.Is(err, eflint.ErrCommandFailed) && !errors.Is(err, eflint.ErrInvalidResponse) &&
+		!errors.Is(err, eflint.ErrEmptyCommand) && !errors.Is(err, eflint.ErrReadOnly)
+}
@@ -0,0 +1,137 @@
+package reasoner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// breakerStep is one operation on a circuit breaker and the state expected after it.
+type breakerStep struct {
+	op    string // call, cancelled, admit, finish, abandon, rejected, elapse or reset
+	err   error  // Outcome recorded by call, cancelled, finish and abandon
+	state string
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failed := errors.New("connection refused")
+	answered := fmt.Errorf("%w: phrase rejected", eflint.ErrCommandFailed)
+
+	tests := []struct {
+		name  string
+		steps []breakerStep
+	}{
+		{"opens after consecutive failures", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitOpen},
+			{"rejected", nil, circuitOpen},
+		}},
+		{"success resets the failure count", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", nil, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+		}},
+		{"answered errors are not failures", []breakerStep{
+			{"call", answered, circuitClosed},
+			{"call", answered, circuitClosed},
+			{"call", answered, circuitClosed},
+		}},
+		{"cancelled calls are ignored", []breakerStep{
+			{"cancelled", failed, circuitClosed},
+			{"cancelled", failed, circuitClosed},
+			{"cancelled", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+		}},
+		{"successful probe closes", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitOpen},
+			{"elapse", nil, circuitOpen},
+			{"admit", nil, circuitHalfOpen},
+			{"rejected", nil, circuitHalfOpen}, // A single probe at a time
+			{"finish", nil, circuitClosed},
+			{"call", nil, circuitClosed},
+		}},
+		{"failed probe reopens", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitOpen},
+			{"elapse", nil, circuitOpen},
+			{"admit", nil, circuitHalfOpen},
+			{"finish", failed, circuitOpen},
+			{"rejected", nil, circuitOpen},
+		}},
+		{"cancelled probe admits another", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitOpen},
+			{"elapse", nil, circuitOpen},
+			{"admit", nil, circuitHalfOpen},
+			{"abandon", failed, circuitHalfOpen},
+			{"call", nil, circuitClosed},
+		}},
+		{"reset closes", []breakerStep{
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitOpen},
+			{"reset", nil, circuitClosed},
+			{"call", failed, circuitClosed},
+			{"call", failed, circuitClosed},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute}, zap.NewNop())
+			cancelled, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			for i, step := range tt.steps {
+				switch step.op {
+				case "call", "cancelled":
+					if err := b.allow(); err != nil {
+						t.Fatalf("step %d (%s): allow() error = %v", i, step.op, err)
+					}
+					ctx := context.Background()
+					if step.op == "cancelled" {
+						ctx = cancelled
+					}
+					b.record(ctx, step.err)
+				case "admit":
+					if err := b.allow(); err != nil {
+						t.Fatalf("step %d (admit): allow() error = %v", i, err)
+					}
+				case "finish":
+					b.record(context.Background(), step.err)
+				case "abandon":
+					b.record(cancelled, step.err)
+				case "rejected":
+					if err := b.allow(); !errors.Is(err, ErrReasonerNotRunning) {
+						t.Fatalf("step %d (rejected): allow() error = %v, want ErrReasonerNotRunning", i, err)
+					}
+				case "elapse":
+					b.openedAt = b.openedAt.Add(-b.config.Cooldown)
+				case "reset":
+					b.reset()
+				}
+				if b.state != step.state {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, step.op, b.state, step.state)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	if b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 0, Cooldown: time.Minute}, zap.NewNop()); b != nil {
+		t.Error("newCircuitBreaker() returned a breaker for a threshold of 0")
+	}
+}
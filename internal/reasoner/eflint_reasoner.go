@@ -1,33 +1,227 @@
 package reasoner
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
 )
 
 // -----------------------------------------------------------------------------
 // eFLINT Reasoner Implementation
 // -----------------------------------------------------------------------------
 
+// DefaultFactsCommand is the command that lists all facts on eflint-server.
+const DefaultFactsCommand = `{"command": "facts"}`
+
+// DefaultFactsKey is the key of the facts array in the response to the facts command.
+const DefaultFactsKey = "values"
+
+// EflintConfig holds configuration for the eFLINT reasoner.
+type EflintConfig struct {
+	CircuitBreaker CircuitBreakerConfig // Circuit breaker around eFLINT commands
+
+	// FactsCommand and FactsKey adapt fact queries to eflint-server builds with a
+	// different dialect (empty = DefaultFactsCommand and DefaultFactsKey). The
+	// response must be a JSON object whose FactsKey member is an array of facts,
+	// each shaped like eflint.Fact:
+	//
+	//	{"values": [{"fact-type": "...", "arguments": [{"fact-type": "...", "value": "..."}]}]}
+	FactsCommand string
+	FactsKey     string
+
+	// CacheFacts keeps the facts of a full facts query until the eFLINT state
+	// changes and answers fact-based queries from them, so repeated queries skip the
+	// server. Queries then always fetch all facts, even when they keep only a few.
+	CacheFacts bool
+
+	// Checkpoints is the state manager whose checkpoints ChangesSince compares with
+	// (nil = not supported). The reasoner becomes its FactsSource, so that every saved
+	// state and checkpoint records the facts that held.
+	Checkpoints *eflint.StateManager
+
+	// KnownActs are checked one by one by GetEnabledActs when the server does not
+	// list enabled transitions (nil = DefaultKnownActs).
+	KnownActs []KnownAct
+
+	// ClauseTypes are registered in addition to BuiltinClauseTypes, so that clause
+	// types of newer models can be queried by name with GetAllowedClauses. Types
+	// named like a built-in one are ignored.
+	ClauseTypes []ClauseType
+
+	// RequestAct names the act IsRequestAllowed checks and its parameters, for
+	// models with other naming conventions. Empty fields keep the names of
+	// DefaultRequestAct.
+	RequestAct RequestAct
+}
+
+// Fact types of the organization and requester in the agreement model. They are
+// the argument types a KnownAct can be checked with.
+const (
+	ArgOrganization = "org"
+	ArgRequester    = "req"
+)
+
+// KnownAct is an act whose arguments are all filled in from a principal, so that
+// it can be checked with the "enabled" command.
+type KnownAct struct {
+	Type      string   // The act (e.g., "register-requester")
+	Arguments []string // Argument fact types in order, each ArgOrganization or ArgRequester
+}
+
+// DefaultKnownActs are the acts of the DYNAMOS agreement model that take only an
+// organization and a requester.
+var DefaultKnownActs = []KnownAct{
+	{Type: "register-requester", Arguments: []string{ArgOrganization, ArgRequester}},
+	{Type: "unregister-requester", Arguments: []string{ArgOrganization, ArgRequester}},
+}
+
+// RequestAct maps a request validation onto an act of the model: the act checked
+// with the "enabled" command and the fact types of its parameters, in the order
+// the act declares them.
+type RequestAct struct {
+	Act             string // The act (e.g., "submit-request")
+	Requester       string // Fact type of the requester parameter (e.g., "req")
+	Organization    string // Fact type of the organization parameter (e.g., "org")
+	RequestType     string // Fact type of the request type parameter (e.g., "rtype")
+	DataSet         string // Fact type of the data set parameter (e.g., "dataset")
+	Archetype       string // Fact type of the archetype parameter (e.g., "arch")
+	ComputeProvider string // Fact type of the compute provider parameter (e.g., "provider")
+}
+
+// DefaultRequestAct is the submit-request act of the DYNAMOS agreement model.
+var DefaultRequestAct = RequestAct{
+	Act:             "submit-request",
+	Requester:       ArgRequester,
+	Organization:    ArgOrganization,
+	RequestType:     "rtype",
+	DataSet:         "dataset",
+	Archetype:       "arch",
+	ComputeProvider: "provider",
+}
+
+// withDefaults returns the act with empty fields set from DefaultRequestAct.
+func (a RequestAct) withDefaults() RequestAct {
+	fill := func(name *string, def string) {
+		if *name == "" {
+			*name = def
+		}
+	}
+	fill(&a.Act, DefaultRequestAct.Act)
+	fill(&a.Requester, DefaultRequestAct.Requester)
+	fill(&a.Organization, DefaultRequestAct.Organization)
+	fill(&a.RequestType, DefaultRequestAct.RequestType)
+	fill(&a.DataSet, DefaultRequestAct.DataSet)
+	fill(&a.Archetype, DefaultRequestAct.Archetype)
+	fill(&a.ComputeProvider, DefaultRequestAct.ComputeProvider)
+	return a
+}
+
+// arguments returns the act's arguments for the parameters of a request.
+func (a RequestAct) arguments(params RequestParams) []eflint.FactArgument {
+	return []eflint.FactArgument{
+		{FactType: a.Requester, Value: params.Requester},
+		{FactType: a.Organization, Value: params.Organization},
+		{FactType: a.RequestType, Value: params.RequestType},
+		{FactType: a.DataSet, Value: params.DataSet},
+		{FactType: a.Archetype, Value: params.Archetype},
+		{FactType: a.ComputeProvider, Value: params.ComputeProvider},
+	}
+}
+
+// String formats the act with its parameters, e.g. "submit-request(req, org, ...)".
+func (a RequestAct) String() string {
+	args := a.arguments(RequestParams{})
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		names = append(names, arg.FactType)
+	}
+	return a.Act + "(" + strings.Join(names, ", ") + ")"
+}
+
 // EflintReasoner implements the Reasoner interface using an eFLINT server.
 // It translates Reasoner API calls into eFLINT commands and parses the responses.
 type EflintReasoner struct {
-	manager *eflint.Manager
-	logger  *zap.Logger
+	manager      *eflint.Manager
+	state        *eflint.StateManager  // Checkpoints state for change simulations
+	checkpoints  *eflint.StateManager  // Named checkpoints for ChangesSince (nil = not supported)
+	breaker      *circuitBreaker       // nil when the circuit breaker is disabled
+	factsCommand string                // Command listing all facts
+	factsKey     string                // Key of the facts array in its response
+	knownActs    []KnownAct            // Acts checked when enabled transitions are not listed
+	clauseTypes  map[string]ClauseType // Clause types queryable by name
+	requestAct   RequestAct            // Act checked by IsRequestAllowed
+	factsCache   *factsCache           // nil when facts caching is disabled
+	logger       *zap.Logger
 }
 
 // NewEflintReasoner creates a new eFLINT-based reasoner.
-func NewEflintReasoner(manager *eflint.Manager, logger *zap.Logger) *EflintReasoner {
-	return &EflintReasoner{
-		manager: manager,
-		logger:  logger,
+// When the circuit breaker is enabled, it is reset whenever the eFLINT instance
+// is (re)started, so a manual start does not wait out the cooldown.
+func NewEflintReasoner(manager *eflint.Manager, config EflintConfig, logger *zap.Logger) *EflintReasoner {
+	r := &EflintReasoner{
+		manager:      manager,
+		state:        eflint.NewStateManager(manager, nil, logger),
+		breaker:      newCircuitBreaker(config.CircuitBreaker, logger),
+		factsCommand: config.FactsCommand,
+		factsKey:     config.FactsKey,
+		knownActs:    config.KnownActs,
+		checkpoints:  config.Checkpoints,
+		requestAct:   config.RequestAct.withDefaults(),
+		logger:       logger,
+	}
+	if r.factsCommand == "" {
+		r.factsCommand = DefaultFactsCommand
+	}
+	if r.factsKey == "" {
+		r.factsKey = DefaultFactsKey
+	}
+	if config.CacheFacts {
+		r.factsCache = &factsCache{}
+	}
+	if r.knownActs == nil {
+		r.knownActs = DefaultKnownActs
+	}
+	r.clauseTypes = make(map[string]ClauseType, len(BuiltinClauseTypes)+len(config.ClauseTypes))
+	for _, clauseType := range BuiltinClauseTypes {
+		r.clauseTypes[clauseType.Name] = clauseType
+	}
+	for _, clauseType := range config.ClauseTypes {
+		if _, builtin := allowedFactTypes[clauseType.Name]; builtin {
+			logger.Warn("ignoring clause type named like a built-in one", zap.String("clause_type", clauseType.Name))
+			continue
+		}
+		r.clauseTypes[clauseType.Name] = clauseType
+	}
+	if r.checkpoints != nil {
+		r.checkpoints.SetFactsSource(r.FetchFacts)
+	}
+
+	if r.breaker != nil {
+		manager.OnChange(func(reason string) {
+			switch reason {
+			case "start", "restart", "update-model":
+				r.breaker.reset()
+			}
+		})
 	}
+
+	return r
+}
+
+// log returns the logger for a call, tagged with the request ID if ctx carries one.
+func (r *EflintReasoner) log(ctx context.Context) *zap.Logger {
+	return requestid.Logger(ctx, r.logger)
 }
 
 // Name returns the name of this reasoner.
@@ -40,6 +234,12 @@ func (r *EflintReasoner) IsRunning() bool {
 	return r.manager.IsRunning()
 }
 
+// ReadOnly reports whether the eFLINT manager refuses commands that change the
+// state (see eflint.ManagerConfig.ReadOnly).
+func (r *EflintReasoner) ReadOnly() bool {
+	return r.manager.ReadOnly()
+}
+
 // -----------------------------------------------------------------------------
 // Facts Retrieval
 // -----------------------------------------------------------------------------
@@ -47,58 +247,140 @@ func (r *EflintReasoner) IsRunning() bool {
 // FetchFacts retrieves all facts from the eFLINT server.
 // This can be used to fetch facts once and then filter them multiple times
 // without making repeated calls to the eFLINT server.
-func (r *EflintReasoner) FetchFacts(ctx context.Context) ([]eflintFact, error) {
-	response, err := r.manager.SendCommand(`{"command": "facts"}`)
+// The query is aborted as soon as ctx is cancelled or its deadline expires, which
+// also frees the connection; the returned error then wraps ctx.Err().
+func (r *EflintReasoner) FetchFacts(ctx context.Context) ([]Fact, error) {
+	return r.fetchFactsWhere(ctx, nil)
+}
+
+// factPredicate selects the facts kept while a facts response is decoded.
+type factPredicate func(fact *eflint.Fact) bool
+
+// fetchFactsWhere retrieves the facts for which keep returns true (all facts if
+// keep is nil). Facts are decoded one at a time and discarded right away unless
+// they are kept, so filtered queries on large models never materialize the full
+// fact list. With the facts cache enabled, the facts are filtered from the cached
+// full list instead.
+func (r *EflintReasoner) fetchFactsWhere(ctx context.Context, keep factPredicate) ([]eflint.Fact, error) {
+	if r.factsCache != nil {
+		facts, err := r.cachedFacts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if keep == nil {
+			return slices.Clone(facts), nil
+		}
+		return filterFacts(facts, keep), nil
+	}
+
+	return r.queryFacts(ctx, keep)
+}
+
+// queryFacts fetches the facts accepted by keep (all facts if keep is nil),
+// decoding them while the response is read so that it is never held in memory as
+// a whole.
+func (r *EflintReasoner) queryFacts(ctx context.Context, keep factPredicate) ([]eflint.Fact, error) {
+	var facts []eflint.Fact
+	var parseErr error
+	err := r.queryDecode(ctx, r.factsCommand, func(response io.Reader) error {
+		facts, parseErr = readFacts(response, r.factsKey, keep)
+		return parseErr
+	})
+	if err != nil && errors.Is(err, parseErr) {
+		r.log(ctx).Debug("unparseable eFLINT facts response", zap.Error(err))
+		return nil, fmt.Errorf("%w: failed to parse facts response: %w", ErrFactsFetchFailed, err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get facts from eFLINT: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrFactsFetchFailed, err)
 	}
+	return facts, nil
+}
 
-	facts, err := parseFactsResponse(response)
+// cachedFacts returns all facts from the facts cache, fetching them if the state
+// changed since they were cached. The returned slice must not be modified.
+func (r *EflintReasoner) cachedFacts(ctx context.Context) ([]eflint.Fact, error) {
+	generation := r.manager.Generation()
+	if facts, ok := r.factsCache.get(generation); ok {
+		return facts, nil
+	}
+
+	facts, err := r.queryFacts(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse facts response: %w", err)
+		return nil, err
 	}
 
+	r.factsCache.put(generation, facts)
 	return facts, nil
 }
 
+// SelfTest checks that the loaded model answers a harmless "facts" query with a
+// parseable response. It returns the number of facts, and ErrEmptyModel if the
+// model holds no facts at all (usually a sign that the wrong file was loaded).
+func (r *EflintReasoner) SelfTest(ctx context.Context) (int, error) {
+	facts, err := r.FetchFacts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(facts) == 0 {
+		return 0, ErrEmptyModel
+	}
+	return len(facts), nil
+}
+
 // -----------------------------------------------------------------------------
 // Allowed Clauses Retrieval
 // -----------------------------------------------------------------------------
 
 // GetAllowedRequestTypes returns all request types allowed for a requester at an organization.
 func (r *EflintReasoner) GetAllowedRequestTypes(ctx context.Context, organization, requester string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return r.filterAllowedClauses(facts, "allowed-request-type", "request-type", organization, requester), nil
+	return r.GetAllowedClauses(ctx, organization, requester, ClauseRequestType)
 }
 
 // GetAllowedDataSets returns all datasets allowed for a requester at an organization.
 func (r *EflintReasoner) GetAllowedDataSets(ctx context.Context, organization, requester string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return r.filterAllowedClauses(facts, "allowed-data-set", "data-set", organization, requester), nil
+	return r.GetAllowedClauses(ctx, organization, requester, ClauseDataSet)
 }
 
 // GetAllowedArchetypes returns all archetypes allowed for a requester at an organization.
 func (r *EflintReasoner) GetAllowedArchetypes(ctx context.Context, organization, requester string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return r.filterAllowedClauses(facts, "allowed-archetype", "archetype", organization, requester), nil
+	return r.GetAllowedClauses(ctx, organization, requester, ClauseArchetype)
 }
 
 // GetAllowedComputeProviders returns all compute providers allowed for a requester at an organization.
 func (r *EflintReasoner) GetAllowedComputeProviders(ctx context.Context, organization, requester string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
+	return r.GetAllowedClauses(ctx, organization, requester, ClauseComputeProvider)
+}
+
+// ClauseTypes returns the built-in and configured clause types, sorted by name.
+func (r *EflintReasoner) ClauseTypes() []ClauseType {
+	clauseTypes := make([]ClauseType, 0, len(r.clauseTypes))
+	for _, clauseType := range r.clauseTypes {
+		clauseTypes = append(clauseTypes, clauseType)
+	}
+	slices.SortFunc(clauseTypes, func(a, b ClauseType) int { return cmp.Compare(a.Name, b.Name) })
+	return clauseTypes
+}
+
+// GetAllowedClauses returns the values of a registered clause type allowed for a
+// requester at an organization.
+func (r *EflintReasoner) GetAllowedClauses(ctx context.Context, organization, requester, clauseType string) ([]string, error) {
+	definition, ok := r.clauseTypes[clauseType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownClauseType, clauseType)
+	}
+	return r.GetAllowedClausesOfType(ctx, organization, requester, definition.FactType, definition.ValueFactType)
+}
+
+// GetAllowedClausesOfType returns the values allowed for a requester at an
+// organization by facts of factType, whose arguments are the organization, the
+// requester and a value of valueFactType. It works for any fact type of that
+// shape, registered as a clause type or not.
+func (r *EflintReasoner) GetAllowedClausesOfType(ctx context.Context, organization, requester, factType, valueFactType string) ([]string, error) {
+	facts, err := r.fetchFactsWhere(ctx, ofRequester(organization, requester))
 	if err != nil {
 		return nil, err
 	}
-	return r.filterAllowedClauses(facts, "allowed-compute-provider", "compute-provider", organization, requester), nil
+	return r.filterAllowedClauses(facts, factType, valueFactType, organization, requester), nil
 }
 
 // GetAllAllowedClauses returns all allowed clauses for a requester at an organization.
@@ -106,30 +388,50 @@ func (r *EflintReasoner) GetAllowedComputeProviders(ctx context.Context, organiz
 // facts from the eFLINT server once.
 func (r *EflintReasoner) GetAllAllowedClauses(ctx context.Context, organization, requester string) (*AllAllowedClauses, error) {
 	// Fetch facts once
-	facts, err := r.FetchFacts(ctx)
+	facts, err := r.fetchFactsWhere(ctx, ofRequester(organization, requester))
 	if err != nil {
 		return nil, err
 	}
 
 	// Filter all clause types from the same facts
+	return r.filterAllAllowedClauses(facts, organization, requester), nil
+}
+
+// GetAllAllowedClausesBatch returns the allowed clauses for many (organization, requester)
+// pairs. The eFLINT "facts" command is issued exactly once, regardless of the number of pairs.
+func (r *EflintReasoner) GetAllAllowedClausesBatch(ctx context.Context, pairs []OrgRequester) (map[OrgRequester]*AllAllowedClauses, error) {
+	facts, err := r.fetchFactsWhere(ctx, ofType(allowedFactTypeList()...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[OrgRequester]*AllAllowedClauses, len(pairs))
+	for _, pair := range pairs {
+		results[pair] = r.filterAllAllowedClauses(facts, pair.Organization, pair.Requester)
+	}
+	return results, nil
+}
+
+// filterAllAllowedClauses filters pre-fetched facts for all clause types of a requester.
+func (r *EflintReasoner) filterAllAllowedClauses(facts []eflint.Fact, organization, requester string) *AllAllowedClauses {
 	return &AllAllowedClauses{
 		RequestTypes:     r.filterAllowedClauses(facts, "allowed-request-type", "request-type", organization, requester),
 		DataSets:         r.filterAllowedClauses(facts, "allowed-data-set", "data-set", organization, requester),
 		Archetypes:       r.filterAllowedClauses(facts, "allowed-archetype", "archetype", organization, requester),
 		ComputeProviders: r.filterAllowedClauses(facts, "allowed-compute-provider", "compute-provider", organization, requester),
-	}, nil
+	}
 }
 
-// filterAllowedClauses filters pre-fetched facts for allowed clauses.
-// This is a pure function that doesn't make any network calls.
+// filterAllowedClauses filters pre-fetched facts for allowed clauses. Values are
+// deduplicated and sorted. This is a pure function that doesn't make any network calls.
 func (r *EflintReasoner) filterAllowedClauses(
-	facts []eflintFact,
+	facts []eflint.Fact,
 	factType string, // e.g., "allowed-archetype"
 	valueFactType string, // e.g., "archetype"
 	organization string,
 	requester string,
 ) []string {
-	var values []string
+	values := make(map[string]struct{})
 	for _, fact := range facts {
 		if fact.FactType == factType && len(fact.Arguments) >= 3 {
 			// Arguments: [0]=organization, [1]=requester, [2]=value
@@ -138,11 +440,461 @@ func (r *EflintReasoner) filterAllowedClauses(
 				fact.Arguments[1].FactType == "requester" &&
 				fact.Arguments[1].Value == requester &&
 				fact.Arguments[2].FactType == valueFactType {
-				values = append(values, fact.Arguments[2].Value)
+				values[fact.Arguments[2].Value] = struct{}{}
 			}
 		}
 	}
-	return values
+	return sortedValues(values)
+}
+
+// GetRequestersAllowed returns all requesters at an organization that are allowed
+// the given clause value. This is the reverse of the GetAllowed* lookups.
+func (r *EflintReasoner) GetRequestersAllowed(ctx context.Context, organization, clauseType, value string) ([]string, error) {
+	factType, err := allowedFactType(clauseType)
+	if err != nil {
+		return nil, err
+	}
+
+	facts, err := r.fetchFactsWhere(ctx, ofOrganization(organization))
+	if err != nil {
+		return nil, err
+	}
+	return r.filterRequestersAllowed(facts, factType, clauseType, organization, value), nil
+}
+
+// filterRequestersAllowed filters pre-fetched facts for requesters allowed a clause value.
+// Requesters are deduplicated and sorted. This is a pure function that doesn't make
+// any network calls.
+func (r *EflintReasoner) filterRequestersAllowed(
+	facts []eflint.Fact,
+	factType string, // e.g., "allowed-archetype"
+	valueFactType string, // e.g., "archetype"
+	organization string,
+	value string,
+) []string {
+	requesters := make(map[string]struct{})
+	for _, fact := range facts {
+		if fact.FactType == factType && len(fact.Arguments) >= 3 {
+			// Arguments: [0]=organization, [1]=requester, [2]=value
+			if fact.Arguments[0].FactType == "organization" &&
+				fact.Arguments[0].Value == organization &&
+				fact.Arguments[1].FactType == "requester" &&
+				fact.Arguments[2].FactType == valueFactType &&
+				fact.Arguments[2].Value == value {
+				requesters[fact.Arguments[1].Value] = struct{}{}
+			}
+		}
+	}
+	return sortedValues(requesters)
+}
+
+// BuiltinClauseTypes are the clause types of the DYNAMOS agreement model. They have
+// dedicated methods and endpoints, and are always registered.
+var BuiltinClauseTypes = []ClauseType{
+	{Name: ClauseRequestType, FactType: "allowed-request-type", ValueFactType: "request-type"},
+	{Name: ClauseDataSet, FactType: "allowed-data-set", ValueFactType: "data-set"},
+	{Name: ClauseArchetype, FactType: "allowed-archetype", ValueFactType: "archetype"},
+	{Name: ClauseComputeProvider, FactType: "allowed-compute-provider", ValueFactType: "compute-provider"},
+}
+
+// allowedFactTypes maps the built-in clause types to the eFLINT fact types that grant them.
+var allowedFactTypes = func() map[string]string {
+	factTypes := make(map[string]string, len(BuiltinClauseTypes))
+	for _, clauseType := range BuiltinClauseTypes {
+		factTypes[clauseType.Name] = clauseType.FactType
+	}
+	return factTypes
+}()
+
+// allowedFactTypeList returns the eFLINT fact types of all clause types.
+func allowedFactTypeList() []string {
+	factTypes := make([]string, 0, len(allowedFactTypes))
+	for _, factType := range allowedFactTypes {
+		factTypes = append(factTypes, factType)
+	}
+	return factTypes
+}
+
+// allowedFactType returns the eFLINT fact type for a clause type.
+func allowedFactType(clauseType string) (string, error) {
+	factType, ok := allowedFactTypes[clauseType]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownClauseType, clauseType)
+	}
+	return factType, nil
+}
+
+// ClauseTypeOfFact returns the clause type granted by an eFLINT fact type, e.g.
+// ClauseArchetype for "allowed-archetype". It reports false for other fact types.
+func ClauseTypeOfFact(factType string) (string, bool) {
+	for clauseType, allowed := range allowedFactTypes {
+		if allowed == factType {
+			return clauseType, true
+		}
+	}
+	return "", false
+}
+
+// -----------------------------------------------------------------------------
+// Revocation
+// -----------------------------------------------------------------------------
+
+// RevokeFact terminates a fact by sending the eFLINT retraction phrase `-fact(...)`.
+// The arguments are positional string values, e.g. ("VU", "user@example.com", "computeToData").
+func (r *EflintReasoner) RevokeFact(ctx context.Context, factType string, args []string) error {
+	phrase, err := eflint.BuildFactPhrase(eflint.FactTerminate, factType, args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRevokeFailed, err)
+	}
+
+	if _, err := r.sendPhrase(ctx, phrase); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrRevokeFailed, phrase, err)
+	}
+
+	r.log(ctx).Info("revoked fact", zap.String("phrase", phrase))
+	return nil
+}
+
+// RevokeAllowedClause revokes a single allowed clause for a requester at an organization.
+func (r *EflintReasoner) RevokeAllowedClause(ctx context.Context, organization, requester, clauseType, value string) error {
+	factType, err := allowedFactType(clauseType)
+	if err != nil {
+		return err
+	}
+	return r.RevokeFact(ctx, factType, []string{organization, requester, value})
+}
+
+// -----------------------------------------------------------------------------
+// Policy Export / Import
+// -----------------------------------------------------------------------------
+
+// availableFactTypes maps availability clause types to the eFLINT fact types that
+// make them available at an organization.
+var availableFactTypes = map[string]string{
+	ClauseArchetype:       "available-archetype",
+	ClauseComputeProvider: "available-compute-provider",
+}
+
+// ExportOrgPolicy returns all clauses granted by an organization, grouped per
+// requester (sorted by name), together with the organization's available resources.
+// The eFLINT "facts" command is issued exactly once.
+func (r *EflintReasoner) ExportOrgPolicy(ctx context.Context, organization string) (*OrgPolicy, error) {
+	facts, err := r.fetchFactsWhere(ctx, ofOrganization(organization))
+	if err != nil {
+		return nil, err
+	}
+
+	var requesters []string
+	seen := make(map[string]bool)
+	for _, clauseType := range []string{ClauseRequestType, ClauseDataSet, ClauseArchetype, ClauseComputeProvider} {
+		for _, fact := range facts {
+			if fact.FactType == allowedFactTypes[clauseType] && len(fact.Arguments) >= 3 &&
+				fact.Arguments[0].FactType == "organization" &&
+				fact.Arguments[0].Value == organization &&
+				fact.Arguments[1].FactType == "requester" &&
+				!seen[fact.Arguments[1].Value] {
+				seen[fact.Arguments[1].Value] = true
+				requesters = append(requesters, fact.Arguments[1].Value)
+			}
+		}
+	}
+	slices.Sort(requesters)
+
+	policy := &OrgPolicy{
+		Organization:              organization,
+		Grants:                    make([]RequesterGrants, 0, len(requesters)),
+		AvailableArchetypes:       r.filterAvailableFacts(facts, availableFactTypes[ClauseArchetype], ClauseArchetype, organization),
+		AvailableComputeProviders: r.filterAvailableFacts(facts, availableFactTypes[ClauseComputeProvider], ClauseComputeProvider, organization),
+	}
+	for _, requester := range requesters {
+		policy.Grants = append(policy.Grants, RequesterGrants{
+			Requester:         requester,
+			AllAllowedClauses: *r.filterAllAllowedClauses(facts, organization, requester),
+		})
+	}
+	return policy, nil
+}
+
+// ImportOrgPolicy asserts every grant and availability in the policy document as
+// eFLINT facts. It stops at the first phrase the server rejects; facts asserted
+// before that point remain in place.
+func (r *EflintReasoner) ImportOrgPolicy(ctx context.Context, policy *OrgPolicy) (int, error) {
+	org := policy.Organization
+	asserted := 0
+
+	assert := func(factType string, args ...string) error {
+		if err := r.AssertFact(ctx, factType, args); err != nil {
+			return err
+		}
+		asserted++
+		return nil
+	}
+
+	for _, value := range policy.AvailableArchetypes {
+		if err := assert(availableFactTypes[ClauseArchetype], org, value); err != nil {
+			return asserted, err
+		}
+	}
+	for _, value := range policy.AvailableComputeProviders {
+		if err := assert(availableFactTypes[ClauseComputeProvider], org, value); err != nil {
+			return asserted, err
+		}
+	}
+
+	for _, grant := range policy.Grants {
+		clauses := map[string][]string{
+			ClauseRequestType:     grant.RequestTypes,
+			ClauseDataSet:         grant.DataSets,
+			ClauseArchetype:       grant.Archetypes,
+			ClauseComputeProvider: grant.ComputeProviders,
+		}
+		for _, clauseType := range []string{ClauseRequestType, ClauseDataSet, ClauseArchetype, ClauseComputeProvider} {
+			for _, value := range clauses[clauseType] {
+				if err := assert(allowedFactTypes[clauseType], org, grant.Requester, value); err != nil {
+					return asserted, err
+				}
+			}
+		}
+	}
+
+	return asserted, nil
+}
+
+// AssertFact creates a fact by sending the eFLINT phrase `+fact(...)`.
+// The arguments are positional string values, e.g. ("VU", "user@example.com", "computeToData").
+func (r *EflintReasoner) AssertFact(ctx context.Context, factType string, args []string) error {
+	phrase, err := eflint.BuildFactPhrase(eflint.FactCreate, factType, args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAssertFailed, err)
+	}
+
+	if _, err := r.sendPhrase(ctx, phrase); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrAssertFailed, phrase, err)
+	}
+
+	r.log(ctx).Debug("asserted fact", zap.String("phrase", phrase))
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Act Execution
+// -----------------------------------------------------------------------------
+
+// ExecuteAct performs an act by sending the eFLINT phrase `act(...)`, e.g.
+// ("register-requester", ["VU", "user@example.com"]). This changes the state: the
+// facts the act creates and terminates are applied, also when the act was not
+// enabled, in which case the violation is reported.
+func (r *EflintReasoner) ExecuteAct(ctx context.Context, actType string, args []string) (*ActExecution, error) {
+	phrase, err := eflint.BuildActPhrase(actType, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrActFailed, err)
+	}
+
+	response, err := r.sendPhrase(ctx, phrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrActFailed, phrase, err)
+	}
+	result, err := eflint.ParsePhraseResult(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrActFailed, phrase, err)
+	}
+
+	r.log(ctx).Debug("executed act",
+		zap.String("phrase", phrase),
+		zap.Int("created", len(result.NewFacts)),
+		zap.Int("terminated", len(result.RemovedFacts)),
+		zap.Int("violations", len(result.Violations)),
+	)
+	return &ActExecution{
+		Phrase:     phrase,
+		Created:    result.NewFacts,
+		Terminated: result.RemovedFacts,
+		Violations: result.Violations,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Enabled Acts
+// -----------------------------------------------------------------------------
+
+// GetEnabledActs lists the enabled acts whose arguments include both the
+// organization and the requester of the principal. It reads the enabled
+// transitions from the "status" command; if the server does not list them, each
+// of the known acts is checked with the "enabled" command instead.
+func (r *EflintReasoner) GetEnabledActs(ctx context.Context, principal OrgRequester) (*EnabledActs, error) {
+	response, err := r.query(ctx, `{"command": "status"}`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEnabledActsFailed, err)
+	}
+	if err := decodeEflintResponse(response, &struct{}{}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEnabledActsFailed, err)
+	}
+
+	transitions, listed, err := eflint.ParseEnabledTransitions(response)
+	if err != nil {
+		r.log(ctx).Debug("unparseable eFLINT status response", zap.String("response", response), zap.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrEnabledActsFailed, err)
+	}
+	if !listed {
+		r.log(ctx).Debug("eFLINT server does not list enabled transitions, checking known acts",
+			zap.Int("known_acts", len(r.knownActs)),
+		)
+		return r.checkKnownActs(ctx, principal)
+	}
+
+	acts := []EnabledAct{}
+	for _, t := range transitions {
+		if involves(t.Arguments, principal) {
+			acts = append(acts, EnabledAct{Act: t.Type, Arguments: t.Arguments})
+		}
+	}
+	return &EnabledActs{Acts: acts, Source: api.EnabledActsFromTransitions}, nil
+}
+
+// checkKnownActs asks, for each known act, whether it is enabled with the
+// arguments of the principal.
+func (r *EflintReasoner) checkKnownActs(ctx context.Context, principal OrgRequester) (*EnabledActs, error) {
+	acts := []EnabledAct{}
+	for _, known := range r.knownActs {
+		args := make([]eflint.FactArgument, 0, len(known.Arguments))
+		for _, argType := range known.Arguments {
+			value := principal.Requester
+			if argType == ArgOrganization {
+				value = principal.Organization
+			}
+			args = append(args, eflint.FactArgument{FactType: argType, Value: value})
+		}
+
+		enabled, err := r.isActEnabled(ctx, known.Type, args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrEnabledActsFailed, known.Type, err)
+		}
+		if enabled {
+			acts = append(acts, EnabledAct{Act: known.Type, Arguments: args})
+		}
+	}
+	return &EnabledActs{Acts: acts, Source: api.EnabledActsFromKnownActs}, nil
+}
+
+// isActEnabled sends an "enabled" command for an act with the given arguments.
+func (r *EflintReasoner) isActEnabled(ctx context.Context, actType string, args []eflint.FactArgument) (bool, error) {
+	values := make([]map[string]interface{}, 0, len(args))
+	for _, arg := range args {
+		values = append(values, map[string]interface{}{"fact-type": arg.FactType, "value": arg.Value})
+	}
+	cmdJSON, err := json.Marshal(map[string]interface{}{
+		"command": "enabled",
+		"value":   map[string]interface{}{"fact-type": actType, "value": values},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal command: %w", err)
+	}
+
+	response, err := r.query(ctx, string(cmdJSON))
+	if err != nil {
+		return false, err
+	}
+
+	var resp struct {
+		QueryResults []string `json:"query-results"`
+	}
+	if err := decodeEflintResponse(response, &resp); err != nil {
+		return false, err
+	}
+	return len(resp.QueryResults) > 0 && strings.EqualFold(resp.QueryResults[0], "success"), nil
+}
+
+// involves reports whether arguments include the organization and the requester
+// of a principal.
+func involves(args []eflint.FactArgument, principal OrgRequester) bool {
+	var org, req bool
+	for _, arg := range args {
+		switch arg.FactType {
+		case ArgOrganization:
+			org = org || arg.Value == principal.Organization
+		case ArgRequester:
+			req = req || arg.Value == principal.Requester
+		}
+	}
+	return org && req
+}
+
+// -----------------------------------------------------------------------------
+// Change Tracking
+// -----------------------------------------------------------------------------
+
+// ChangesSince compares the allowed clauses of the current facts with those
+// recorded in a checkpoint of the eFLINT state API.
+func (r *EflintReasoner) ChangesSince(ctx context.Context, checkpoint string) (*ClauseChanges, error) {
+	if r.checkpoints == nil {
+		return nil, fmt.Errorf("%w: checkpoints do not record facts", ErrNotSupported)
+	}
+
+	saved, err := r.checkpoints.LoadCheckpoint(checkpoint)
+	if errors.Is(err, eflint.ErrStateNotFound) {
+		return nil, fmt.Errorf("%w: %q", ErrCheckpointNotFound, checkpoint)
+	} else if err != nil {
+		return nil, err
+	}
+	if saved.Facts == nil {
+		return nil, fmt.Errorf("%w: %q", ErrCheckpointWithoutFacts, checkpoint)
+	}
+
+	current, err := r.FetchFacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := allowedClauseSet(saved.Facts), allowedClauseSet(current)
+	return &ClauseChanges{
+		CheckpointedAt: saved.SavedAt,
+		Added:          clauseDifference(after, before),
+		Removed:        clauseDifference(before, after),
+	}, nil
+}
+
+// allowedClauseSet collects the allowed clauses granted by facts.
+func allowedClauseSet(facts []eflint.Fact) map[ClauseChange]struct{} {
+	clauseTypes := make(map[string]string, len(allowedFactTypes))
+	for clauseType, factType := range allowedFactTypes {
+		clauseTypes[factType] = clauseType
+	}
+
+	clauses := make(map[ClauseChange]struct{})
+	for _, fact := range facts {
+		clauseType, ok := clauseTypes[fact.FactType]
+		if !ok || len(fact.Arguments) < 3 {
+			continue
+		}
+		// Arguments: [0]=organization, [1]=requester, [2]=value
+		clauses[ClauseChange{
+			Organization: fact.Arguments[0].Value,
+			Requester:    fact.Arguments[1].Value,
+			Type:         clauseType,
+			Value:        fact.Arguments[2].Value,
+		}] = struct{}{}
+	}
+	return clauses
+}
+
+// clauseDifference returns the clauses in a that are not in b, sorted by
+// organization, requester, clause type and value.
+func clauseDifference(a, b map[ClauseChange]struct{}) []ClauseChange {
+	diff := []ClauseChange{}
+	for clause := range a {
+		if _, ok := b[clause]; !ok {
+			diff = append(diff, clause)
+		}
+	}
+	slices.SortFunc(diff, func(x, y ClauseChange) int {
+		return cmp.Or(
+			cmp.Compare(x.Organization, y.Organization),
+			cmp.Compare(x.Requester, y.Requester),
+			cmp.Compare(x.Type, y.Type),
+			cmp.Compare(x.Value, y.Value),
+		)
+	})
+	return diff
 }
 
 // -----------------------------------------------------------------------------
@@ -150,36 +902,20 @@ func (r *EflintReasoner) filterAllowedClauses(
 // -----------------------------------------------------------------------------
 
 // IsRequestAllowed checks if a specific request is permitted according to the eFLINT policy.
-// It uses the "enabled" command on the submit-request act to determine if the request is allowed.
+// It uses the "enabled" command on the request act (submit-request by default) to
+// determine if the request is allowed.
 func (r *EflintReasoner) IsRequestAllowed(ctx context.Context, params RequestParams) (*RequestValidationResult, error) {
-	// Build the eFLINT "enabled" command with a properly structured VALUE
-	// This checks if the submit-request action is enabled with the given parameters
-	cmd := map[string]interface{}{
-		"command": "enabled",
-		"value": map[string]interface{}{
-			"fact-type": "submit-request",
-			"value": []map[string]interface{}{
-				{"fact-type": "req", "value": params.Requester},
-				{"fact-type": "org", "value": params.Organization},
-				{"fact-type": "rtype", "value": params.RequestType},
-				{"fact-type": "dataset", "value": params.DataSet},
-				{"fact-type": "arch", "value": params.Archetype},
-				{"fact-type": "provider", "value": params.ComputeProvider},
-			},
-		},
-	}
-
-	cmdJSON, err := json.Marshal(cmd)
+	cmdJSON, err := r.requestActCommand(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal command: %w", err)
+		return nil, fmt.Errorf("%w: failed to marshal command: %w", ErrValidationFailed, err)
 	}
 
-	response, err := r.manager.SendCommand(string(cmdJSON))
+	response, err := r.query(ctx, string(cmdJSON))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query eFLINT: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
 	}
 
-	r.logger.Debug("eFLINT enabled query response",
+	r.log(ctx).Debug("eFLINT enabled query response",
 		zap.String("command", string(cmdJSON)),
 		zap.String("response", response),
 	)
@@ -187,29 +923,87 @@ func (r *EflintReasoner) IsRequestAllowed(ctx context.Context, params RequestPar
 	// Parse the response and include raw response for debugging
 	result, err := r.parseValidationResponse(response, params)
 	if err != nil {
+		r.log(ctx).Debug("unparseable eFLINT enabled response", zap.String("response", response), zap.Error(err))
 		return nil, err
 	}
 	return result, nil
 }
 
+// requestActCommand builds the "enabled" command checking the request act with
+// the parameters of a request.
+func (r *EflintReasoner) requestActCommand(params RequestParams) ([]byte, error) {
+	args := r.requestAct.arguments(params)
+	values := make([]map[string]interface{}, 0, len(args))
+	for _, arg := range args {
+		values = append(values, map[string]interface{}{"fact-type": arg.FactType, "value": arg.Value})
+	}
+	return json.Marshal(map[string]interface{}{
+		"command": "enabled",
+		"value":   map[string]interface{}{"fact-type": r.requestAct.Act, "value": values},
+	})
+}
+
+// requestActProbe is the value of every parameter when CheckRequestAct checks the
+// request act.
+const requestActProbe = "policy-enforcer-probe"
+
+// CheckRequestAct checks with the server's type checker that the loaded model
+// declares the request act with the configured parameters, by asking whether the
+// act is enabled for placeholder values. A model with other names would otherwise
+// deny every request as "not permitted"; here the parse and type errors of the
+// server are returned, wrapped in ErrRequestActMismatch.
+func (r *EflintReasoner) CheckRequestAct(ctx context.Context) error {
+	cmdJSON, err := r.requestActCommand(RequestParams{
+		Organization:    requestActProbe,
+		Requester:       requestActProbe,
+		RequestType:     requestActProbe,
+		DataSet:         requestActProbe,
+		Archetype:       requestActProbe,
+		ComputeProvider: requestActProbe,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: failed to marshal command: %w", ErrValidationFailed, err)
+	}
+
+	response, err := r.query(ctx, string(cmdJSON))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	var resp struct {
+		Errors []eflint.Diagnostic `json:"errors"`
+	}
+	if err := decodeEflintResponse(response, &resp); err != nil {
+		// The server rejected the command outright, e.g. because the act is undeclared
+		return fmt.Errorf("%w: %s: %w", ErrRequestActMismatch, r.requestAct, err)
+	}
+
+	switch eflint.ClassifyErrors(resp.Errors) {
+	case ErrorCategoryParse, ErrorCategoryType:
+		messages := make([]string, 0, len(resp.Errors))
+		for _, d := range resp.Errors {
+			messages = append(messages, d.Message)
+		}
+		return fmt.Errorf("%w: %s: %s", ErrRequestActMismatch, r.requestAct, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
 // parseValidationResponse parses the eFLINT response for an "enabled" query.
 // The enabled command returns a Status response with query-results containing "success" if enabled.
 func (r *EflintReasoner) parseValidationResponse(response string, params RequestParams) (*RequestValidationResult, error) {
 	var resp struct {
-		Response     string   `json:"response"`
-		QueryResults []string `json:"query-results"` // eFLINT returns "success" when enabled
-		Errors       []struct {
-			Type    string `json:"type"`
-			Message string `json:"message"`
-		} `json:"errors"`
-		Violations []struct {
+		Response     string              `json:"response"`
+		QueryResults []string            `json:"query-results"` // eFLINT returns "success" when enabled
+		Errors       []eflint.Diagnostic `json:"errors"`
+		Violations   []struct {
 			Type    string `json:"type"`
 			Message string `json:"message"`
 		} `json:"violations"`
 	}
 
-	if err := json.Unmarshal([]byte(response), &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse eFLINT response: %w", err)
+	if err := decodeEflintResponse(response, &resp); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse eFLINT response: %w", ErrValidationFailed, err)
 	}
 
 	// Check if the enabled query succeeded
@@ -237,6 +1031,33 @@ func (r *EflintReasoner) parseValidationResponse(response string, params Request
 		result.Reason = "Request is not permitted by the agreement"
 	}
 
+	// Derive the reason code; availability problems take precedence over other
+	// violations because they are the most actionable for the requester
+	switch {
+	case result.Allowed:
+		result.ReasonCode = ReasonPermitted
+		// Servers that report the duties an enabled act creates list them as
+		// "new-duties"; others yield none
+		duties, err := eflint.ParseNewDuties(response)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+		}
+		result.Duties = duties
+	case len(resp.Errors) > 0:
+		result.ReasonCode = ReasonError
+		result.ErrorCategory = eflint.ClassifyErrors(resp.Errors)
+	case len(resp.Violations) > 0:
+		result.ReasonCode = ReasonViolation
+		for _, v := range resp.Violations {
+			if strings.HasPrefix(v.Type, "available-") {
+				result.ReasonCode = ReasonResourceUnavailable
+				break
+			}
+		}
+	default:
+		result.ReasonCode = ReasonNotPermitted
+	}
+
 	return result, nil
 }
 
@@ -246,7 +1067,7 @@ func (r *EflintReasoner) parseValidationResponse(response string, params Request
 
 // GetAvailableArchetypes returns archetypes available at an organization.
 func (r *EflintReasoner) GetAvailableArchetypes(ctx context.Context, organization string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
+	facts, err := r.fetchFactsWhere(ctx, ofOrganization(organization))
 	if err != nil {
 		return nil, err
 	}
@@ -255,7 +1076,7 @@ func (r *EflintReasoner) GetAvailableArchetypes(ctx context.Context, organizatio
 
 // GetAvailableComputeProviders returns compute providers available at an organization.
 func (r *EflintReasoner) GetAvailableComputeProviders(ctx context.Context, organization string) ([]string, error) {
-	facts, err := r.FetchFacts(ctx)
+	facts, err := r.fetchFactsWhere(ctx, ofOrganization(organization))
 	if err != nil {
 		return nil, err
 	}
@@ -263,54 +1084,435 @@ func (r *EflintReasoner) GetAvailableComputeProviders(ctx context.Context, organ
 }
 
 // filterAvailableFacts filters pre-fetched facts for available resources at an organization.
-// This is a pure function that doesn't make any network calls.
+// Values are deduplicated and sorted. This is a pure function that doesn't make any network calls.
 func (r *EflintReasoner) filterAvailableFacts(
-	facts []eflintFact,
+	facts []eflint.Fact,
 	factType string,
 	valueFactType string,
 	organization string,
 ) []string {
-	var values []string
+	values := make(map[string]struct{})
 	for _, fact := range facts {
 		if fact.FactType == factType && len(fact.Arguments) >= 2 {
 			// Arguments: [0]=organization, [1]=value
 			if fact.Arguments[0].FactType == "organization" &&
 				fact.Arguments[0].Value == organization &&
 				fact.Arguments[1].FactType == valueFactType {
-				values = append(values, fact.Arguments[1].Value)
+				values[fact.Arguments[1].Value] = struct{}{}
 			}
 		}
 	}
+	return sortedValues(values)
+}
+
+// sortedValues returns the keys of a value set in sorted order, or nil if it is empty.
+func sortedValues(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	slices.Sort(values)
 	return values
 }
 
 // -----------------------------------------------------------------------------
-// Helper Types and Functions
+// Change Simulation
 // -----------------------------------------------------------------------------
 
-// eflintFact represents a fact from the eFLINT facts response.
-type eflintFact struct {
-	FactType   string `json:"fact-type"`
-	TaggedType string `json:"tagged-type"`
-	Arguments  []struct {
-		FactType string `json:"fact-type"`
-		Value    string `json:"value"`
-	} `json:"arguments"`
+// SimulateChange exports the current state as an in-memory checkpoint, sends the
+// phrases, validates the request and imports the checkpoint again. If the import
+// fails (load-export is fragile in the eFLINT server), the instance is restarted
+// from its model instead. The instance is held exclusively from the checkpoint
+// through the rollback (see eflint.Manager.Exclusive), so simulations are
+// serialized and other requests never observe the simulated state.
+func (r *EflintReasoner) SimulateChange(ctx context.Context, phrases []string, params RequestParams) (sim *ChangeSimulation, err error) {
+	err = r.manager.Exclusive(ctx, func(ctx context.Context) error {
+		checkpoint, err := r.state.ExportStateContext(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCheckpointFailed, err)
+		}
+
+		sim = &ChangeSimulation{}
+		for _, phrase := range phrases {
+			if _, err := r.sendPhrase(ctx, phrase); err != nil {
+				sim.ChangeError = fmt.Errorf("%w: %s: %w", ErrAssertFailed, phrase, err)
+				break
+			}
+			sim.Applied++
+		}
+
+		if sim.ChangeError == nil {
+			sim.Result, sim.ChangeError = r.IsRequestAllowed(ctx, params)
+		}
+
+		// Roll back even if the caller has gone away meanwhile
+		r.rollback(context.WithoutCancel(ctx), checkpoint, sim)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sim, nil
 }
 
-// parseFactsResponse parses the JSON response from an eFLINT "facts" command.
-func parseFactsResponse(response string) ([]eflintFact, error) {
-	var factsResponse struct {
-		Values []eflintFact `json:"values"`
+// rollback restores the checkpoint, or restarts the instance if that fails, and
+// records the outcome in sim.
+func (r *EflintReasoner) rollback(ctx context.Context, checkpoint *eflint.SavedState, sim *ChangeSimulation) {
+	restoreErr := r.state.ImportStateContext(ctx, checkpoint)
+	if restoreErr == nil {
+		sim.Rollback = RollbackRestored
+		return
 	}
 
-	if err := json.Unmarshal([]byte(response), &factsResponse); err != nil {
+	r.log(ctx).Warn("failed to restore checkpoint after simulation, restarting instance", zap.Error(restoreErr))
+	if err := r.manager.Restart(); err != nil {
+		r.log(ctx).Error("failed to restart instance after simulation", zap.Error(err))
+		sim.Rollback = RollbackFailed
+		sim.RollbackError = fmt.Errorf("restore failed: %w; restart failed: %w", restoreErr, err)
+		return
+	}
+
+	sim.Rollback = RollbackRestarted
+	sim.RollbackError = restoreErr
+}
+
+// -----------------------------------------------------------------------------
+// Change Notification
+// -----------------------------------------------------------------------------
+
+// OnChange registers a callback that is invoked whenever the eFLINT instance
+// state may have changed (mutating commands, restarts, model updates).
+func (r *EflintReasoner) OnChange(callback func()) (unsubscribe func()) {
+	return r.manager.OnChange(func(string) { callback() })
+}
+
+// -----------------------------------------------------------------------------
+// Consistent Reads
+// -----------------------------------------------------------------------------
+
+// maxStaleReadRetries bounds how often a read overlapping a mutation is retried.
+const maxStaleReadRetries = 3
+
+// query sends a read-only command and retries it if a mutating command completed
+// while it was in flight (detected via the manager's generation counter). A result
+// returned with an unchanged generation reflects every mutation that returned before
+// the read finished, so e.g. a validation never sees facts from before a revoke that
+// already completed. Under continuous mutations the last result is returned after
+// maxStaleReadRetries attempts; it still reflects all mutations that completed
+// before that attempt started.
+func (r *EflintReasoner) query(ctx context.Context, command string) (string, error) {
+	var response string
+	for attempt := 1; ; attempt++ {
+		generation := r.manager.Generation()
+
+		var err error
+		response, err = r.sendCommand(ctx, command)
+		if err != nil {
+			return "", err
+		}
+
+		if r.manager.Generation() == generation || attempt > maxStaleReadRetries {
+			return response, nil
+		}
+
+		r.log(ctx).Debug("eFLINT state changed during read, retrying",
+			zap.Int("attempt", attempt),
+		)
+	}
+}
+
+// sendCommand sends a command to the eFLINT server through the circuit breaker.
+func (r *EflintReasoner) sendCommand(ctx context.Context, command string) (string, error) {
+	if r.breaker == nil {
+		return r.manager.SendCommandContext(ctx, command)
+	}
+	if err := r.breaker.allow(); err != nil {
+		return "", err
+	}
+
+	response, err := r.manager.SendCommandContext(ctx, command)
+	r.breaker.record(ctx, err)
+	return response, err
+}
+
+// queryDecode is query for a response decoded while it is read; see
+// eflint.Manager.SendCommandDecode. decode may be called once per attempt.
+func (r *EflintReasoner) queryDecode(ctx context.Context, command string, decode func(response io.Reader) error) error {
+	for attempt := 1; ; attempt++ {
+		generation := r.manager.Generation()
+
+		if err := r.sendCommandDecode(ctx, command, decode); err != nil {
+			return err
+		}
+
+		if r.manager.Generation() == generation || attempt > maxStaleReadRetries {
+			return nil
+		}
+
+		r.log(ctx).Debug("eFLINT state changed during read, retrying",
+			zap.Int("attempt", attempt),
+		)
+	}
+}
+
+// sendCommandDecode sends a command to the eFLINT server through the circuit
+// breaker, decoding the response with decode. A response that decode rejects
+// still came from a healthy server, so it does not count as a failure.
+func (r *EflintReasoner) sendCommandDecode(ctx context.Context, command string, decode func(response io.Reader) error) error {
+	if r.breaker == nil {
+		return r.manager.SendCommandDecode(ctx, command, decode)
+	}
+	if err := r.breaker.allow(); err != nil {
+		return err
+	}
+
+	var decodeErr error
+	err := r.manager.SendCommandDecode(ctx, command, func(response io.Reader) error {
+		decodeErr = decode(response)
+		return decodeErr
+	})
+	if decodeErr != nil && errors.Is(err, decodeErr) {
+		r.breaker.record(ctx, nil)
+	} else {
+		r.breaker.record(ctx, err)
+	}
+	return err
+}
+
+// sendPhrase sends a phrase to the eFLINT server through the circuit breaker and
+// returns the raw response.
+func (r *EflintReasoner) sendPhrase(ctx context.Context, phrase string) (string, error) {
+	if r.breaker == nil {
+		return r.manager.SendPhraseContext(ctx, phrase)
+	}
+	if err := r.breaker.allow(); err != nil {
+		return "", err
+	}
+
+	response, err := r.manager.SendPhraseContext(ctx, phrase)
+	r.breaker.record(ctx, err)
+	return response, err
+}
+
+// -----------------------------------------------------------------------------
+// Helper Types and Functions
+// -----------------------------------------------------------------------------
+
+// factsResponseHeadLimit is the number of bytes of a facts response kept to
+// explain why it could not be decoded.
+const factsResponseHeadLimit = 64 << 10
+
+// readFacts decodes the JSON response from an eFLINT "facts" command as it is read
+// from response, whose facts are in the array under key, keeping only the facts
+// accepted by keep (all facts if keep is nil).
+func readFacts(response io.Reader, key string, keep factPredicate) ([]eflint.Fact, error) {
+	head := &headBuffer{limit: factsResponseHeadLimit}
+	if facts, ok := decodeFacts(io.TeeReader(response, head), key, keep); ok {
+		return facts, nil
+	}
+	if _, err := io.Copy(head, response); err != nil {
+		return nil, err
+	}
+	if head.truncated {
+		return nil, fmt.Errorf("%w: not a well-formed facts response (response: %q)",
+			eflint.ErrInvalidResponse, truncateResponse(head.String()))
+	}
+
+	// Not a well-formed facts response, but a short one: decode it in full to report why
+	text := strings.TrimSpace(head.String())
+	var factsResponse map[string]json.RawMessage
+	if err := decodeEflintResponse(text, &factsResponse); err != nil {
 		return nil, err
 	}
+	raw, ok := factsResponse[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected response shape: missing %q (response: %q)",
+			eflint.ErrInvalidResponse, key, truncateResponse(text))
+	}
+
+	var facts []eflint.Fact
+	if err := json.Unmarshal(raw, &facts); err != nil || facts == nil {
+		return nil, fmt.Errorf("%w: unexpected response shape: %q is not an array of facts (response: %q)",
+			eflint.ErrInvalidResponse, key, truncateResponse(text))
+	}
+	return filterFacts(facts, keep), nil
+}
+
+// headBuffer keeps the first limit bytes written to it and discards the rest.
+type headBuffer struct {
+	strings.Builder
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer. It never fails, so that the whole input is consumed.
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Builder.Write(p[:max(room, 0)])
+	} else {
+		b.Builder.Write(p)
+	}
+	return len(p), nil
+}
 
-	return factsResponse.Values, nil
+// decodeFacts decodes the facts array under key of a facts response one fact at a
+// time, applying keep during the decode. It returns false if the response is not a
+// well-formed facts response (malformed JSON, a server error or no facts array).
+func decodeFacts(response io.Reader, key string, keep factPredicate) ([]eflint.Fact, bool) {
+	dec := json.NewDecoder(response)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+
+	var facts []eflint.Fact
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+
+		switch tok {
+		case key:
+			if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+				return nil, false
+			}
+			facts = []eflint.Fact{}
+			var fact eflint.Fact
+			for dec.More() {
+				// Reuse the arguments of a discarded fact; kept facts get their own copy
+				fact = eflint.Fact{Arguments: fact.Arguments[:0]}
+				if err := dec.Decode(&fact); err != nil {
+					return nil, false
+				}
+				if keep == nil || keep(&fact) {
+					kept := fact
+					kept.Arguments = slices.Clone(fact.Arguments)
+					facts = append(facts, kept)
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, false
+			}
+		case "response":
+			var status string
+			if err := dec.Decode(&status); err != nil || strings.HasPrefix(status, "invalid") {
+				return nil, false
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, false
+			}
+		}
+	}
+
+	// Closing brace, then nothing but whitespace
+	if _, err := dec.Token(); err != nil {
+		return nil, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false
+	}
+	return facts, facts != nil
+}
+
+// filterFacts returns the facts accepted by keep (all facts if keep is nil).
+func filterFacts(facts []eflint.Fact, keep factPredicate) []eflint.Fact {
+	if keep == nil {
+		return facts
+	}
+	kept := []eflint.Fact{}
+	for i := range facts {
+		if keep(&facts[i]) {
+			kept = append(kept, facts[i])
+		}
+	}
+	return kept
+}
+
+// ofRequester keeps facts about a requester at an organization, i.e. facts whose
+// first two arguments are that organization and requester (the allowed-* facts).
+func ofRequester(organization, requester string) factPredicate {
+	return func(fact *eflint.Fact) bool {
+		return len(fact.Arguments) >= 2 &&
+			fact.Arguments[0].FactType == "organization" &&
+			fact.Arguments[0].Value == organization &&
+			fact.Arguments[1].FactType == "requester" &&
+			fact.Arguments[1].Value == requester
+	}
+}
+
+// ofOrganization keeps facts whose first argument is the organization.
+func ofOrganization(organization string) factPredicate {
+	return func(fact *eflint.Fact) bool {
+		return len(fact.Arguments) >= 1 &&
+			fact.Arguments[0].FactType == "organization" &&
+			fact.Arguments[0].Value == organization
+	}
+}
+
+// ofType keeps facts of the given fact types.
+func ofType(factTypes ...string) factPredicate {
+	return func(fact *eflint.Fact) bool {
+		return slices.Contains(factTypes, fact.FactType)
+	}
+}
+
+// responsePreviewLimit is the number of response bytes included in error messages.
+const responsePreviewLimit = 200
+
+// decodeEflintResponse unmarshals an eFLINT response into out. All failures wrap
+// eflint.ErrInvalidResponse and include a truncated copy of the response, and an
+// error object returned by the server (e.g. {"response": "invalid command"}) is
+// reported as such rather than as an unexpected shape.
+func decodeEflintResponse(response string, out interface{}) error {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(response), &envelope); err != nil {
+		return fmt.Errorf("%w: response is not a JSON object: %v (response: %q)",
+			eflint.ErrInvalidResponse, err, truncateResponse(response))
+	}
+
+	var status string
+	if raw, ok := envelope["response"]; ok && json.Unmarshal(raw, &status) == nil && strings.HasPrefix(status, "invalid") {
+		var message string
+		if raw, ok := envelope["message"]; ok {
+			_ = json.Unmarshal(raw, &message)
+		}
+		return fmt.Errorf("%w: server returned an error: %s %s (response: %q)",
+			eflint.ErrInvalidResponse, status, message, truncateResponse(response))
+	}
+
+	if err := json.Unmarshal([]byte(response), out); err != nil {
+		return fmt.Errorf("%w: unexpected response shape: %v (response: %q)",
+			eflint.ErrInvalidResponse, err, truncateResponse(response))
+	}
+	return nil
+}
+
+// truncateResponse shortens a response for inclusion in error messages.
+func truncateResponse(response string) string {
+	if len(response) <= responsePreviewLimit {
+		return response
+	}
+	return response[:responsePreviewLimit] + "..."
 }
 
 // Ensure EflintReasoner implements the interfaces
 var _ Reasoner = (*EflintReasoner)(nil)
 var _ AvailabilityProvider = (*EflintReasoner)(nil)
+var _ ClauseRevoker = (*EflintReasoner)(nil)
+var _ RequesterLookup = (*EflintReasoner)(nil)
+var _ SelfTester = (*EflintReasoner)(nil)
+var _ RequestActChecker = (*EflintReasoner)(nil)
+var _ ReadOnlyReporter = (*EflintReasoner)(nil)
+var _ FactsProvider = (*EflintReasoner)(nil)
+var _ ChangeNotifier = (*EflintReasoner)(nil)
+var _ BatchClauseProvider = (*EflintReasoner)(nil)
+var _ ChangeSimulator = (*EflintReasoner)(nil)
+var _ ActExecutor = (*EflintReasoner)(nil)
+var _ EnabledActsProvider = (*EflintReasoner)(nil)
+var _ ChangeTracker = (*EflintReasoner)(nil)
@@ -0,0 +1,63 @@
+package reasoner
+
+import "errors"
+
+// -----------------------------------------------------------------------------
+// Errors
+// -----------------------------------------------------------------------------
+//
+// These errors are returned (wrapped) by reasoner implementations and by the
+// policy enforcer. Use errors.Is() to check for specific error types.
+
+var (
+	// ErrReasonerNotRunning is returned when an operation requires a running reasoner
+	// backend, but the backend is not operational.
+	ErrReasonerNotRunning = errors.New("reasoner is not running")
+
+	// ErrFactsFetchFailed is returned when the reasoner fails to retrieve or parse
+	// facts from the backend.
+	ErrFactsFetchFailed = errors.New("failed to fetch facts from reasoner")
+
+	// ErrValidationFailed is returned when the reasoner fails to evaluate a request
+	// validation query. It does not indicate a denied request.
+	ErrValidationFailed = errors.New("failed to validate request with reasoner")
+
+	// ErrEmptyModel is returned by the startup self-test when the loaded model
+	// contains no facts, which usually means the wrong model file was loaded.
+	ErrEmptyModel = errors.New("model returned no facts")
+
+	// ErrRequestActMismatch is returned by the startup check when the loaded model
+	// does not declare the request act with the configured parameters.
+	ErrRequestActMismatch = errors.New("model does not match the request act")
+
+	// ErrUnknownClauseType is returned when a clause type name is not recognized.
+	ErrUnknownClauseType = errors.New("unknown clause type")
+
+	// ErrRevokeFailed is returned when the reasoner fails to revoke a fact.
+	ErrRevokeFailed = errors.New("failed to revoke fact")
+
+	// ErrAssertFailed is returned when the reasoner fails to assert a fact.
+	ErrAssertFailed = errors.New("failed to assert fact")
+
+	// ErrActFailed is returned when the reasoner fails to execute an act.
+	ErrActFailed = errors.New("failed to execute act")
+
+	// ErrEnabledActsFailed is returned when the reasoner fails to determine the
+	// enabled acts of a principal.
+	ErrEnabledActsFailed = errors.New("failed to determine enabled acts")
+
+	// ErrCheckpointFailed is returned when the reasoner cannot checkpoint its state
+	// before simulating a change.
+	ErrCheckpointFailed = errors.New("failed to checkpoint reasoner state")
+
+	// ErrCheckpointNotFound is returned when a checkpoint to compare against does not exist.
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+	// ErrCheckpointWithoutFacts is returned when a checkpoint to compare against did
+	// not record its facts, e.g. because it was created before facts were recorded.
+	ErrCheckpointWithoutFacts = errors.New("checkpoint has no recorded facts")
+
+	// ErrNotSupported is returned when an operation requires an optional interface
+	// (e.g. AvailabilityProvider) that the active reasoner does not implement.
+	ErrNotSupported = errors.New("reasoner does not support this operation")
+)
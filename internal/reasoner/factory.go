@@ -0,0 +1,35 @@
+package reasoner
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// -----------------------------------------------------------------------------
+// Reasoner Factory
+// -----------------------------------------------------------------------------
+
+// Config selects and configures the reasoner backend.
+type Config struct {
+	Type     string         // Reasoner type: "eflint" (default) or "symboleo"
+	Eflint   EflintConfig   // Settings for the eFLINT reasoner
+	Symboleo SymboleoConfig // Settings for the Symboleo reasoner
+}
+
+// New creates the reasoner selected by config.Type.
+// The eFLINT manager is only used by the eFLINT reasoner.
+func New(config Config, manager *eflint.Manager, logger *zap.Logger) (Reasoner, error) {
+	switch config.Type {
+	case "", "eflint":
+		return NewEflintReasoner(manager, config.Eflint, logger), nil
+	case "symboleo":
+		if config.Symboleo.Endpoint == "" {
+			return nil, fmt.Errorf("symboleo reasoner requires an endpoint")
+		}
+		return NewSymboleoReasoner(config.Symboleo, logger), nil
+	}
+	return nil, fmt.Errorf("unknown reasoner type %q", config.Type)
+}
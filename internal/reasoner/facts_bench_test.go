@@ -0,0 +1,41 @@
+package reasoner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// factsResponse returns a facts response with n allowed-archetype facts spread
+// over 100 organizations.
+func factsResponse(n int) string {
+	facts := make([]string, n)
+	for i := range facts {
+		facts[i] = fmt.Sprintf(`{"fact-type": "allowed-archetype", "arguments": [`+
+			`{"fact-type": "organization", "value": "org-%d"}, `+
+			`{"fact-type": "requester", "value": "user-%d@example.com"}, `+
+			`{"fact-type": "archetype", "value": "computeToData"}]}`, i%100, i)
+	}
+	return `{"response": "success", "values": [` + strings.Join(facts, ", ") + `]}`
+}
+
+// BenchmarkFetchFactsWhere measures fetching the facts of one organization out of
+// a large facts response, including the round trip to a fake eflint-server.
+func BenchmarkFetchFactsWhere(b *testing.B) {
+	response := factsResponse(20000)
+	r, _ := newTestReasoner(b, EflintConfig{}, func(string) string { return response })
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(response)))
+	for b.Loop() {
+		facts, err := r.fetchFactsWhere(ctx, ofOrganization("org-7"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(facts) != 200 {
+			b.Fatalf("got %d facts, want 200", len(facts))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package reasoner
+
+import (
+	"sync"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+// -----------------------------------------------------------------------------
+// Facts Cache
+// -----------------------------------------------------------------------------
+
+// factsCache keeps the facts of the last full facts query for as long as the
+// eFLINT state is unchanged, i.e. while the manager's generation counter has not
+// moved. Queries served from it are filtered in memory instead of asking the
+// server. Thread-safe for concurrent access.
+type factsCache struct {
+	mu         sync.Mutex
+	facts      []eflint.Fact
+	generation uint64
+	valid      bool
+}
+
+// get returns the cached facts if they were fetched at generation.
+func (c *factsCache) get(generation uint64) ([]eflint.Fact, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.valid || c.generation != generation {
+		return nil, false
+	}
+	return c.facts, true
+}
+
+// put stores facts fetched at generation, unless newer facts are cached already.
+// Facts fetched while a mutation was in flight carry an outdated generation and
+// are never returned by get.
+func (c *factsCache) put(generation uint64, facts []eflint.Fact) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid && c.generation > generation {
+		return
+	}
+	c.facts = facts
+	c.generation = generation
+	c.valid = true
+}
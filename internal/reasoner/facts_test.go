@@ -0,0 +1,149 @@
+package reasoner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+)
+
+func TestFetchFactsReportsInvalidResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"server error", `{"response": "invalid command", "message": "unknown type"}`, "server returned an error"},
+		{"missing facts", `{"response": "success"}`, `missing "values"`},
+		{"not an array", `{"response": "success", "values": "none"}`, "not an array of facts"},
+		{"not JSON", `facts unavailable`, "not a JSON object"},
+		{"large malformed response", strings.TrimSuffix(factsResponse(2000), "]}") + `, 42]}`, "not a well-formed facts response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReasoner(t, EflintConfig{}, func(command string) string {
+				if commandName(command) == "facts" {
+					return tt.response
+				}
+				return `{"success": true}`
+			})
+
+			_, err := r.FetchFacts(context.Background())
+			if !errors.Is(err, ErrFactsFetchFailed) || !errors.Is(err, eflint.ErrInvalidResponse) {
+				t.Fatalf("FetchFacts() error = %v, want ErrFactsFetchFailed wrapping ErrInvalidResponse", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("FetchFacts() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFactsWhereDecodesWhileReading(t *testing.T) {
+	r, _ := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		// A response split across lines, as the server pretty-prints it
+		return strings.ReplaceAll(factsResponse(300), "}, {", "},\n{")
+	})
+
+	for range 2 {
+		facts, err := r.fetchFactsWhere(context.Background(), ofOrganization("org-7"))
+		if err != nil {
+			t.Fatalf("fetchFactsWhere() error = %v", err)
+		}
+		if len(facts) != 3 {
+			t.Fatalf("fetchFactsWhere() = %d facts, want 3", len(facts))
+		}
+		for _, fact := range facts {
+			if fact.Arguments[0].Value != "org-7" {
+				t.Errorf("fetchFactsWhere() kept %+v", fact)
+			}
+		}
+	}
+}
+
+func TestFetchFactsReturnsWhenCancelled(t *testing.T) {
+	release := make(chan struct{})
+	r, _ := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		if commandName(command) == "facts" {
+			<-release
+		}
+		return `{"response": "success", "values": []}`
+	})
+	t.Cleanup(func() { close(release) })
+
+	reads := map[string]func(ctx context.Context) error{
+		"FetchFacts": func(ctx context.Context) error {
+			_, err := r.FetchFacts(ctx)
+			return err
+		},
+		"GetAllowedArchetypes": func(ctx context.Context) error {
+			_, err := r.GetAllowedArchetypes(ctx, "org-0", "user-0@example.com")
+			return err
+		},
+	}
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(100*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := read(ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("%s() error = %v, want context.Canceled", name, err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("%s() returned after %v, want it to return once cancelled", name, elapsed)
+			}
+		})
+	}
+}
+
+func TestFilteredValuesAreDedupedAndSorted(t *testing.T) {
+	allowed := func(requester, archetype string) string {
+		return `{"fact-type": "allowed-archetype", "arguments": [` +
+			`{"fact-type": "organization", "value": "VU"}, ` +
+			`{"fact-type": "requester", "value": "` + requester + `"}, ` +
+			`{"fact-type": "archetype", "value": "` + archetype + `"}]}`
+	}
+	available := func(archetype string) string {
+		return `{"fact-type": "available-archetype", "arguments": [` +
+			`{"fact-type": "organization", "value": "VU"}, ` +
+			`{"fact-type": "archetype", "value": "` + archetype + `"}]}`
+	}
+	response := `{"response": "success", "values": [` + strings.Join([]string{
+		allowed("bob", "dataThroughTtp"), allowed("bob", "computeToData"), allowed("bob", "dataThroughTtp"),
+		allowed("carol", "computeToData"), allowed("alice", "computeToData"), allowed("carol", "computeToData"),
+		available("dataThroughTtp"), available("computeToData"), available("dataThroughTtp"),
+	}, ", ") + `]}`
+	r, _ := newTestReasoner(t, EflintConfig{}, func(string) string { return response })
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		lookup func() ([]string, error)
+		want   []string
+	}{
+		{"GetAllowedArchetypes", func() ([]string, error) { return r.GetAllowedArchetypes(ctx, "VU", "bob") },
+			[]string{"computeToData", "dataThroughTtp"}},
+		{"GetRequestersAllowed", func() ([]string, error) {
+			return r.GetRequestersAllowed(ctx, "VU", ClauseArchetype, "computeToData")
+		}, []string{"alice", "bob", "carol"}},
+		{"GetAvailableArchetypes", func() ([]string, error) { return r.GetAvailableArchetypes(ctx, "VU") },
+			[]string{"computeToData", "dataThroughTtp"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.lookup()
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package reasoner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+// revokeDuringReadHandler answers reads with the state from before a revoke, holding
+// the first read until proceed is closed; reading is closed once it is held.
+func revokeDuringReadHandler(revoked *atomic.Bool, reading, proceed chan struct{}) eflinttest.Handler {
+	var once sync.Once
+	return func(command string) string {
+		switch commandName(command) {
+		case "phrase":
+			revoked.Store(true)
+			return `{"response": "success"}`
+		case "enabled":
+			granted := !revoked.Load()
+			once.Do(func() { close(reading); <-proceed })
+			if granted {
+				return `{"response": "success", "query-results": ["success"]}`
+			}
+			return `{"response": "success", "query-results": []}`
+		case "facts":
+			granted := !revoked.Load()
+			once.Do(func() { close(reading); <-proceed })
+			if granted {
+				return factsResponse(1)
+			}
+			return `{"response": "success", "values": []}`
+		}
+		return `{}`
+	}
+}
+
+// TestReadOverlappingRevokeIsRetried revokes the clause a validation or facts
+// fetch depends on while the read is at the server: the read must not return the
+// state from before the completed revoke.
+func TestReadOverlappingRevokeIsRetried(t *testing.T) {
+	reads := map[string]func(r *EflintReasoner) (granted bool, err error){
+		"IsRequestAllowed": func(r *EflintReasoner) (bool, error) {
+			result, err := r.IsRequestAllowed(context.Background(), RequestParams{
+				Organization: "org-0", Requester: "user-0@example.com", Archetype: "computeToData",
+			})
+			return err == nil && result.Allowed, err
+		},
+		"FetchFacts": func(r *EflintReasoner) (bool, error) {
+			facts, err := r.FetchFacts(context.Background())
+			return len(facts) > 0, err
+		},
+		"GetAllowedArchetypes": func(r *EflintReasoner) (bool, error) {
+			archetypes, err := r.GetAllowedArchetypes(context.Background(), "org-0", "user-0@example.com")
+			return len(archetypes) > 0, err
+		},
+	}
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			var revoked atomic.Bool
+			reading := make(chan struct{})
+			proceed := make(chan struct{})
+			r, server := newTestReasoner(t, EflintConfig{CacheFacts: true}, revokeDuringReadHandler(&revoked, reading, proceed))
+
+			type outcome struct {
+				granted bool
+				err     error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				granted, err := read(r)
+				done <- outcome{granted, err}
+			}()
+			<-reading
+
+			// The revoke completes while the read is at the server
+			if err := r.RevokeAllowedClause(context.Background(), "org-0", "user-0@example.com", ClauseArchetype, "computeToData"); err != nil {
+				t.Fatalf("RevokeAllowedClause() error = %v", err)
+			}
+			close(proceed)
+
+			got := <-done
+			if got.err != nil {
+				t.Fatalf("read error = %v", got.err)
+			}
+			if got.granted {
+				t.Error("read returned the state from before a completed revoke")
+			}
+			var reads int
+			for _, command := range server.Commands() {
+				if name := commandName(command); name == "enabled" || name == "facts" {
+					reads++
+				}
+			}
+			if reads != 2 {
+				t.Errorf("%d reads sent, want the overlapping read retried once", reads)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package reasoner
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint/eflinttest"
+)
+
+func TestMain(m *testing.M) {
+	eflinttest.Main(m)
+}
+
+// startManager starts a Manager whose eflint-server answers commands with handler.
+func startManager(t testing.TB, handler eflinttest.Handler) (*eflint.Manager, *eflinttest.Server) {
+	t.Helper()
+	server := eflinttest.NewServer(t, handler)
+
+	config := eflint.DefaultManagerConfig()
+	config.EflintServerPath = eflinttest.Path()
+	config.StartupDelay = 0
+	config.ConnectionTimeout = 5 * time.Second
+	config.KillTimeout = time.Second
+	manager := eflint.NewManager(config, zap.NewNop())
+	if err := manager.Start(server.Model); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { manager.Stop() })
+
+	eflinttest.WaitReady(t, manager.Ping)
+	return manager, server
+}
+
+// newTestReasoner returns a reasoner backed by a fake eflint-server.
+func newTestReasoner(t testing.TB, config EflintConfig, handler eflinttest.Handler) (*EflintReasoner, *eflinttest.Server) {
+	t.Helper()
+	manager, server := startManager(t, handler)
+	return NewEflintReasoner(manager, config, zap.NewNop()), server
+}
+
+// commandName returns the "command" field of a command sent to the server.
+func commandName(command string) string {
+	var cmd struct {
+		Command string `json:"command"`
+	}
+	json.Unmarshal([]byte(command), &cmd)
+	return cmd.Command
+}
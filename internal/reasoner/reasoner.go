@@ -3,7 +3,13 @@
 // such as eFLINT, Symboleo, or JSON-based agreement formats.
 package reasoner
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
 
 // -----------------------------------------------------------------------------
 // Core Types
@@ -19,13 +25,35 @@ type AllowedClause struct {
 
 // AllAllowedClauses contains all allowed clauses for a requester at an organization.
 // This is returned by the optimized GetAllAllowedClauses method.
-type AllAllowedClauses struct {
-	RequestTypes     []string `json:"request_types"`     // Allowed request types
-	DataSets         []string `json:"data_sets"`         // Allowed datasets
-	Archetypes       []string `json:"archetypes"`        // Allowed archetypes
-	ComputeProviders []string `json:"compute_providers"` // Allowed compute providers
+type AllAllowedClauses = api.AllAllowedClauses
+
+// Clause types identify the kinds of clauses that can be granted to a requester.
+// They are used wherever a single clause type is selected by name (e.g. in query parameters).
+const (
+	ClauseRequestType     = "request-type"
+	ClauseDataSet         = "data-set"
+	ClauseArchetype       = "archetype"
+	ClauseComputeProvider = "compute-provider"
+)
+
+// ClauseType defines a clause type that can be queried by name: the fact type that
+// grants it, whose arguments are the organization, the requester and the granted value.
+type ClauseType struct {
+	Name          string `json:"name"`            // Name selecting the clause type, e.g. "archetype"
+	FactType      string `json:"fact_type"`       // Fact type granting the clause, e.g. "allowed-archetype"
+	ValueFactType string `json:"value_fact_type"` // Fact type of the granted value, e.g. "archetype"
 }
 
+// OrgRequester identifies a requester at an organization.
+type OrgRequester = api.OrgRequester
+
+// OrgPolicy is a portable snapshot of everything an organization has granted,
+// plus the resources it makes available. It can be exported and re-imported.
+type OrgPolicy = api.OrgPolicy
+
+// RequesterGrants lists all clauses granted to a single requester.
+type RequesterGrants = api.RequesterGrants
+
 // RequestParams contains all parameters needed to validate a data request.
 type RequestParams struct {
 	Organization    string `json:"organization"`     // The data steward organization
@@ -36,11 +64,91 @@ type RequestParams struct {
 	ComputeProvider string `json:"compute_provider"` // Where the computation runs (e.g., "SURF")
 }
 
+// ReasonCode is a stable, machine-readable classification of a validation decision
+// (see api.ReasonCode).
+type ReasonCode = api.ReasonCode
+
+// Reason codes returned with every validation result.
+const (
+	ReasonPermitted           = api.ReasonPermitted
+	ReasonNotPermitted        = api.ReasonNotPermitted
+	ReasonViolation           = api.ReasonViolation
+	ReasonResourceUnavailable = api.ReasonResourceUnavailable
+	ReasonError               = api.ReasonError
+)
+
+// Duty is a duty an act creates (see api.Duty).
+type Duty = api.Duty
+
+// Fact is a fact that holds in a reasoner's state (see api.Fact).
+type Fact = api.Fact
+
+// ErrorCategory classifies an error the reasoner reported (see api.ErrorCategory).
+type ErrorCategory = api.ErrorCategory
+
+// Error categories reported with ReasonError results.
+const (
+	ErrorCategoryParse   = api.ErrorCategoryParse
+	ErrorCategoryType    = api.ErrorCategoryType
+	ErrorCategoryRuntime = api.ErrorCategoryRuntime
+)
+
 // RequestValidationResult contains the outcome of a request validation.
 type RequestValidationResult struct {
-	Allowed     bool   `json:"allowed"`                // Whether the request is permitted
-	Reason      string `json:"reason,omitempty"`       // Explanation for the decision
-	RawResponse string `json:"raw_response,omitempty"` // DEBUG: Raw response from the reasoner
+	Allowed       bool          `json:"allowed"`                  // Whether the request is permitted
+	ReasonCode    ReasonCode    `json:"reason_code"`              // Machine-readable classification of the decision
+	Reason        string        `json:"reason,omitempty"`         // Explanation for the decision
+	ErrorCategory ErrorCategory `json:"error_category,omitempty"` // With ReasonError: what kind of error was reported
+	Duties        []Duty        `json:"duties,omitempty"`         // When allowed: duties the request would create
+	RawResponse   string        `json:"raw_response,omitempty"`   // DEBUG: Raw response from the reasoner
+}
+
+// Rollback outcomes of a ChangeSimulation.
+const (
+	// RollbackRestored means the state from before the simulation was restored.
+	RollbackRestored = "restored"
+	// RollbackRestarted means restoring failed and the reasoner was restarted from its
+	// model: the simulated changes are gone, but so are all changes made since startup.
+	RollbackRestarted = "restarted"
+	// RollbackFailed means neither restoring nor restarting worked; the simulated
+	// changes may still be in effect.
+	RollbackFailed = "failed"
+)
+
+// ChangeSimulation is the outcome of a simulated policy change.
+type ChangeSimulation struct {
+	Result        *RequestValidationResult // Validation result with the changes applied; nil if ChangeError is set
+	Applied       int                      // Number of changes applied before validating (or failing)
+	ChangeError   error                    // Why applying a change or validating failed, if it did
+	Rollback      string                   // RollbackRestored, RollbackRestarted or RollbackFailed
+	RollbackError error                    // Why restoring (and restarting, if attempted) failed
+}
+
+// ActExecution is the outcome of an executed act.
+type ActExecution struct {
+	Phrase     string              // The statement sent to the reasoner, e.g. an eFLINT phrase
+	Created    []eflint.Fact       // Facts created by the act
+	Terminated []eflint.Fact       // Facts terminated by the act
+	Violations []eflint.Diagnostic // Violations caused by the act (e.g. it was not enabled)
+}
+
+// EnabledAct is an act a principal could currently perform (see api.EnabledAct).
+type EnabledAct = api.EnabledAct
+
+// EnabledActs lists the acts a principal could currently perform.
+type EnabledActs struct {
+	Acts   []EnabledAct // Enabled acts, in the reasoner's order
+	Source string       // api.EnabledActsFromTransitions or api.EnabledActsFromKnownActs
+}
+
+// ClauseChange is an allowed clause that was granted or revoked (see api.ClauseChange).
+type ClauseChange = api.ClauseChange
+
+// ClauseChanges lists the allowed clauses changed since a checkpoint.
+type ClauseChanges struct {
+	CheckpointedAt time.Time      // When the checkpoint was created
+	Added          []ClauseChange // Granted since the checkpoint, sorted
+	Removed        []ClauseChange // Revoked since the checkpoint, sorted
 }
 
 // -----------------------------------------------------------------------------
@@ -101,3 +209,125 @@ type StateManager interface {
 	// ImportState imports a previously exported state.
 	ImportState(ctx context.Context, state []byte) error
 }
+
+// ClauseRevoker is an optional interface for reasoners that can revoke previously
+// granted clauses.
+type ClauseRevoker interface {
+	// RevokeAllowedClause revokes a single allowed clause of the given clause type
+	// (one of the Clause* constants) for a requester at an organization.
+	RevokeAllowedClause(ctx context.Context, organization, requester, clauseType, value string) error
+}
+
+// RequesterLookup is an optional interface for reasoners that support reverse
+// lookups from a clause to the requesters it has been granted to.
+type RequesterLookup interface {
+	// GetRequestersAllowed returns all requesters at an organization that are allowed
+	// the given clause value of the given clause type (one of the Clause* constants).
+	GetRequestersAllowed(ctx context.Context, organization, clauseType, value string) ([]string, error)
+}
+
+// SelfTester is an optional interface for reasoners that can verify, after startup,
+// that their policy model is loaded and answers queries.
+type SelfTester interface {
+	// SelfTest runs a harmless query and returns the number of facts in the model.
+	SelfTest(ctx context.Context) (int, error)
+}
+
+// RequestActChecker is an optional interface for reasoners that can verify, after
+// startup, that the policy model declares the act request validations are
+// checked with.
+type RequestActChecker interface {
+	// CheckRequestAct returns ErrRequestActMismatch if the model does not declare
+	// the request act with the expected parameters.
+	CheckRequestAct(ctx context.Context) error
+}
+
+// ReadOnlyReporter is an optional interface for reasoners that can run in a
+// read-only mode, in which they refuse state changes (e.g. on a query replica).
+type ReadOnlyReporter interface {
+	// ReadOnly reports whether state changes are refused.
+	ReadOnly() bool
+}
+
+// FactsProvider is an optional interface for reasoners that can list the raw facts
+// that currently hold, e.g. for debugging a policy model.
+type FactsProvider interface {
+	// FetchFacts returns all facts in the reasoner's current state.
+	FetchFacts(ctx context.Context) ([]Fact, error)
+}
+
+// ChangeNotifier is an optional interface for reasoners that can report when their
+// policy state may have changed, so that callers can invalidate derived data.
+type ChangeNotifier interface {
+	// OnChange registers a callback invoked after each state change.
+	// It returns a function that unregisters the callback.
+	OnChange(callback func()) (unsubscribe func())
+}
+
+// BatchClauseProvider is an optional interface for reasoners that can answer
+// allowed-clause queries for many requesters with a single backend query.
+type BatchClauseProvider interface {
+	// GetAllAllowedClausesBatch returns the allowed clauses for each given pair.
+	GetAllAllowedClausesBatch(ctx context.Context, pairs []OrgRequester) (map[OrgRequester]*AllAllowedClauses, error)
+}
+
+// PolicyExporter is an optional interface for reasoners that can export all
+// permissions of an organization as a portable document and import them again.
+type PolicyExporter interface {
+	// ExportOrgPolicy returns all clauses granted by an organization and the
+	// resources it makes available.
+	ExportOrgPolicy(ctx context.Context, organization string) (*OrgPolicy, error)
+
+	// ImportOrgPolicy grants every clause and availability in the document and
+	// returns the number of facts asserted. Existing facts are left in place.
+	ImportOrgPolicy(ctx context.Context, policy *OrgPolicy) (int, error)
+}
+
+// ChangeSimulator is an optional interface for reasoners that can answer "what if"
+// questions: would a request be allowed after a policy change, without keeping it.
+type ChangeSimulator interface {
+	// SimulateChange checkpoints the current state, applies the changes in order
+	// (e.g. eFLINT phrases), validates the request and rolls back to the checkpoint.
+	// An error is returned only if nothing was changed because the checkpoint could
+	// not be taken; later failures are reported in the simulation. Other callers
+	// never observe the simulated state.
+	SimulateChange(ctx context.Context, changes []string, params RequestParams) (*ChangeSimulation, error)
+}
+
+// ActExecutor is an optional interface for reasoners that can execute acts, changing
+// their state. Unlike IsRequestAllowed, which only asks whether an act is enabled,
+// executing an act applies its effects: facts are created and terminated.
+type ActExecutor interface {
+	// ExecuteAct performs the act with the given positional arguments and returns
+	// the facts it created and terminated and the violations it caused. A disabled
+	// act is still executed; its violation is reported in the result.
+	ExecuteAct(ctx context.Context, actType string, args []string) (*ActExecution, error)
+}
+
+// EnabledActsProvider is an optional interface for reasoners that can list the acts
+// a principal could currently perform, complementing IsRequestAllowed, which checks
+// a single act.
+type EnabledActsProvider interface {
+	// GetEnabledActs returns the enabled acts that involve both the organization and
+	// the requester of the principal.
+	GetEnabledActs(ctx context.Context, principal OrgRequester) (*EnabledActs, error)
+}
+
+// ClauseTypeRegistry is an optional interface for reasoners that can query clause
+// types by name, including types defined by configuration rather than built in.
+type ClauseTypeRegistry interface {
+	// ClauseTypes returns the registered clause types, sorted by name.
+	ClauseTypes() []ClauseType
+
+	// GetAllowedClauses returns the values of a clause type allowed for a requester at
+	// an organization. Unregistered clause types return ErrUnknownClauseType.
+	GetAllowedClauses(ctx context.Context, organization, requester, clauseType string) ([]string, error)
+}
+
+// ChangeTracker is an optional interface for reasoners that can compare their
+// current state with a checkpoint in domain terms.
+type ChangeTracker interface {
+	// ChangesSince returns the allowed clauses granted and revoked since the named
+	// checkpoint was created.
+	ChangesSince(ctx context.Context, checkpoint string) (*ClauseChanges, error)
+}
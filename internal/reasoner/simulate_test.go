@@ -0,0 +1,63 @@
+package reasoner
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSimulateChangeHoldsInstanceExclusively(t *testing.T) {
+	var once sync.Once
+	applying := make(chan struct{})
+	release := make(chan struct{})
+	r, server := newTestReasoner(t, EflintConfig{}, func(command string) string {
+		switch commandName(command) {
+		case "phrase":
+			once.Do(func() { close(applying) })
+			<-release
+			return `{"response": "success"}`
+		case "create-export":
+			return `{"current": 0, "edges": [], "nodes": []}`
+		case "enabled":
+			return `{"response": "success", "query-results": ["success"]}`
+		}
+		return `{}`
+	})
+
+	simulated := make(chan error, 1)
+	go func() {
+		_, err := r.SimulateChange(context.Background(), []string{`+requester("VU", "simulated").`}, RequestParams{Requester: "simulated"})
+		simulated <- err
+	}()
+	<-applying
+
+	validated := make(chan error, 1)
+	go func() {
+		_, err := r.IsRequestAllowed(context.Background(), RequestParams{Requester: "concurrent"})
+		validated <- err
+	}()
+	select {
+	case <-validated:
+		t.Fatal("IsRequestAllowed() completed while a simulation held the instance")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-simulated; err != nil {
+		t.Fatalf("SimulateChange() error = %v", err)
+	}
+	if err := <-validated; err != nil {
+		t.Fatalf("IsRequestAllowed() error = %v", err)
+	}
+
+	// The concurrent validation reached the server only after the rollback
+	commands := server.Commands()
+	restored := slices.IndexFunc(commands, func(c string) bool { return commandName(c) == "load-export" })
+	concurrent := slices.IndexFunc(commands, func(c string) bool { return strings.Contains(c, `"concurrent"`) })
+	if restored < 0 || concurrent < restored {
+		t.Errorf("commands = %q, want the concurrent validation after load-export", commands)
+	}
+}
@@ -0,0 +1,265 @@
+package reasoner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+)
+
+// -----------------------------------------------------------------------------
+// Symboleo Reasoner Implementation
+// -----------------------------------------------------------------------------
+//
+// SymboleoReasoner talks to a Symboleo engine exposed over a small JSON/HTTP protocol:
+//
+//	GET  {endpoint}/health       -> 200 when the engine is ready
+//	POST {endpoint}/permissions  {"organization", "requester"}
+//	                             -> {"permissions": [{"type": "archetype", "value": "computeToData"}, ...]}
+//	POST {endpoint}/evaluate     {"event": "submitRequest", "parameters": {...}}
+//	                             -> {"allowed": true, "reason": "...", "violations": ["..."]}
+//
+// In Symboleo terms, a clause granted to a requester is a permission whose
+// consequent is the corresponding event; the clause queries therefore map to a
+// filtered view of the active permissions. Request validation maps to evaluating
+// the submitRequest event against the contract's current state.
+
+// SymboleoConfig holds configuration for the Symboleo reasoner.
+type SymboleoConfig struct {
+	Endpoint string        // Base URL of the Symboleo engine (e.g., "http://localhost:9090")
+	Timeout  time.Duration // Timeout for requests to the engine
+}
+
+// SymboleoReasoner implements the Reasoner interface using a Symboleo engine.
+type SymboleoReasoner struct {
+	config SymboleoConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewSymboleoReasoner creates a new Symboleo-based reasoner.
+func NewSymboleoReasoner(config SymboleoConfig, logger *zap.Logger) *SymboleoReasoner {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &SymboleoReasoner{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: logger,
+	}
+}
+
+// Name returns the name of this reasoner.
+func (r *SymboleoReasoner) Name() string {
+	return "symboleo"
+}
+
+// IsRunning checks if the Symboleo engine answers its health endpoint.
+func (r *SymboleoReasoner) IsRunning() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url("/health"), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+// -----------------------------------------------------------------------------
+// Allowed Clauses Retrieval
+// -----------------------------------------------------------------------------
+
+// symboleoPermission is a permission as reported by the Symboleo engine.
+type symboleoPermission struct {
+	Type  string `json:"type"`  // Clause type (e.g., "archetype")
+	Value string `json:"value"` // Clause value (e.g., "computeToData")
+}
+
+// fetchPermissions retrieves the active permissions of a requester at an organization.
+func (r *SymboleoReasoner) fetchPermissions(ctx context.Context, organization, requester string) ([]symboleoPermission, error) {
+	var resp struct {
+		Permissions []symboleoPermission `json:"permissions"`
+	}
+
+	body := map[string]string{
+		"organization": organization,
+		"requester":    requester,
+	}
+	if err := r.post(ctx, "/permissions", body, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFactsFetchFailed, err)
+	}
+
+	return resp.Permissions, nil
+}
+
+// filterPermissions returns the values of all permissions of the given clause type,
+// deduplicated and sorted. This is a pure function that doesn't make any network calls.
+func filterPermissions(permissions []symboleoPermission, clauseType string) []string {
+	values := make(map[string]struct{})
+	for _, p := range permissions {
+		if p.Type == clauseType {
+			values[p.Value] = struct{}{}
+		}
+	}
+	return sortedValues(values)
+}
+
+// GetAllowedRequestTypes returns all request types allowed for a requester at an organization.
+func (r *SymboleoReasoner) GetAllowedRequestTypes(ctx context.Context, organization, requester string) ([]string, error) {
+	permissions, err := r.fetchPermissions(ctx, organization, requester)
+	if err != nil {
+		return nil, err
+	}
+	return filterPermissions(permissions, ClauseRequestType), nil
+}
+
+// GetAllowedDataSets returns all datasets allowed for a requester at an organization.
+func (r *SymboleoReasoner) GetAllowedDataSets(ctx context.Context, organization, requester string) ([]string, error) {
+	permissions, err := r.fetchPermissions(ctx, organization, requester)
+	if err != nil {
+		return nil, err
+	}
+	return filterPermissions(permissions, ClauseDataSet), nil
+}
+
+// GetAllowedArchetypes returns all archetypes allowed for a requester at an organization.
+func (r *SymboleoReasoner) GetAllowedArchetypes(ctx context.Context, organization, requester string) ([]string, error) {
+	permissions, err := r.fetchPermissions(ctx, organization, requester)
+	if err != nil {
+		return nil, err
+	}
+	return filterPermissions(permissions, ClauseArchetype), nil
+}
+
+// GetAllowedComputeProviders returns all compute providers allowed for a requester at an organization.
+func (r *SymboleoReasoner) GetAllowedComputeProviders(ctx context.Context, organization, requester string) ([]string, error) {
+	permissions, err := r.fetchPermissions(ctx, organization, requester)
+	if err != nil {
+		return nil, err
+	}
+	return filterPermissions(permissions, ClauseComputeProvider), nil
+}
+
+// GetAllAllowedClauses returns all allowed clauses for a requester at an organization.
+// Permissions are fetched from the engine only once.
+func (r *SymboleoReasoner) GetAllAllowedClauses(ctx context.Context, organization, requester string) (*AllAllowedClauses, error) {
+	permissions, err := r.fetchPermissions(ctx, organization, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AllAllowedClauses{
+		RequestTypes:     filterPermissions(permissions, ClauseRequestType),
+		DataSets:         filterPermissions(permissions, ClauseDataSet),
+		Archetypes:       filterPermissions(permissions, ClauseArchetype),
+		ComputeProviders: filterPermissions(permissions, ClauseComputeProvider),
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Request Validation
+// -----------------------------------------------------------------------------
+
+// IsRequestAllowed evaluates the submitRequest event against the Symboleo contract.
+func (r *SymboleoReasoner) IsRequestAllowed(ctx context.Context, params RequestParams) (*RequestValidationResult, error) {
+	var resp struct {
+		Allowed    bool     `json:"allowed"`
+		Reason     string   `json:"reason"`
+		Violations []string `json:"violations"`
+	}
+
+	body := map[string]interface{}{
+		"event":      "submitRequest",
+		"parameters": params,
+	}
+	if err := r.post(ctx, "/evaluate", body, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	result := &RequestValidationResult{
+		Allowed: resp.Allowed && len(resp.Violations) == 0,
+		Reason:  resp.Reason,
+	}
+
+	switch {
+	case result.Allowed:
+		result.ReasonCode = ReasonPermitted
+	case len(resp.Violations) > 0:
+		result.ReasonCode = ReasonViolation
+	default:
+		result.ReasonCode = ReasonNotPermitted
+	}
+
+	if len(resp.Violations) > 0 {
+		result.Reason = strings.Join(resp.Violations, "; ")
+	} else if result.Reason == "" && result.Allowed {
+		result.Reason = "Request is permitted by the agreement"
+	} else if result.Reason == "" {
+		result.Reason = "Request is not permitted by the agreement"
+	}
+
+	return result, nil
+}
+
+// -----------------------------------------------------------------------------
+// Helper Functions
+// -----------------------------------------------------------------------------
+
+// url joins the engine endpoint with a path.
+func (r *SymboleoReasoner) url(path string) string {
+	return strings.TrimRight(r.config.Endpoint, "/") + path
+}
+
+// post sends a JSON request to the engine and decodes the JSON response into out.
+func (r *SymboleoReasoner) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url(path), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if id := requestid.FromContext(ctx); id != "" {
+		// Let the engine correlate its logs with ours
+		req.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Symboleo engine: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("symboleo engine returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Symboleo response: %w", err)
+	}
+
+	requestid.Logger(ctx, r.logger).Debug("symboleo request completed", zap.String("path", path))
+	return nil
+}
+
+// Ensure SymboleoReasoner implements the interface
+var _ Reasoner = (*SymboleoReasoner)(nil)
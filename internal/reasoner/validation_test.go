@@ -0,0 +1,72 @@
+package reasoner
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsRequestAllowedClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		errorType string
+		want      ErrorCategory
+	}{
+		{"parse error", ErrorCategoryParse},
+		{"type error", ErrorCategoryType},
+		{"compilation error", ErrorCategoryType},
+		{"runtime error", ErrorCategoryRuntime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.errorType, func(t *testing.T) {
+			r, _ := newTestReasoner(t, EflintConfig{}, func(string) string {
+				return `{"response": "success", "query-results": [], "errors": [{"type": "` + tt.errorType + `", "message": "failed"}]}`
+			})
+
+			result, err := r.IsRequestAllowed(context.Background(), RequestParams{Organization: "VU", Requester: "user@example.com"})
+			if err != nil {
+				t.Fatalf("IsRequestAllowed() error = %v", err)
+			}
+			if result.Allowed || result.ReasonCode != ReasonError || result.ErrorCategory != tt.want {
+				t.Errorf("IsRequestAllowed() = %+v, want a %s error", result, tt.want)
+			}
+		})
+	}
+
+	r, _ := newTestReasoner(t, EflintConfig{}, func(string) string {
+		return `{"response": "success", "query-results": [], "violations": [{"type": "act", "message": "not enabled"}]}`
+	})
+	result, err := r.IsRequestAllowed(context.Background(), RequestParams{Organization: "VU", Requester: "user@example.com"})
+	if err != nil || result.ErrorCategory != "" {
+		t.Errorf("IsRequestAllowed() = %+v, %v, want no error category for a violation", result, err)
+	}
+}
+
+func TestIsRequestAllowedReportsDuties(t *testing.T) {
+	var denied atomic.Bool
+	r, _ := newTestReasoner(t, EflintConfig{}, func(string) string {
+		if denied.Load() {
+			return `{"response": "success", "query-results": []}`
+		}
+		return `{"response": "success", "query-results": ["success"], "new-duties": [` +
+			`{"fact-type": "report-results", "value": [` +
+			`{"fact-type": "requester", "value": "user@example.com"}, {"fact-type": "organization", "value": "VU"}]}]}`
+	})
+	params := RequestParams{Organization: "VU", Requester: "user@example.com"}
+
+	result, err := r.IsRequestAllowed(context.Background(), params)
+	if err != nil {
+		t.Fatalf("IsRequestAllowed() error = %v", err)
+	}
+	if !result.Allowed || len(result.Duties) != 1 {
+		t.Fatalf("IsRequestAllowed() = %+v, want allowed with one duty", result)
+	}
+	if duty := result.Duties[0]; duty.Type != "report-results" || duty.Holder != "user@example.com" || duty.Claimant != "VU" {
+		t.Errorf("duty = %+v, want report-results owed by user@example.com to VU", duty)
+	}
+
+	// A denied request creates no duties
+	denied.Store(true)
+	if result, err := r.IsRequestAllowed(context.Background(), params); err != nil || result.Allowed || result.Duties != nil {
+		t.Errorf("IsRequestAllowed() = %+v, %v, want denied without duties", result, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package requestid provides an Echo middleware that assigns every request an ID
+// and a logger carrying it, so log lines of a single request can be correlated
+// across the HTTP handlers, the enforcer, the reasoner and the eFLINT manager.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// Header is the header carrying the request ID, both on requests and responses.
+	Header = echo.HeaderXRequestID
+	// LogField is the log field holding the request ID.
+	LogField = "request_id"
+)
+
+// maxLength bounds caller-supplied request IDs; longer IDs are replaced.
+const maxLength = 128
+
+type contextKey struct{}
+
+type entry struct {
+	id     string
+	logger *zap.Logger
+}
+
+// Middleware returns a middleware that reads the request ID from the
+// `X-Request-ID` header, or generates one if the header is missing or invalid.
+// The ID is echoed in the response header and stored in the request context
+// together with a child of logger that has the `request_id` field set.
+// Downstream components obtain that logger via Logger.
+func Middleware(logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(Header)
+			if !valid(id) {
+				id = generate()
+				// Keep the request header in sync so Echo's access log reports the same ID
+				c.Request().Header.Set(Header, id)
+			}
+			c.Response().Header().Set(Header, id)
+
+			ctx := NewContext(c.Request().Context(), id, logger)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// NewContext returns a copy of ctx carrying id and a child of logger with the
+// request ID field set.
+func NewContext(ctx context.Context, id string, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry{
+		id:     id,
+		logger: logger.With(zap.String(LogField, id)),
+	})
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	if e, ok := ctx.Value(contextKey{}).(entry); ok {
+		return e.id
+	}
+	return ""
+}
+
+// Logger returns the request-scoped logger stored in ctx. Outside a request it
+// returns fallback, so components can use it unconditionally.
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if e, ok := ctx.Value(contextKey{}).(entry); ok {
+		return e.logger
+	}
+	return fallback
+}
+
+// valid reports whether a caller-supplied ID is safe to log and echo: non-empty,
+// bounded in length and limited to printable ASCII.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// generate returns a random 128-bit request ID in hex.
+func generate() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GRPCServer serves gRPC services on a listener of their own. It implements
+// grpc.ServiceRegistrar, so services register on it directly.
+type GRPCServer struct {
+	server *grpc.Server
+	addr   string
+	tls    bool
+	logger *zap.Logger
+}
+
+// NewGRPC creates a gRPC server listening on addr. With TLS enabled it serves the
+// certificate and key files of tls; certificates obtained via ACME are only
+// available to the HTTP server, so autocert is rejected.
+func NewGRPC(addr string, tls TLSConfig, logger *zap.Logger, opts ...grpc.ServerOption) (*GRPCServer, error) {
+	if tls.Enabled {
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			return nil, errors.New("gRPC over TLS requires a certificate and key file; autocert is not supported")
+		}
+		creds, err := credentials.NewServerTLSFromFile(tls.CertFile, tls.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	return &GRPCServer{
+		server: grpc.NewServer(opts...),
+		addr:   addr,
+		tls:    tls.Enabled,
+		logger: logger,
+	}, nil
+}
+
+// RegisterService registers a service and its implementation.
+func (s *GRPCServer) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// Start listens on the server's address and serves until Shutdown or Close, after
+// which it returns nil.
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	s.logger.Info("starting gRPC server", zap.String("address", s.addr), zap.Bool("tls", s.tls))
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight calls to finish.
+// If ctx expires first, the remaining calls are cancelled and ctx's error is
+// returned; open streams otherwise keep a graceful shutdown waiting.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// Close closes all listeners and connections immediately.
+func (s *GRPCServer) Close() error {
+	s.server.Stop()
+	return nil
+}
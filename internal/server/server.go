@@ -0,0 +1,137 @@
+// Package server starts the Echo API over plain HTTP or over TLS, with
+// certificates from files or obtained automatically via ACME (autocert), and an
+// optional listener that redirects HTTP to HTTPS. GRPCServer serves the gRPC API
+// on a listener of its own.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig configures HTTPS serving.
+type TLSConfig struct {
+	Enabled          bool     // Serve HTTPS instead of HTTP
+	CertFile         string   // PEM certificate (chain) file
+	KeyFile          string   // PEM private key file
+	AutocertDomains  []string // Obtain certificates for these domains via ACME instead of CertFile/KeyFile
+	AutocertCacheDir string   // Directory where ACME certificates are cached
+	HTTP2            bool     // Negotiate HTTP/2 over TLS
+	RedirectAddress  string   // Address of a plain HTTP listener redirecting to HTTPS (e.g. ":80"); "" disables it
+}
+
+// Server serves an Echo instance.
+type Server struct {
+	e        *echo.Echo
+	addr     string
+	tls      TLSConfig
+	redirect *http.Server // nil unless TLS and a redirect address are configured
+	logger   *zap.Logger
+}
+
+// New creates a server for e listening on addr.
+func New(e *echo.Echo, addr string, tls TLSConfig, logger *zap.Logger) *Server {
+	s := &Server{e: e, addr: addr, tls: tls, logger: logger}
+	if !tls.Enabled {
+		return s
+	}
+
+	e.DisableHTTP2 = !tls.HTTP2
+	if len(tls.AutocertDomains) > 0 {
+		e.AutoTLSManager.HostPolicy = autocert.HostWhitelist(tls.AutocertDomains...)
+		if tls.AutocertCacheDir != "" {
+			e.AutoTLSManager.Cache = autocert.DirCache(tls.AutocertCacheDir)
+		}
+	}
+
+	if tls.RedirectAddress != "" {
+		var handler http.Handler = http.HandlerFunc(s.redirectToHTTPS)
+		if len(tls.AutocertDomains) > 0 {
+			// Also answer ACME HTTP-01 challenges on the plain listener
+			handler = e.AutoTLSManager.HTTPHandler(handler)
+		}
+		s.redirect = &http.Server{
+			Addr:              tls.RedirectAddress,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+	return s
+}
+
+// Start serves the API and, if configured, the redirect listener. It blocks until
+// the API server stops and returns http.ErrServerClosed after Shutdown or Close.
+func (s *Server) Start() error {
+	if !s.tls.Enabled {
+		s.logger.Info("starting HTTP server", zap.String("address", s.addr))
+		return s.e.Start(s.addr)
+	}
+
+	if s.redirect != nil {
+		go func() {
+			s.logger.Info("starting HTTP to HTTPS redirect listener", zap.String("address", s.redirect.Addr))
+			if err := s.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("HTTP to HTTPS redirect listener failed", zap.Error(err))
+			}
+		}()
+	}
+
+	s.logger.Info("starting HTTPS server",
+		zap.String("address", s.addr),
+		zap.Bool("http2", s.tls.HTTP2),
+		zap.Bool("autocert", len(s.tls.AutocertDomains) > 0),
+	)
+	if len(s.tls.AutocertDomains) > 0 {
+		return s.e.StartAutoTLS(s.addr)
+	}
+	return s.e.StartTLS(s.addr, s.tls.CertFile, s.tls.KeyFile)
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish or for ctx to expire.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.redirect != nil {
+		if err := s.redirect.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down redirect listener: %w", err)
+		}
+	}
+	return s.e.Shutdown(ctx)
+}
+
+// Close closes all listeners and any remaining connections immediately.
+func (s *Server) Close() error {
+	if s.redirect != nil {
+		if err := s.redirect.Close(); err != nil {
+			return fmt.Errorf("failed to close redirect listener: %w", err)
+		}
+	}
+	return s.e.Close()
+}
+
+// redirectToHTTPS permanently redirects a request to the same URL on the HTTPS
+// listener. The port is omitted when the API listens on 443.
+func (s *Server) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 literal
+		}
+	}
+	if _, port, err := net.SplitHostPort(s.addr); err == nil && port != "443" {
+		// Strip the brackets of a bare IPv6 literal; JoinHostPort adds them back
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusPermanentRedirect)
+}
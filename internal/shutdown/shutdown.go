@@ -0,0 +1,60 @@
+// Package shutdown runs the service's shutdown sequence as ordered stages with
+// independent timeouts, so a stage that hangs (e.g. stopping the eFLINT server)
+// cannot prevent the remaining stages from running.
+package shutdown
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultStageTimeout is used for stages without a timeout.
+const DefaultStageTimeout = 10 * time.Second
+
+// Stage is a single step of the shutdown sequence.
+type Stage struct {
+	Name    string                          // Name used in log lines
+	Timeout time.Duration                   // Maximum time the stage may take (default: DefaultStageTimeout)
+	Run     func(ctx context.Context) error // Performs the stage; ctx expires after Timeout
+}
+
+// Run runs the stages in order. Each stage gets its own timeout; a stage that has
+// not returned when its timeout expires is abandoned (it keeps running in the
+// background) and the next stage starts. The outcome of every stage is logged.
+func Run(stages []Stage, logger *zap.Logger) {
+	for _, stage := range stages {
+		runStage(stage, logger.With(zap.String("stage", stage.Name)))
+	}
+}
+
+// runStage runs a single stage and logs its outcome.
+func runStage(stage Stage, logger *zap.Logger) {
+	timeout := stage.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStageTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	logger.Info("shutdown stage started", zap.Duration("timeout", timeout))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- stage.Run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logger.Error("shutdown stage failed", zap.Duration("duration", time.Since(start)), zap.Error(err))
+			return
+		}
+		logger.Info("shutdown stage completed", zap.Duration("duration", time.Since(start)))
+	case <-ctx.Done():
+		logger.Error("shutdown stage timed out; continuing with the next stage", zap.Duration("timeout", timeout))
+	}
+}
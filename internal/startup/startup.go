@@ -0,0 +1,73 @@
+// Package startup runs the checks that follow auto-starting the reasoner at boot,
+// logging their outcome. A failing check is a warning unless the configuration
+// makes it fatal.
+package startup
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+// ModelSelfTest verifies that the freshly started model answers a facts query.
+// Problems are logged as warnings, or are fatal if failOnEmpty is set.
+func ModelSelfTest(r reasoner.SelfTester, failOnEmpty bool, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.SelfTest(ctx)
+	if err == nil {
+		logger.Info("eFLINT model self-test passed", zap.Int("facts", count))
+		return
+	}
+
+	if failOnEmpty {
+		logger.Fatal("eFLINT model self-test failed", zap.Error(err))
+	}
+	logger.Warn("eFLINT model self-test failed; check that the correct model is loaded", zap.Error(err))
+}
+
+// RequestActCheck verifies that the freshly started model declares the act
+// request validations are checked with (eflint.request_act). A mismatch is logged
+// as an error, or is fatal if failOnMismatch is set.
+func RequestActCheck(r reasoner.RequestActChecker, failOnMismatch bool, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.CheckRequestAct(ctx)
+	if err == nil {
+		logger.Info("eFLINT model declares the request act")
+		return
+	}
+
+	if failOnMismatch {
+		logger.Fatal("eFLINT model request act check failed", zap.Error(err))
+	}
+	logger.Error("eFLINT model request act check failed; every request validation may be denied, check eflint.request_act", zap.Error(err))
+}
+
+// Warmup fetches the facts once, so that the first request does not pay for it
+// and, with eflint.facts_cache, finds them cached. It gives up after timeout.
+// A failure is logged as a warning, or is fatal if strict is set.
+func Warmup(r reasoner.FactsProvider, timeout time.Duration, strict bool, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	facts, err := r.FetchFacts(ctx)
+	if err == nil {
+		logger.Info("eFLINT warmup loaded facts",
+			zap.Int("facts", len(facts)),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return
+	}
+
+	if strict {
+		logger.Fatal("eFLINT warmup failed", zap.Error(err))
+	}
+	logger.Warn("eFLINT warmup failed; the first requests fetch the facts instead", zap.Error(err))
+}
@@ -0,0 +1,109 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+)
+
+// selfTester answers SelfTest with a fixed result.
+type selfTester struct {
+	count int
+	err   error
+}
+
+func (s selfTester) SelfTest(context.Context) (int, error) {
+	return s.count, s.err
+}
+
+func TestModelSelfTest(t *testing.T) {
+	tests := []struct {
+		name   string
+		tester selfTester
+		level  zapcore.Level
+	}{
+		{"passed", selfTester{count: 12}, zapcore.InfoLevel},
+		{"failed", selfTester{err: errors.New("model has no facts")}, zapcore.WarnLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			ModelSelfTest(tt.tester, false, zap.New(core))
+
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].Level != tt.level {
+				t.Fatalf("logged %+v, want one %s entry", entries, tt.level)
+			}
+		})
+	}
+}
+
+// requestActChecker answers CheckRequestAct with a fixed error.
+type requestActChecker struct {
+	err error
+}
+
+func (c requestActChecker) CheckRequestAct(context.Context) error {
+	return c.err
+}
+
+func TestRequestActCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker requestActChecker
+		level   zapcore.Level
+	}{
+		{"declared", requestActChecker{}, zapcore.InfoLevel},
+		{"mismatch", requestActChecker{err: errors.New("submit-request is not declared")}, zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			RequestActCheck(tt.checker, false, zap.New(core))
+
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].Level != tt.level {
+				t.Fatalf("logged %+v, want one %s entry", entries, tt.level)
+			}
+		})
+	}
+}
+
+// factsProvider answers FetchFacts with a fixed result.
+type factsProvider struct {
+	facts []reasoner.Fact
+	err   error
+}
+
+func (p factsProvider) FetchFacts(context.Context) ([]reasoner.Fact, error) {
+	return p.facts, p.err
+}
+
+func TestWarmup(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider factsProvider
+		level    zapcore.Level
+	}{
+		{"loaded", factsProvider{facts: []reasoner.Fact{{FactType: "organization"}}}, zapcore.InfoLevel},
+		{"failed", factsProvider{err: errors.New("connection refused")}, zapcore.WarnLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			Warmup(tt.provider, time.Second, false, zap.New(core))
+
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].Level != tt.level {
+				t.Fatalf("logged %+v, want one %s entry", entries, tt.level)
+			}
+		})
+	}
+}
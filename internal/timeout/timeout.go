@@ -0,0 +1,110 @@
+// Package timeout provides an Echo middleware that lets callers shorten the
+// deadline of a single request via a query parameter or header.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// QueryParam is the query parameter carrying a per-request timeout.
+	QueryParam = "timeout"
+	// Header is the header carrying a per-request timeout.
+	Header = "X-Timeout"
+)
+
+// ErrInvalidTimeout is returned for malformed or non-positive timeout values.
+var ErrInvalidTimeout = errors.New("invalid timeout")
+
+// Config configures the per-request timeout middleware.
+type Config struct {
+	Max   time.Duration // Requested timeouts above this are clamped; 0 means no upper bound
+	Limit *Limit        // If set, replaces Max, so the bound can change at runtime
+}
+
+// Limit is an upper bound for per-request timeouts that can be changed while
+// requests are served, e.g. on a config reload.
+type Limit struct {
+	max atomic.Int64
+}
+
+// NewLimit returns a limit of max; 0 means no upper bound.
+func NewLimit(max time.Duration) *Limit {
+	l := &Limit{}
+	l.Set(max)
+	return l
+}
+
+// Set changes the limit for requests that start afterwards.
+func (l *Limit) Set(max time.Duration) {
+	l.max.Store(int64(max))
+}
+
+// Get returns the current limit.
+func (l *Limit) Get() time.Duration {
+	return time.Duration(l.max.Load())
+}
+
+// Middleware returns a middleware that reads an optional timeout from the
+// `timeout` query parameter or the `X-Timeout` header and derives a request
+// context with that deadline. The query parameter takes precedence.
+//
+// Values are Go durations ("500ms", "5s") or a plain number of seconds ("5").
+// Requests without a timeout keep their context unchanged, so the global
+// connection timeout applies. Malformed or non-positive values are rejected
+// with 400 Bad Request.
+func Middleware(config Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw := c.QueryParam(QueryParam)
+			if raw == "" {
+				raw = c.Request().Header.Get(Header)
+			}
+			if raw == "" {
+				return next(c)
+			}
+
+			d, err := Parse(raw)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
+			max := config.Max
+			if config.Limit != nil {
+				max = config.Limit.Get()
+			}
+			if max > 0 && d > max {
+				d = max
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// Parse parses a timeout given as a Go duration or a plain number of seconds.
+func Parse(raw string) (time.Duration, error) {
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		secs, convErr := strconv.ParseFloat(raw, 64)
+		if convErr != nil {
+			return 0, fmt.Errorf("%w %q: expected a duration such as \"5s\" or a number of seconds", ErrInvalidTimeout, raw)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%w %q: must be positive", ErrInvalidTimeout, raw)
+	}
+	return d, nil
+}
@@ -4,22 +4,36 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/apperr"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/auth"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/compress"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/config"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/eflint"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/jsoncase"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/policyenforcer"
 	"github.com/nielsarts/dynamos-policy-enforcer/internal/reasoner"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/requestid"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/server"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/shutdown"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/startup"
+	"github.com/nielsarts/dynamos-policy-enforcer/internal/timeout"
 )
 
 func main() {
@@ -30,7 +44,7 @@ func main() {
 	flag.Parse()
 
 	// Initialize logger
-	logger, err := initLogger()
+	logger, logLevel, err := initLogger()
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -39,22 +53,90 @@ func main() {
 
 	// Load configuration
 	cfg, err := config.Load(*configPath)
+	if errors.Is(err, config.ErrInvalidConfig) {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
 	if err != nil {
 		logger.Warn("failed to load config, using defaults", zap.Error(err))
 		cfg = &config.Config{
+			Server: config.ServerConfig{
+				BindAddress:       "0.0.0.0",
+				BodyLimit:         "10M",
+				MaxRequestTimeout: 60 * time.Second,
+				Compression: config.CompressionConfig{
+					Enabled:   true,
+					Level:     -1,
+					MinLength: compress.DefaultMinLength,
+				},
+			},
 			EFlint: config.EFlintConfig{
 				ServerPath: "eflint-server",
 				ModelPath:  "eflint/dynamos-agreement.eflint",
 				Timeout:    60 * time.Second,
+				MinPort:    eflint.DefaultMinPort,
+				MaxPort:    eflint.DefaultMaxPort,
+
+				MaxResponseSize: eflint.DefaultMaxResponseSize,
+				HistorySize:     eflint.DefaultHistorySize,
+				ServerLogSize:   eflint.DefaultServerLogSize,
+				KillTimeout:     eflint.DefaultKillTimeout,
+				StateAPIEnabled: true,
+				StateFacts:      true,
+				StateDir:        "eflint-states",
 			},
+			Async: config.AsyncConfig{
+				Enabled:   false,
+				Workers:   4,
+				QueueSize: 100,
+				TTL:       10 * time.Minute,
+				Timeout:   5 * time.Minute,
+			},
+		}
+	}
+
+	// The logger is built before the config is loaded, so apply its level now
+	if cfg.Logging.Level != "" {
+		level, err := config.ParseLevel(cfg.Logging.Level)
+		if err != nil {
+			logger.Warn("invalid log level, keeping the default", zap.Error(err))
+		} else {
+			logLevel.SetLevel(level)
 		}
 	}
 
 	// Create Echo instance
 	e := echo.New()
 	e.HideBanner = true
+
+	// Render errors returned by handlers (apperr.Error, echo.HTTPError, others as 500) uniformly
+	e.HTTPErrorHandler = apperr.HTTPErrorHandler(logger)
+
+	// Tag every request with an X-Request-ID that is echoed back and carried by downstream logs
+	e.Use(requestid.Middleware(logger))
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	if cfg.Server.BodyLimit != "" {
+		e.Use(middleware.BodyLimit(cfg.Server.BodyLimit))
+	}
+
+	// gzip large responses (facts, state graphs) for clients that accept it
+	if cfg.Server.Compression.Enabled {
+		e.Use(compress.Middleware(compress.Config{
+			Level:     cfg.Server.Compression.Level,
+			MinLength: cfg.Server.Compression.MinLength,
+		}))
+	}
+
+	// Let callers cap the wait per request via ?timeout= or the X-Timeout header
+	// (bound reloadable with server.max_request_timeout)
+	requestTimeout := timeout.NewLimit(cfg.Server.MaxRequestTimeout)
+	e.Use(timeout.Middleware(timeout.Config{Limit: requestTimeout}))
+
+	// Serve camelCase JSON keys to clients asking for them (Accept: application/json; case=camel or ?case=camel)
+	// Only the API's own field names are converted, never keys of embedded data
+	jsonFields := jsoncase.FieldsOf(append(append(policyenforcer.APITypes(), eflint.APITypes()...),
+		cfg, config.ReloadResult{})...).Add("http_status")
+	e.Use(jsoncase.Middleware(jsonFields))
 	e.Use(middleware.CORS())
 
 	// Health check endpoint
@@ -63,31 +145,138 @@ func main() {
 	})
 
 	// Initialize eFLINT Manager
+	excludedPorts, err := eflint.ParsePortRanges(cfg.EFlint.ExcludedPorts)
+	if err != nil {
+		logger.Fatal("invalid eflint.excluded_ports", zap.Error(err))
+	}
 	managerConfig := &eflint.ManagerConfig{
 		EflintServerPath:  cfg.EFlint.ServerPath,
-		MinPort:           1025,
-		MaxPort:           65535,
+		ServerArgs:        cfg.EFlint.ServerArgs,
+		MinPort:           cfg.EFlint.MinPort,
+		MaxPort:           cfg.EFlint.MaxPort,
+		ExcludedPorts:     excludedPorts,
+		ModelURLHosts:     cfg.EFlint.ModelURLHosts,
 		StartupDelay:      3 * time.Second,
 		ConnectionTimeout: cfg.EFlint.Timeout,
+		DialTimeout:       cfg.EFlint.DialTimeout,
+		CommandTimeout:    cfg.EFlint.CommandTimeout,
+		MaxResponseSize:   cfg.EFlint.MaxResponseSize,
+		PoolSize:          cfg.EFlint.PoolSize,
+		PoolMaxOpen:       cfg.EFlint.PoolMaxOpen,
+		PoolIdleTimeout:   cfg.EFlint.PoolIdleTimeout,
+		HistorySize:       cfg.EFlint.HistorySize,
+		RedactPhrases:     cfg.EFlint.RedactPhrases,
+		ServerLogSize:     cfg.EFlint.ServerLogSize,
+		KillTimeout:       cfg.EFlint.KillTimeout,
+		PIDFile:           cfg.EFlint.PIDFile,
+		ReadyTimeout:      cfg.EFlint.ReadyTimeout,
+		ReadOnly:          cfg.EFlint.ReadOnly,
+		ReadOnlyCommands:  cfg.EFlint.ReadOnlyCommands,
 	}
 	manager := eflint.NewManager(managerConfig, logger)
 
+	// Kill eflint-server processes left behind by a previous run that did not stop them
+	if killed, err := manager.CleanupOrphans(); err != nil {
+		logger.Warn("failed to clean up orphaned eFLINT processes", zap.Error(err))
+	} else if killed > 0 {
+		logger.Warn("killed orphaned eFLINT processes", zap.Int("count", killed))
+	}
+
+	// Readiness requires the eFLINT server to actually answer, not just be alive, and
+	// stays off after a (re)start until the new model answers (eflint.ready_timeout)
+	e.GET("/health/ready", func(c echo.Context) error {
+		if err := manager.Ready(c.Request().Context()); err != nil {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "not ready", "error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "ready"})
+	})
+
 	// Initialize StateManager for checkpointing (POC)
-	stateManager := eflint.NewStateManager(manager, "eflint-states", logger)
+	var stateManager *eflint.StateManager
+	if cfg.EFlint.StateAPIEnabled {
+		stateStore, err := eflint.NewStateStore(eflint.StateStoreConfig{
+			Type: cfg.EFlint.StateStore,
+			Dir:  cfg.EFlint.StateDir,
+			S3: eflint.S3Config{
+				Endpoint:        cfg.EFlint.StateS3.Endpoint,
+				Region:          cfg.EFlint.StateS3.Region,
+				Bucket:          cfg.EFlint.StateS3.Bucket,
+				Prefix:          cfg.EFlint.StateS3.Prefix,
+				AccessKeyID:     cfg.EFlint.StateS3.AccessKeyID,
+				SecretAccessKey: cfg.EFlint.StateS3.SecretAccessKey,
+				SessionToken:    cfg.EFlint.StateS3.SessionToken,
+				Timeout:         cfg.EFlint.StateS3.Timeout,
+			},
+		})
+		if err != nil {
+			logger.Fatal("failed to initialize eFLINT state store", zap.Error(err))
+		}
+		stateManager = eflint.NewStateManager(manager, stateStore, logger)
+	}
+
+	// Require a JWT bearer token on the API groups if configured; /health stays open
+	var apiMiddleware []echo.MiddlewareFunc
+	var authenticator *auth.Authenticator // nil unless auth is enabled; also used by the gRPC server
+	if cfg.Auth.Enabled {
+		authenticator, err = auth.NewAuthenticator(auth.Config{
+			Secret:         cfg.Auth.Secret,
+			JWKSURL:        cfg.Auth.JWKSURL,
+			Issuer:         cfg.Auth.Issuer,
+			Audience:       cfg.Auth.Audience,
+			RequiredClaims: cfg.Auth.RequiredClaims,
+			Leeway:         cfg.Auth.Leeway,
+			JWKSRefresh:    cfg.Auth.JWKSRefresh,
+		}, logger)
+		if err != nil {
+			logger.Fatal("failed to initialize authentication", zap.Error(err))
+		}
+		apiMiddleware = append(apiMiddleware, authenticator.Middleware())
+		logger.Info("JWT authentication enabled")
+	}
+
+	// Debugging endpoints expose internals, so each must be enabled explicitly
+	debugGroup := e.Group("/debug", apiMiddleware...)
+
+	// Effective configuration with secrets redacted, for debugging deployments
+	if cfg.Debug.ConfigEndpoint {
+		debugGroup.GET("/config", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, cfg.Redacted())
+		})
+		logger.Warn("debug config endpoint enabled", zap.String("path", "/debug/config"))
+	}
+
+	// Runtime metrics (e.g. clause cache hits and misses) in expvar format
+	if cfg.Debug.Metrics {
+		debugGroup.GET("/vars", echo.WrapHandler(expvar.Handler()))
+		logger.Warn("debug metrics endpoint enabled", zap.String("path", "/debug/vars"))
+	}
+
+	// Runtime profiles for performance investigation (go tool pprof)
+	if cfg.Debug.Pprof {
+		debugGroup.GET("/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+		debugGroup.GET("/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+		debugGroup.GET("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+		debugGroup.POST("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+		debugGroup.GET("/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+		debugGroup.GET("/pprof/*", echo.WrapHandler(http.HandlerFunc(pprof.Index))) // Index and named profiles (heap, goroutine, ...)
+		logger.Warn("pprof endpoints enabled", zap.String("path", "/debug/pprof/"))
+	}
 
 	// -----------------------------------------------------------------------------
 	// eFLINT API Group - Low-level eFLINT server management
 	// These endpoints provide direct access to the eFLINT reasoner
 	// -----------------------------------------------------------------------------
-	eflintGroup := e.Group("/eflint")
+	eflintGroup := e.Group("/eflint", apiMiddleware...)
 
 	// Instance management API
 	instanceAPIHandler := eflint.NewInstanceAPIHandler(manager, logger)
 	instanceAPIHandler.RegisterRoutes(eflintGroup)
 
 	// State management API (POC)
-	stateAPIHandler := eflint.NewStateAPIHandler(stateManager, logger)
-	stateAPIHandler.RegisterRoutes(eflintGroup)
+	if stateManager != nil {
+		stateAPIHandler := eflint.NewStateAPIHandler(stateManager, logger)
+		stateAPIHandler.RegisterRoutes(eflintGroup)
+	}
 
 	// -----------------------------------------------------------------------------
 	// Policy Enforcer API Group - High-level policy enforcement
@@ -95,43 +284,196 @@ func main() {
 	// In the future, this could work with Symboleo, JSON-based agreements, etc.
 	// -----------------------------------------------------------------------------
 
-	// Create the eFLINT reasoner (implements the Reasoner interface)
-	eflintReasoner := reasoner.NewEflintReasoner(manager, logger)
+	// Checkpoints record their facts (for /policy-enforcer/changes-since) only if enabled
+	var checkpoints *eflint.StateManager
+	if cfg.EFlint.StateFacts {
+		checkpoints = stateManager
+	}
+
+	// Create the configured reasoner (eFLINT by default; implements the Reasoner interface)
+	policyReasoner, err := reasoner.New(reasoner.Config{
+		Type: cfg.Reasoner.Type,
+		Eflint: reasoner.EflintConfig{
+			CircuitBreaker: reasoner.CircuitBreakerConfig{
+				FailureThreshold: cfg.Reasoner.CircuitBreaker.FailureThreshold,
+				Cooldown:         cfg.Reasoner.CircuitBreaker.Cooldown,
+			},
+			FactsCommand: cfg.EFlint.FactsCommand,
+			FactsKey:     cfg.EFlint.FactsKey,
+			CacheFacts:   cfg.EFlint.FactsCache,
+			KnownActs:    knownActs(cfg.EFlint.KnownActs),
+			ClauseTypes:  clauseTypes(cfg.EFlint.ClauseTypes),
+			RequestAct:   reasoner.RequestAct(cfg.EFlint.RequestAct),
+			Checkpoints:  checkpoints,
+		},
+		Symboleo: reasoner.SymboleoConfig{
+			Endpoint: cfg.Reasoner.Symboleo.Endpoint,
+			Timeout:  cfg.Reasoner.Symboleo.Timeout,
+		},
+	}, manager, logger)
+	if err != nil {
+		logger.Fatal("failed to create reasoner", zap.Error(err))
+	}
+	logger.Info("reasoner initialized", zap.String("reasoner", policyReasoner.Name()))
 
 	// Create the policy enforcer (uses the Reasoner interface)
-	enforcer := policyenforcer.NewEnforcer(eflintReasoner, logger)
+	enforcer := policyenforcer.NewEnforcer(policyReasoner, &policyenforcer.EnforcerConfig{
+		Cache: policyenforcer.CacheConfig{
+			Size: cfg.Cache.Size,
+			TTL:  cfg.Cache.TTL,
+		},
+		Concurrency: policyenforcer.ConcurrencyConfig{
+			MaxInFlight:  cfg.Concurrency.MaxInFlight,
+			QueueSize:    cfg.Concurrency.QueueSize,
+			QueueTimeout: cfg.Concurrency.QueueTimeout,
+		},
+	}, logger)
 
 	// Register HTTP handlers for policy enforcer
-	policyEnforcerGroup := e.Group("/policy-enforcer")
-	policyEnforcerHandler := policyenforcer.NewHTTPHandler(enforcer, logger)
+	policyEnforcerGroup := e.Group("/policy-enforcer", apiMiddleware...)
+	policyEnforcerHandler := policyenforcer.NewHTTPHandler(enforcer, &policyenforcer.HTTPHandlerConfig{
+		RateLimit: rateLimitConfig(cfg.RateLimit),
+		OrgScope:  orgScopeConfig(cfg.OrgScope),
+		Async: policyenforcer.AsyncConfig{
+			Enabled:   cfg.Async.Enabled,
+			Workers:   cfg.Async.Workers,
+			QueueSize: cfg.Async.QueueSize,
+			TTL:       cfg.Async.TTL,
+			Timeout:   cfg.Async.Timeout,
+		},
+		DefaultOrganization: cfg.Policy.DefaultOrganization,
+		DefaultRequester:    cfg.Policy.DefaultRequester,
+		ExecuteActs:         cfg.Policy.ExecuteActs,
+		StrictJSON:          cfg.Policy.StrictJSON,
+		DenialStatus:        policyenforcer.DenialStatus(cfg.Policy.DenialStatus),
+	}, logger)
 	policyEnforcerHandler.RegisterRoutes(policyEnforcerGroup)
 
+	// -----------------------------------------------------------------------------
+	// Config Reload - SIGHUP or POST /admin/reload-config re-reads the config file
+	// and applies these settings; other changes are logged as requiring a restart
+	// -----------------------------------------------------------------------------
+	reloader := config.NewReloader(*configPath, cfg)
+	reloader.Handle("logging.level", func(next *config.Config) error {
+		level, err := config.ParseLevel(next.Logging.Level)
+		if err != nil {
+			return err
+		}
+		logLevel.SetLevel(level)
+		return nil
+	})
+	reloader.Handle("server.max_request_timeout", func(next *config.Config) error {
+		requestTimeout.Set(next.Server.MaxRequestTimeout)
+		return nil
+	})
+	reloader.Handle("rate_limit", func(next *config.Config) error {
+		return policyEnforcerHandler.SetRateLimit(rateLimitConfig(next.RateLimit))
+	})
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			reloadConfig(reloader, logger)
+		}
+	}()
+
+	// Admin endpoints change the running service, so they must be enabled explicitly
+	if cfg.Admin.Enabled {
+		adminGroup := e.Group("/admin", apiMiddleware...)
+		adminGroup.POST("/reload-config", func(c echo.Context) error {
+			result, err := reloadConfig(reloader, logger)
+			if err != nil {
+				return apperr.Unprocessable("config file is invalid; nothing was applied: %v", err)
+			}
+			return c.JSON(http.StatusOK, result)
+		})
+
+		// Log level at runtime, e.g. debug logging during an incident; until the next
+		// restart or config reload that changes logging.level
+		adminGroup.GET("/log-level", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, logLevelBody{Level: logLevel.Level().String()})
+		})
+		adminGroup.PUT("/log-level", func(c echo.Context) error {
+			var body logLevelBody
+			if err := c.Bind(&body); err != nil {
+				return apperr.BadRequest("invalid request body")
+			}
+			level, err := config.ParseLevel(body.Level)
+			if err != nil || body.Level == "" {
+				return apperr.BadRequest("level must be debug, info, warn or error").WithFields("level")
+			}
+			// Logged at the more verbose of both levels, so the change itself is recorded
+			previous := logLevel.Level()
+			logLevel.SetLevel(min(previous, level))
+			logger.Warn("log level changed", zap.Stringer("from", previous), zap.Stringer("to", level))
+			logLevel.SetLevel(level)
+			return c.JSON(http.StatusOK, logLevelBody{Level: level.String()})
+		})
+	}
+
 	// -----------------------------------------------------------------------------
 	// Auto-start eFLINT if configured
 	// -----------------------------------------------------------------------------
-	if *autoStart && cfg.EFlint.ModelPath != "" {
+	if models := cfg.EFlint.ModelLocations(); *autoStart && len(models) == 0 {
+		logger.Info("no eFLINT model configured; start one via POST /eflint/start")
+	} else if *autoStart {
+		// A configured model that is missing is a deployment error, not "no model"
+		if err := eflint.CheckModelLocations(models); err != nil {
+			logger.Fatal("configured eFLINT model not found; check eflint.model_path and eflint.model_paths", zap.Error(err))
+		}
 		logger.Info("auto-starting eFLINT server",
-			zap.String("model", cfg.EFlint.ModelPath),
+			zap.Strings("models", models),
 		)
-		if err := manager.Start(cfg.EFlint.ModelPath); err != nil {
+		if err := manager.StartModels(models); errors.Is(err, eflint.ErrServerBinaryNotFound) {
+			// A missing binary cannot be fixed via the API, so fail loudly at boot
+			logger.Fatal("eFLINT server binary not found; check eflint.server_path", zap.Error(err))
+		} else if err != nil {
 			logger.Error("failed to auto-start eFLINT server", zap.Error(err))
 			// Continue anyway - the server can be started manually via API
+		} else {
+			if cfg.EFlint.SelfTest {
+				if st, ok := policyReasoner.(reasoner.SelfTester); ok {
+					startup.ModelSelfTest(st, cfg.EFlint.FailOnEmptyModel, logger)
+				}
+				if checker, ok := policyReasoner.(reasoner.RequestActChecker); ok {
+					startup.RequestActCheck(checker, cfg.EFlint.FailOnEmptyModel, logger)
+				}
+			}
+			if cfg.EFlint.Warmup {
+				if fp, ok := policyReasoner.(reasoner.FactsProvider); ok {
+					startup.Warmup(fp, cfg.EFlint.WarmupTimeout, cfg.EFlint.WarmupStrict, logger)
+				}
+			}
 		}
 	}
 
 	// -----------------------------------------------------------------------------
 	// Start HTTP Server
 	// -----------------------------------------------------------------------------
+	srv := server.New(e, cfg.Server.Addr(strconv.Itoa(*httpPort)), server.TLSConfig{
+		Enabled:          cfg.Server.TLS.Enabled,
+		CertFile:         cfg.Server.TLS.CertFile,
+		KeyFile:          cfg.Server.TLS.KeyFile,
+		AutocertDomains:  cfg.Server.TLS.AutocertDomains,
+		AutocertCacheDir: cfg.Server.TLS.AutocertCacheDir,
+		HTTP2:            cfg.Server.TLS.HTTP2,
+		RedirectAddress:  cfg.Server.TLS.RedirectAddress,
+	}, logger)
 	go func() {
-		addr := fmt.Sprintf(":%d", *httpPort)
-		logger.Info("starting HTTP server",
-			zap.String("address", addr),
-		)
-		if err := e.Start(addr); err != nil && err != http.ErrServerClosed {
+		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("failed to start HTTP server", zap.Error(err))
 		}
 	}()
 
+	// -----------------------------------------------------------------------------
+	// Start gRPC Server (optional) - the PolicyEnforcer service on its own port
+	// -----------------------------------------------------------------------------
+	var grpcSrv *server.GRPCServer
+	if cfg.GRPC.Enabled {
+		grpcSrv = startGRPCServer(cfg, enforcer, authenticator, logger)
+	}
+
 	// -----------------------------------------------------------------------------
 	// Graceful Shutdown
 	// -----------------------------------------------------------------------------
@@ -141,28 +483,166 @@ func main() {
 
 	logger.Info("shutting down...")
 
-	// Stop eFLINT server
-	if manager.IsRunning() {
-		if err := manager.Stop(); err != nil {
-			logger.Error("failed to stop eFLINT server", zap.Error(err))
+	// Each stage has its own timeout, so a stuck stage cannot block the ones after it
+	shutdown.Run([]shutdown.Stage{
+		{
+			// Refuse new requests and let in-flight ones finish before eFLINT goes away
+			Name:    "drain-requests",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run:     srv.Shutdown,
+		},
+		{
+			// Let in-flight gRPC calls finish as well; open streams are cut off at the timeout
+			Name:    "drain-grpc",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run: func(ctx context.Context) error {
+				if grpcSrv == nil {
+					return nil
+				}
+				return grpcSrv.Shutdown(ctx)
+			},
+		},
+		{
+			// Finish queued asynchronous validations while the reasoner is still up
+			Name:    "drain-validation-jobs",
+			Timeout: cfg.Shutdown.DrainTimeout,
+			Run:     policyEnforcerHandler.Shutdown,
+		},
+		{
+			Name:    "stop-eflint",
+			Timeout: cfg.Shutdown.EflintTimeout,
+			Run: func(context.Context) error {
+				if !manager.IsRunning() {
+					return nil
+				}
+				return manager.Stop()
+			},
+		},
+		{
+			// Close whatever the drain left behind (e.g. open log streams)
+			Name:    "shutdown-http",
+			Timeout: cfg.Shutdown.HTTPTimeout,
+			Run: func(context.Context) error {
+				if grpcSrv != nil {
+					grpcSrv.Close()
+				}
+				return srv.Close()
+			},
+		},
+	}, logger)
+
+	logger.Info("shutdown complete")
+}
+
+// logLevelBody is the request and response body of /admin/log-level.
+type logLevelBody struct {
+	Level string `json:"level"` // debug, info, warn or error
+}
+
+// rateLimitConfig converts the configured rate limits for the policy enforcer API.
+func rateLimitConfig(cfg config.RateLimitConfig) policyenforcer.RateLimitConfig {
+	return policyenforcer.RateLimitConfig{
+		Enabled:        cfg.Enabled,
+		RequesterRate:  cfg.RequesterRate,
+		RequesterBurst: cfg.RequesterBurst,
+		GlobalRate:     cfg.GlobalRate,
+		GlobalBurst:    cfg.GlobalBurst,
+	}
+}
+
+// orgScopeConfig converts the configured org scoping for the policy enforcer API.
+func orgScopeConfig(cfg config.OrgScopeConfig) policyenforcer.OrgScopeConfig {
+	return policyenforcer.OrgScopeConfig{
+		Enabled:         cfg.Enabled,
+		PrincipalHeader: cfg.PrincipalHeader,
+		PrincipalClaim:  cfg.PrincipalClaim,
+		Organizations:   cfg.Organizations,
+	}
+}
+
+// startGRPCServer serves the PolicyEnforcer gRPC service in the background. Calls
+// are authenticated like the HTTP API if authenticator is set.
+func startGRPCServer(cfg *config.Config, enforcer *policyenforcer.Enforcer, authenticator *auth.Authenticator, logger *zap.Logger) *server.GRPCServer {
+	var opts []grpc.ServerOption
+	if authenticator != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+		)
+	}
+
+	grpcSrv, err := server.NewGRPC(cfg.Server.Addr(strconv.Itoa(cfg.GRPC.Port)), server.TLSConfig{
+		Enabled:  cfg.Server.TLS.Enabled,
+		CertFile: cfg.Server.TLS.CertFile,
+		KeyFile:  cfg.Server.TLS.KeyFile,
+	}, logger, opts...)
+	if err != nil {
+		logger.Fatal("failed to create gRPC server", zap.Error(err))
+	}
+
+	policyenforcer.NewGRPCHandler(enforcer, &policyenforcer.GRPCHandlerConfig{
+		OrgScope:            orgScopeConfig(cfg.OrgScope),
+		DefaultOrganization: cfg.Policy.DefaultOrganization,
+		DefaultRequester:    cfg.Policy.DefaultRequester,
+	}, logger).Register(grpcSrv)
+
+	go func() {
+		if err := grpcSrv.Start(); err != nil {
+			logger.Fatal("failed to start gRPC server", zap.Error(err))
 		}
+	}()
+	return grpcSrv
+}
+
+// reloadConfig re-reads the config file and logs which changed settings were
+// applied, could not be applied, or take effect only after a restart.
+func reloadConfig(reloader *config.Reloader, logger *zap.Logger) (*config.ReloadResult, error) {
+	result, err := reloader.Reload()
+	if err != nil {
+		logger.Error("config reload failed; keeping the current configuration", zap.Error(err))
+		return nil, err
 	}
 
-	// Shutdown HTTP server
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := e.Shutdown(ctx); err != nil {
-		logger.Error("failed to shutdown HTTP server", zap.Error(err))
+	logger.Info("config reloaded", zap.Strings("applied", result.Applied))
+	for setting, reason := range result.Failed {
+		logger.Error("config reload could not apply setting", zap.String("setting", setting), zap.String("error", reason))
 	}
+	if len(result.RestartRequired) > 0 {
+		logger.Warn("changed settings take effect after a restart", zap.Strings("settings", result.RestartRequired))
+	}
+	return result, nil
+}
 
-	logger.Info("shutdown complete")
+// knownActs converts the configured known acts for the eFLINT reasoner. An unset
+// list (nil) selects reasoner.DefaultKnownActs.
+func knownActs(acts []config.KnownAct) []reasoner.KnownAct {
+	if acts == nil {
+		return nil
+	}
+	converted := make([]reasoner.KnownAct, 0, len(acts))
+	for _, act := range acts {
+		converted = append(converted, reasoner.KnownAct{Type: act.Act, Arguments: act.Arguments})
+	}
+	return converted
+}
+
+// clauseTypes converts the configured clause types for the eFLINT reasoner.
+func clauseTypes(types []config.ClauseType) []reasoner.ClauseType {
+	converted := make([]reasoner.ClauseType, 0, len(types))
+	for _, t := range types {
+		converted = append(converted, reasoner.ClauseType{Name: t.Name, FactType: t.FactType, ValueFactType: t.ValueFactType})
+	}
+	return converted
 }
 
-// initLogger initializes the zap logger.
-func initLogger() (*zap.Logger, error) {
+// initLogger initializes the zap logger. Its level can be changed at runtime
+// through the returned AtomicLevel.
+func initLogger() (*zap.Logger, zap.AtomicLevel, error) {
+	zapConfig := zap.NewProductionConfig()
 	// Check if we're in development mode
 	if os.Getenv("PE_LOGGING_DEVELOPMENT") == "true" {
-		return zap.NewDevelopment()
+		zapConfig = zap.NewDevelopmentConfig()
 	}
-	return zap.NewProduction()
+	logger, err := zapConfig.Build()
+	return logger, zapConfig.Level, err
 }
@@ -0,0 +1,113 @@
+// Package api defines the JSON request and response types of the policy enforcer
+// HTTP API. The server's handlers use these types directly, so clients in other
+// modules (such as pkg/client) can share the exact definitions instead of
+// copying them.
+package api
+
+// -----------------------------------------------------------------------------
+// Shared Types
+// -----------------------------------------------------------------------------
+
+// ErrorResponse is the JSON body of an error response.
+type ErrorResponse struct {
+	Error  string   `json:"error"`            // Human-readable error message
+	Code   string   `json:"code"`             // Stable machine-readable error code
+	Fields []string `json:"fields,omitempty"` // Offending request fields, for validation errors
+}
+
+// Fact is a fact instance as reported by the eFLINT "facts" command.
+type Fact struct {
+	FactType   string         `json:"fact-type"`   // The fact type (e.g., "allowed-archetype")
+	TaggedType string         `json:"tagged-type"` // The fact type with its tag, as reported by eFLINT
+	Arguments  []FactArgument `json:"arguments"`   // Positional arguments of the fact
+}
+
+// FactArgument is a single argument of a Fact. eFLINT values are strings, integers
+// or instances of composite types; Value holds all of them as text, so arguments
+// compare by Value regardless of their kind (see ValueKind). In JSON the value has
+// the type of its kind: a string, an integer or the array of a composite's
+// arguments (see MarshalJSON).
+type FactArgument struct {
+	FactType  string         // The argument's fact type (e.g., "organization")
+	Value     string         // The argument's value (e.g., "VU", "42" or `agreement("VU", 3)`)
+	Kind      ValueKind      // The kind of value; empty for strings
+	Arguments []FactArgument // With KindComposite: the composite's own arguments
+}
+
+// Diagnostic is an error or violation reported by the eFLINT server.
+type Diagnostic struct {
+	Type    string `json:"type"`    // The kind of error or the violated fact type
+	Message string `json:"message"` // Human-readable description
+}
+
+// Duty is a duty that performing an act creates, such as an obligation the
+// requester takes on when a permitted request is submitted.
+type Duty struct {
+	Type      string         `json:"type"`               // The duty type (e.g., "report-results")
+	Holder    string         `json:"holder,omitempty"`   // Who owes the duty: the first argument
+	Claimant  string         `json:"claimant,omitempty"` // To whom it is owed: the second argument
+	Arguments []FactArgument `json:"arguments"`          // Positional arguments of the duty, including holder and claimant
+}
+
+// ErrorCategory classifies an error the reasoner reported while evaluating a query.
+type ErrorCategory string
+
+// Error categories, derived from the type of the eFLINT error.
+const (
+	// ErrorCategoryParse means the query could not be parsed, i.e. the client sent bad input.
+	ErrorCategoryParse ErrorCategory = "parse"
+	// ErrorCategoryType means the query did not type-check against the model, e.g. a
+	// value of the wrong type; this is also caused by client input.
+	ErrorCategoryType ErrorCategory = "type"
+	// ErrorCategoryRuntime means the query was valid, but evaluating it failed.
+	ErrorCategoryRuntime ErrorCategory = "runtime"
+)
+
+// ReasonCode is a stable, machine-readable classification of a validation decision.
+// Unlike the human-readable Reason, codes are part of the API contract: UIs may map
+// them to localized messages, so existing values must never be renamed or removed.
+type ReasonCode string
+
+// Reason codes returned with every validation result.
+const (
+	// ReasonPermitted means the request is permitted by the agreement.
+	ReasonPermitted ReasonCode = "PERMITTED"
+	// ReasonNotPermitted means the agreement does not enable the request.
+	ReasonNotPermitted ReasonCode = "NOT_PERMITTED"
+	// ReasonViolation means the request would violate a duty or invariant of the agreement.
+	ReasonViolation ReasonCode = "VIOLATION"
+	// ReasonResourceUnavailable means a requested archetype or compute provider is not
+	// available at the organization.
+	ReasonResourceUnavailable ReasonCode = "RESOURCE_UNAVAILABLE"
+	// ReasonError means the reasoner reported an error while evaluating the request.
+	ReasonError ReasonCode = "ERROR"
+)
+
+// OrgRequester identifies a requester at an organization.
+type OrgRequester struct {
+	Organization string `json:"organization"` // The organization/steward
+	Requester    string `json:"requester"`    // The user/requester
+}
+
+// AllAllowedClauses contains all allowed clauses for a requester at an organization.
+type AllAllowedClauses struct {
+	RequestTypes     []string `json:"request_types"`     // Allowed request types
+	DataSets         []string `json:"data_sets"`         // Allowed datasets
+	Archetypes       []string `json:"archetypes"`        // Allowed archetypes
+	ComputeProviders []string `json:"compute_providers"` // Allowed compute providers
+}
+
+// OrgPolicy is a portable snapshot of everything an organization has granted,
+// plus the resources it makes available. It can be exported and re-imported.
+type OrgPolicy struct {
+	Organization              string            `json:"organization" validate:"required"` // The organization/steward
+	Grants                    []RequesterGrants `json:"grants"`                           // Clauses granted, per requester
+	AvailableArchetypes       []string          `json:"available_archetypes"`             // Archetypes available at the organization
+	AvailableComputeProviders []string          `json:"available_compute_providers"`      // Compute providers available at the organization
+}
+
+// RequesterGrants lists all clauses granted to a single requester.
+type RequesterGrants struct {
+	Requester string `json:"requester"` // The user/requester receiving the permissions
+	AllAllowedClauses
+}
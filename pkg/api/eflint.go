@@ -0,0 +1,29 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// eFLINT Instance Types
+// -----------------------------------------------------------------------------
+
+// EflintStatusResponse represents the response for status-related endpoints.
+type EflintStatusResponse struct {
+	Running       bool            `json:"running"`                  // Whether the instance is running
+	Port          int             `json:"port,omitempty"`           // The port the instance is listening on
+	ModelLocation string          `json:"model_location,omitempty"` // Path to the loaded model
+	ModelFiles    []string        `json:"model_files,omitempty"`    // Source files merged into the model, in load order
+	StartedAt     *time.Time      `json:"started_at,omitempty"`     // When the instance was last (re)started
+	UptimeSeconds float64         `json:"uptime_seconds,omitempty"` // Seconds since started_at; a short uptime after a long run hints at a crash loop
+	EflintStatus  json.RawMessage `json:"eflint_status,omitempty"`  // Status response from the eFLINT server
+}
+
+// EflintStartRequest represents the request body for starting an instance.
+type EflintStartRequest struct {
+	ModelLocation  string   `json:"model_location,omitempty"`  // Path or http(s) URL of the eFLINT model
+	ModelLocations []string `json:"model_locations,omitempty"` // Model files or directories merged in order, used instead of model_location
+	ModelContent   string   `json:"model_content,omitempty"`   // Inline model source, used instead of model_location
+	Force          bool     `json:"force,omitempty"`           // Force restart if already running
+}
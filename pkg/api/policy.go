@@ -0,0 +1,325 @@
+package api
+
+import "time"
+
+// -----------------------------------------------------------------------------
+// Policy Enforcer Request Types
+// -----------------------------------------------------------------------------
+//
+// Fields tagged `validate:"required"` must be set; the server rejects requests
+// missing them with 400. Organization and requester may be omitted when the
+// server has defaults configured.
+
+// AllowedClausesRequest represents a request to get allowed clauses for a requester.
+type AllowedClausesRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+	Requester    string `query:"requester" json:"requester" validate:"required"`       // The user/requester
+}
+
+// AllowedOfTypeRequest represents a request for the allowed values of a clause type
+// selected by name, including clause types registered by configuration.
+type AllowedOfTypeRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+	Requester    string `query:"requester" json:"requester" validate:"required"`       // The user/requester
+	ClauseType   string `query:"type" json:"type" validate:"required"`                 // The clause type (e.g., "archetype")
+}
+
+// OrganizationRequest represents a request scoped to an organization only.
+type OrganizationRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+}
+
+// RequestersAllowedRequest represents a request for the requesters allowed a clause.
+type RequestersAllowedRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+	ClauseType   string `query:"type" json:"type" validate:"required"`                 // The clause type (e.g., "archetype")
+	Value        string `query:"value" json:"value" validate:"required"`               // The clause value (e.g., "computeToData")
+}
+
+// ClauseAllowedRequest represents a request to check a single clause value.
+type ClauseAllowedRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+	Requester    string `query:"requester" json:"requester" validate:"required"`       // The user/requester
+	ClauseType   string `query:"type" json:"type" validate:"required"`                 // The clause type (e.g., "archetype")
+	Value        string `query:"value" json:"value" validate:"required"`               // The clause value to check
+}
+
+// RevokeClauseRequest represents a request to revoke a single allowed clause.
+type RevokeClauseRequest struct {
+	Organization string `query:"organization" json:"organization" validate:"required"` // The organization/steward
+	Requester    string `query:"requester" json:"requester" validate:"required"`       // The user/requester
+	ClauseType   string `query:"type" json:"type" validate:"required"`                 // The clause type (e.g., "archetype")
+	Value        string `query:"value" json:"value" validate:"required"`               // The clause value to revoke
+}
+
+// BatchAllowedClausesRequest represents a request for the allowed clauses of many requesters.
+type BatchAllowedClausesRequest struct {
+	Pairs []OrgRequester `json:"pairs" validate:"required"` // The (organization, requester) pairs to query
+}
+
+// ValidateRequestParams represents a request to validate if a specific operation is allowed.
+// It is usually sent as JSON; the form tags let simple clients and HTML forms post
+// it as application/x-www-form-urlencoded instead.
+type ValidateRequestParams struct {
+	Organization    string `json:"organization" form:"organization" validate:"required"`         // The data steward organization
+	Requester       string `json:"requester" form:"requester" validate:"required"`               // The user making the request
+	RequestType     string `json:"request_type" form:"request_type" validate:"required"`         // Type of request (e.g., "sqlDataRequest")
+	DataSet         string `json:"data_set" form:"data_set" validate:"required"`                 // The dataset being requested
+	Archetype       string `json:"archetype" form:"archetype" validate:"required"`               // The processing archetype
+	ComputeProvider string `json:"compute_provider" form:"compute_provider" validate:"required"` // Where the computation runs
+}
+
+// SimulateChangeRequest represents a request to validate a request against a
+// hypothetical policy change. The request to validate is given inline.
+type SimulateChangeRequest struct {
+	Phrases []string `json:"phrases" validate:"required"` // Changes to apply, in order (eFLINT phrases, e.g. `+accepted(...).`)
+	ValidateRequestParams
+}
+
+// ChangesSinceRequest represents a request for the allowed clauses changed since a checkpoint.
+type ChangesSinceRequest struct {
+	Checkpoint string `query:"checkpoint" json:"checkpoint" validate:"required"` // Name of a checkpoint of the eFLINT state API
+}
+
+// ExecuteActRequest represents a request to perform an eFLINT act. The arguments
+// are positional string values, e.g. ["VU", "user@example.com"] for register-requester.
+type ExecuteActRequest struct {
+	ActType   string   `json:"act_type" validate:"required"` // The act (e.g., "register-requester")
+	Arguments []string `json:"arguments"`                    // The act's arguments, in order
+}
+
+// FactQuery represents a query for the facts of one type, optionally filtered by
+// argument. Arguments are matched by position, like the arguments of a fact.
+type FactQuery struct {
+	FactType  string               `json:"fact_type" validate:"required"` // The fact type (e.g., "allowed-archetype")
+	Arguments []FactArgumentFilter `json:"arguments,omitempty"`           // Filters on the fact's arguments, in order
+}
+
+// FactArgumentFilter filters facts on one argument. Empty fields match anything.
+type FactArgumentFilter struct {
+	FactType string `json:"fact_type,omitempty"` // The argument's fact type (e.g., "organization")
+	Value    string `json:"value,omitempty"`     // The argument's value (e.g., "VU")
+}
+
+// -----------------------------------------------------------------------------
+// Policy Enforcer Response Types
+// -----------------------------------------------------------------------------
+
+// AllowedClausesResponse represents the response containing allowed clauses.
+type AllowedClausesResponse struct {
+	Organization string   `json:"organization"`   // The organization/steward
+	Requester    string   `json:"requester"`      // The user/requester
+	Type         string   `json:"type,omitempty"` // The clause type, for GET /policy-enforcer/allowed
+	Values       []string `json:"values"`         // List of allowed values
+	// With value= query parameters: whether each given value is allowed. Values
+	// then holds only the given values that are allowed.
+	Checked map[string]bool `json:"checked,omitempty"`
+}
+
+// AllAllowedClausesResponse contains all allowed clauses for a requester at an organization.
+type AllAllowedClausesResponse struct {
+	Organization     string   `json:"organization"`      // The organization/steward
+	Requester        string   `json:"requester"`         // The user/requester
+	RequestTypes     []string `json:"request_types"`     // Allowed request types
+	DataSets         []string `json:"data_sets"`         // Allowed datasets
+	Archetypes       []string `json:"archetypes"`        // Allowed archetypes
+	ComputeProviders []string `json:"compute_providers"` // Allowed compute providers
+}
+
+// BatchAllowedClausesResponse contains the allowed clauses for each requested pair,
+// in request order (duplicate pairs are returned once).
+type BatchAllowedClausesResponse struct {
+	Results []*AllAllowedClausesResponse `json:"results"` // Allowed clauses per (organization, requester) pair
+}
+
+// ValidationResponse represents the response from validating a request.
+type ValidationResponse struct {
+	Allowed         bool                `json:"allowed"`                    // Whether the request is permitted
+	ReasonCode      ReasonCode          `json:"reason_code"`                // Stable machine-readable classification (see ReasonCode)
+	Reason          string              `json:"reason,omitempty"`           // Explanation for the decision
+	ErrorCategory   ErrorCategory       `json:"error_category,omitempty"`   // With reason code ERROR: what kind of error the reasoner reported
+	Organization    string              `json:"organization"`               // The organization checked
+	Requester       string              `json:"requester"`                  // The requester checked
+	RequestType     string              `json:"request_type,omitempty"`     // The request type checked
+	DataSet         string              `json:"data_set,omitempty"`         // The dataset checked
+	Archetype       string              `json:"archetype,omitempty"`        // The archetype checked
+	ComputeProvider string              `json:"compute_provider,omitempty"` // The compute provider checked
+	Duties          []Duty              `json:"duties,omitempty"`           // When allowed: duties the request would create
+	Suggestions     map[string][]string `json:"suggestions,omitempty"`      // On denial with ?suggest=true: allowed values per failing clause type
+	DebugResponse   string              `json:"debug_response,omitempty"`   // DEBUG: Raw response from the reasoner (temporary)
+}
+
+// JobStatus is the state of an asynchronous validation job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // Waiting for a worker
+	JobRunning   JobStatus = "running"   // Being validated
+	JobSucceeded JobStatus = "succeeded" // Done; the result is available
+	JobFailed    JobStatus = "failed"    // Done; the validation returned an error
+)
+
+// ValidationJobResponse describes an asynchronous validation job
+// (POST /policy-enforcer/validate?async=true).
+type ValidationJobResponse struct {
+	JobID      string              `json:"job_id"`                // ID to poll with GET /policy-enforcer/jobs/:id
+	Status     JobStatus           `json:"status"`                // pending, running, succeeded or failed
+	CreatedAt  time.Time           `json:"created_at"`            // When the job was submitted
+	FinishedAt *time.Time          `json:"finished_at,omitempty"` // When the job finished
+	Result     *ValidationResponse `json:"result,omitempty"`      // The validation result, once succeeded
+	Error      *ErrorResponse      `json:"error,omitempty"`       // Why the validation failed, once failed
+}
+
+// Done reports whether the job has finished.
+func (j *ValidationJobResponse) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// QuickCheckResponse represents the approximate result of comparing a request
+// against the allowed clauses. It is a heuristic: unlike ValidationResponse it does
+// not account for duties, violations or the combination of clauses in the agreement.
+type QuickCheckResponse struct {
+	Allowed         bool     `json:"allowed"`                  // Whether every field is in its allowed set
+	Approximate     bool     `json:"approximate"`              // Always true; use /validate for an authoritative answer
+	DeniedClauses   []string `json:"denied_clauses,omitempty"` // Clause types whose value is not allowed
+	Organization    string   `json:"organization"`             // The organization checked
+	Requester       string   `json:"requester"`                // The requester checked
+	RequestType     string   `json:"request_type"`             // The request type checked
+	DataSet         string   `json:"data_set"`                 // The dataset checked
+	Archetype       string   `json:"archetype"`                // The archetype checked
+	ComputeProvider string   `json:"compute_provider"`         // The compute provider checked
+}
+
+// RequestersAllowedResponse lists the requesters allowed a specific clause value.
+type RequestersAllowedResponse struct {
+	Organization string   `json:"organization"` // The organization/steward
+	Type         string   `json:"type"`         // The clause type (e.g., "archetype")
+	Value        string   `json:"value"`        // The clause value (e.g., "computeToData")
+	Requesters   []string `json:"requesters"`   // Requesters allowed the clause value
+}
+
+// ClauseAllowedResponse represents whether a single clause value is allowed.
+type ClauseAllowedResponse struct {
+	Allowed      bool   `json:"allowed"`      // Whether the requester is allowed the clause value
+	Organization string `json:"organization"` // The organization/steward
+	Requester    string `json:"requester"`    // The user/requester
+	Type         string `json:"type"`         // The clause type (e.g., "archetype")
+	Value        string `json:"value"`        // The clause value checked
+}
+
+// RevokeClauseResponse represents the response from revoking an allowed clause.
+type RevokeClauseResponse struct {
+	Organization string `json:"organization"` // The organization/steward
+	Requester    string `json:"requester"`    // The user/requester
+	Type         string `json:"type"`         // The clause type (e.g., "archetype")
+	Value        string `json:"value"`        // The revoked value
+	Revoked      bool   `json:"revoked"`      // Whether the clause no longer holds after revocation
+}
+
+// SimulateChangeResponse represents the outcome of a simulated policy change.
+type SimulateChangeResponse struct {
+	Validation     *ValidationResponse `json:"validation,omitempty"`     // Validation result with the changes applied; omitted if Error is set
+	PhrasesApplied int                 `json:"phrases_applied"`          // Number of phrases applied before validating (or failing)
+	Error          string              `json:"error,omitempty"`          // Why applying a phrase or validating failed
+	RolledBack     bool                `json:"rolled_back"`              // Whether the state from before the simulation was restored
+	Rollback       string              `json:"rollback"`                 // How the changes were undone: restored, restarted or failed
+	RollbackError  string              `json:"rollback_error,omitempty"` // Why restoring (and restarting, if attempted) failed
+}
+
+// ExecuteActResponse reports the state changes made by an executed act.
+type ExecuteActResponse struct {
+	Phrase          string       `json:"phrase"`           // The phrase sent to the reasoner
+	CreatedFacts    []Fact       `json:"created_facts"`    // Facts created by the act
+	TerminatedFacts []Fact       `json:"terminated_facts"` // Facts terminated by the act
+	Violations      []Diagnostic `json:"violations"`       // Violations caused by the act, e.g. because it was not enabled
+}
+
+// EnabledAct is an act that a principal could currently perform, with the
+// arguments it would be performed with.
+type EnabledAct struct {
+	Act       string         `json:"act"`       // The act (e.g., "register-requester")
+	Arguments []FactArgument `json:"arguments"` // The act's arguments, in order
+}
+
+// Sources of an EnabledActsResponse.
+const (
+	// EnabledActsFromTransitions means the reasoner enumerated all enabled acts.
+	EnabledActsFromTransitions = "transitions"
+	// EnabledActsFromKnownActs means only the configured known acts were checked,
+	// because the reasoner could not enumerate enabled acts.
+	EnabledActsFromKnownActs = "known-acts"
+)
+
+// EnabledActsResponse lists the acts a requester could currently perform at an organization.
+type EnabledActsResponse struct {
+	Organization string       `json:"organization"` // The organization/steward
+	Requester    string       `json:"requester"`    // The user/requester
+	Acts         []EnabledAct `json:"acts"`         // Enabled acts involving both
+	Count        int          `json:"count"`        // Number of enabled acts
+	Source       string       `json:"source"`       // EnabledActsFromTransitions or EnabledActsFromKnownActs
+}
+
+// ClauseChange is an allowed clause that was granted or revoked.
+type ClauseChange struct {
+	Organization string `json:"organization"` // The organization/steward
+	Requester    string `json:"requester"`    // The user/requester
+	Type         string `json:"type"`         // The clause type (e.g., "archetype")
+	Value        string `json:"value"`        // The clause value
+}
+
+// ChangesSinceResponse lists the allowed clauses granted and revoked since a checkpoint.
+type ChangesSinceResponse struct {
+	Checkpoint     string         `json:"checkpoint"`      // The checkpoint compared against
+	CheckpointedAt time.Time      `json:"checkpointed_at"` // When the checkpoint was created
+	Added          []ClauseChange `json:"added"`           // Clauses granted since the checkpoint
+	Removed        []ClauseChange `json:"removed"`         // Clauses revoked since the checkpoint
+}
+
+// PolicyStatsResponse summarizes the size and shape of the policy: how many facts
+// hold, of which types, and how many organizations, requesters and grants they name.
+type PolicyStatsResponse struct {
+	TotalFacts           int            `json:"total_facts"`             // Facts that currently hold
+	FactTypes            map[string]int `json:"fact_types"`              // Number of facts per fact type
+	Organizations        int            `json:"organizations"`           // Distinct organizations named in facts
+	Requesters           int            `json:"requesters"`              // Distinct requesters named in facts
+	AllowedClauses       int            `json:"allowed_clauses"`         // Allowed-clause grants
+	AllowedClausesByType map[string]int `json:"allowed_clauses_by_type"` // Allowed-clause grants per clause type
+}
+
+// QueryFactsResponse lists the facts matching a FactQuery.
+type QueryFactsResponse struct {
+	Facts []Fact `json:"facts"` // Matching facts, in the reasoner's order
+	Count int    `json:"count"` // Number of matching facts
+}
+
+// ImportOrgPolicyResponse represents the response from importing an organization policy.
+type ImportOrgPolicyResponse struct {
+	Organization  string `json:"organization"`   // The organization/steward
+	FactsAsserted int    `json:"facts_asserted"` // Number of grants and availabilities asserted
+}
+
+// ReasonerInfoResponse provides information about the active reasoner.
+type ReasonerInfoResponse struct {
+	Name         string               `json:"name"`         // Name/type of the reasoner (e.g., "eflint", "symboleo")
+	Running      bool                 `json:"running"`      // Whether the reasoner is operational
+	ReadOnly     bool                 `json:"read_only"`    // Whether state changes are refused (read replica)
+	Capabilities ReasonerCapabilities `json:"capabilities"` // Optional features the reasoner supports
+}
+
+// ReasonerCapabilities lists the optional features of the active reasoner, so
+// clients can feature-detect instead of relying on 501 Not Implemented responses.
+type ReasonerCapabilities struct {
+	Availability        bool `json:"availability"`         // Available archetypes and compute providers
+	StateManagement     bool `json:"state_management"`     // Exporting and importing reasoner state
+	FactsIntrospection  bool `json:"facts_introspection"`  // Listing the raw facts that currently hold
+	Revocation          bool `json:"revocation"`           // Revoking allowed clauses
+	RequesterLookup     bool `json:"requester_lookup"`     // Finding requesters allowed a clause
+	PolicyExport        bool `json:"policy_export"`        // Exporting and importing organization policies
+	BatchQueries        bool `json:"batch_queries"`        // Allowed clauses for many requesters in one backend query
+	ChangeNotifications bool `json:"change_notifications"` // Reporting state changes (enables cache invalidation)
+	ChangeSimulation    bool `json:"change_simulation"`    // Validating requests against hypothetical changes
+	ActExecution        bool `json:"act_execution"`        // Executing acts, which changes the state
+	EnabledActs         bool `json:"enabled_acts"`         // Listing the acts a requester could currently perform
+	ChangeTracking      bool `json:"change_tracking"`      // Listing the allowed clauses changed since a checkpoint
+	ClauseTypes         bool `json:"clause_types"`         // Querying clause types by name, including configured ones
+}
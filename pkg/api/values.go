@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueKind is the kind of value of a FactArgument.
+type ValueKind string
+
+// Value kinds. Strings have no kind, so responses for string-only models are
+// unchanged.
+const (
+	// KindString is a string value, such as an organization name.
+	KindString ValueKind = ""
+	// KindInt is an integer value; Value holds it in decimal.
+	KindInt ValueKind = "int"
+	// KindComposite is an instance of a composite type; Arguments holds its
+	// arguments and Value its eFLINT literal, e.g. `agreement("VU", 3)`.
+	KindComposite ValueKind = "composite"
+)
+
+// factArgumentJSON is the JSON form of a FactArgument. The value is encoded as
+// the eFLINT server reports it: a JSON string, an integer, or the array of a
+// composite's arguments. Kind is informational and ignored when decoding, so the
+// kind of a value always follows from its JSON type.
+type factArgumentJSON struct {
+	FactType string          `json:"fact-type"`
+	Value    json.RawMessage `json:"value"`
+	Kind     ValueKind       `json:"kind,omitempty"`
+}
+
+// MarshalJSON encodes the argument with a value of its kind's JSON type.
+func (a FactArgument) MarshalJSON() ([]byte, error) {
+	var value []byte
+	var err error
+	switch a.Kind {
+	case KindString:
+		value, err = json.Marshal(a.Value)
+	case KindInt:
+		if _, err := strconv.ParseInt(a.Value, 10, 64); err != nil {
+			return nil, fmt.Errorf("value of %q: %q is not an integer", a.FactType, a.Value)
+		}
+		value = []byte(a.Value)
+	case KindComposite:
+		args := a.Arguments
+		if args == nil {
+			args = []FactArgument{}
+		}
+		value, err = json.Marshal(args)
+	default:
+		return nil, fmt.Errorf("value of %q: unknown kind %q", a.FactType, a.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(factArgumentJSON{FactType: a.FactType, Value: value, Kind: a.Kind})
+}
+
+// UnmarshalJSON decodes an argument as reported by the eFLINT server or encoded by
+// MarshalJSON. The kind follows from the JSON type of the value alone: a string, an
+// integer, or an array or object holding the argument(s) of a composite value.
+func (a *FactArgument) UnmarshalJSON(data []byte) error {
+	var raw factArgumentJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = FactArgument{FactType: raw.FactType}
+
+	value := bytes.TrimSpace(raw.Value)
+	switch {
+	case len(value) == 0 || bytes.Equal(value, []byte("null")):
+	case value[0] == '"':
+		return json.Unmarshal(value, &a.Value)
+	case value[0] == '[':
+		if err := json.Unmarshal(value, &a.Arguments); err != nil {
+			return fmt.Errorf("composite value of %q: %w", a.FactType, err)
+		}
+		a.setComposite()
+	case value[0] == '{':
+		var arg FactArgument
+		if err := json.Unmarshal(value, &arg); err != nil {
+			return fmt.Errorf("composite value of %q: %w", a.FactType, err)
+		}
+		a.Arguments = []FactArgument{arg}
+		a.setComposite()
+	default:
+		n, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("value of %q: %s is neither a string, an integer nor a composite", a.FactType, value)
+		}
+		a.Value, a.Kind = strconv.FormatInt(n, 10), KindInt
+	}
+	return nil
+}
+
+// setComposite marks a as a composite value and derives its text from Arguments.
+func (a *FactArgument) setComposite() {
+	a.Kind = KindComposite
+	if a.Arguments == nil {
+		a.Arguments = []FactArgument{}
+	}
+	a.Value = a.text()
+}
+
+// text returns the value as it reads in eFLINT: strings quoted, integers in
+// decimal and composites as their type applied to their arguments. It is for
+// display and comparison only; phrases are built by the eflint package, which
+// validates the value first.
+func (a FactArgument) text() string {
+	switch a.Kind {
+	case KindInt:
+		return a.Value
+	case KindComposite:
+		texts := make([]string, len(a.Arguments))
+		for i, arg := range a.Arguments {
+			texts[i] = arg.text()
+		}
+		return a.FactType + "(" + strings.Join(texts, ", ") + ")"
+	}
+	escaped := strings.ReplaceAll(a.Value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFactArgumentUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want FactArgument
+	}{
+		{
+			name: "string",
+			json: `{"fact-type": "organization", "value": "VU"}`,
+			want: FactArgument{FactType: "organization", Value: "VU"},
+		},
+		{
+			name: "integer",
+			json: `{"fact-type": "budget", "value": 100}`,
+			want: FactArgument{FactType: "budget", Value: "100", Kind: KindInt},
+		},
+		{
+			name: "negative integer",
+			json: `{"fact-type": "delta", "value": -3}`,
+			want: FactArgument{FactType: "delta", Value: "-3", Kind: KindInt},
+		},
+		{
+			name: "composite array",
+			json: `{"fact-type": "agreement", "value": [{"fact-type": "organization", "value": "VU"}, {"fact-type": "version", "value": 3}]}`,
+			want: FactArgument{FactType: "agreement", Value: `agreement("VU", 3)`, Kind: KindComposite, Arguments: []FactArgument{
+				{FactType: "organization", Value: "VU"},
+				{FactType: "version", Value: "3", Kind: KindInt},
+			}},
+		},
+		{
+			name: "composite object",
+			json: `{"fact-type": "owner", "value": {"fact-type": "organization", "value": "VU"}}`,
+			want: FactArgument{FactType: "owner", Value: `owner("VU")`, Kind: KindComposite, Arguments: []FactArgument{
+				{FactType: "organization", Value: "VU"},
+			}},
+		},
+		{
+			name: "nested composite",
+			json: `{"fact-type": "a", "value": [{"fact-type": "b", "value": [{"fact-type": "c", "value": 1}]}]}`,
+			want: FactArgument{FactType: "a", Value: "a(b(1))", Kind: KindComposite, Arguments: []FactArgument{
+				{FactType: "b", Value: "b(1)", Kind: KindComposite, Arguments: []FactArgument{
+					{FactType: "c", Value: "1", Kind: KindInt},
+				}},
+			}},
+		},
+		{
+			name: "kind of a string value is ignored",
+			json: `{"fact-type": "x", "value": "1). +x(\"a\"", "kind": "int"}`,
+			want: FactArgument{FactType: "x", Value: `1). +x("a"`},
+		},
+		{
+			name: "composite kind of a string value is ignored",
+			json: `{"fact-type": "x", "value": "evil", "kind": "composite", "arguments": [{"fact-type": "y", "value": "z"}]}`,
+			want: FactArgument{FactType: "x", Value: "evil"},
+		},
+		{
+			name: "missing value",
+			json: `{"fact-type": "x"}`,
+			want: FactArgument{FactType: "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FactArgument
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFactArgumentUnmarshalRejectsOtherValues(t *testing.T) {
+	for _, data := range []string{
+		`{"fact-type": "x", "value": 1.5}`,
+		`{"fact-type": "x", "value": 1e3}`,
+		`{"fact-type": "x", "value": true}`,
+		`{"fact-type": "x", "value": 99999999999999999999}`,
+		`{"fact-type": "x", "value": [1]}`,
+	} {
+		var got FactArgument
+		if err := json.Unmarshal([]byte(data), &got); err == nil {
+			t.Errorf("Unmarshal(%s) = %#v, want an error", data, got)
+		}
+	}
+}
+
+func TestFactArgumentRoundTrip(t *testing.T) {
+	fact := Fact{FactType: "budget", Arguments: []FactArgument{
+		{FactType: "organization", Value: `V"U`},
+		{FactType: "amount", Value: "100", Kind: KindInt},
+		{FactType: "agreement", Value: `agreement("VU", 3)`, Kind: KindComposite, Arguments: []FactArgument{
+			{FactType: "organization", Value: "VU"},
+			{FactType: "version", Value: "3", Kind: KindInt},
+		}},
+	}}
+
+	data, err := json.Marshal(fact)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got Fact
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", data, err)
+	}
+	if !reflect.DeepEqual(got, fact) {
+		t.Errorf("round trip of %s = %#v, want %#v", data, got, fact)
+	}
+}
+
+func TestFactArgumentMarshalTypesValues(t *testing.T) {
+	data, err := json.Marshal(FactArgument{FactType: "amount", Value: "100", Kind: KindInt})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"fact-type":"amount","value":100,"kind":"int"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	if _, err := json.Marshal(FactArgument{FactType: "amount", Value: "1). +x(", Kind: KindInt}); err == nil {
+		t.Error("Marshal() of a non-integer int value succeeded")
+	}
+}
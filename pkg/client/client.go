@@ -0,0 +1,123 @@
+// Package client is a typed Go client for the DYNAMOS policy enforcer API, for
+// sibling services that would otherwise hand-roll HTTP calls. Methods mirror the
+// endpoints of /policy-enforcer and /eflint, take and return the types of pkg/api,
+// and turn error responses into an *APIError.
+//
+//	c, err := client.New("http://policy-enforcer:8080", client.Options{Timeout: 5 * time.Second})
+//	result, err := c.ValidateRequest(ctx, api.ValidateRequestParams{Organization: "VU", ...}, client.ValidateOptions{})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Header names understood by the server.
+const (
+	timeoutHeader        = "X-Timeout"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Options configures a Client. The zero value is usable.
+type Options struct {
+	HTTPClient  *http.Client      // Used for all requests; defaults to a client with Timeout
+	Timeout     time.Duration     // Limit for each request when HTTPClient is not set (0 = no limit)
+	BearerToken string            // Sent as Authorization: Bearer when the server has auth enabled
+	Headers     map[string]string // Sent with every request, e.g. X-Principal behind a trusted proxy
+}
+
+// Client calls the policy enforcer API. It is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	options Options
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: opts.Timeout}
+	}
+	return &Client{baseURL: u, http: httpClient, options: opts}, nil
+}
+
+// get sends a GET request with the given query parameters and decodes the response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, nil, out)
+}
+
+// post sends a POST request with a JSON body and decodes the response into out.
+func (c *Client) post(ctx context.Context, path string, body, out any) error {
+	return c.do(ctx, http.MethodPost, path, nil, nil, body, out)
+}
+
+// do sends a request and decodes a 2xx JSON response into out (if not nil). Other
+// statuses are returned as an *APIError. If ctx has a deadline, the remaining time
+// is also sent in X-Timeout, so the server gives up when the client does.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out any) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	for name, value := range c.options.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.options.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.options.BearerToken)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining > 0 {
+			req.Header.Set(timeoutHeader, remaining.Round(time.Millisecond).String())
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// eflintPath is the prefix of the eFLINT instance API.
+const eflintPath = "/eflint"
+
+// Status returns the state of the eFLINT instance.
+// GET /eflint/status
+func (c *Client) Status(ctx context.Context) (*api.EflintStatusResponse, error) {
+	var status api.EflintStatusResponse
+	if err := c.get(ctx, eflintPath+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StartInstance starts the eFLINT instance. If one is already running and
+// req.Force is not set, the error matches ErrConflict.
+// POST /eflint/start
+func (c *Client) StartInstance(ctx context.Context, req api.EflintStartRequest) (*api.EflintStatusResponse, error) {
+	return c.instanceAction(ctx, "/start", req)
+}
+
+// StopInstance stops the eFLINT instance.
+// POST /eflint/stop
+func (c *Client) StopInstance(ctx context.Context) (*api.EflintStatusResponse, error) {
+	return c.instanceAction(ctx, "/stop", nil)
+}
+
+// ResetInstance restarts the eFLINT instance with its model, discarding the state.
+// POST /eflint/reset
+func (c *Client) ResetInstance(ctx context.Context) (*api.EflintStatusResponse, error) {
+	return c.instanceAction(ctx, "/reset", nil)
+}
+
+// instanceAction posts to an instance management endpoint.
+func (c *Client) instanceAction(ctx context.Context, path string, body any) (*api.EflintStatusResponse, error) {
+	var status api.EflintStatusResponse
+	if err := c.post(ctx, eflintPath+path, body, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SendCommand sends a raw eFLINT command, e.g. map[string]any{"command": "status"},
+// and returns the server's response.
+// POST /eflint/command
+func (c *Client) SendCommand(ctx context.Context, command any) (json.RawMessage, error) {
+	var result struct {
+		Response json.RawMessage `json:"response"`
+	}
+	if err := c.post(ctx, eflintPath+"/command", map[string]any{"command": command}, &result); err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Errors matching the status of an APIError, for use with errors.Is.
+var (
+	ErrBadRequest     = errors.New("bad request")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrForbidden      = errors.New("forbidden")
+	ErrNotFound       = errors.New("not found")
+	ErrConflict       = errors.New("conflict")
+	ErrUnprocessable  = errors.New("unprocessable")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrNotImplemented = errors.New("not implemented")
+	ErrBadGateway     = errors.New("reasoner failed")
+	ErrUnavailable    = errors.New("unavailable")
+	ErrTimeout        = errors.New("timed out")
+	ErrServer         = errors.New("server error")
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// APIError is a non-2xx response from the server. errors.Is matches it against
+// the Err* variable for its status, e.g. errors.Is(err, client.ErrNotFound).
+type APIError struct {
+	StatusCode int      // HTTP status code
+	Code       string   // Stable machine-readable code, e.g. "reasoner_not_running" (empty for /eflint errors)
+	Message    string   // Human-readable message
+	Fields     []string // Offending request fields, for validation errors
+}
+
+// Error returns the status and message.
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("policy enforcer: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("policy enforcer: %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the Err* variable matching the status, if any.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnprocessableEntity:
+		return ErrUnprocessable
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusNotImplemented:
+		return ErrNotImplemented
+	case http.StatusBadGateway:
+		return ErrBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	if e.StatusCode >= 500 {
+		return ErrServer
+	}
+	return nil
+}
+
+// newAPIError builds an APIError from an error response. Both the policy enforcer
+// format ({"error", "code", "fields"}) and the plain {"error"} of /eflint are read;
+// other bodies are used as the message.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var decoded struct {
+		Error  string   `json:"error"`
+		Code   string   `json:"code"`
+		Fields []string `json:"fields"`
+	}
+	switch {
+	case json.Unmarshal(body, &decoded) == nil && decoded.Error != "":
+		apiErr.Message, apiErr.Code, apiErr.Fields = decoded.Error, decoded.Code, decoded.Fields
+	case len(body) > 0:
+		apiErr.Message = string(body)
+	}
+	return apiErr
+}
@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/nielsarts/dynamos-policy-enforcer/pkg/api"
+)
+
+// policyPath is the prefix of the policy enforcer API.
+const policyPath = "/policy-enforcer"
+
+// -----------------------------------------------------------------------------
+// Reasoner Info
+// -----------------------------------------------------------------------------
+
+// Info returns the active reasoner and its capabilities.
+// GET /policy-enforcer/info
+func (c *Client) Info(ctx context.Context) (*api.ReasonerInfoResponse, error) {
+	var info api.ReasonerInfoResponse
+	if err := c.get(ctx, policyPath+"/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// -----------------------------------------------------------------------------
+// Allowed Clauses
+// -----------------------------------------------------------------------------
+
+// GetAllowedRequestTypes returns the request types allowed for a requester at an organization.
+func (c *Client) GetAllowedRequestTypes(ctx context.Context, organization, requester string) (*api.AllowedClausesResponse, error) {
+	return c.getAllowed(ctx, "/allowed-request-types", organization, requester)
+}
+
+// GetAllowedDataSets returns the datasets allowed for a requester at an organization.
+func (c *Client) GetAllowedDataSets(ctx context.Context, organization, requester string) (*api.AllowedClausesResponse, error) {
+	return c.getAllowed(ctx, "/allowed-data-sets", organization, requester)
+}
+
+// GetAllowedArchetypes returns the archetypes allowed for a requester at an organization.
+func (c *Client) GetAllowedArchetypes(ctx context.Context, organization, requester string) (*api.AllowedClausesResponse, error) {
+	return c.getAllowed(ctx, "/allowed-archetypes", organization, requester)
+}
+
+// GetAllowedComputeProviders returns the compute providers allowed for a requester at an organization.
+func (c *Client) GetAllowedComputeProviders(ctx context.Context, organization, requester string) (*api.AllowedClausesResponse, error) {
+	return c.getAllowed(ctx, "/allowed-compute-providers", organization, requester)
+}
+
+// GetAllowedOfType returns the values of a clause type, built in or configured, allowed
+// for a requester at an organization.
+// GET /policy-enforcer/allowed
+func (c *Client) GetAllowedOfType(ctx context.Context, organization, requester, clauseType string) (*api.AllowedClausesResponse, error) {
+	query := orgRequester(organization, requester)
+	query.Set("type", clauseType)
+
+	var result api.AllowedClausesResponse
+	if err := c.get(ctx, policyPath+"/allowed", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// getAllowed queries one allowed-clauses endpoint.
+func (c *Client) getAllowed(ctx context.Context, path, organization, requester string) (*api.AllowedClausesResponse, error) {
+	var result api.AllowedClausesResponse
+	if err := c.get(ctx, policyPath+path, orgRequester(organization, requester), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAllAllowedClauses returns every allowed clause of a requester at an organization.
+// GET /policy-enforcer/allowed-clauses
+func (c *Client) GetAllAllowedClauses(ctx context.Context, organization, requester string) (*api.AllAllowedClausesResponse, error) {
+	var result api.AllAllowedClausesResponse
+	if err := c.get(ctx, policyPath+"/allowed-clauses", orgRequester(organization, requester), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IsClauseAllowed reports whether a requester is allowed one clause value, e.g.
+// clauseType "archetype" and value "computeToData".
+// GET /policy-enforcer/is-allowed
+func (c *Client) IsClauseAllowed(ctx context.Context, organization, requester, clauseType, value string) (*api.ClauseAllowedResponse, error) {
+	query := orgRequester(organization, requester)
+	query.Set("type", clauseType)
+	query.Set("value", value)
+
+	var result api.ClauseAllowedResponse
+	if err := c.get(ctx, policyPath+"/is-allowed", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeAllowedClause revokes one allowed clause value of a requester.
+// DELETE /policy-enforcer/allowed-clauses
+func (c *Client) RevokeAllowedClause(ctx context.Context, organization, requester, clauseType, value string) (*api.RevokeClauseResponse, error) {
+	query := orgRequester(organization, requester)
+	query.Set("type", clauseType)
+	query.Set("value", value)
+
+	var result api.RevokeClauseResponse
+	if err := c.do(ctx, http.MethodDelete, policyPath+"/allowed-clauses", query, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetEnabledActs returns the acts a requester could currently perform at an organization.
+// GET /policy-enforcer/enabled-acts
+func (c *Client) GetEnabledActs(ctx context.Context, organization, requester string) (*api.EnabledActsResponse, error) {
+	var result api.EnabledActsResponse
+	if err := c.get(ctx, policyPath+"/enabled-acts", orgRequester(organization, requester), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRequestersAllowed returns the requesters at an organization allowed a clause value.
+// GET /policy-enforcer/who-can
+func (c *Client) GetRequestersAllowed(ctx context.Context, organization, clauseType, value string) (*api.RequestersAllowedResponse, error) {
+	query := url.Values{"organization": {organization}, "type": {clauseType}, "value": {value}}
+
+	var result api.RequestersAllowedResponse
+	if err := c.get(ctx, policyPath+"/who-can", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// -----------------------------------------------------------------------------
+// Request Validation
+// -----------------------------------------------------------------------------
+
+// ValidateOptions tunes a validation.
+type ValidateOptions struct {
+	Suggest        bool   // On denial, list the allowed values of every failing clause type
+	IdempotencyKey string // Retries with the same key and body return the first result
+}
+
+// ValidateRequest checks whether a data request is allowed by the policy. A denied
+// request is not an error: check Allowed and ReasonCode of the response.
+// POST /policy-enforcer/validate
+func (c *Client) ValidateRequest(ctx context.Context, req api.ValidateRequestParams, opts ValidateOptions) (*api.ValidationResponse, error) {
+	var result api.ValidationResponse
+	if err := c.validate(ctx, req, opts, false, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubmitValidation queues a validation on the server and returns the job to poll
+// with GetValidationJob.
+// POST /policy-enforcer/validate?async=true
+func (c *Client) SubmitValidation(ctx context.Context, req api.ValidateRequestParams, opts ValidateOptions) (*api.ValidationJobResponse, error) {
+	var job api.ValidationJobResponse
+	if err := c.validate(ctx, req, opts, true, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// validate posts a validation request.
+func (c *Client) validate(ctx context.Context, req api.ValidateRequestParams, opts ValidateOptions, async bool, out any) error {
+	query := url.Values{}
+	if opts.Suggest {
+		query.Set("suggest", "true")
+	}
+	if async {
+		query.Set("async", "true")
+	} else {
+		// Denials are results, not errors, even where the server answers them with 403
+		query.Set("http_status", "ok")
+	}
+	header := http.Header{}
+	if opts.IdempotencyKey != "" {
+		header.Set(idempotencyKeyHeader, opts.IdempotencyKey)
+	}
+	return c.do(ctx, http.MethodPost, policyPath+"/validate", query, header, req, out)
+}
+
+// GetValidationJob returns an asynchronous validation. Unknown and expired jobs
+// return an error matching ErrNotFound.
+// GET /policy-enforcer/jobs/:id
+func (c *Client) GetValidationJob(ctx context.Context, id string) (*api.ValidationJobResponse, error) {
+	var job api.ValidationJobResponse
+	if err := c.get(ctx, policyPath+"/jobs/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// QuickCheck compares a request against the allowed clauses without consulting
+// the full policy. The answer is approximate.
+// POST /policy-enforcer/quick-check
+func (c *Client) QuickCheck(ctx context.Context, req api.ValidateRequestParams) (*api.QuickCheckResponse, error) {
+	var result api.QuickCheckResponse
+	if err := c.post(ctx, policyPath+"/quick-check", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// -----------------------------------------------------------------------------
+// Availability and Facts
+// -----------------------------------------------------------------------------
+
+// GetAvailableArchetypes returns the archetypes available at an organization.
+// GET /policy-enforcer/available-archetypes
+func (c *Client) GetAvailableArchetypes(ctx context.Context, organization string) ([]string, error) {
+	var result struct {
+		Archetypes []string `json:"archetypes"`
+	}
+	if err := c.get(ctx, policyPath+"/available-archetypes", url.Values{"organization": {organization}}, &result); err != nil {
+		return nil, err
+	}
+	return result.Archetypes, nil
+}
+
+// GetAvailableComputeProviders returns the compute providers available at an organization.
+// GET /policy-enforcer/available-compute-providers
+func (c *Client) GetAvailableComputeProviders(ctx context.Context, organization string) ([]string, error) {
+	var result struct {
+		ComputeProviders []string `json:"compute_providers"`
+	}
+	if err := c.get(ctx, policyPath+"/available-compute-providers", url.Values{"organization": {organization}}, &result); err != nil {
+		return nil, err
+	}
+	return result.ComputeProviders, nil
+}
+
+// ChangesSince returns the allowed clauses granted and revoked since a checkpoint
+// of the eFLINT state API. Unknown checkpoints return an error matching ErrNotFound.
+// GET /policy-enforcer/changes-since
+func (c *Client) ChangesSince(ctx context.Context, checkpoint string) (*api.ChangesSinceResponse, error) {
+	var result api.ChangesSinceResponse
+	if err := c.get(ctx, policyPath+"/changes-since", url.Values{"checkpoint": {checkpoint}}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// QueryFacts returns the facts matching a query.
+// POST /policy-enforcer/query-facts
+func (c *Client) QueryFacts(ctx context.Context, query api.FactQuery) (*api.QueryFactsResponse, error) {
+	var result api.QueryFactsResponse
+	if err := c.post(ctx, policyPath+"/query-facts", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetStats returns aggregate counts of the policy's facts.
+// GET /policy-enforcer/stats
+func (c *Client) GetStats(ctx context.Context) (*api.PolicyStatsResponse, error) {
+	var result api.PolicyStatsResponse
+	if err := c.get(ctx, policyPath+"/stats", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// orgRequester returns the query parameters naming an organization and requester.
+// Empty values are omitted, so the server's defaults apply.
+func orgRequester(organization, requester string) url.Values {
+	query := url.Values{}
+	if organization != "" {
+		query.Set("organization", organization)
+	}
+	if requester != "" {
+		query.Set("requester", requester)
+	}
+	return query
+}
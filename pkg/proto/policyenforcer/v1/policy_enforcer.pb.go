@@ -0,0 +1,735 @@
+// gRPC mirror of the /policy-enforcer REST API for internal DYNAMOS callers.
+// Messages use the same field names and semantics as the JSON types in pkg/api;
+// errors map to gRPC status codes the way apperr maps them to HTTP statuses
+// (400 = INVALID_ARGUMENT, 403 = PERMISSION_DENIED, 501 = UNIMPLEMENTED,
+// 502/503 = UNAVAILABLE, 504 = DEADLINE_EXCEEDED).
+//
+// Served by policyenforcer.GRPCHandler when grpc.enabled is set. After changing
+// this file, regenerate the Go stubs next to it from the repository root:
+//
+//   protoc -I pkg/proto --go_out=pkg/proto --go_opt=paths=source_relative \
+//     --go-grpc_out=pkg/proto --go-grpc_opt=paths=source_relative \
+//     policyenforcer/v1/policy_enforcer.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: policyenforcer/v1/policy_enforcer.proto
+
+package policyenforcerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ValidateRequestParams is the request to validate (api.ValidateRequestParams).
+type ValidateRequestParams struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Organization    string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Requester       string                 `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	RequestType     string                 `protobuf:"bytes,3,opt,name=request_type,json=requestType,proto3" json:"request_type,omitempty"`
+	DataSet         string                 `protobuf:"bytes,4,opt,name=data_set,json=dataSet,proto3" json:"data_set,omitempty"`
+	Archetype       string                 `protobuf:"bytes,5,opt,name=archetype,proto3" json:"archetype,omitempty"`
+	ComputeProvider string                 `protobuf:"bytes,6,opt,name=compute_provider,json=computeProvider,proto3" json:"compute_provider,omitempty"`
+	Suggest         bool                   `protobuf:"varint,7,opt,name=suggest,proto3" json:"suggest,omitempty"` // As ?suggest=true: list allowed values for failing clause types
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ValidateRequestParams) Reset() {
+	*x = ValidateRequestParams{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequestParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequestParams) ProtoMessage() {}
+
+func (x *ValidateRequestParams) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequestParams.ProtoReflect.Descriptor instead.
+func (*ValidateRequestParams) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ValidateRequestParams) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetRequestType() string {
+	if x != nil {
+		return x.RequestType
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetDataSet() string {
+	if x != nil {
+		return x.DataSet
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetArchetype() string {
+	if x != nil {
+		return x.Archetype
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetComputeProvider() string {
+	if x != nil {
+		return x.ComputeProvider
+	}
+	return ""
+}
+
+func (x *ValidateRequestParams) GetSuggest() bool {
+	if x != nil {
+		return x.Suggest
+	}
+	return false
+}
+
+// ValidationResponse is the outcome of a validation (api.ValidationResponse).
+type ValidationResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Allowed         bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	ReasonCode      string                 `protobuf:"bytes,2,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"` // PERMITTED, NOT_PERMITTED, VIOLATION, RESOURCE_UNAVAILABLE or ERROR
+	Reason          string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	ErrorCategory   string                 `protobuf:"bytes,4,opt,name=error_category,json=errorCategory,proto3" json:"error_category,omitempty"` // parse, type or runtime (with reason code ERROR)
+	Organization    string                 `protobuf:"bytes,5,opt,name=organization,proto3" json:"organization,omitempty"`
+	Requester       string                 `protobuf:"bytes,6,opt,name=requester,proto3" json:"requester,omitempty"`
+	RequestType     string                 `protobuf:"bytes,7,opt,name=request_type,json=requestType,proto3" json:"request_type,omitempty"`
+	DataSet         string                 `protobuf:"bytes,8,opt,name=data_set,json=dataSet,proto3" json:"data_set,omitempty"`
+	Archetype       string                 `protobuf:"bytes,9,opt,name=archetype,proto3" json:"archetype,omitempty"`
+	ComputeProvider string                 `protobuf:"bytes,10,opt,name=compute_provider,json=computeProvider,proto3" json:"compute_provider,omitempty"`
+	Suggestions     map[string]*Values     `protobuf:"bytes,11,rep,name=suggestions,proto3" json:"suggestions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ValidationResponse) Reset() {
+	*x = ValidationResponse{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationResponse) ProtoMessage() {}
+
+func (x *ValidationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationResponse.ProtoReflect.Descriptor instead.
+func (*ValidationResponse) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ValidationResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *ValidationResponse) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetErrorCategory() string {
+	if x != nil {
+		return x.ErrorCategory
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetRequestType() string {
+	if x != nil {
+		return x.RequestType
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetDataSet() string {
+	if x != nil {
+		return x.DataSet
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetArchetype() string {
+	if x != nil {
+		return x.Archetype
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetComputeProvider() string {
+	if x != nil {
+		return x.ComputeProvider
+	}
+	return ""
+}
+
+func (x *ValidationResponse) GetSuggestions() map[string]*Values {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+// Values is a list of clause values.
+type Values struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Values) Reset() {
+	*x = Values{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Values) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Values) ProtoMessage() {}
+
+func (x *Values) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Values.ProtoReflect.Descriptor instead.
+func (*Values) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Values) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// OrgRequester identifies a requester at an organization (api.OrgRequester).
+type OrgRequester struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Requester     string                 `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrgRequester) Reset() {
+	*x = OrgRequester{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrgRequester) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrgRequester) ProtoMessage() {}
+
+func (x *OrgRequester) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrgRequester.ProtoReflect.Descriptor instead.
+func (*OrgRequester) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrgRequester) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *OrgRequester) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+// OrganizationRequest names an organization.
+type OrganizationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrganizationRequest) Reset() {
+	*x = OrganizationRequest{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrganizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrganizationRequest) ProtoMessage() {}
+
+func (x *OrganizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrganizationRequest.ProtoReflect.Descriptor instead.
+func (*OrganizationRequest) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrganizationRequest) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+// AllowedClausesResponse lists the allowed values of one clause type (api.AllowedClausesResponse).
+type AllowedClausesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Requester     string                 `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	Values        []string               `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllowedClausesResponse) Reset() {
+	*x = AllowedClausesResponse{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllowedClausesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllowedClausesResponse) ProtoMessage() {}
+
+func (x *AllowedClausesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllowedClausesResponse.ProtoReflect.Descriptor instead.
+func (*AllowedClausesResponse) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AllowedClausesResponse) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *AllowedClausesResponse) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *AllowedClausesResponse) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// AllAllowedClausesResponse lists all allowed clauses (api.AllAllowedClausesResponse).
+type AllAllowedClausesResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Organization     string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Requester        string                 `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	RequestTypes     []string               `protobuf:"bytes,3,rep,name=request_types,json=requestTypes,proto3" json:"request_types,omitempty"`
+	DataSets         []string               `protobuf:"bytes,4,rep,name=data_sets,json=dataSets,proto3" json:"data_sets,omitempty"`
+	Archetypes       []string               `protobuf:"bytes,5,rep,name=archetypes,proto3" json:"archetypes,omitempty"`
+	ComputeProviders []string               `protobuf:"bytes,6,rep,name=compute_providers,json=computeProviders,proto3" json:"compute_providers,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AllAllowedClausesResponse) Reset() {
+	*x = AllAllowedClausesResponse{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllAllowedClausesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllAllowedClausesResponse) ProtoMessage() {}
+
+func (x *AllAllowedClausesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllAllowedClausesResponse.ProtoReflect.Descriptor instead.
+func (*AllAllowedClausesResponse) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AllAllowedClausesResponse) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *AllAllowedClausesResponse) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *AllAllowedClausesResponse) GetRequestTypes() []string {
+	if x != nil {
+		return x.RequestTypes
+	}
+	return nil
+}
+
+func (x *AllAllowedClausesResponse) GetDataSets() []string {
+	if x != nil {
+		return x.DataSets
+	}
+	return nil
+}
+
+func (x *AllAllowedClausesResponse) GetArchetypes() []string {
+	if x != nil {
+		return x.Archetypes
+	}
+	return nil
+}
+
+func (x *AllAllowedClausesResponse) GetComputeProviders() []string {
+	if x != nil {
+		return x.ComputeProviders
+	}
+	return nil
+}
+
+// AvailableResponse lists resources available at an organization.
+type AvailableResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organization  string                 `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	Values        []string               `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvailableResponse) Reset() {
+	*x = AvailableResponse{}
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvailableResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvailableResponse) ProtoMessage() {}
+
+func (x *AvailableResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_policyenforcer_v1_policy_enforcer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvailableResponse.ProtoReflect.Descriptor instead.
+func (*AvailableResponse) Descriptor() ([]byte, []int) {
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AvailableResponse) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *AvailableResponse) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_policyenforcer_v1_policy_enforcer_proto protoreflect.FileDescriptor
+
+const file_policyenforcer_v1_policy_enforcer_proto_rawDesc = "" +
+	"\n" +
+	"'policyenforcer/v1/policy_enforcer.proto\x12\x19dynamos.policyenforcer.v1\"\xfa\x01\n" +
+	"\x15ValidateRequestParams\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\x12\x1c\n" +
+	"\trequester\x18\x02 \x01(\tR\trequester\x12!\n" +
+	"\frequest_type\x18\x03 \x01(\tR\vrequestType\x12\x19\n" +
+	"\bdata_set\x18\x04 \x01(\tR\adataSet\x12\x1c\n" +
+	"\tarchetype\x18\x05 \x01(\tR\tarchetype\x12)\n" +
+	"\x10compute_provider\x18\x06 \x01(\tR\x0fcomputeProvider\x12\x18\n" +
+	"\asuggest\x18\a \x01(\bR\asuggest\"\x9c\x04\n" +
+	"\x12ValidationResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x1f\n" +
+	"\vreason_code\x18\x02 \x01(\tR\n" +
+	"reasonCode\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12%\n" +
+	"\x0eerror_category\x18\x04 \x01(\tR\rerrorCategory\x12\"\n" +
+	"\forganization\x18\x05 \x01(\tR\forganization\x12\x1c\n" +
+	"\trequester\x18\x06 \x01(\tR\trequester\x12!\n" +
+	"\frequest_type\x18\a \x01(\tR\vrequestType\x12\x19\n" +
+	"\bdata_set\x18\b \x01(\tR\adataSet\x12\x1c\n" +
+	"\tarchetype\x18\t \x01(\tR\tarchetype\x12)\n" +
+	"\x10compute_provider\x18\n" +
+	" \x01(\tR\x0fcomputeProvider\x12`\n" +
+	"\vsuggestions\x18\v \x03(\v2>.dynamos.policyenforcer.v1.ValidationResponse.SuggestionsEntryR\vsuggestions\x1aa\n" +
+	"\x10SuggestionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x127\n" +
+	"\x05value\x18\x02 \x01(\v2!.dynamos.policyenforcer.v1.ValuesR\x05value:\x028\x01\" \n" +
+	"\x06Values\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"P\n" +
+	"\fOrgRequester\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\x12\x1c\n" +
+	"\trequester\x18\x02 \x01(\tR\trequester\"9\n" +
+	"\x13OrganizationRequest\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\"r\n" +
+	"\x16AllowedClausesResponse\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\x12\x1c\n" +
+	"\trequester\x18\x02 \x01(\tR\trequester\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\tR\x06values\"\xec\x01\n" +
+	"\x19AllAllowedClausesResponse\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\x12\x1c\n" +
+	"\trequester\x18\x02 \x01(\tR\trequester\x12#\n" +
+	"\rrequest_types\x18\x03 \x03(\tR\frequestTypes\x12\x1b\n" +
+	"\tdata_sets\x18\x04 \x03(\tR\bdataSets\x12\x1e\n" +
+	"\n" +
+	"archetypes\x18\x05 \x03(\tR\n" +
+	"archetypes\x12+\n" +
+	"\x11compute_providers\x18\x06 \x03(\tR\x10computeProviders\"O\n" +
+	"\x11AvailableResponse\x12\"\n" +
+	"\forganization\x18\x01 \x01(\tR\forganization\x12\x16\n" +
+	"\x06values\x18\x02 \x03(\tR\x06values2\xc0\b\n" +
+	"\x0ePolicyEnforcer\x12r\n" +
+	"\x0fValidateRequest\x120.dynamos.policyenforcer.v1.ValidateRequestParams\x1a-.dynamos.policyenforcer.v1.ValidationResponse\x12w\n" +
+	"\x10ValidateRequests\x120.dynamos.policyenforcer.v1.ValidateRequestParams\x1a-.dynamos.policyenforcer.v1.ValidationResponse(\x010\x01\x12u\n" +
+	"\x14GetAllAllowedClauses\x12'.dynamos.policyenforcer.v1.OrgRequester\x1a4.dynamos.policyenforcer.v1.AllAllowedClausesResponse\x12t\n" +
+	"\x16GetAllowedRequestTypes\x12'.dynamos.policyenforcer.v1.OrgRequester\x1a1.dynamos.policyenforcer.v1.AllowedClausesResponse\x12p\n" +
+	"\x12GetAllowedDataSets\x12'.dynamos.policyenforcer.v1.OrgRequester\x1a1.dynamos.policyenforcer.v1.AllowedClausesResponse\x12r\n" +
+	"\x14GetAllowedArchetypes\x12'.dynamos.policyenforcer.v1.OrgRequester\x1a1.dynamos.policyenforcer.v1.AllowedClausesResponse\x12x\n" +
+	"\x1aGetAllowedComputeProviders\x12'.dynamos.policyenforcer.v1.OrgRequester\x1a1.dynamos.policyenforcer.v1.AllowedClausesResponse\x12v\n" +
+	"\x16GetAvailableArchetypes\x12..dynamos.policyenforcer.v1.OrganizationRequest\x1a,.dynamos.policyenforcer.v1.AvailableResponse\x12|\n" +
+	"\x1cGetAvailableComputeProviders\x12..dynamos.policyenforcer.v1.OrganizationRequest\x1a,.dynamos.policyenforcer.v1.AvailableResponseB[ZYgithub.com/nielsarts/dynamos-policy-enforcer/pkg/proto/policyenforcer/v1;policyenforcerv1b\x06proto3"
+
+var (
+	file_policyenforcer_v1_policy_enforcer_proto_rawDescOnce sync.Once
+	file_policyenforcer_v1_policy_enforcer_proto_rawDescData []byte
+)
+
+func file_policyenforcer_v1_policy_enforcer_proto_rawDescGZIP() []byte {
+	file_policyenforcer_v1_policy_enforcer_proto_rawDescOnce.Do(func() {
+		file_policyenforcer_v1_policy_enforcer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_policyenforcer_v1_policy_enforcer_proto_rawDesc), len(file_policyenforcer_v1_policy_enforcer_proto_rawDesc)))
+	})
+	return file_policyenforcer_v1_policy_enforcer_proto_rawDescData
+}
+
+var file_policyenforcer_v1_policy_enforcer_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_policyenforcer_v1_policy_enforcer_proto_goTypes = []any{
+	(*ValidateRequestParams)(nil),     // 0: dynamos.policyenforcer.v1.ValidateRequestParams
+	(*ValidationResponse)(nil),        // 1: dynamos.policyenforcer.v1.ValidationResponse
+	(*Values)(nil),                    // 2: dynamos.policyenforcer.v1.Values
+	(*OrgRequester)(nil),              // 3: dynamos.policyenforcer.v1.OrgRequester
+	(*OrganizationRequest)(nil),       // 4: dynamos.policyenforcer.v1.OrganizationRequest
+	(*AllowedClausesResponse)(nil),    // 5: dynamos.policyenforcer.v1.AllowedClausesResponse
+	(*AllAllowedClausesResponse)(nil), // 6: dynamos.policyenforcer.v1.AllAllowedClausesResponse
+	(*AvailableResponse)(nil),         // 7: dynamos.policyenforcer.v1.AvailableResponse
+	nil,                               // 8: dynamos.policyenforcer.v1.ValidationResponse.SuggestionsEntry
+}
+var file_policyenforcer_v1_policy_enforcer_proto_depIdxs = []int32{
+	8,  // 0: dynamos.policyenforcer.v1.ValidationResponse.suggestions:type_name -> dynamos.policyenforcer.v1.ValidationResponse.SuggestionsEntry
+	2,  // 1: dynamos.policyenforcer.v1.ValidationResponse.SuggestionsEntry.value:type_name -> dynamos.policyenforcer.v1.Values
+	0,  // 2: dynamos.policyenforcer.v1.PolicyEnforcer.ValidateRequest:input_type -> dynamos.policyenforcer.v1.ValidateRequestParams
+	0,  // 3: dynamos.policyenforcer.v1.PolicyEnforcer.ValidateRequests:input_type -> dynamos.policyenforcer.v1.ValidateRequestParams
+	3,  // 4: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllAllowedClauses:input_type -> dynamos.policyenforcer.v1.OrgRequester
+	3,  // 5: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedRequestTypes:input_type -> dynamos.policyenforcer.v1.OrgRequester
+	3,  // 6: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedDataSets:input_type -> dynamos.policyenforcer.v1.OrgRequester
+	3,  // 7: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedArchetypes:input_type -> dynamos.policyenforcer.v1.OrgRequester
+	3,  // 8: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedComputeProviders:input_type -> dynamos.policyenforcer.v1.OrgRequester
+	4,  // 9: dynamos.policyenforcer.v1.PolicyEnforcer.GetAvailableArchetypes:input_type -> dynamos.policyenforcer.v1.OrganizationRequest
+	4,  // 10: dynamos.policyenforcer.v1.PolicyEnforcer.GetAvailableComputeProviders:input_type -> dynamos.policyenforcer.v1.OrganizationRequest
+	1,  // 11: dynamos.policyenforcer.v1.PolicyEnforcer.ValidateRequest:output_type -> dynamos.policyenforcer.v1.ValidationResponse
+	1,  // 12: dynamos.policyenforcer.v1.PolicyEnforcer.ValidateRequests:output_type -> dynamos.policyenforcer.v1.ValidationResponse
+	6,  // 13: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllAllowedClauses:output_type -> dynamos.policyenforcer.v1.AllAllowedClausesResponse
+	5,  // 14: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedRequestTypes:output_type -> dynamos.policyenforcer.v1.AllowedClausesResponse
+	5,  // 15: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedDataSets:output_type -> dynamos.policyenforcer.v1.AllowedClausesResponse
+	5,  // 16: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedArchetypes:output_type -> dynamos.policyenforcer.v1.AllowedClausesResponse
+	5,  // 17: dynamos.policyenforcer.v1.PolicyEnforcer.GetAllowedComputeProviders:output_type -> dynamos.policyenforcer.v1.AllowedClausesResponse
+	7,  // 18: dynamos.policyenforcer.v1.PolicyEnforcer.GetAvailableArchetypes:output_type -> dynamos.policyenforcer.v1.AvailableResponse
+	7,  // 19: dynamos.policyenforcer.v1.PolicyEnforcer.GetAvailableComputeProviders:output_type -> dynamos.policyenforcer.v1.AvailableResponse
+	11, // [11:20] is the sub-list for method output_type
+	2,  // [2:11] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_policyenforcer_v1_policy_enforcer_proto_init() }
+func file_policyenforcer_v1_policy_enforcer_proto_init() {
+	if File_policyenforcer_v1_policy_enforcer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_policyenforcer_v1_policy_enforcer_proto_rawDesc), len(file_policyenforcer_v1_policy_enforcer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_policyenforcer_v1_policy_enforcer_proto_goTypes,
+		DependencyIndexes: file_policyenforcer_v1_policy_enforcer_proto_depIdxs,
+		MessageInfos:      file_policyenforcer_v1_policy_enforcer_proto_msgTypes,
+	}.Build()
+	File_policyenforcer_v1_policy_enforcer_proto = out.File
+	file_policyenforcer_v1_policy_enforcer_proto_goTypes = nil
+	file_policyenforcer_v1_policy_enforcer_proto_depIdxs = nil
+}
@@ -0,0 +1,459 @@
+// gRPC mirror of the /policy-enforcer REST API for internal DYNAMOS callers.
+// Messages use the same field names and semantics as the JSON types in pkg/api;
+// errors map to gRPC status codes the way apperr maps them to HTTP statuses
+// (400 = INVALID_ARGUMENT, 403 = PERMISSION_DENIED, 501 = UNIMPLEMENTED,
+// 502/503 = UNAVAILABLE, 504 = DEADLINE_EXCEEDED).
+//
+// Served by policyenforcer.GRPCHandler when grpc.enabled is set. After changing
+// this file, regenerate the Go stubs next to it from the repository root:
+//
+//   protoc -I pkg/proto --go_out=pkg/proto --go_opt=paths=source_relative \
+//     --go-grpc_out=pkg/proto --go-grpc_opt=paths=source_relative \
+//     policyenforcer/v1/policy_enforcer.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: policyenforcer/v1/policy_enforcer.proto
+
+package policyenforcerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PolicyEnforcer_ValidateRequest_FullMethodName              = "/dynamos.policyenforcer.v1.PolicyEnforcer/ValidateRequest"
+	PolicyEnforcer_ValidateRequests_FullMethodName             = "/dynamos.policyenforcer.v1.PolicyEnforcer/ValidateRequests"
+	PolicyEnforcer_GetAllAllowedClauses_FullMethodName         = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAllAllowedClauses"
+	PolicyEnforcer_GetAllowedRequestTypes_FullMethodName       = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAllowedRequestTypes"
+	PolicyEnforcer_GetAllowedDataSets_FullMethodName           = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAllowedDataSets"
+	PolicyEnforcer_GetAllowedArchetypes_FullMethodName         = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAllowedArchetypes"
+	PolicyEnforcer_GetAllowedComputeProviders_FullMethodName   = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAllowedComputeProviders"
+	PolicyEnforcer_GetAvailableArchetypes_FullMethodName       = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAvailableArchetypes"
+	PolicyEnforcer_GetAvailableComputeProviders_FullMethodName = "/dynamos.policyenforcer.v1.PolicyEnforcer/GetAvailableComputeProviders"
+)
+
+// PolicyEnforcerClient is the client API for PolicyEnforcer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PolicyEnforcer exposes the core Enforcer operations.
+type PolicyEnforcerClient interface {
+	// ValidateRequest checks whether a request is allowed (POST /policy-enforcer/validate).
+	ValidateRequest(ctx context.Context, in *ValidateRequestParams, opts ...grpc.CallOption) (*ValidationResponse, error)
+	// ValidateRequests validates a stream of requests, answering each in order.
+	ValidateRequests(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateRequestParams, ValidationResponse], error)
+	// GetAllAllowedClauses returns all allowed clauses of a requester (GET /policy-enforcer/allowed-clauses).
+	GetAllAllowedClauses(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllAllowedClausesResponse, error)
+	// GetAllowedRequestTypes returns the allowed request types (GET /policy-enforcer/allowed-request-types).
+	GetAllowedRequestTypes(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error)
+	// GetAllowedDataSets returns the allowed datasets (GET /policy-enforcer/allowed-data-sets).
+	GetAllowedDataSets(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error)
+	// GetAllowedArchetypes returns the allowed archetypes (GET /policy-enforcer/allowed-archetypes).
+	GetAllowedArchetypes(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error)
+	// GetAllowedComputeProviders returns the allowed compute providers (GET /policy-enforcer/allowed-compute-providers).
+	GetAllowedComputeProviders(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error)
+	// GetAvailableArchetypes returns the archetypes available at an organization
+	// (GET /policy-enforcer/available-archetypes).
+	GetAvailableArchetypes(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*AvailableResponse, error)
+	// GetAvailableComputeProviders returns the compute providers available at an
+	// organization (GET /policy-enforcer/available-compute-providers).
+	GetAvailableComputeProviders(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*AvailableResponse, error)
+}
+
+type policyEnforcerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPolicyEnforcerClient(cc grpc.ClientConnInterface) PolicyEnforcerClient {
+	return &policyEnforcerClient{cc}
+}
+
+func (c *policyEnforcerClient) ValidateRequest(ctx context.Context, in *ValidateRequestParams, opts ...grpc.CallOption) (*ValidationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidationResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_ValidateRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) ValidateRequests(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateRequestParams, ValidationResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PolicyEnforcer_ServiceDesc.Streams[0], PolicyEnforcer_ValidateRequests_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ValidateRequestParams, ValidationResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PolicyEnforcer_ValidateRequestsClient = grpc.BidiStreamingClient[ValidateRequestParams, ValidationResponse]
+
+func (c *policyEnforcerClient) GetAllAllowedClauses(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllAllowedClausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllAllowedClausesResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAllAllowedClauses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAllowedRequestTypes(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllowedClausesResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAllowedRequestTypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAllowedDataSets(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllowedClausesResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAllowedDataSets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAllowedArchetypes(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllowedClausesResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAllowedArchetypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAllowedComputeProviders(ctx context.Context, in *OrgRequester, opts ...grpc.CallOption) (*AllowedClausesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllowedClausesResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAllowedComputeProviders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAvailableArchetypes(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*AvailableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AvailableResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAvailableArchetypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyEnforcerClient) GetAvailableComputeProviders(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*AvailableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AvailableResponse)
+	err := c.cc.Invoke(ctx, PolicyEnforcer_GetAvailableComputeProviders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PolicyEnforcerServer is the server API for PolicyEnforcer service.
+// All implementations must embed UnimplementedPolicyEnforcerServer
+// for forward compatibility.
+//
+// PolicyEnforcer exposes the core Enforcer operations.
+type PolicyEnforcerServer interface {
+	// ValidateRequest checks whether a request is allowed (POST /policy-enforcer/validate).
+	ValidateRequest(context.Context, *ValidateRequestParams) (*ValidationResponse, error)
+	// ValidateRequests validates a stream of requests, answering each in order.
+	ValidateRequests(grpc.BidiStreamingServer[ValidateRequestParams, ValidationResponse]) error
+	// GetAllAllowedClauses returns all allowed clauses of a requester (GET /policy-enforcer/allowed-clauses).
+	GetAllAllowedClauses(context.Context, *OrgRequester) (*AllAllowedClausesResponse, error)
+	// GetAllowedRequestTypes returns the allowed request types (GET /policy-enforcer/allowed-request-types).
+	GetAllowedRequestTypes(context.Context, *OrgRequester) (*AllowedClausesResponse, error)
+	// GetAllowedDataSets returns the allowed datasets (GET /policy-enforcer/allowed-data-sets).
+	GetAllowedDataSets(context.Context, *OrgRequester) (*AllowedClausesResponse, error)
+	// GetAllowedArchetypes returns the allowed archetypes (GET /policy-enforcer/allowed-archetypes).
+	GetAllowedArchetypes(context.Context, *OrgRequester) (*AllowedClausesResponse, error)
+	// GetAllowedComputeProviders returns the allowed compute providers (GET /policy-enforcer/allowed-compute-providers).
+	GetAllowedComputeProviders(context.Context, *OrgRequester) (*AllowedClausesResponse, error)
+	// GetAvailableArchetypes returns the archetypes available at an organization
+	// (GET /policy-enforcer/available-archetypes).
+	GetAvailableArchetypes(context.Context, *OrganizationRequest) (*AvailableResponse, error)
+	// GetAvailableComputeProviders returns the compute providers available at an
+	// organization (GET /policy-enforcer/available-compute-providers).
+	GetAvailableComputeProviders(context.Context, *OrganizationRequest) (*AvailableResponse, error)
+	mustEmbedUnimplementedPolicyEnforcerServer()
+}
+
+// UnimplementedPolicyEnforcerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPolicyEnforcerServer struct{}
+
+func (UnimplementedPolicyEnforcerServer) ValidateRequest(context.Context, *ValidateRequestParams) (*ValidationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateRequest not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) ValidateRequests(grpc.BidiStreamingServer[ValidateRequestParams, ValidationResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ValidateRequests not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAllAllowedClauses(context.Context, *OrgRequester) (*AllAllowedClausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllAllowedClauses not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAllowedRequestTypes(context.Context, *OrgRequester) (*AllowedClausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllowedRequestTypes not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAllowedDataSets(context.Context, *OrgRequester) (*AllowedClausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllowedDataSets not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAllowedArchetypes(context.Context, *OrgRequester) (*AllowedClausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllowedArchetypes not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAllowedComputeProviders(context.Context, *OrgRequester) (*AllowedClausesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllowedComputeProviders not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAvailableArchetypes(context.Context, *OrganizationRequest) (*AvailableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableArchetypes not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) GetAvailableComputeProviders(context.Context, *OrganizationRequest) (*AvailableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableComputeProviders not implemented")
+}
+func (UnimplementedPolicyEnforcerServer) mustEmbedUnimplementedPolicyEnforcerServer() {}
+func (UnimplementedPolicyEnforcerServer) testEmbeddedByValue()                        {}
+
+// UnsafePolicyEnforcerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PolicyEnforcerServer will
+// result in compilation errors.
+type UnsafePolicyEnforcerServer interface {
+	mustEmbedUnimplementedPolicyEnforcerServer()
+}
+
+func RegisterPolicyEnforcerServer(s grpc.ServiceRegistrar, srv PolicyEnforcerServer) {
+	// If the following call pancis, it indicates UnimplementedPolicyEnforcerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PolicyEnforcer_ServiceDesc, srv)
+}
+
+func _PolicyEnforcer_ValidateRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequestParams)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).ValidateRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_ValidateRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).ValidateRequest(ctx, req.(*ValidateRequestParams))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_ValidateRequests_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PolicyEnforcerServer).ValidateRequests(&grpc.GenericServerStream[ValidateRequestParams, ValidationResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PolicyEnforcer_ValidateRequestsServer = grpc.BidiStreamingServer[ValidateRequestParams, ValidationResponse]
+
+func _PolicyEnforcer_GetAllAllowedClauses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequester)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAllAllowedClauses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAllAllowedClauses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAllAllowedClauses(ctx, req.(*OrgRequester))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAllowedRequestTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequester)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAllowedRequestTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAllowedRequestTypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAllowedRequestTypes(ctx, req.(*OrgRequester))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAllowedDataSets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequester)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAllowedDataSets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAllowedDataSets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAllowedDataSets(ctx, req.(*OrgRequester))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAllowedArchetypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequester)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAllowedArchetypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAllowedArchetypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAllowedArchetypes(ctx, req.(*OrgRequester))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAllowedComputeProviders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequester)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAllowedComputeProviders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAllowedComputeProviders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAllowedComputeProviders(ctx, req.(*OrgRequester))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAvailableArchetypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAvailableArchetypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAvailableArchetypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAvailableArchetypes(ctx, req.(*OrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyEnforcer_GetAvailableComputeProviders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyEnforcerServer).GetAvailableComputeProviders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyEnforcer_GetAvailableComputeProviders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyEnforcerServer).GetAvailableComputeProviders(ctx, req.(*OrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PolicyEnforcer_ServiceDesc is the grpc.ServiceDesc for PolicyEnforcer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PolicyEnforcer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dynamos.policyenforcer.v1.PolicyEnforcer",
+	HandlerType: (*PolicyEnforcerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateRequest",
+			Handler:    _PolicyEnforcer_ValidateRequest_Handler,
+		},
+		{
+			MethodName: "GetAllAllowedClauses",
+			Handler:    _PolicyEnforcer_GetAllAllowedClauses_Handler,
+		},
+		{
+			MethodName: "GetAllowedRequestTypes",
+			Handler:    _PolicyEnforcer_GetAllowedRequestTypes_Handler,
+		},
+		{
+			MethodName: "GetAllowedDataSets",
+			Handler:    _PolicyEnforcer_GetAllowedDataSets_Handler,
+		},
+		{
+			MethodName: "GetAllowedArchetypes",
+			Handler:    _PolicyEnforcer_GetAllowedArchetypes_Handler,
+		},
+		{
+			MethodName: "GetAllowedComputeProviders",
+			Handler:    _PolicyEnforcer_GetAllowedComputeProviders_Handler,
+		},
+		{
+			MethodName: "GetAvailableArchetypes",
+			Handler:    _PolicyEnforcer_GetAvailableArchetypes_Handler,
+		},
+		{
+			MethodName: "GetAvailableComputeProviders",
+			Handler:    _PolicyEnforcer_GetAvailableComputeProviders_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ValidateRequests",
+			Handler:       _PolicyEnforcer_ValidateRequests_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "policyenforcer/v1/policy_enforcer.proto",
+}